@@ -9,8 +9,10 @@ import (
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/auth"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -56,6 +58,17 @@ func main() {
 		log.Printf("Login successful: %s", loginResp.Message)
 		log.Printf("Token: %s", loginResp.Token)
 		token = loginResp.Token
+
+		// Exchange the refresh token for a new token pair
+		refreshResp, err := authClient.Refresh(context.Background(), &auth.RefreshRequest{
+			RefreshToken: loginResp.RefreshToken,
+		})
+		if err != nil {
+			log.Printf("Refresh failed: %v", err)
+		} else {
+			log.Printf("Tokens refreshed, access token expires at %s", refreshResp.ExpiresAt.AsTime())
+			token = refreshResp.Token
+		}
 	}
 
 	// Create authenticated context for subsequent requests
@@ -65,6 +78,11 @@ func main() {
 		ctx = metadata.NewOutgoingContext(ctx, md)
 	}
 
+	// Log the changes made below as they happen
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	defer stopWatching()
+	go watchEvents(watchCtx, eventClient)
+
 	// Create an event
 	log.Println("Creating event...")
 	eventResp, err := eventClient.CreateEvent(ctx, &event.CreateEventRequest{
@@ -75,14 +93,26 @@ func main() {
 	})
 	if err != nil {
 		log.Printf("Create event failed: %v", err)
+		return
+	}
+	log.Printf("Event created: %+v", eventResp.Event)
+	eventID := eventResp.Event.Id
+
+	// Publish the event
+	log.Println("Publishing event...")
+	_, err = eventClient.PublishEvent(ctx, &event.PublishEventRequest{
+		Id: eventID,
+	})
+	if err != nil {
+		log.Printf("Publish event failed: %v", err)
 	} else {
-		log.Printf("Event created: %+v", eventResp.Event)
+		log.Printf("Event published successfully")
 	}
 
 	// Update the event
 	log.Println("Updating event...")
 	_, err = eventClient.UpdateEvent(ctx, &event.UpdateEventRequest{
-		Id:          3, // Use the ID from the created event
+		Id:          eventID,
 		Name:        "Updated gRPC Test Event",
 		Description: "Updated description for Kafka testing",
 		Location:    "Updated Location",
@@ -97,11 +127,50 @@ func main() {
 	// Delete the event
 	log.Println("Deleting event...")
 	_, err = eventClient.DeleteEvent(ctx, &event.DeleteEventRequest{
-		Id: 3,
+		Id: eventID,
 	})
 	if err != nil {
 		log.Printf("Delete event failed: %v", err)
 	} else {
 		log.Printf("Event deleted successfully")
 	}
+
+	// Give the watch time to receive the last changes
+	time.Sleep(2 * time.Second)
+}
+
+// watchEvents logs event changes until ctx is cancelled. When the stream ends
+// with UNAVAILABLE, it watches again from the last cursor received.
+func watchEvents(ctx context.Context, client event.EventServiceClient) {
+	cursor := ""
+	for ctx.Err() == nil {
+		watch, err := client.WatchEvents(ctx, &event.WatchEventsRequest{Cursor: cursor})
+		if err != nil {
+			log.Printf("Watch failed: %v", err)
+			return
+		}
+		for {
+			resp, err := watch.Recv()
+			if status.Code(err) == codes.Unavailable {
+				log.Printf("Watch interrupted, resuming: %v", err)
+				time.Sleep(time.Second)
+				break
+			}
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Printf("Watch ended: %v", err)
+				}
+				return
+			}
+			switch message := resp.Message.(type) {
+			case *event.WatchEventsResponse_Change:
+				cursor = message.Change.Cursor
+				log.Printf("Watched change: %s event %d", message.Change.Action, message.Change.Event.GetId())
+			case *event.WatchEventsResponse_Heartbeat:
+				cursor = message.Heartbeat.Cursor
+			case *event.WatchEventsResponse_Resync:
+				log.Println("Watch could not resume, events should be reloaded")
+			}
+		}
+	}
 }
@@ -0,0 +1,282 @@
+// Package config loads the application settings from a YAML or TOML file,
+// environment variables and command-line flags into a typed Config.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Config holds every setting of the application
+type Config struct {
+	Server   Server
+	Database Database
+	Kafka    Kafka
+	Outbox   Outbox
+	Stream   Stream
+	Webhooks Webhooks
+	Events   Events
+	Auth     Auth
+}
+
+// Server configures the REST and gRPC servers
+type Server struct {
+	RESTAddr        string
+	GRPCAddr        string
+	ShutdownTimeout time.Duration
+}
+
+// Database configures the PostgreSQL connection
+type Database struct {
+	Host     string
+	Port     int
+	User     string
+	Password Secret
+	Name     string
+
+	// AutoMigrate applies pending migrations on startup. Without it the
+	// schema is only changed by the migrate command.
+	AutoMigrate bool
+}
+
+// DSN returns the connection string of the database
+func (d Database) DSN() string {
+	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
+		d.Host, d.Port, d.User, d.Password.Value(), d.Name)
+}
+
+// Kafka configures the events topic, its consumers and the producer
+type Kafka struct {
+	Enabled        bool
+	Brokers        []string
+	Topic          string
+	DLQTopic       string
+	GroupID        string
+	StreamGroupID  string
+	WebhookGroupID string
+	ProducerName   string
+	MessageFormat  string
+}
+
+// Outbox configures the relay of queued event changes
+type Outbox struct {
+	PollInterval time.Duration
+}
+
+// Stream configures the live event stream
+type Stream struct {
+	HistorySize  int
+	ClientBuffer int
+}
+
+// Webhooks configures webhook deliveries
+type Webhooks struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	MaxAttempts  int
+}
+
+// Events configures the jobs maintaining events
+type Events struct {
+	CompletionInterval time.Duration
+}
+
+// Auth configures passwords, access tokens and roles
+type Auth struct {
+	BcryptCost     int
+	AccessTokenTTL time.Duration
+	// SigningKeyFile takes precedence over SigningKey
+	SigningKeyFile string
+	SigningKey     Secret
+	SigningKeyID   string
+	// VerificationKeys are PEM files, "path" or "kid=path"
+	VerificationKeys []string
+	JWTSecret        Secret
+	// EphemeralSigningKey allows signing with a key generated at startup when
+	// none is configured. Tokens then break on every restart and between
+	// replicas, so it is only meant for development.
+	EphemeralSigningKey  bool
+	AdminEmails          []string
+	OrganizerOnlyActions []string
+}
+
+// Default returns the settings used when nothing else is configured
+func Default() *Config {
+	hostname, _ := os.Hostname()
+	return &Config{
+		Server: Server{
+			RESTAddr:        ":8080",
+			GRPCAddr:        "localhost:50051",
+			ShutdownTimeout: 30 * time.Second,
+		},
+		Database: Database{
+			Host:        "localhost",
+			Port:        5432,
+			User:        "postgres",
+			Password:    "postgres",
+			Name:        "eventdb",
+			AutoMigrate: true,
+		},
+		Kafka: Kafka{
+			Enabled:  true,
+			Brokers:  []string{"localhost:9092"},
+			Topic:    "events",
+			DLQTopic: "events.dlq",
+			GroupID:  "event-consumer-group",
+			// Every instance streams every change, so each has its own group
+			StreamGroupID:  "event-stream-" + hostname,
+			WebhookGroupID: "webhook-dispatcher",
+			ProducerName:   "event-api",
+			MessageFormat:  "protobuf",
+		},
+		Outbox:   Outbox{PollInterval: time.Second},
+		Stream:   Stream{HistorySize: 1000, ClientBuffer: 64},
+		Webhooks: Webhooks{PollInterval: time.Second, Timeout: 10 * time.Second, MaxAttempts: 8},
+		Events:   Events{CompletionInterval: time.Minute},
+		Auth: Auth{
+			BcryptCost:     14,
+			AccessTokenTTL: 15 * time.Minute,
+		},
+	}
+}
+
+// setting binds a field of Config to its key in the file, its environment
+// variable and its flag
+type setting struct {
+	key   string // section.name in the file; the flag is -section.name with dashes
+	env   string
+	usage string
+	value flag.Value
+}
+
+// flagName returns the command-line flag of a setting
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.key, "_", "-")
+}
+
+// settings lists the settings of c in the order they are printed
+func (c *Config) settings() []setting {
+	return []setting{
+		{"server.rest_addr", "REST_ADDR", "address of the REST server", stringValue{&c.Server.RESTAddr}},
+		{"server.grpc_addr", "GRPC_ADDR", "address of the gRPC server", stringValue{&c.Server.GRPCAddr}},
+		{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "deadline of a graceful shutdown", durationValue{&c.Server.ShutdownTimeout}},
+
+		{"database.host", "DB_HOST", "PostgreSQL host", stringValue{&c.Database.Host}},
+		{"database.port", "DB_PORT", "PostgreSQL port", intValue{&c.Database.Port}},
+		{"database.user", "DB_USER", "PostgreSQL user", stringValue{&c.Database.User}},
+		{"database.password", "DB_PASSWORD", "PostgreSQL password", secretValue{&c.Database.Password}},
+		{"database.name", "DB_NAME", "PostgreSQL database", stringValue{&c.Database.Name}},
+		{"database.auto_migrate", "DB_AUTO_MIGRATE", "apply pending schema migrations on startup", boolValue{&c.Database.AutoMigrate}},
+
+		{"kafka.enabled", "KAFKA_ENABLED", "use Kafka for event changes; false streams them within the process", boolValue{&c.Kafka.Enabled}},
+		{"kafka.brokers", "KAFKA_BROKERS", "comma-separated Kafka broker addresses", listValue{&c.Kafka.Brokers}},
+		{"kafka.topic", "KAFKA_TOPIC", "topic of event changes", stringValue{&c.Kafka.Topic}},
+		{"kafka.dlq_topic", "KAFKA_DLQ_TOPIC", "dead-letter topic of messages the consumers could not handle", stringValue{&c.Kafka.DLQTopic}},
+		{"kafka.group_id", "KAFKA_GROUP_ID", "consumer group logging event changes", stringValue{&c.Kafka.GroupID}},
+		{"kafka.stream_group_id", "KAFKA_STREAM_GROUP_ID", "consumer group of this instance's live event stream", stringValue{&c.Kafka.StreamGroupID}},
+		{"kafka.webhook_group_id", "KAFKA_WEBHOOK_GROUP_ID", "consumer group queuing changes for webhooks", stringValue{&c.Kafka.WebhookGroupID}},
+		{"kafka.producer_name", "KAFKA_PRODUCER_NAME", "producer name set on published envelopes", stringValue{&c.Kafka.ProducerName}},
+		{"kafka.message_format", "KAFKA_MESSAGE_FORMAT", `format of published messages, "protobuf" or "json"`, stringValue{&c.Kafka.MessageFormat}},
+
+		{"outbox.poll_interval", "OUTBOX_POLL_INTERVAL", "how often queued event changes are relayed", durationValue{&c.Outbox.PollInterval}},
+
+		{"stream.history_size", "STREAM_HISTORY_SIZE", "changes kept for resuming the event stream", intValue{&c.Stream.HistorySize}},
+		{"stream.client_buffer", "STREAM_CLIENT_BUFFER", "changes buffered per stream client before it is disconnected", intValue{&c.Stream.ClientBuffer}},
+
+		{"webhooks.poll_interval", "WEBHOOK_POLL_INTERVAL", "how often queued webhook deliveries are sent", durationValue{&c.Webhooks.PollInterval}},
+		{"webhooks.timeout", "WEBHOOK_TIMEOUT", "timeout of each webhook request", durationValue{&c.Webhooks.Timeout}},
+		{"webhooks.max_attempts", "WEBHOOK_MAX_ATTEMPTS", "attempts before a webhook delivery fails", intValue{&c.Webhooks.MaxAttempts}},
+
+		{"events.completion_interval", "EVENT_COMPLETION_INTERVAL", "how often ended events are marked completed", durationValue{&c.Events.CompletionInterval}},
+
+		{"auth.bcrypt_cost", "BCRYPT_COST", "bcrypt cost of password hashes", intValue{&c.Auth.BcryptCost}},
+		{"auth.access_token_ttl", "ACCESS_TOKEN_TTL", "how long an access token is valid", durationValue{&c.Auth.AccessTokenTTL}},
+		{"auth.jwt_signing_key_file", "JWT_SIGNING_KEY_FILE", "PEM private key file that signs access tokens", stringValue{&c.Auth.SigningKeyFile}},
+		{"auth.jwt_signing_key", "JWT_SIGNING_KEY", "PEM private key that signs access tokens", secretValue{&c.Auth.SigningKey}},
+		{"auth.jwt_signing_key_id", "JWT_SIGNING_KEY_ID", "kid of the signing key", stringValue{&c.Auth.SigningKeyID}},
+		{"auth.jwt_verification_keys", "JWT_VERIFICATION_KEYS", `comma-separated PEM files ("path" or "kid=path") of further verification keys`, listValue{&c.Auth.VerificationKeys}},
+		{"auth.jwt_secret", "JWT_SECRET", "HS256 secret, accepted for verification and signing when no private key is set", secretValue{&c.Auth.JWTSecret}},
+		{"auth.jwt_ephemeral_key", "JWT_EPHEMERAL_KEY", "development only: sign with a key generated at startup when no key is set", boolValue{&c.Auth.EphemeralSigningKey}},
+		{"auth.admin_emails", "ADMIN_EMAILS", "comma-separated emails of existing users given the admin role at startup", listValue{&c.Auth.AdminEmails}},
+		{"auth.organizer_only_actions", "ORGANIZER_ONLY_ACTIONS", "comma-separated actions restricted to organizers and admins", listValue{&c.Auth.OrganizerOnlyActions}},
+	}
+}
+
+// String lists the settings, one "key = value" per line, with secrets redacted
+func (c *Config) String() string {
+	var b strings.Builder
+	for _, s := range c.settings() {
+		fmt.Fprintf(&b, "%s = %s\n", s.key, s.value.String())
+	}
+	return b.String()
+}
+
+// Validate checks every setting and reports all the invalid ones together
+func (c *Config) Validate() error {
+	envs := map[string]string{}
+	for _, s := range c.settings() {
+		envs[s.key] = s.env
+	}
+	var errs []error
+	check := func(ok bool, key, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s (%s): %s", key, envs[key], fmt.Sprintf(format, args...)))
+		}
+	}
+	positive := func(key string, value time.Duration) {
+		check(value > 0, key, "must be positive")
+	}
+
+	check(validAddr(c.Server.RESTAddr), "server.rest_addr", "must be host:port")
+	check(validAddr(c.Server.GRPCAddr), "server.grpc_addr", "must be host:port")
+	positive("server.shutdown_timeout", c.Server.ShutdownTimeout)
+
+	check(c.Database.Host != "", "database.host", "is required")
+	check(c.Database.Port > 0 && c.Database.Port <= 65535, "database.port", "must be between 1 and 65535")
+	check(c.Database.User != "", "database.user", "is required")
+	check(c.Database.Name != "", "database.name", "is required")
+
+	if c.Kafka.Enabled {
+		check(len(c.Kafka.Brokers) > 0, "kafka.brokers", "is required when Kafka is enabled")
+		check(c.Kafka.Topic != "", "kafka.topic", "is required when Kafka is enabled")
+		check(c.Kafka.DLQTopic != "", "kafka.dlq_topic", "is required when Kafka is enabled")
+		check(c.Kafka.GroupID != "", "kafka.group_id", "is required when Kafka is enabled")
+		check(c.Kafka.StreamGroupID != "", "kafka.stream_group_id", "is required when Kafka is enabled")
+		check(c.Kafka.WebhookGroupID != "", "kafka.webhook_group_id", "is required when Kafka is enabled")
+	}
+	check(c.Kafka.MessageFormat == "protobuf" || c.Kafka.MessageFormat == "json", "kafka.message_format", `must be "protobuf" or "json"`)
+
+	positive("outbox.poll_interval", c.Outbox.PollInterval)
+	check(c.Stream.HistorySize > 0, "stream.history_size", "must be positive")
+	check(c.Stream.ClientBuffer > 0, "stream.client_buffer", "must be positive")
+	positive("webhooks.poll_interval", c.Webhooks.PollInterval)
+	positive("webhooks.timeout", c.Webhooks.Timeout)
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts", "must be positive")
+	positive("events.completion_interval", c.Events.CompletionInterval)
+
+	check(c.Auth.BcryptCost >= bcrypt.MinCost && c.Auth.BcryptCost <= bcrypt.MaxCost, "auth.bcrypt_cost", "must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	positive("auth.access_token_ttl", c.Auth.AccessTokenTTL)
+	if _, err := policy.Parse(c.Auth.OrganizerOnlyActions); err != nil {
+		check(false, "auth.organizer_only_actions", "%v", err)
+	}
+	return errors.Join(errs...)
+}
+
+// validAddr reports whether addr is a host (possibly empty) and a port
+func validAddr(addr string) bool {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
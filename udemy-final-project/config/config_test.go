@@ -0,0 +1,175 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// env returns a lookup of the given variables
+func env(vars map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+// writeFile writes a config file with the given name to a temporary directory
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := load(nil, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Server.RESTAddr)
+	assert.Equal(t, "localhost:50051", cfg.Server.GRPCAddr)
+	assert.Equal(t, []string{"localhost:9092"}, cfg.Kafka.Brokers)
+	assert.Equal(t, "events", cfg.Kafka.Topic)
+	assert.Equal(t, "event-consumer-group", cfg.Kafka.GroupID)
+	assert.Equal(t, 14, cfg.Auth.BcryptCost)
+	assert.Equal(t, 15*time.Minute, cfg.Auth.AccessTokenTTL)
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  rest_addr: ":9000"
+  grpc_addr: ":9001"
+  shutdown_timeout: 10s
+database:
+  host: db.internal
+  port: 6432
+kafka:
+  brokers: [kafka-1:9092, kafka-2:9092]
+auth:
+  bcrypt_cost: 12
+`)
+	vars := map[string]string{
+		"CONFIG_FILE": path,
+		"GRPC_ADDR":   ":9101",
+		"DB_PORT":     "7432",
+		"BCRYPT_COST": "",
+	}
+	cfg, err := load([]string{"-database.port=8432", "-kafka.enabled=false"}, env(vars))
+	require.NoError(t, err)
+
+	// The file overrides the defaults
+	assert.Equal(t, ":9000", cfg.Server.RESTAddr)
+	assert.Equal(t, 10*time.Second, cfg.Server.ShutdownTimeout)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, cfg.Kafka.Brokers)
+	// The environment overrides the file, but empty variables are ignored
+	assert.Equal(t, ":9101", cfg.Server.GRPCAddr)
+	assert.Equal(t, 12, cfg.Auth.BcryptCost)
+	// Flags override the environment
+	assert.Equal(t, 8432, cfg.Database.Port)
+	assert.False(t, cfg.Kafka.Enabled)
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+[kafka]
+topic = "changes"
+brokers = "kafka-1:9092, kafka-2:9092"
+
+[auth]
+access_token_ttl = "5m"
+admin_emails = ["admin@example.com"]
+`)
+	cfg, err := load([]string{"-config", path}, env(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "changes", cfg.Kafka.Topic)
+	assert.Equal(t, []string{"kafka-1:9092", "kafka-2:9092"}, cfg.Kafka.Brokers)
+	assert.Equal(t, 5*time.Minute, cfg.Auth.AccessTokenTTL)
+	assert.Equal(t, []string{"admin@example.com"}, cfg.Auth.AdminEmails)
+}
+
+func TestLoad_AggregatesErrors(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  port: 8080
+webhooks:
+  timeout: 10
+`)
+	vars := map[string]string{"CONFIG_FILE": path, "DB_PORT": "postgres"}
+	_, err := load([]string{"-stream.history-size=many"}, env(vars))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "server.port: unknown setting")
+	assert.ErrorContains(t, err, "webhooks.timeout: must be a duration")
+	assert.ErrorContains(t, err, "DB_PORT: must be an integer")
+	assert.ErrorContains(t, err, "-stream.history-size: must be an integer")
+
+	vars = map[string]string{
+		"REST_ADDR":              "8080",
+		"BCRYPT_COST":            "40",
+		"KAFKA_MESSAGE_FORMAT":   "avro",
+		"ORGANIZER_ONLY_ACTIONS": "create_event,launch_rockets",
+	}
+	_, err = load([]string{"-webhooks.max-attempts=0"}, env(vars))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "server.rest_addr (REST_ADDR): must be host:port")
+	assert.ErrorContains(t, err, "auth.bcrypt_cost (BCRYPT_COST): must be between 4 and 31")
+	assert.ErrorContains(t, err, `kafka.message_format (KAFKA_MESSAGE_FORMAT): must be "protobuf" or "json"`)
+	assert.ErrorContains(t, err, `auth.organizer_only_actions (ORGANIZER_ONLY_ACTIONS): unknown action "launch_rockets"`)
+	assert.ErrorContains(t, err, "webhooks.max_attempts (WEBHOOK_MAX_ATTEMPTS): must be positive")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := load([]string{"-config", "missing.yaml"}, env(nil))
+	assert.ErrorContains(t, err, "config file")
+
+	path := writeFile(t, "config.json", `{}`)
+	_, err = load([]string{"-config", path}, env(nil))
+	assert.ErrorContains(t, err, "unsupported format")
+
+	_, err = load([]string{"serve"}, env(nil))
+	assert.ErrorContains(t, err, `unexpected argument "serve"`)
+}
+
+func TestLoad_Help(t *testing.T) {
+	stderr := os.Stderr
+	null, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	defer func() { os.Stderr = stderr; _ = null.Close() }()
+	os.Stderr = null
+
+	_, err = load([]string{"-h"}, env(nil))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func TestConfig_RedactsSecrets(t *testing.T) {
+	vars := map[string]string{"DB_PASSWORD": "hunter2", "JWT_SECRET": "jwt-secret"}
+	cfg, err := load(nil, env(vars))
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", cfg.Database.Password.Value())
+	assert.Contains(t, cfg.Database.DSN(), "password=hunter2")
+
+	for _, printed := range []string{
+		cfg.String(),
+		fmt.Sprintf("%v", *cfg),
+		fmt.Sprintf("%+v", cfg.Database),
+		fmt.Sprintf("%#v", cfg.Auth),
+	} {
+		assert.NotContains(t, printed, "hunter2")
+		assert.NotContains(t, printed, "jwt-secret")
+	}
+	assert.Contains(t, cfg.String(), "database.password = [REDACTED]")
+	assert.Contains(t, cfg.String(), "auth.jwt_signing_key = \n")
+}
+
+func TestLoad_ExampleFile(t *testing.T) {
+	cfg, err := load([]string{"-config", "../config.example.yaml"}, env(nil))
+	require.NoError(t, err)
+	// The example documents the defaults
+	assert.Equal(t, Default().String(), cfg.String())
+}
@@ -0,0 +1,167 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Load builds the configuration from, in increasing precedence, the defaults,
+// the file named by the -config flag or CONFIG_FILE, the environment and the
+// flags in args. Empty environment variables are ignored. Every invalid
+// setting is reported in the returned error; -h returns flag.ErrHelp after
+// printing the flags.
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv)
+}
+
+// load is Load with the environment read through lookupEnv
+func load(args []string, lookupEnv func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+	settings := cfg.settings()
+
+	// Flags are parsed first to find the file, but applied last
+	flags := flag.NewFlagSet(filepath.Base(os.Args[0]), flag.ContinueOnError)
+	file := flags.String("config", "", "YAML (.yaml, .yml) or TOML (.toml) configuration file (env CONFIG_FILE)")
+	var given []flagValue
+	for _, s := range settings {
+		flags.Var(&flagRecorder{setting: s, given: &given, defaultValue: s.value.String()}, s.flagName(), fmt.Sprintf("%s (env %s)", s.usage, s.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	var errs []error
+	if *file == "" {
+		*file, _ = lookupEnv("CONFIG_FILE")
+	}
+	if *file != "" {
+		if err := loadFile(*file, settings); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	for _, s := range settings {
+		if value, _ := lookupEnv(s.env); value != "" {
+			if err := s.value.Set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+			}
+		}
+	}
+	for _, f := range given {
+		if err := f.setting.value.Set(f.value); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", f.setting.flagName(), err))
+		}
+	}
+	if len(errs) > 0 {
+		// Validating settings that could not be parsed would report them twice
+		return nil, errors.Join(errs...)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// flagValue is a flag given on the command line
+type flagValue struct {
+	setting setting
+	value   string
+}
+
+// flagRecorder records a flag to be applied after the file and the
+// environment. It prints the setting's default in the usage.
+type flagRecorder struct {
+	setting      setting
+	given        *[]flagValue
+	defaultValue string
+}
+
+func (r *flagRecorder) Set(value string) error {
+	*r.given = append(*r.given, flagValue{setting: r.setting, value: value})
+	return nil
+}
+
+func (r *flagRecorder) String() string {
+	return r.defaultValue
+}
+
+func (r *flagRecorder) IsBoolFlag() bool {
+	_, ok := r.setting.value.(boolValue)
+	return ok
+}
+
+// loadFile applies the settings of a YAML or TOML file, whose sections and
+// keys are those of the settings, such as database.host. Lists may be arrays
+// or comma-separated strings.
+func loadFile(path string, settings []setting) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	var errs []error
+	byKey := map[string]setting{}
+	for _, s := range settings {
+		byKey[s.key] = s
+	}
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		s, ok := byKey[key]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%s: unknown setting", key))
+			continue
+		}
+		if err := s.value.Set(values[key]); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// flatten collects the scalar values of tree by their dotted key. Lists are
+// joined with commas.
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for name, value := range tree {
+		key := prefix + name
+		switch value := value.(type) {
+		case map[string]any:
+			flatten(key+".", value, values)
+		case []any:
+			entries := make([]string, len(value))
+			for i, entry := range value {
+				entries[i] = fmt.Sprint(entry)
+			}
+			values[key] = strings.Join(entries, ",")
+		case nil:
+		default:
+			values[key] = fmt.Sprint(value)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Secret is a setting that is redacted when printed. Value returns it.
+type Secret string
+
+// redacted replaces secrets that are set when printed
+const redacted = "[REDACTED]"
+
+// Value returns the secret itself
+func (s Secret) Value() string {
+	return string(s)
+}
+
+// String returns the redacted secret, or "" when it is not set
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// GoString redacts the secret from %#v
+func (s Secret) GoString() string {
+	return strconv.Quote(s.String())
+}
+
+// MarshalText redacts the secret from encoded output
+func (s Secret) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// The values below implement flag.Value over a field of Config, so the file,
+// the environment and the flags all parse settings the same way
+
+type stringValue struct{ p *string }
+
+func (v stringValue) Set(s string) error { *v.p = s; return nil }
+func (v stringValue) String() string     { return *v.p }
+
+type secretValue struct{ p *Secret }
+
+func (v secretValue) Set(s string) error { *v.p = Secret(s); return nil }
+func (v secretValue) String() string     { return v.p.String() }
+
+type intValue struct{ p *int }
+
+func (v intValue) Set(s string) error {
+	parsed, err := strconv.Atoi(s)
+	if err != nil {
+		return errors.New("must be an integer")
+	}
+	*v.p = parsed
+	return nil
+}
+func (v intValue) String() string { return strconv.Itoa(*v.p) }
+
+type boolValue struct{ p *bool }
+
+func (v boolValue) Set(s string) error {
+	parsed, err := strconv.ParseBool(s)
+	if err != nil {
+		return errors.New("must be true or false")
+	}
+	*v.p = parsed
+	return nil
+}
+func (v boolValue) String() string   { return strconv.FormatBool(*v.p) }
+func (v boolValue) IsBoolFlag() bool { return true }
+
+type durationValue struct{ p *time.Duration }
+
+func (v durationValue) Set(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.New(`must be a duration such as "30s" or "5m"`)
+	}
+	*v.p = parsed
+	return nil
+}
+func (v durationValue) String() string { return v.p.String() }
+
+// listValue is a comma-separated list. Blank entries are dropped.
+type listValue struct{ p *[]string }
+
+func (v listValue) Set(s string) error {
+	var list []string
+	for _, entry := range strings.Split(s, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	*v.p = list
+	return nil
+}
+func (v listValue) String() string { return strings.Join(*v.p, ",") }
@@ -1,74 +1,89 @@
-// Package db provides database connection and initialization functionality.
+// Package db provides the database connection and the versioned schema
+// migrations applied to it.
 package db
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"time"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-// User model for migration
-type User struct {
-	ID       int64  `gorm:"primaryKey;autoIncrement"`
-	Email    string `gorm:"unique;not null"`
-	Password string `gorm:"not null"`
-}
-
-// Event model for migration
-type Event struct {
-	ID          int64     `gorm:"primaryKey;autoIncrement"`
-	Name        string    `gorm:"not null"`
-	Description string    `gorm:"not null"`
-	Location    string    `gorm:"not null"`
-	DateTime    time.Time `gorm:"not null"`
-	UserID      int64     `gorm:"not null"`
-}
-
-// Registration model for migration
-type Registration struct {
-	ID      int64 `gorm:"primaryKey;autoIncrement"`
-	UserID  int64 `gorm:"not null"`
-	EventID int64 `gorm:"not null"`
-}
-
 // DB is the global database connection instance
 var DB *gorm.DB
 
-// InitDB initializes the database connection and performs auto-migration
-func InitDB() {
-	var err error
-	dbHost := getEnv("DB_HOST", "localhost")
-	dbPort := getEnv("DB_PORT", "5432")
-	dbUser := getEnv("DB_USER", "postgres")
-	dbPassword := getEnv("DB_PASSWORD", "postgres")
-	dbName := getEnv("DB_NAME", "eventdb")
-
-	dataSourceName := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		dbHost, dbPort, dbUser, dbPassword, dbName)
-
-	DB, err = gorm.Open(postgres.Open(dataSourceName), &gorm.Config{})
-	if err != nil {
+// InitDB connects to the database and, unless cfg.AutoMigrate is off,
+// applies the pending migrations
+func InitDB(cfg config.Database) {
+	if err := Connect(cfg); err != nil {
 		panic("Failed to connect to database: " + err.Error())
 	}
-
-	if err = DB.AutoMigrate(&User{}, &Event{}, &Registration{}); err != nil {
-		panic("Failed to migrate database: " + err.Error())
+	if cfg.AutoMigrate {
+		if err := Migrate(context.Background()); err != nil {
+			panic("Failed to migrate database: " + err.Error())
+		}
 	}
-
 	fmt.Println("Database connection established")
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// Connect opens the database connection without changing the schema
+func Connect(cfg config.Database) error {
+	var err error
+	// TranslateError reports unique violations as gorm.ErrDuplicatedKey
+	DB, err = gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{TranslateError: true})
+	return err
+}
+
+// Migrate applies the pending migrations of DB. Replicas starting together
+// wait for each other, so each migration is applied once.
+func Migrate(ctx context.Context) error {
+	migrator, err := GetMigrator()
+	if err != nil {
+		return err
 	}
-	return defaultValue
+	_, err = migrator.Up(ctx)
+	return err
+}
+
+// GetMigrator returns a Migrator of the embedded migrations over DB
+func GetMigrator() (*Migrator, error) {
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return nil, err
+	}
+	return NewMigrator(sqlDB)
 }
 
 // GetDB returns the global database connection instance
 func GetDB() *gorm.DB {
 	return DB
 }
+
+// Pool owns the connections of DB so they are closed on shutdown
+type Pool struct {
+	DB *gorm.DB
+}
+
+// NewPool wraps the connections opened by InitDB
+func NewPool() *Pool {
+	return &Pool{DB: DB}
+}
+
+// Shutdown closes the connections once the queries in progress finish, or
+// gives up when ctx is done
+func (p *Pool) Shutdown(ctx context.Context) error {
+	sqlDB, err := p.DB.DB()
+	if err != nil {
+		return err
+	}
+	closed := make(chan error, 1)
+	go func() { closed <- sqlDB.Close() }()
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("closing database connections: %w", ctx.Err())
+	}
+}
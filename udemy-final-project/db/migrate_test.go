@@ -0,0 +1,90 @@
+package db
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations_Embedded(t *testing.T) {
+	migrations, err := Migrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, "0001_baseline", migrations[0].String())
+
+	for i, migration := range migrations {
+		// Versions are numbered without gaps so a missing file stands out
+		assert.Equal(t, int64(i+1), migration.Version)
+		assert.NotEmpty(t, migration.Up, migration.String())
+		assert.NotEmpty(t, migration.Down, migration.String())
+		assert.Len(t, migration.Checksum, 64, migration.String())
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"0010_add_index.up.sql":   {Data: []byte("CREATE INDEX i ON t (c);")},
+		"0010_add_index.down.sql": {Data: []byte("DROP INDEX i;")},
+		"0002_create.up.sql":      {Data: []byte("CREATE TABLE t (c text);")},
+		"0002_create.down.sql":    {Data: []byte("DROP TABLE t;")},
+	}
+	migrations, err := loadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	assert.Equal(t, "0002_create", migrations[0].String())
+	assert.Equal(t, "CREATE TABLE t (c text);", migrations[0].Up)
+	assert.Equal(t, "DROP TABLE t;", migrations[0].Down)
+	assert.Equal(t, int64(10), migrations[1].Version)
+	assert.Equal(t, "add_index", migrations[1].Name)
+
+	// The checksum covers the up script only
+	fsys["0002_create.down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE IF EXISTS t;")}
+	again, err := loadMigrations(fsys)
+	require.NoError(t, err)
+	assert.Equal(t, migrations[0].Checksum, again[0].Checksum)
+	fsys["0002_create.up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE t (c text NOT NULL);")}
+	again, err = loadMigrations(fsys)
+	require.NoError(t, err)
+	assert.NotEqual(t, migrations[0].Checksum, again[0].Checksum)
+}
+
+func TestLoadMigrations_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		fsys  fstest.MapFS
+		error string
+	}{
+		{
+			name:  "bad name",
+			fsys:  fstest.MapFS{"create.sql": {}},
+			error: "name must be like 0001_name.up.sql",
+		},
+		{
+			name:  "zero version",
+			fsys:  fstest.MapFS{"0000_create.up.sql": {Data: []byte("x")}},
+			error: "version must be a positive number",
+		},
+		{
+			name:  "missing down",
+			fsys:  fstest.MapFS{"0001_create.up.sql": {Data: []byte("x")}},
+			error: "0001_create: needs both an up and a down file",
+		},
+		{
+			name: "duplicate version",
+			fsys: fstest.MapFS{
+				"0001_create.up.sql":   {Data: []byte("x")},
+				"0001_create.down.sql": {Data: []byte("x")},
+				"0001_other.up.sql":    {Data: []byte("x")},
+			},
+			error: "version 1 is also used by create",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadMigrations(tt.fsys)
+			assert.ErrorContains(t, err, tt.error)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+-- The schema as created by GORM AutoMigrate before versioned migrations.
+-- Every statement is idempotent so databases created by AutoMigrate adopt
+-- it without changes.
+
+CREATE TABLE IF NOT EXISTS users (
+    id bigserial PRIMARY KEY,
+    email text NOT NULL,
+    password text NOT NULL,
+    role text NOT NULL DEFAULT 'user',
+    calendar_token_hash text,
+    CONSTRAINT uni_users_email UNIQUE (email)
+);
+CREATE INDEX IF NOT EXISTS idx_users_calendar_token_hash ON users (calendar_token_hash);
+
+CREATE TABLE IF NOT EXISTS events (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    date_time timestamptz NOT NULL,
+    user_id bigint NOT NULL,
+    capacity bigint NOT NULL DEFAULT 0,
+    rrule text,
+    exdates text,
+    latitude decimal,
+    longitude decimal,
+    address_street text,
+    address_city text,
+    address_region text,
+    address_postal_code text,
+    address_country text,
+    -- Events created before drafts existed stay visible
+    status text NOT NULL DEFAULT 'published',
+    cancel_reason text,
+    published_at timestamptz,
+    cancelled_at timestamptz,
+    completed_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_events_date_time_id ON events (date_time, id);
+CREATE INDEX IF NOT EXISTS idx_events_name_id ON events (name, id);
+CREATE INDEX IF NOT EXISTS idx_events_lat_lng ON events (latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events (user_id);
+CREATE INDEX IF NOT EXISTS idx_events_status ON events (status);
+
+CREATE TABLE IF NOT EXISTS registrations (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    event_id bigint NOT NULL,
+    status text NOT NULL DEFAULT 'confirmed',
+    occurrence_start timestamptz,
+    scope text,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_registrations_event_status ON registrations (event_id, status);
+
+CREATE TABLE IF NOT EXISTS occurrence_overrides (
+    id bigserial PRIMARY KEY,
+    event_id bigint NOT NULL,
+    occurrence_start timestamptz NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    date_time timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_occurrence_overrides_event ON occurrence_overrides (event_id, occurrence_start);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id bigserial PRIMARY KEY,
+    action text NOT NULL,
+    event_id bigint NOT NULL,
+    payload text NOT NULL,
+    registration text,
+    attempts bigint NOT NULL DEFAULT 0,
+    last_error text,
+    message_id text,
+    trace_id text,
+    next_attempt_at timestamptz NOT NULL,
+    created_at timestamptz,
+    delivered_at timestamptz
+);
+-- Undelivered messages are found through an index of only those rows
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (id) WHERE delivered_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_delivered_at ON outbox_messages (delivered_at);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    actions text,
+    active boolean NOT NULL DEFAULT true,
+    created_at timestamptz,
+    updated_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id bigserial PRIMARY KEY,
+    webhook_id bigint NOT NULL,
+    message_id text NOT NULL,
+    action text NOT NULL,
+    event_id bigint NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts bigint NOT NULL DEFAULT 0,
+    last_status_code bigint,
+    last_error text,
+    next_attempt_at timestamptz NOT NULL,
+    redelivery_of bigint,
+    created_at timestamptz,
+    delivered_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
+-- A change is queued once per webhook, except as a manual redelivery
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_message ON webhook_deliveries (webhook_id, message_id) WHERE redelivery_of IS NULL;
+-- Due deliveries are found through an index of only the pending rows
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id bigserial PRIMARY KEY,
+    user_id bigint NOT NULL,
+    family_id text NOT NULL,
+    token_hash text NOT NULL,
+    access_token_id text,
+    access_expires_at timestamptz,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz,
+    used_at timestamptz,
+    revoked_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_token_id ON refresh_tokens (access_token_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+
+-- Access tokens are looked up by jti on every authenticated request
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id text PRIMARY KEY,
+    expires_at timestamptz NOT NULL,
+    created_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
@@ -0,0 +1,9 @@
+-- A weighted full-text search vector over event name (A), location (B) and
+-- description (C), kept up to date by PostgreSQL, and the GIN index used by
+-- @@ queries
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('english', coalesce(name, '')), 'A') ||
+    setweight(to_tsvector('english', coalesce(location, '')), 'B') ||
+    setweight(to_tsvector('english', coalesce(description, '')), 'C')
+) STORED;
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_registrations_user_id;
+ALTER TABLE refresh_tokens DROP CONSTRAINT IF EXISTS fk_refresh_tokens_user;
+ALTER TABLE webhook_deliveries DROP CONSTRAINT IF EXISTS fk_webhook_deliveries_webhook;
+ALTER TABLE webhooks DROP CONSTRAINT IF EXISTS fk_webhooks_user;
+ALTER TABLE occurrence_overrides DROP CONSTRAINT IF EXISTS fk_occurrence_overrides_event;
+ALTER TABLE registrations
+    DROP CONSTRAINT IF EXISTS fk_registrations_event,
+    DROP CONSTRAINT IF EXISTS fk_registrations_user;
+ALTER TABLE events DROP CONSTRAINT IF EXISTS fk_events_user;
@@ -0,0 +1,24 @@
+-- Foreign keys that AutoMigrate never created. Registrations, overrides,
+-- webhooks, deliveries and refresh tokens go with the row they belong to;
+-- a user who still organizes events cannot be deleted, as deleting an event
+-- must go through the outbox. Outbox messages and webhook deliveries keep
+-- their event_id without a key, as they outlive deleted events.
+--
+-- The constraints are NOT VALID so rows left behind by earlier deletes do not
+-- fail the migration; new and updated rows are checked.
+ALTER TABLE events
+    ADD CONSTRAINT fk_events_user FOREIGN KEY (user_id) REFERENCES users (id) NOT VALID;
+ALTER TABLE registrations
+    ADD CONSTRAINT fk_registrations_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE NOT VALID,
+    ADD CONSTRAINT fk_registrations_event FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE occurrence_overrides
+    ADD CONSTRAINT fk_occurrence_overrides_event FOREIGN KEY (event_id) REFERENCES events (id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE webhooks
+    ADD CONSTRAINT fk_webhooks_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE webhook_deliveries
+    ADD CONSTRAINT fk_webhook_deliveries_webhook FOREIGN KEY (webhook_id) REFERENCES webhooks (id) ON DELETE CASCADE NOT VALID;
+ALTER TABLE refresh_tokens
+    ADD CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE NOT VALID;
+
+-- Registrations are looked up by user, and the keys are checked on delete
+CREATE INDEX IF NOT EXISTS idx_registrations_user_id ON registrations (user_id);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS registration_changes;
+ALTER TABLE registrations DROP COLUMN IF EXISTS updated_at;
+DROP INDEX IF EXISTS idx_registrations_user_occurrence;
+DROP INDEX IF EXISTS idx_registrations_user_event;
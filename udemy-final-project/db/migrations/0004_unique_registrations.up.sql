@@ -0,0 +1,36 @@
+-- A user registers for an event at most once: a registration for the series
+-- is unique per user and event, and one for a single occurrence of a
+-- recurring event is unique per user, event and occurrence. Duplicates left
+-- by earlier releases are removed first, keeping the confirmed, then the
+-- oldest, registration of each.
+DELETE FROM registrations
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, row_number() OVER (
+            PARTITION BY user_id, event_id, occurrence_start
+            ORDER BY status = 'confirmed' DESC, created_at, id
+        ) AS n
+        FROM registrations
+    ) ranked
+    WHERE n > 1
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registrations_user_event
+    ON registrations (user_id, event_id) WHERE occurrence_start IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registrations_user_occurrence
+    ON registrations (user_id, event_id, occurrence_start) WHERE occurrence_start IS NOT NULL;
+
+-- When a registration last changed, and the statuses it has had. Existing
+-- registrations start their history with their current status.
+ALTER TABLE registrations ADD COLUMN IF NOT EXISTS updated_at timestamptz;
+UPDATE registrations SET updated_at = created_at WHERE updated_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS registration_changes (
+    id bigserial PRIMARY KEY,
+    registration_id bigint NOT NULL
+        CONSTRAINT fk_registration_changes_registration REFERENCES registrations (id) ON DELETE CASCADE,
+    status text NOT NULL,
+    changed_at timestamptz NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_registration_changes_registration_id ON registration_changes (registration_id);
+INSERT INTO registration_changes (registration_id, status, changed_at)
+SELECT id, status, coalesce(created_at, now()) FROM registrations;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_outbox_messages_failed_at;
+DROP INDEX IF EXISTS idx_outbox_messages_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (id) WHERE delivered_at IS NULL;
+
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS failed_at;
+ALTER TABLE outbox_messages DROP COLUMN IF EXISTS claimed_until;
@@ -0,0 +1,10 @@
+-- Relays claim outbox messages for a short lease instead of keeping them
+-- locked while they are sent, and give up on a message after too many
+-- failed attempts so it no longer holds back the ones queued behind it.
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS claimed_until timestamptz;
+ALTER TABLE outbox_messages ADD COLUMN IF NOT EXISTS failed_at timestamptz;
+
+DROP INDEX IF EXISTS idx_outbox_messages_pending;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending
+    ON outbox_messages (id) WHERE delivered_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_failed_at ON outbox_messages (failed_at) WHERE failed_at IS NOT NULL;
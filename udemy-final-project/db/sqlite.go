@@ -0,0 +1,42 @@
+package db
+
+import (
+	_ "embed" // for the SQLite schema
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// sqliteSchema is the schema of the migrations translated for SQLite
+//
+//go:embed sqlite.sql
+var sqliteSchema string
+
+// OpenSQLite opens, creating if needed, the SQLite database at path with the
+// schema of the migrations and foreign keys enforced. ":memory:" opens a
+// private in-memory database.
+//
+// SQLite stores times as text, which only orders correctly in a single time
+// zone, so times are written in UTC and queries must pass UTC times too.
+func OpenSQLite(path string) (*gorm.DB, error) {
+	gormDB, err := gorm.Open(sqlite.Open(path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"), &gorm.Config{
+		TranslateError: true,
+		NowFunc:        func() time.Time { return time.Now().UTC() },
+	})
+	if err != nil {
+		return nil, err
+	}
+	sqlDB, err := gormDB.DB()
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer, and each connection to ":memory:" would be
+	// a database of its own
+	sqlDB.SetMaxOpenConns(1)
+	if err := gormDB.Exec(sqliteSchema).Error; err != nil {
+		_ = sqlDB.Close()
+		return nil, err
+	}
+	return gormDB, nil
+}
@@ -0,0 +1,155 @@
+-- The schema of the migrations for SQLite, used by tests and local runs
+-- without PostgreSQL. Full-text search and distances are computed in Go on
+-- SQLite, so there is no search_vector column.
+
+CREATE TABLE IF NOT EXISTS users (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    email text NOT NULL CONSTRAINT uni_users_email UNIQUE,
+    password text NOT NULL,
+    role text NOT NULL DEFAULT 'user',
+    calendar_token_hash text
+);
+CREATE INDEX IF NOT EXISTS idx_users_calendar_token_hash ON users (calendar_token_hash);
+
+CREATE TABLE IF NOT EXISTS events (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    name text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    date_time datetime NOT NULL,
+    user_id integer NOT NULL CONSTRAINT fk_events_user REFERENCES users (id),
+    capacity integer NOT NULL DEFAULT 0,
+    rrule text,
+    exdates text,
+    latitude real,
+    longitude real,
+    address_street text,
+    address_city text,
+    address_region text,
+    address_postal_code text,
+    address_country text,
+    status text NOT NULL DEFAULT 'published',
+    cancel_reason text,
+    published_at datetime,
+    cancelled_at datetime,
+    completed_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_events_date_time_id ON events (date_time, id);
+CREATE INDEX IF NOT EXISTS idx_events_name_id ON events (name, id);
+CREATE INDEX IF NOT EXISTS idx_events_lat_lng ON events (latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_events_user_id ON events (user_id);
+CREATE INDEX IF NOT EXISTS idx_events_status ON events (status);
+
+CREATE TABLE IF NOT EXISTS registrations (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL CONSTRAINT fk_registrations_user REFERENCES users (id) ON DELETE CASCADE,
+    event_id integer NOT NULL CONSTRAINT fk_registrations_event REFERENCES events (id) ON DELETE CASCADE,
+    status text NOT NULL DEFAULT 'confirmed',
+    occurrence_start datetime,
+    scope text,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_registrations_event_status ON registrations (event_id, status);
+CREATE INDEX IF NOT EXISTS idx_registrations_user_id ON registrations (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registrations_user_event
+    ON registrations (user_id, event_id) WHERE occurrence_start IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_registrations_user_occurrence
+    ON registrations (user_id, event_id, occurrence_start) WHERE occurrence_start IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS registration_changes (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    registration_id integer NOT NULL
+        CONSTRAINT fk_registration_changes_registration REFERENCES registrations (id) ON DELETE CASCADE,
+    status text NOT NULL,
+    changed_at datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_registration_changes_registration_id ON registration_changes (registration_id);
+
+CREATE TABLE IF NOT EXISTS occurrence_overrides (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    event_id integer NOT NULL CONSTRAINT fk_occurrence_overrides_event REFERENCES events (id) ON DELETE CASCADE,
+    occurrence_start datetime NOT NULL,
+    name text NOT NULL,
+    description text NOT NULL,
+    location text NOT NULL,
+    date_time datetime NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_occurrence_overrides_event ON occurrence_overrides (event_id, occurrence_start);
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    action text NOT NULL,
+    event_id integer NOT NULL,
+    payload text NOT NULL,
+    registration text,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text,
+    message_id text,
+    trace_id text,
+    next_attempt_at datetime NOT NULL,
+    created_at datetime,
+    delivered_at datetime,
+    claimed_until datetime,
+    failed_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages (id) WHERE delivered_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_delivered_at ON outbox_messages (delivered_at);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_failed_at ON outbox_messages (failed_at) WHERE failed_at IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL CONSTRAINT fk_webhooks_user REFERENCES users (id) ON DELETE CASCADE,
+    url text NOT NULL,
+    secret text NOT NULL,
+    actions text,
+    active boolean NOT NULL DEFAULT true,
+    created_at datetime,
+    updated_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_webhooks_user_id ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    webhook_id integer NOT NULL CONSTRAINT fk_webhook_deliveries_webhook REFERENCES webhooks (id) ON DELETE CASCADE,
+    message_id text NOT NULL,
+    action text NOT NULL,
+    event_id integer NOT NULL,
+    payload text NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts integer NOT NULL DEFAULT 0,
+    last_status_code integer,
+    last_error text,
+    next_attempt_at datetime NOT NULL,
+    redelivery_of integer,
+    created_at datetime,
+    delivered_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook ON webhook_deliveries (webhook_id, id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_message ON webhook_deliveries (webhook_id, message_id) WHERE redelivery_of IS NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id integer PRIMARY KEY AUTOINCREMENT,
+    user_id integer NOT NULL CONSTRAINT fk_refresh_tokens_user REFERENCES users (id) ON DELETE CASCADE,
+    family_id text NOT NULL,
+    token_hash text NOT NULL,
+    access_token_id text,
+    access_expires_at datetime,
+    expires_at datetime NOT NULL,
+    created_at datetime,
+    used_at datetime,
+    revoked_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_token_id ON refresh_tokens (access_token_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens (expires_at);
+
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id text PRIMARY KEY,
+    expires_at datetime NOT NULL,
+    created_at datetime
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
@@ -2,7 +2,14 @@
 package di
 
 import (
+	"context"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
 	"github.com/samber/do/v2"
 )
 
@@ -11,20 +18,88 @@ type Container struct {
 	Injector *do.RootScope
 }
 
-// NewContainer creates a new DI container
-func NewContainer() *Container {
+// NewContainer creates a new DI container providing cfg and the services
+// configured by it. Each service invokes the services it depends on, so
+// Shutdown stops the dependents of a service before it and closes the
+// database last.
+func NewContainer(cfg *config.Config) *Container {
 	injector := do.New()
 
 	// Register services
-	do.ProvideNamedValue(injector, "userService", services.NewUserService())
-	do.ProvideNamedValue(injector, "eventService", services.NewEventService())
-	do.ProvideNamedValue(injector, "authService", services.NewAuthService())
+	do.ProvideNamedValue(injector, "config", cfg)
+	do.ProvideNamed(injector, "database", func(do.Injector) (*db.Pool, error) {
+		return db.NewPool(), nil
+	})
+	do.ProvideNamed(injector, "eventRepository", func(i do.Injector) (repository.EventRepository, error) {
+		return repository.NewGorm(do.MustInvokeNamed[*db.Pool](i, "database").DB).Events, nil
+	})
+	do.ProvideNamed(injector, "userRepository", func(i do.Injector) (repository.UserRepository, error) {
+		return repository.NewGorm(do.MustInvokeNamed[*db.Pool](i, "database").DB).Users, nil
+	})
+	do.ProvideNamed(injector, "registrationRepository", func(i do.Injector) (repository.RegistrationRepository, error) {
+		return repository.NewGorm(do.MustInvokeNamed[*db.Pool](i, "database").DB).Registrations, nil
+	})
+	do.ProvideNamed(injector, "userService", func(i do.Injector) (services.UserService, error) {
+		return services.NewUserService(
+			do.MustInvokeNamed[repository.UserRepository](i, "userRepository"),
+			do.MustInvokeNamed[*config.Config](i, "config").Auth.AdminEmails,
+		), nil
+	})
+	do.ProvideNamed(injector, "eventService", func(i do.Injector) (services.EventService, error) {
+		return services.NewEventService(
+			do.MustInvokeNamed[repository.EventRepository](i, "eventRepository"),
+			do.MustInvokeNamed[repository.RegistrationRepository](i, "registrationRepository"),
+		), nil
+	})
+	do.ProvideNamed(injector, "authService", func(i do.Injector) (services.AuthService, error) {
+		do.MustInvokeNamed[*db.Pool](i, "database")
+		return services.NewAuthService(), nil
+	})
+	do.ProvideNamed(injector, "calendarService", func(i do.Injector) (services.CalendarService, error) {
+		return services.NewCalendarService(do.MustInvokeNamed[services.EventService](i, "eventService"), repository.Repositories{
+			Events:        do.MustInvokeNamed[repository.EventRepository](i, "eventRepository"),
+			Users:         do.MustInvokeNamed[repository.UserRepository](i, "userRepository"),
+			Registrations: do.MustInvokeNamed[repository.RegistrationRepository](i, "registrationRepository"),
+		}), nil
+	})
+	do.ProvideNamed(injector, "broadcaster", func(i do.Injector) (*stream.Broadcaster, error) {
+		cfg := do.MustInvokeNamed[*config.Config](i, "config").Stream
+		return stream.NewBroadcaster(cfg.HistorySize, cfg.ClientBuffer), nil
+	})
+	do.ProvideNamed(injector, "webhookService", func(i do.Injector) (services.WebhookService, error) {
+		do.MustInvokeNamed[*db.Pool](i, "database")
+		return services.NewWebhookService(do.MustInvokeNamed[*config.Config](i, "config").Webhooks), nil
+	})
+	do.ProvideNamed(injector, "outboxRelay", func(i do.Injector) (services.OutboxRelay, error) {
+		do.MustInvokeNamed[*db.Pool](i, "database")
+		cfg := do.MustInvokeNamed[*config.Config](i, "config")
+		broadcaster := do.MustInvokeNamed[*stream.Broadcaster](i, "broadcaster")
+		return services.NewOutboxRelay(cfg.Kafka, cfg.Outbox, broadcaster, do.MustInvokeNamed[services.WebhookService](i, "webhookService")), nil
+	})
+	do.ProvideNamed(injector, "policy", func(i do.Injector) (*policy.Policy, error) {
+		return policy.Parse(do.MustInvokeNamed[*config.Config](i, "config").Auth.OrganizerOnlyActions)
+	})
 
 	return &Container{
 		Injector: injector,
 	}
 }
 
+// Shutdown stops the services that were used, each after the services that
+// depend on it, within the deadline of ctx
+func (c *Container) Shutdown(ctx context.Context) error {
+	report := c.Injector.ShutdownWithContext(ctx)
+	if !report.Succeed {
+		return report
+	}
+	return nil
+}
+
+// GetConfig returns the configuration from the container
+func (c *Container) GetConfig() *config.Config {
+	return do.MustInvokeNamed[*config.Config](c.Injector, "config")
+}
+
 // GetUserService returns the user service from the container
 func (c *Container) GetUserService() services.UserService {
 	return do.MustInvokeNamed[services.UserService](c.Injector, "userService")
@@ -39,3 +114,30 @@ func (c *Container) GetEventService() services.EventService {
 func (c *Container) GetAuthService() services.AuthService {
 	return do.MustInvokeNamed[services.AuthService](c.Injector, "authService")
 }
+
+// GetCalendarService returns the calendar service from the container
+func (c *Container) GetCalendarService() services.CalendarService {
+	return do.MustInvokeNamed[services.CalendarService](c.Injector, "calendarService")
+}
+
+// GetWebhookService returns the webhook service from the container
+func (c *Container) GetWebhookService() services.WebhookService {
+	return do.MustInvokeNamed[services.WebhookService](c.Injector, "webhookService")
+}
+
+// GetOutboxRelay returns the outbox relay from the container
+func (c *Container) GetOutboxRelay() services.OutboxRelay {
+	return do.MustInvokeNamed[services.OutboxRelay](c.Injector, "outboxRelay")
+}
+
+// GetBroadcaster returns the broadcaster of live event changes from the container
+func (c *Container) GetBroadcaster() *stream.Broadcaster {
+	return do.MustInvokeNamed[*stream.Broadcaster](c.Injector, "broadcaster")
+}
+
+// GetPolicy returns the authorization policy from the container. It panics
+// when the organizer-only actions are invalid, which the configuration is
+// validated against.
+func (c *Container) GetPolicy() *policy.Policy {
+	return do.MustInvokeNamed[*policy.Policy](c.Injector, "policy")
+}
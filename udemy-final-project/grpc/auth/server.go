@@ -3,25 +3,37 @@ package auth
 
 import (
 	"context"
-	"errors"
 	"log"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/interceptor"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
 	authpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/auth"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// Methods lists the access of each AuthService method. Only logging out and
+// changing roles need an access token.
+var Methods = interceptor.Methods{
+	authpb.AuthService_Register_FullMethodName: interceptor.Public,
+	authpb.AuthService_Login_FullMethodName:    interceptor.Public,
+	authpb.AuthService_Refresh_FullMethodName:  interceptor.Public,
+}
+
 // Server implements the gRPC AuthService server
 type Server struct {
 	authpb.UnimplementedAuthServiceServer
 	userService services.UserService
 	authService services.AuthService
+	policy      *policy.Policy
 }
 
 // NewAuthServer creates a new AuthServer instance
-func NewAuthServer(userService services.UserService, authService services.AuthService) *Server {
+func NewAuthServer(userService services.UserService, authService services.AuthService, p *policy.Policy) *Server {
 	return &Server{
 		userService: userService,
 		authService: authService,
+		policy:      p,
 	}
 }
 
@@ -37,6 +49,7 @@ func (s *Server) Register(_ context.Context, req *authpb.RegisterRequest) (*auth
 		User: &authpb.User{
 			Id:    user.ID,
 			Email: user.Email,
+			Role:  user.Role,
 		},
 	}, nil
 }
@@ -50,18 +63,69 @@ func (s *Server) Login(_ context.Context, req *authpb.LoginRequest) (*authpb.Log
 		log.Printf("Failed to verify user credentials: %v", err)
 		return nil, err
 	}
-	if verifiedUser == nil {
-		return nil, errors.New("invalid email or password")
-	}
 
-	token, err := s.authService.GenerateToken(verifiedUser.Email, verifiedUser.ID)
+	pair, err := s.authService.StartSession(verifiedUser)
 	if err != nil {
 		log.Printf("Failed to generate token: %v", err)
 		return nil, err
 	}
 
 	return &authpb.LoginResponse{
-		Token:   token,
-		Message: "login successful",
+		Token:        pair.AccessToken,
+		Message:      "login successful",
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    timestamppb.New(pair.ExpiresAt),
+	}, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair via gRPC
+func (s *Server) Refresh(_ context.Context, req *authpb.RefreshRequest) (*authpb.RefreshResponse, error) {
+	if req.RefreshToken == "" {
+		return nil, services.Validation("REFRESH_TOKEN_REQUIRED", "refresh token is required")
+	}
+
+	pair, err := s.authService.RefreshSession(req.RefreshToken)
+	if err != nil {
+		log.Printf("Failed to refresh tokens: %v", err)
+		return nil, err
+	}
+
+	return &authpb.RefreshResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresAt:    timestamppb.New(pair.ExpiresAt),
+	}, nil
+}
+
+// Logout revokes the caller's access token and its session via gRPC
+func (s *Server) Logout(ctx context.Context, _ *authpb.LogoutRequest) (*authpb.LogoutResponse, error) {
+	claims, _ := interceptor.ClaimsFromContext(ctx)
+	if err := s.authService.EndSession(claims); err != nil {
+		log.Printf("Failed to logout: %v", err)
+		return nil, err
+	}
+
+	return &authpb.LogoutResponse{}, nil
+}
+
+// SetUserRole changes the role of a user via gRPC. Only admins may call it.
+func (s *Server) SetUserRole(ctx context.Context, req *authpb.SetUserRoleRequest) (*authpb.SetUserRoleResponse, error) {
+	subject := interceptor.SubjectFromContext(ctx)
+	if err := s.policy.Authorize(subject, policy.ActionManageRoles, policy.Resource{OwnerID: req.UserId}); err != nil {
+		return nil, services.Forbidden("PERMISSION_DENIED", "only admins can change roles")
+	}
+
+	user, err := s.userService.SetUserRole(req.UserId, req.Role)
+	if err != nil {
+		log.Printf("Failed to set user role: %v", err)
+		return nil, err
+	}
+
+	return &authpb.SetUserRoleResponse{
+		User: &authpb.User{
+			Id:    user.ID,
+			Email: user.Email,
+			Role:  user.Role,
+		},
 	}, nil
 }
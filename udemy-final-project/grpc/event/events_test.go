@@ -0,0 +1,90 @@
+package event
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/interceptor"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// start is the date of the events of the tests
+var start = time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC)
+
+// eventServer returns an EventService over an in-memory store with the event
+// service it uses
+func eventServer() (*Server, services.EventService) {
+	repositories := repository.NewMemory().Repositories()
+	events := services.NewEventService(repositories.Events, repositories.Registrations)
+	return NewEventServer(events, nil, policy.New(), nil), events
+}
+
+// as returns a context authenticated as user
+func as(userID int64) context.Context {
+	return interceptor.WithClaims(context.Background(), &security.Claims{UserID: userID, Role: policy.RoleUser})
+}
+
+func TestUpdateEvent_KeepsCapacity(t *testing.T) {
+	server, events := eventServer()
+	created, err := server.CreateEvent(as(1), &eventpb.CreateEventRequest{
+		Name: "Workshop", Description: "Hands-on", Location: "Istanbul",
+		DateTime: timestamppb.New(start), Capacity: 1, Status: models.EventStatusPublished,
+	})
+	require.NoError(t, err)
+	id := created.Event.Id
+	for _, userID := range []int64{2, 3} {
+		_, err := server.RegisterForEvent(as(userID), &eventpb.RegisterForEventRequest{EventId: id})
+		require.NoError(t, err)
+	}
+
+	update := &eventpb.UpdateEventRequest{
+		Id: id, Name: "Workshop", Description: "Hands-on", Location: "Istanbul", DateTime: timestamppb.New(start),
+	}
+	updated, err := server.UpdateEvent(as(1), update)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), updated.Event.Capacity, "an update without capacity keeps it")
+	registrations, err := events.GetRegistrations(3, strconv.FormatInt(id, 10))
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, registrations[0].Status)
+
+	unlimited := int32(0)
+	update.Capacity = &unlimited
+	updated, err = server.UpdateEvent(as(1), update)
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), updated.Event.Capacity)
+	registrations, err = events.GetRegistrations(3, strconv.FormatInt(id, 10))
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registrations[0].Status)
+}
+
+func TestGetEvents_Window(t *testing.T) {
+	server, _ := eventServer()
+	_, err := server.CreateEvent(as(1), &eventpb.CreateEventRequest{
+		Name: "Weekly", Description: "Meetup", Location: "Istanbul", DateTime: timestamppb.New(start),
+		Rrule: "FREQ=WEEKLY;COUNT=4", Status: models.EventStatusPublished,
+	})
+	require.NoError(t, err)
+
+	for _, req := range []*eventpb.GetEventsRequest{
+		{From: timestamppb.New(start)},
+		{To: timestamppb.New(start.AddDate(0, 0, 14))},
+	} {
+		_, err := server.GetEvents(context.Background(), req)
+		assert.ErrorIs(t, err, services.ErrValidation, "a window needs both bounds")
+	}
+	resp, err := server.GetEvents(context.Background(), &eventpb.GetEventsRequest{
+		From: timestamppb.New(start), To: timestamppb.New(start.AddDate(0, 0, 14)),
+	})
+	require.NoError(t, err)
+	assert.Len(t, resp.Events, 2)
+}
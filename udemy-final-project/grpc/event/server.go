@@ -2,103 +2,230 @@
 package event
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"io"
+	"slices"
 	"strconv"
+	"time"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/interceptor"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
 	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
-	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
-	"google.golang.org/grpc/metadata"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// DefaultHeartbeatInterval is how often WatchEvents sends a heartbeat while
+// no change is sent
+const DefaultHeartbeatInterval = 15 * time.Second
+
 // Server implements the gRPC EventService server
 type Server struct {
 	eventpb.UnimplementedEventServiceServer
-	eventService services.EventService
+	eventService      services.EventService
+	calendarService   services.CalendarService
+	policy            *policy.Policy
+	broadcaster       *stream.Broadcaster
+	heartbeatInterval time.Duration
 }
 
-// NewEventServer creates a new EventServer instance
-func NewEventServer(eventService services.EventService) *Server {
+// NewEventServer creates a new EventServer instance. WatchEvents streams the
+// changes published to the broadcaster.
+func NewEventServer(eventService services.EventService, calendarService services.CalendarService, p *policy.Policy, broadcaster *stream.Broadcaster) *Server {
 	return &Server{
-		eventService: eventService,
+		eventService:      eventService,
+		calendarService:   calendarService,
+		policy:            p,
+		broadcaster:       broadcaster,
+		heartbeatInterval: DefaultHeartbeatInterval,
+	}
+}
+
+// Methods lists the access of each EventService method. Listings accept an
+// optional token, which lets owners see their drafts.
+var Methods = interceptor.Methods{
+	eventpb.EventService_GetEvents_FullMethodName:       interceptor.Optional,
+	eventpb.EventService_GetEvent_FullMethodName:        interceptor.Optional,
+	eventpb.EventService_SearchEvents_FullMethodName:    interceptor.Optional,
+	eventpb.EventService_GetNearbyEvents_FullMethodName: interceptor.Optional,
+	eventpb.EventService_WatchEvents_FullMethodName:     interceptor.Optional,
+}
+
+// Helper function to return a permission error with a message naming the action
+func permissionDenied(message string) error {
+	return services.Forbidden("PERMISSION_DENIED", message)
+}
+
+// Helper function to convert a protobuf Address to the model address
+func convertFromProtoAddress(a *eventpb.Address) models.Address {
+	return models.Address{
+		Street:     a.GetStreet(),
+		City:       a.GetCity(),
+		Region:     a.GetRegion(),
+		PostalCode: a.GetPostalCode(),
+		Country:    a.GetCountry(),
 	}
 }
 
-// Helper function to extract user ID from gRPC context
-func getUserIDFromContext(ctx context.Context) (int64, error) {
-	// Get authorization header from metadata
-	md, ok := metadata.FromIncomingContext(ctx)
+// Helper function to convert protobuf timestamps to times
+func convertFromProtoTimestamps(timestamps []*timestamppb.Timestamp) []time.Time {
+	var times []time.Time
+	for _, ts := range timestamps {
+		times = append(times, ts.AsTime())
+	}
+	return times
+}
+
+// Helper function to build an occurrence target from protobuf request fields
+func convertToOccurrenceTarget(start *timestamppb.Timestamp, scope eventpb.RecurrenceScope) (models.OccurrenceTarget, error) {
+	var startTime *time.Time
+	if start != nil {
+		t := start.AsTime()
+		startTime = &t
+	}
+	scopes := map[eventpb.RecurrenceScope]string{
+		eventpb.RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED: "",
+		eventpb.RecurrenceScope_RECURRENCE_SCOPE_OCCURRENCE:  models.ScopeOccurrence,
+		eventpb.RecurrenceScope_RECURRENCE_SCOPE_FOLLOWING:   models.ScopeFollowing,
+		eventpb.RecurrenceScope_RECURRENCE_SCOPE_SERIES:      models.ScopeSeries,
+	}
+	name, ok := scopes[scope]
 	if !ok {
-		return 0, errors.New("no metadata found")
+		return models.OccurrenceTarget{}, models.ErrInvalidScope
 	}
+	return models.NewOccurrenceTarget(startTime, name)
+}
 
-	authHeader, exists := md["authorization"]
-	if !exists || len(authHeader) == 0 {
-		return 0, errors.New("authorization header is required")
+// GetEvents retrieves a page of events via gRPC, expanding recurring events when a time window is given
+func (s *Server) GetEvents(ctx context.Context, req *eventpb.GetEventsRequest) (*eventpb.GetEventsResponse, error) {
+	viewerID := interceptor.SubjectFromContext(ctx).UserID
+	sorts := map[eventpb.EventSort]string{
+		eventpb.EventSort_EVENT_SORT_UNSPECIFIED: "",
+		eventpb.EventSort_EVENT_SORT_DATE_TIME:   models.SortByDateTime,
+		eventpb.EventSort_EVENT_SORT_NAME:        models.SortByName,
+	}
+	sortBy, ok := sorts[req.SortBy]
+	if !ok {
+		return nil, models.ErrInvalidSort
 	}
 
-	tokenString := authHeader[0]
-	// Remove "Bearer " prefix if present
-	if len(tokenString) > 7 && tokenString[:7] == "Bearer " {
-		tokenString = tokenString[7:]
+	query := models.EventQuery{
+		Location:   req.Location,
+		UserID:     req.UserId,
+		Status:     req.Status,
+		ViewerID:   viewerID,
+		SortBy:     sortBy,
+		Descending: req.Descending,
+		PageSize:   int(req.PageSize),
+		PageToken:  req.PageToken,
+	}
+	if req.From != nil || req.To != nil {
+		if req.From == nil || req.To == nil {
+			return nil, services.Validation("INVALID_WINDOW", "from and to must be given together")
+		}
+		query.From, query.To = req.From.AsTime(), req.To.AsTime()
 	}
 
-	userID, err := security.ValidateToken(tokenString)
+	page, err := s.eventService.ListEvents(query)
 	if err != nil {
-		return 0, errors.New("invalid token")
+		return nil, err
 	}
 
-	return userID, nil
+	var protoEvents []*eventpb.Event
+	for _, event := range page.Events {
+		protoEvents = append(protoEvents, event.ToProto())
+	}
+
+	return &eventpb.GetEventsResponse{
+		Events:        protoEvents,
+		NextPageToken: page.NextPageToken,
+	}, nil
 }
 
-// Helper function to convert model Event to protobuf Event
-func convertToProtoEvent(e models.Event) *eventpb.Event {
-	return &eventpb.Event{
-		Id:          e.ID,
-		Name:        e.Name,
-		Description: e.Description,
-		Location:    e.Location,
-		DateTime:    timestamppb.New(e.DateTime),
-		UserId:      e.UserID,
+// SearchEvents runs a full-text search over events via gRPC
+func (s *Server) SearchEvents(ctx context.Context, req *eventpb.SearchEventsRequest) (*eventpb.SearchEventsResponse, error) {
+	viewerID := interceptor.SubjectFromContext(ctx).UserID
+	page, err := s.eventService.SearchEvents(models.SearchQuery{
+		Text:      req.Query,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+		ViewerID:  viewerID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &eventpb.SearchEventsResponse{NextPageToken: page.NextPageToken}
+	for _, result := range page.Results {
+		response.Results = append(response.Results, &eventpb.SearchResult{
+			Event:      result.Event.ToProto(),
+			Rank:       result.Rank,
+			Highlights: result.Highlights,
+		})
 	}
+	return response, nil
 }
 
-// GetEvents retrieves all events via gRPC
-func (s *Server) GetEvents(_ context.Context, _ *eventpb.GetEventsRequest) (*eventpb.GetEventsResponse, error) {
-	events, err := s.eventService.GetAllEvents()
+// GetNearbyEvents retrieves the events within a radius of a point via gRPC, nearest first
+func (s *Server) GetNearbyEvents(ctx context.Context, req *eventpb.GetNearbyEventsRequest) (*eventpb.GetNearbyEventsResponse, error) {
+	viewerID := interceptor.SubjectFromContext(ctx).UserID
+	events, err := s.eventService.GetNearbyEvents(models.NearbyQuery{
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		RadiusKm:  req.RadiusKm,
+		PageSize:  int(req.PageSize),
+		ViewerID:  viewerID,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	var protoEvents []*eventpb.Event
+	response := &eventpb.GetNearbyEventsResponse{}
 	for _, event := range events {
-		protoEvents = append(protoEvents, convertToProtoEvent(event))
+		response.Events = append(response.Events, &eventpb.NearbyEvent{
+			Event:      event.Event.ToProto(),
+			DistanceKm: event.DistanceKm,
+		})
 	}
-
-	return &eventpb.GetEventsResponse{
-		Events: protoEvents,
-	}, nil
+	return response, nil
 }
 
 // GetEvent retrieves a specific event by ID via gRPC
-func (s *Server) GetEvent(_ context.Context, req *eventpb.GetEventRequest) (*eventpb.GetEventResponse, error) {
+func (s *Server) GetEvent(ctx context.Context, req *eventpb.GetEventRequest) (*eventpb.GetEventResponse, error) {
+	viewerID := interceptor.SubjectFromContext(ctx).UserID
 	event, err := s.eventService.GetEventByID(strconv.FormatInt(req.Id, 10))
 	if err != nil {
 		return nil, err
 	}
+	if !event.VisibleTo(viewerID) {
+		return nil, services.NotFound("EVENT_NOT_FOUND", models.ErrEventNotFound.Error())
+	}
 
 	return &eventpb.GetEventResponse{
-		Event: convertToProtoEvent(*event),
+		Event: event.ToProto(),
 	}, nil
 }
 
 // CreateEvent creates a new event via gRPC
 func (s *Server) CreateEvent(ctx context.Context, req *eventpb.CreateEventRequest) (*eventpb.CreateEventResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
-	if err != nil {
+	subject := interceptor.SubjectFromContext(ctx)
+	if err := s.policy.Authorize(subject, policy.ActionCreateEvent, policy.Resource{OwnerID: subject.UserID}); err != nil {
+		return nil, permissionDenied("you do not have permission to create events")
+	}
+	userID := subject.UserID
+	if req.Capacity < 0 {
+		return nil, services.Validation("INVALID_CAPACITY", "capacity must not be negative")
+	}
+	if err := models.ValidateRecurrence(req.Rrule); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateCoordinates(req.Latitude, req.Longitude); err != nil {
 		return nil, err
 	}
 
@@ -108,32 +235,53 @@ func (s *Server) CreateEvent(ctx context.Context, req *eventpb.CreateEventReques
 		Location:    req.Location,
 		DateTime:    req.DateTime.AsTime(),
 		UserID:      userID,
+		Capacity:    int(req.Capacity),
+		RRule:       req.Rrule,
+		ExDates:     convertFromProtoTimestamps(req.Exdates),
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Address:     convertFromProtoAddress(req.Address),
+		Status:      req.Status,
 	}
 
-	createdEvent, err := s.eventService.CreateEvent(event)
+	createdEvent, err := s.eventService.CreateEvent(ctx, event)
 	if err != nil {
 		return nil, err
 	}
 
 	return &eventpb.CreateEventResponse{
-		Event: convertToProtoEvent(*createdEvent),
+		Event: createdEvent.ToProto(),
 	}, nil
 }
 
 // UpdateEvent updates an existing event via gRPC
 func (s *Server) UpdateEvent(ctx context.Context, req *eventpb.UpdateEventRequest) (*eventpb.UpdateEventResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
-	if err != nil {
-		return nil, err
-	}
+	subject := interceptor.SubjectFromContext(ctx)
 
-	// Check if the event exists and belongs to the user
+	// Check if the event exists and the user may edit it
 	existingEvent, err := s.eventService.GetEventByID(strconv.FormatInt(req.Id, 10))
 	if err != nil {
 		return nil, err
 	}
-	if existingEvent.UserID != userID {
-		return nil, errors.New("you do not have permission to update this event")
+	if err := s.policy.Authorize(subject, policy.ActionUpdateEvent, policy.Resource{OwnerID: existingEvent.UserID}); err != nil {
+		return nil, permissionDenied("you do not have permission to update this event")
+	}
+	if req.GetCapacity() < 0 {
+		return nil, services.Validation("INVALID_CAPACITY", "capacity must not be negative")
+	}
+	if err := models.ValidateRecurrence(req.Rrule); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+	capacity := existingEvent.Capacity
+	if req.Capacity != nil {
+		capacity = int(*req.Capacity)
+	}
+	target, err := convertToOccurrenceTarget(req.OccurrenceStart, req.Scope)
+	if err != nil {
+		return nil, err
 	}
 
 	updatedEvent := models.Event{
@@ -142,76 +290,196 @@ func (s *Server) UpdateEvent(ctx context.Context, req *eventpb.UpdateEventReques
 		Description: req.Description,
 		Location:    req.Location,
 		DateTime:    req.DateTime.AsTime(),
-		UserID:      userID,
+		UserID:      existingEvent.UserID, // an admin's edit keeps the owner
+		Capacity:    capacity,
+		RRule:       req.Rrule,
+		ExDates:     convertFromProtoTimestamps(req.Exdates),
+		Latitude:    req.Latitude,
+		Longitude:   req.Longitude,
+		Address:     convertFromProtoAddress(req.Address),
 	}
 
-	if err := s.eventService.UpdateEvent(updatedEvent); err != nil {
+	result, err := s.eventService.UpdateEventOccurrence(ctx, updatedEvent, target)
+	if err != nil {
 		return nil, err
 	}
 
 	return &eventpb.UpdateEventResponse{
-		Event: convertToProtoEvent(updatedEvent),
+		Event: result.ToProto(),
 	}, nil
 }
 
 // DeleteEvent deletes an event via gRPC
 func (s *Server) DeleteEvent(ctx context.Context, req *eventpb.DeleteEventRequest) (*eventpb.DeleteEventResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
+	subject := interceptor.SubjectFromContext(ctx)
+
+	// Check if the event exists and the user may delete it
+	event, err := s.eventService.GetEventByID(strconv.FormatInt(req.Id, 10))
 	if err != nil {
 		return nil, err
 	}
+	if err := s.policy.Authorize(subject, policy.ActionDeleteEvent, policy.Resource{OwnerID: event.UserID}); err != nil {
+		return nil, permissionDenied("you do not have permission to delete this event")
+	}
 
-	// Check if the event exists and belongs to the user
-	event, err := s.eventService.GetEventByID(strconv.FormatInt(req.Id, 10))
+	target, err := convertToOccurrenceTarget(req.OccurrenceStart, req.Scope)
 	if err != nil {
 		return nil, err
 	}
-	if event.UserID != userID {
-		return nil, errors.New("you do not have permission to delete this event")
+	if err := s.eventService.CancelEventOccurrence(ctx, strconv.FormatInt(req.Id, 10), target); err != nil {
+		return nil, err
 	}
 
-	if err := s.eventService.DeleteEvent(strconv.FormatInt(req.Id, 10)); err != nil {
+	return &eventpb.DeleteEventResponse{}, nil
+}
+
+// PublishEvent publishes a draft event via gRPC
+func (s *Server) PublishEvent(ctx context.Context, req *eventpb.PublishEventRequest) (*eventpb.PublishEventResponse, error) {
+	if err := s.requireEventOwner(ctx, req.Id, policy.ActionPublishEvent, "publish"); err != nil {
 		return nil, err
 	}
 
-	return &eventpb.DeleteEventResponse{}, nil
+	event, err := s.eventService.PublishEvent(ctx, strconv.FormatInt(req.Id, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventpb.PublishEventResponse{
+		Event: event.ToProto(),
+	}, nil
+}
+
+// CancelEvent cancels a draft or published event with a reason via gRPC
+func (s *Server) CancelEvent(ctx context.Context, req *eventpb.CancelEventRequest) (*eventpb.CancelEventResponse, error) {
+	if err := s.requireEventOwner(ctx, req.Id, policy.ActionCancelEvent, "cancel"); err != nil {
+		return nil, err
+	}
+
+	event, err := s.eventService.CancelEvent(ctx, strconv.FormatInt(req.Id, 10), req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventpb.CancelEventResponse{
+		Event: event.ToProto(),
+	}, nil
+}
+
+// Helper function to check that an event exists and the caller may perform
+// action on it, as its owner or an admin
+func (s *Server) requireEventOwner(ctx context.Context, id int64, action policy.Action, verb string) error {
+	subject := interceptor.SubjectFromContext(ctx)
+	event, err := s.eventService.GetEventByID(strconv.FormatInt(id, 10))
+	if err != nil {
+		return err
+	}
+	allowed := s.policy.Allowed(subject, action, policy.Resource{OwnerID: event.UserID})
+	if !allowed && !event.VisibleTo(subject.UserID) {
+		return models.ErrEventNotFound
+	}
+	if !allowed {
+		return permissionDenied("you do not have permission to " + verb + " this event")
+	}
+	return nil
 }
 
 // RegisterForEvent registers a user for an event via gRPC
 func (s *Server) RegisterForEvent(ctx context.Context, req *eventpb.RegisterForEventRequest) (*eventpb.RegisterForEventResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
+	userID := interceptor.SubjectFromContext(ctx).UserID
+
+	target, err := convertToOccurrenceTarget(req.OccurrenceStart, req.Scope)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.eventService.RegisterForEvent(userID, strconv.FormatInt(req.EventId, 10)); err != nil {
+	registration, err := s.eventService.RegisterForEvent(ctx, userID, strconv.FormatInt(req.EventId, 10), target)
+	if err != nil {
 		return nil, err
 	}
 
-	return &eventpb.RegisterForEventResponse{}, nil
+	response := &eventpb.RegisterForEventResponse{Status: registration.Status}
+	if registration.Status == models.RegistrationStatusWaitlisted {
+		position, err := s.eventService.GetWaitlistPosition(registration)
+		if err != nil {
+			return nil, err
+		}
+		response.WaitlistPosition = position
+	}
+	return response, nil
 }
 
 // CancelRegistration cancels a user's registration for an event via gRPC
 func (s *Server) CancelRegistration(ctx context.Context, req *eventpb.CancelRegistrationRequest) (*eventpb.CancelRegistrationResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
+	userID := interceptor.SubjectFromContext(ctx).UserID
+
+	target, err := convertToOccurrenceTarget(req.OccurrenceStart, eventpb.RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := s.eventService.CancelRegistration(userID, strconv.FormatInt(req.EventId, 10)); err != nil {
+	if err := s.eventService.CancelRegistration(ctx, userID, strconv.FormatInt(req.EventId, 10), target); err != nil {
 		return nil, err
 	}
 
 	return &eventpb.CancelRegistrationResponse{}, nil
 }
 
-// GetUserRegistrations retrieves all events a user is registered for via gRPC
-func (s *Server) GetUserRegistrations(ctx context.Context, _ *eventpb.GetUserRegistrationsRequest) (*eventpb.GetUserRegistrationsResponse, error) {
-	userID, err := getUserIDFromContext(ctx)
+// GetRegistrations retrieves the caller's registrations for an event with their status history via gRPC
+func (s *Server) GetRegistrations(ctx context.Context, req *eventpb.GetRegistrationsRequest) (*eventpb.GetRegistrationsResponse, error) {
+	userID := interceptor.SubjectFromContext(ctx).UserID
+
+	registrations, err := s.eventService.GetRegistrations(userID, strconv.FormatInt(req.EventId, 10))
 	if err != nil {
 		return nil, err
 	}
 
+	response := &eventpb.GetRegistrationsResponse{}
+	for _, registration := range registrations {
+		response.Registrations = append(response.Registrations, registration.ToProto())
+	}
+	return response, nil
+}
+
+// ListEventAttendees retrieves a page of the registrations for an event owned by the caller via gRPC
+func (s *Server) ListEventAttendees(ctx context.Context, req *eventpb.ListEventAttendeesRequest) (*eventpb.ListEventAttendeesResponse, error) {
+	if err := s.requireEventOwner(ctx, req.EventId, policy.ActionManageAttendees, "view the attendees of"); err != nil {
+		return nil, err
+	}
+
+	page, err := s.eventService.ListEventAttendees(strconv.FormatInt(req.EventId, 10), models.AttendeeQuery{
+		Status:    req.Status,
+		PageSize:  int(req.PageSize),
+		PageToken: req.PageToken,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &eventpb.ListEventAttendeesResponse{NextPageToken: page.NextPageToken}
+	for _, attendee := range page.Attendees {
+		response.Attendees = append(response.Attendees, attendee.ToProto())
+	}
+	return response, nil
+}
+
+// RemoveAttendee removes a registration for an event owned by the caller via gRPC
+func (s *Server) RemoveAttendee(ctx context.Context, req *eventpb.RemoveAttendeeRequest) (*eventpb.RemoveAttendeeResponse, error) {
+	if err := s.requireEventOwner(ctx, req.EventId, policy.ActionManageAttendees, "remove the attendees of"); err != nil {
+		return nil, err
+	}
+
+	err := s.eventService.RemoveAttendee(ctx, strconv.FormatInt(req.EventId, 10), strconv.FormatInt(req.RegistrationId, 10))
+	if err != nil {
+		return nil, err
+	}
+
+	return &eventpb.RemoveAttendeeResponse{}, nil
+}
+
+// GetUserRegistrations retrieves all events a user is registered for via gRPC
+func (s *Server) GetUserRegistrations(ctx context.Context, _ *eventpb.GetUserRegistrationsRequest) (*eventpb.GetUserRegistrationsResponse, error) {
+	userID := interceptor.SubjectFromContext(ctx).UserID
+
 	events, err := s.eventService.GetUserRegistrations(userID)
 	if err != nil {
 		return nil, err
@@ -219,10 +487,191 @@ func (s *Server) GetUserRegistrations(ctx context.Context, _ *eventpb.GetUserReg
 
 	var protoEvents []*eventpb.Event
 	for _, event := range events {
-		protoEvents = append(protoEvents, convertToProtoEvent(event))
+		protoEvents = append(protoEvents, event.ToProto())
 	}
 
 	return &eventpb.GetUserRegistrationsResponse{
 		Events: protoEvents,
 	}, nil
 }
+
+// ImportEvents creates events from an iCalendar document streamed in chunks via gRPC
+func (s *Server) ImportEvents(stream grpc.ClientStreamingServer[eventpb.ImportEventsRequest, eventpb.ImportEventsResponse]) error {
+	subject := interceptor.SubjectFromContext(stream.Context())
+	if err := s.policy.Authorize(subject, policy.ActionImportEvents, policy.Resource{OwnerID: subject.UserID}); err != nil {
+		return permissionDenied("you do not have permission to import events")
+	}
+	userID := subject.UserID
+
+	var document bytes.Buffer
+	dryRun, first := false, true
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if first {
+			dryRun, first = req.DryRun, false
+		}
+		if document.Len()+len(req.Chunk) > services.MaxImportSize {
+			return services.Validation("CALENDAR_TOO_LARGE", "calendar file is too large")
+		}
+		document.Write(req.Chunk)
+	}
+
+	results, err := s.calendarService.ImportEvents(stream.Context(), userID, &document, dryRun)
+	if err != nil {
+		return err
+	}
+
+	response := &eventpb.ImportEventsResponse{DryRun: dryRun}
+	for _, result := range results {
+		protoResult := &eventpb.ImportResult{
+			Index:  int32(result.Index),
+			Uid:    result.UID,
+			Name:   result.Name,
+			Status: result.Status,
+			Error:  result.Error,
+		}
+		if result.Event != nil {
+			protoResult.Event = result.Event.ToProto()
+		}
+		if result.Status == services.ImportStatusFailed {
+			response.Failed++
+		} else if result.Status == services.ImportStatusCreated {
+			response.Created++
+		}
+		response.Results = append(response.Results, protoResult)
+	}
+	return stream.SendAndClose(response)
+}
+
+// WatchEvents streams the event changes matching the request's filters via
+// gRPC until the client cancels the call. A heartbeat carrying the current
+// cursor is sent whenever no change was sent for the heartbeat interval. The
+// call fails with UNAVAILABLE when the client falls behind or the server shuts
+// down; the client then resumes from its last cursor.
+func (s *Server) WatchEvents(req *eventpb.WatchEventsRequest, srv grpc.ServerStreamingServer[eventpb.WatchEventsResponse]) error {
+	filter, err := newWatchFilter(req)
+	if err != nil {
+		return err
+	}
+	ctx := srv.Context()
+	viewerID := interceptor.SubjectFromContext(ctx).UserID
+
+	sub, backlog, resumed := s.broadcaster.Subscribe(req.Cursor)
+	defer sub.Close()
+	if !resumed {
+		if err := srv.Send(&eventpb.WatchEventsResponse{
+			Message: &eventpb.WatchEventsResponse_Resync{Resync: &eventpb.Resync{}},
+		}); err != nil {
+			return err
+		}
+	}
+
+	cursor := ""
+	if resumed {
+		cursor = req.Cursor
+	}
+	send := func(change stream.Change) error {
+		cursor = change.ID
+		if !filter.matches(change, viewerID) {
+			return nil
+		}
+		return srv.Send(&eventpb.WatchEventsResponse{
+			Message: &eventpb.WatchEventsResponse_Change{Change: &eventpb.EventChange{
+				Cursor:     change.ID,
+				Action:     change.Envelope.GetType(),
+				OccurredAt: change.Envelope.GetOccurredAt(),
+				Event:      change.Envelope.GetEvent(),
+			}},
+		})
+	}
+	for _, change := range backlog {
+		if err := send(change); err != nil {
+			return err
+		}
+	}
+
+	heartbeat := time.NewTicker(s.heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-heartbeat.C:
+			if err := srv.Send(&eventpb.WatchEventsResponse{
+				Message: &eventpb.WatchEventsResponse_Heartbeat{Heartbeat: &eventpb.Heartbeat{
+					Time:   timestamppb.Now(),
+					Cursor: cursor,
+				}},
+			}); err != nil {
+				return err
+			}
+		case change, ok := <-sub.Changes():
+			if !ok {
+				return watchClosedError(sub.Err())
+			}
+			if err := send(change); err != nil {
+				return err
+			}
+			heartbeat.Reset(s.heartbeatInterval)
+		}
+	}
+}
+
+// watchFilter selects the changes a WatchEvents call receives
+type watchFilter struct {
+	eventIDs map[int64]bool
+	userID   int64
+	actions  map[string]bool
+}
+
+// Helper function to build the filter of a WatchEvents request, rejecting
+// unknown actions
+func newWatchFilter(req *eventpb.WatchEventsRequest) (watchFilter, error) {
+	filter := watchFilter{userID: req.UserId}
+	if len(req.EventIds) > 0 {
+		filter.eventIDs = map[int64]bool{}
+		for _, id := range req.EventIds {
+			filter.eventIDs[id] = true
+		}
+	}
+	if len(req.Actions) > 0 {
+		filter.actions = map[string]bool{}
+		for _, action := range req.Actions {
+			if !slices.Contains(models.EventActions, action) {
+				return filter, services.Validation("INVALID_ACTION", "unknown action "+strconv.Quote(action))
+			}
+			filter.actions[action] = true
+		}
+	}
+	return filter, nil
+}
+
+// matches reports whether a change passes the filter and viewerID may see it
+func (f watchFilter) matches(change stream.Change, viewerID int64) bool {
+	event := change.Envelope.GetEvent()
+	if f.eventIDs != nil && !f.eventIDs[event.GetId()] {
+		return false
+	}
+	if f.userID != 0 && event.GetUserId() != f.userID {
+		return false
+	}
+	if f.actions != nil && !f.actions[change.Envelope.GetType()] {
+		return false
+	}
+	return change.VisibleTo(viewerID)
+}
+
+// Helper function to return the error ending a watch whose subscription was
+// closed by the broadcaster
+func watchClosedError(err error) error {
+	if errors.Is(err, stream.ErrLagged) {
+		return interceptor.NewError(codes.Unavailable, "WATCH_LAGGED", "the client fell behind; resume from the last cursor")
+	}
+	return interceptor.NewError(codes.Unavailable, "SERVER_SHUTTING_DOWN", "the server is shutting down; resume from the last cursor")
+}
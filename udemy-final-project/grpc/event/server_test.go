@@ -0,0 +1,173 @@
+package event
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/interceptor"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// watchServer starts an EventService serving WatchEvents from broadcaster and
+// returns a client connected to it. Bearer tokens are user IDs: "Bearer 7"
+// authenticates user 7.
+func watchServer(t *testing.T, broadcaster *stream.Broadcaster) eventpb.EventServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	auth := interceptor.NewAuth(Methods, func(token string) (*security.Claims, error) {
+		return &security.Claims{UserID: int64(token[0] - '0')}, nil
+	})
+	server := grpc.NewServer(
+		grpc.ChainStreamInterceptor(interceptor.StreamErrors(), auth.Stream()),
+	)
+	eventServer := NewEventServer(nil, nil, policy.New(), broadcaster)
+	eventServer.heartbeatInterval = 50 * time.Millisecond
+	eventpb.RegisterEventServiceServer(server, eventServer)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return listener.Dial() }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return eventpb.NewEventServiceClient(conn)
+}
+
+// change returns an envelope of a change of an event owned by user 1
+func change(action string, eventID int64, status string) *eventpb.EventEnvelope {
+	return &eventpb.EventEnvelope{
+		Type:  action,
+		Event: &eventpb.Event{Id: eventID, UserId: 1, Status: status},
+	}
+}
+
+// nextChange receives responses until a change arrives
+func nextChange(t *testing.T, watch grpc.ServerStreamingClient[eventpb.WatchEventsResponse]) *eventpb.EventChange {
+	t.Helper()
+	for {
+		resp, err := watch.Recv()
+		require.NoError(t, err)
+		if resp.GetChange() != nil {
+			return resp.GetChange()
+		}
+	}
+}
+
+func TestWatchEvents_Filters(t *testing.T) {
+	broadcaster := stream.NewBroadcaster(100, 100)
+	client := watchServer(t, broadcaster)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.WatchEvents(ctx, &eventpb.WatchEventsRequest{
+		EventIds: []int64{1, 2},
+		Actions:  []string{models.EventActionUpdated, models.EventActionDeleted},
+	})
+	require.NoError(t, err)
+	// Wait for the subscription: the first heartbeat is sent once it exists
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetHeartbeat())
+
+	broadcaster.Publish(change(models.EventActionCreated, 1, models.EventStatusPublished))
+	broadcaster.Publish(change(models.EventActionUpdated, 3, models.EventStatusPublished))
+	broadcaster.Publish(change(models.EventActionUpdated, 2, models.EventStatusDraft)) // someone else's draft
+	last := broadcaster.Publish(change(models.EventActionDeleted, 1, models.EventStatusPublished))
+
+	received := nextChange(t, watch)
+	assert.Equal(t, models.EventActionDeleted, received.Action)
+	assert.Equal(t, int64(1), received.Event.GetId())
+	assert.Equal(t, last.ID, received.Cursor)
+}
+
+func TestWatchEvents_ResumeAndHeartbeat(t *testing.T) {
+	broadcaster := stream.NewBroadcaster(100, 100)
+	client := watchServer(t, broadcaster)
+	first := broadcaster.Publish(change(models.EventActionCreated, 1, models.EventStatusPublished))
+	broadcaster.Publish(change(models.EventActionPublished, 1, models.EventStatusPublished))
+	hidden := broadcaster.Publish(change(models.EventActionUpdated, 4, models.EventStatusDraft))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	// The owner of the draft sees its change
+	ownerCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer 1")
+	watch, err := client.WatchEvents(ownerCtx, &eventpb.WatchEventsRequest{Cursor: first.ID})
+	require.NoError(t, err)
+	assert.Equal(t, models.EventActionPublished, nextChange(t, watch).Action)
+	assert.Equal(t, hidden.ID, nextChange(t, watch).Cursor)
+
+	// An anonymous watcher does not, but its heartbeat moves past it
+	watch, err = client.WatchEvents(ctx, &eventpb.WatchEventsRequest{Cursor: first.ID})
+	require.NoError(t, err)
+	assert.Equal(t, models.EventActionPublished, nextChange(t, watch).Action)
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetHeartbeat())
+	assert.Equal(t, hidden.ID, resp.GetHeartbeat().GetCursor())
+
+	// A cursor that cannot be resumed from asks the client to reload first
+	watch, err = client.WatchEvents(ctx, &eventpb.WatchEventsRequest{Cursor: "expired-1"})
+	require.NoError(t, err)
+	resp, err = watch.Recv()
+	require.NoError(t, err)
+	assert.NotNil(t, resp.GetResync())
+}
+
+func TestWatchEvents_EndOfStream(t *testing.T) {
+	broadcaster := stream.NewBroadcaster(100, 1)
+	client := watchServer(t, broadcaster)
+
+	_, err := recvError(client, &eventpb.WatchEventsRequest{Actions: []string{"renamed"}}, nil)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// The client cancelling ends the call
+	ctx, cancel := context.WithCancel(context.Background())
+	watch, err := client.WatchEvents(ctx, &eventpb.WatchEventsRequest{})
+	require.NoError(t, err)
+	_, err = watch.Recv()
+	require.NoError(t, err)
+	cancel()
+	_, err = watch.Recv()
+	assert.Equal(t, codes.Canceled, status.Code(err))
+
+	// Shutting down ends open watches so they can resume elsewhere
+	_, err = recvError(client, &eventpb.WatchEventsRequest{}, broadcaster.Close)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+// recvError starts a watch, calls action once it is subscribed, and returns
+// the error ending the watch
+func recvError(client eventpb.EventServiceClient, req *eventpb.WatchEventsRequest, action func()) (*eventpb.WatchEventsResponse, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	watch, err := client.WatchEvents(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		resp, err := watch.Recv()
+		if err != nil {
+			return resp, err
+		}
+		if action != nil {
+			action()
+			action = nil
+		}
+	}
+}
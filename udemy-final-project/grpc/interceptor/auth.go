@@ -0,0 +1,154 @@
+// Package interceptor provides the gRPC server interceptors shared by all
+// services: the trace of each call, authentication by method and the mapping
+// of domain errors to gRPC status codes.
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ErrMissingToken is returned when a method requiring authentication is
+// called without an access token
+var ErrMissingToken = errors.New("authorization header is required")
+
+// Access is how a method may be called
+type Access int
+
+const (
+	// Authenticated methods require a valid access token
+	Authenticated Access = iota
+	// Optional methods accept anonymous callers, but a token that is sent must be valid
+	Optional
+	// Public methods ignore the authorization metadata
+	Public
+)
+
+// Methods maps full method names ("/event.EventService/GetEvent") or whole
+// services ("/grpc.reflection.v1.ServerReflection/") to their access. Methods
+// not listed require authentication.
+type Methods map[string]Access
+
+// access returns the access of a full method name
+func (m Methods) access(fullMethod string) Access {
+	if access, ok := m[fullMethod]; ok {
+		return access
+	}
+	if i := strings.LastIndex(fullMethod, "/"); i > 0 {
+		if access, ok := m[fullMethod[:i+1]]; ok {
+			return access
+		}
+	}
+	return Authenticated
+}
+
+// Authenticator verifies an access token and returns its claims
+type Authenticator func(token string) (*security.Claims, error)
+
+// Auth authenticates calls according to the access of each method and stores
+// the caller's claims in the context handlers receive
+type Auth struct {
+	methods      Methods
+	authenticate Authenticator
+}
+
+// NewAuth creates the authentication interceptors for the given methods
+func NewAuth(methods Methods, authenticate Authenticator) *Auth {
+	return &Auth{methods: methods, authenticate: authenticate}
+}
+
+// Unary returns the interceptor for unary calls
+func (a *Auth) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := a.authenticateCall(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// Stream returns the interceptor for streaming calls
+func (a *Auth) Stream() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateCall(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticateCall verifies the token sent in the authorization metadata as
+// the method requires and returns the context carrying its claims
+func (a *Auth) authenticateCall(ctx context.Context, fullMethod string) (context.Context, error) {
+	access := a.methods.access(fullMethod)
+	if access == Public {
+		return ctx, nil
+	}
+
+	token := bearerToken(ctx)
+	if token == "" {
+		if access == Optional {
+			return ctx, nil
+		}
+		return nil, ErrMissingToken
+	}
+	claims, err := a.authenticate(token)
+	if err != nil {
+		return nil, err
+	}
+	return WithClaims(ctx, claims), nil
+}
+
+// bearerToken returns the token of the authorization metadata, without its
+// "Bearer " prefix
+func bearerToken(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(values[0], "Bearer ")
+}
+
+// contextStream is a server stream with a replaced context
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// claimsKey is the context key of the caller's claims
+type claimsKey struct{}
+
+// WithClaims returns a context carrying the caller's claims
+func WithClaims(ctx context.Context, claims *security.Claims) context.Context {
+	return context.WithValue(ctx, claimsKey{}, claims)
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller. It
+// reports false for anonymous callers.
+func ClaimsFromContext(ctx context.Context) (*security.Claims, bool) {
+	claims, ok := ctx.Value(claimsKey{}).(*security.Claims)
+	return claims, ok
+}
+
+// SubjectFromContext returns the authenticated caller as a policy subject.
+// Anonymous callers are the zero subject, user 0.
+func SubjectFromContext(ctx context.Context) policy.Subject {
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok {
+		return policy.Subject{}
+	}
+	return policy.Subject{UserID: claims.UserID, Role: claims.Role}
+}
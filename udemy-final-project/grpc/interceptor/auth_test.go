@@ -0,0 +1,100 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeAuthenticator accepts the token "good" as user 7, an organizer
+func fakeAuthenticator(token string) (*security.Claims, error) {
+	if token != "good" {
+		return nil, models.ErrInvalidToken
+	}
+	return &security.Claims{UserID: 7, Role: policy.RoleOrganizer}, nil
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", token))
+}
+
+// callUnary runs the unary interceptor for method and returns the subject the handler saw
+func callUnary(t *testing.T, auth *Auth, ctx context.Context, method string) (policy.Subject, error) {
+	t.Helper()
+	var subject policy.Subject
+	_, err := auth.Unary()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+		subject = SubjectFromContext(ctx)
+		return nil, nil
+	})
+	return subject, err
+}
+
+func TestAuth_Unary(t *testing.T) {
+	auth := NewAuth(Methods{
+		"/event.EventService/GetEvent":          Optional,
+		"/auth.AuthService/Login":               Public,
+		"/grpc.reflection.v1.ServerReflection/": Public,
+	}, fakeAuthenticator)
+
+	// Methods not listed require a valid token
+	_, err := callUnary(t, auth, context.Background(), "/event.EventService/CreateEvent")
+	assert.ErrorIs(t, err, ErrMissingToken)
+	_, err = callUnary(t, auth, withToken("Bearer bad"), "/event.EventService/CreateEvent")
+	assert.ErrorIs(t, err, models.ErrInvalidToken)
+	subject, err := callUnary(t, auth, withToken("Bearer good"), "/event.EventService/CreateEvent")
+	require.NoError(t, err)
+	assert.Equal(t, policy.Subject{UserID: 7, Role: policy.RoleOrganizer}, subject)
+
+	// Optional methods let anonymous callers through, but check sent tokens
+	subject, err = callUnary(t, auth, context.Background(), "/event.EventService/GetEvent")
+	require.NoError(t, err)
+	assert.Equal(t, policy.Subject{}, subject)
+	_, err = callUnary(t, auth, withToken("bad"), "/event.EventService/GetEvent")
+	assert.ErrorIs(t, err, models.ErrInvalidToken)
+	subject, err = callUnary(t, auth, withToken("good"), "/event.EventService/GetEvent")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), subject.UserID)
+
+	// Public methods and services ignore the token
+	_, err = callUnary(t, auth, withToken("bad"), "/auth.AuthService/Login")
+	assert.NoError(t, err)
+	_, err = callUnary(t, auth, context.Background(), "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo")
+	assert.NoError(t, err)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuth_Stream(t *testing.T) {
+	auth := NewAuth(Methods{}, fakeAuthenticator)
+	info := &grpc.StreamServerInfo{FullMethod: "/event.EventService/ImportEvents", IsClientStream: true}
+
+	var claims *security.Claims
+	handler := func(_ any, stream grpc.ServerStream) error {
+		var ok bool
+		if claims, ok = ClaimsFromContext(stream.Context()); !ok {
+			return errors.New("no claims")
+		}
+		return nil
+	}
+
+	err := auth.Stream()(nil, &fakeStream{ctx: context.Background()}, info, handler)
+	assert.ErrorIs(t, err, ErrMissingToken)
+
+	require.NoError(t, auth.Stream()(nil, &fakeStream{ctx: withToken("Bearer good")}, info, handler))
+	assert.Equal(t, int64(7), claims.UserID)
+}
@@ -0,0 +1,85 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the google.rpc.ErrorInfo details attached to errors
+const ErrorDomain = "event-api"
+
+// kindCodes gives the status code of each kind of domain error.
+// ErrAlreadyExists is listed before ErrConflict, which it wraps.
+var kindCodes = []struct {
+	kind error
+	code codes.Code
+}{
+	{services.ErrNotFound, codes.NotFound},
+	{services.ErrForbidden, codes.PermissionDenied},
+	{services.ErrAlreadyExists, codes.AlreadyExists},
+	{services.ErrConflict, codes.FailedPrecondition},
+	{services.ErrValidation, codes.InvalidArgument},
+	{services.ErrUnauthenticated, codes.Unauthenticated},
+}
+
+// NewError creates a status error with the given code, message and an
+// ErrorInfo detail carrying reason
+func NewError(code codes.Code, reason, message string) error {
+	st := status.New(code, message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// StatusError converts an error returned by a handler to a status error.
+// Status errors are returned as they are, domain errors get the code of their
+// kind (see services.AsError) and
+// context errors become Canceled or DeadlineExceeded. Any other error is
+// logged and reported as Internal, without its message.
+func StatusError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, ErrMissingToken) {
+		return NewError(codes.Unauthenticated, "MISSING_TOKEN", err.Error())
+	}
+	if domainErr, ok := services.AsError(err); ok {
+		for _, kind := range kindCodes {
+			if errors.Is(domainErr.Kind, kind.kind) {
+				return NewError(kind.code, domainErr.Reason, domainErr.Message)
+			}
+		}
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	log.Printf("gRPC internal error: %v", err)
+	return NewError(codes.Internal, "INTERNAL", "internal server error")
+}
+
+// UnaryErrors returns the interceptor converting the errors of unary calls with StatusError
+func UnaryErrors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		return resp, StatusError(err)
+	}
+}
+
+// StreamErrors returns the interceptor converting the errors of streaming calls with StatusError
+func StreamErrors() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return StatusError(handler(srv, stream))
+	}
+}
@@ -0,0 +1,85 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorInfo returns the ErrorInfo detail of a status error
+func errorInfo(t *testing.T, err error) *errdetails.ErrorInfo {
+	t.Helper()
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+	t.Fatalf("no ErrorInfo in %v", err)
+	return nil
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		err    error
+		code   codes.Code
+		reason string
+	}{
+		{models.ErrEventNotFound, codes.NotFound, "EVENT_NOT_FOUND"},
+		{fmt.Errorf("loading event: %w", models.ErrOccurrenceNotFound), codes.NotFound, "OCCURRENCE_NOT_FOUND"},
+		{policy.ErrForbidden, codes.PermissionDenied, "PERMISSION_DENIED"},
+		{models.ErrEmailTaken, codes.AlreadyExists, "EMAIL_TAKEN"},
+		{models.ErrAlreadyRegistered, codes.AlreadyExists, "ALREADY_REGISTERED"},
+		{ErrMissingToken, codes.Unauthenticated, "MISSING_TOKEN"},
+		{models.ErrTokenRevoked, codes.Unauthenticated, "TOKEN_REVOKED"},
+		{models.ErrInvalidScope, codes.InvalidArgument, "INVALID_SCOPE"},
+		{models.ErrRegistrationClosed, codes.FailedPrecondition, "REGISTRATION_CLOSED"},
+		{services.NotFound("CALENDAR_NOT_FOUND", "calendar not found"), codes.NotFound, "CALENDAR_NOT_FOUND"},
+		{services.Validation("INVALID_RECURRENCE", "bad rule"), codes.InvalidArgument, "INVALID_RECURRENCE"},
+		{errors.New("connection reset"), codes.Internal, "INTERNAL"},
+	}
+	for _, test := range tests {
+		t.Run(test.err.Error(), func(t *testing.T) {
+			err := StatusError(test.err)
+			assert.Equal(t, test.code, status.Code(err))
+			info := errorInfo(t, err)
+			assert.Equal(t, test.reason, info.Reason)
+			assert.Equal(t, ErrorDomain, info.Domain)
+		})
+	}
+
+	// Internal errors do not leak their message
+	assert.Equal(t, "internal server error", status.Convert(StatusError(errors.New("dial tcp 10.0.0.1"))).Message())
+	assert.Equal(t, models.ErrEventNotFound.Error(), status.Convert(StatusError(models.ErrEventNotFound)).Message())
+
+	// Status errors and context errors keep their code
+	assert.Equal(t, codes.InvalidArgument, status.Code(StatusError(status.Error(codes.InvalidArgument, "bad"))))
+	assert.Equal(t, codes.Canceled, status.Code(StatusError(context.Canceled)))
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(StatusError(fmt.Errorf("query: %w", context.DeadlineExceeded))))
+	assert.NoError(t, StatusError(nil))
+}
+
+func TestUnaryErrors(t *testing.T) {
+	_, err := UnaryErrors()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return nil, models.ErrEventNotFound
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := UnaryErrors()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
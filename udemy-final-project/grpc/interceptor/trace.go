@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"context"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/tracing"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UnaryTrace returns the interceptor putting the trace of unary calls in their context
+func UnaryTrace() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withTrace(ctx), req)
+	}
+}
+
+// StreamTrace returns the interceptor putting the trace of streaming calls in their context
+func StreamTrace() grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: stream, ctx: withTrace(stream.Context())})
+	}
+}
+
+// withTrace returns ctx carrying the trace ID of the traceparent metadata,
+// or a new one when the caller sent none
+func withTrace(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var traceparent string
+	if values := md.Get(tracing.Header); len(values) > 0 {
+		traceparent = values[0]
+	}
+	return tracing.FromTraceparent(ctx, traceparent)
+}
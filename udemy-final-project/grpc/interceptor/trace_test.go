@@ -0,0 +1,31 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/tracing"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryTrace(t *testing.T) {
+	trace := func(ctx context.Context) string {
+		var id string
+		_, _ = UnaryTrace()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+			id = tracing.ID(ctx)
+			return nil, nil
+		})
+		return id
+	}
+
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(tracing.Header, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace(ctx))
+
+	// Calls without a valid traceparent start a trace
+	ctx = metadata.NewIncomingContext(context.Background(), metadata.Pairs(tracing.Header, "invalid"))
+	assert.Len(t, trace(ctx), 32)
+	assert.Len(t, trace(context.Background()), 32)
+}
@@ -0,0 +1,279 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+)
+
+// ErrInvalidCalendar is returned when a document is not a well-formed VCALENDAR
+var ErrInvalidCalendar = errors.New("invalid iCalendar document")
+
+// maxLineBytes bounds a single unfolded content line
+const maxLineBytes = 1 << 20
+
+// DecodedEvent is a VEVENT read from a document. Err is set when the VEVENT
+// could not be interpreted; the other fields then hold whatever was read.
+type DecodedEvent struct {
+	Event
+	Err error
+}
+
+// property is a single content line such as DTSTART;TZID=Europe/Istanbul:20250107T180000
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode parses the VEVENTs of an iCalendar document. A malformed VEVENT does
+// not abort decoding; it is returned with Err set so callers can report it.
+// Nested components such as VALARM and all other top-level components are ignored.
+func Decode(r io.Reader) ([]DecodedEvent, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events   []DecodedEvent
+		stack    []string
+		current  []property
+		sawStart bool
+		sawEnd   bool
+	)
+	for n, line := range lines {
+		if line == "" {
+			continue
+		}
+		prop, err := parseProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidCalendar, n+1, err)
+		}
+
+		switch prop.name {
+		case "BEGIN":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 {
+				if component != "VCALENDAR" || sawStart {
+					return nil, fmt.Errorf("%w: unexpected BEGIN:%s", ErrInvalidCalendar, component)
+				}
+				sawStart = true
+			}
+			stack = append(stack, component)
+			if component == "VEVENT" && len(stack) == 2 {
+				current = nil
+			}
+			continue
+		case "END":
+			component := strings.ToUpper(prop.value)
+			if len(stack) == 0 || stack[len(stack)-1] != component {
+				return nil, fmt.Errorf("%w: unexpected END:%s", ErrInvalidCalendar, component)
+			}
+			if component == "VEVENT" && len(stack) == 2 {
+				events = append(events, decodeEvent(current))
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				sawEnd = true
+			}
+			continue
+		}
+
+		if len(stack) == 2 && stack[1] == "VEVENT" {
+			current = append(current, prop)
+		}
+	}
+
+	if !sawStart || !sawEnd || len(stack) != 0 {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR or END:VCALENDAR", ErrInvalidCalendar)
+	}
+	return events, nil
+}
+
+// unfold reads content lines, joining folded continuation lines
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCalendar, err)
+	}
+	return lines, nil
+}
+
+// parseProperty splits a content line into its name, parameters and value.
+// Colons and semicolons inside quoted parameter values are not separators.
+func parseProperty(line string) (property, error) {
+	colon, quoted := -1, false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			quoted = !quoted
+		} else if line[i] == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, fmt.Errorf("malformed content line %q", line)
+	}
+
+	head := splitUnquoted(line[:colon], ';')
+	prop := property{
+		name:   strings.ToUpper(head[0]),
+		params: make(map[string]string, len(head)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range head[1:] {
+		name, value, _ := strings.Cut(param, "=")
+		prop.params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func splitUnquoted(s string, sep byte) []string {
+	var parts []string
+	start, quoted := 0, false
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// decodeEvent interprets the properties of a single VEVENT
+func decodeEvent(props []property) DecodedEvent {
+	var (
+		decoded  DecodedEvent
+		errs     []error
+		hasStart bool
+	)
+	for _, prop := range props {
+		var err error
+		switch prop.name {
+		case "UID":
+			decoded.UID = prop.value
+		case "SUMMARY":
+			decoded.Summary = UnescapeText(prop.value)
+		case "DESCRIPTION":
+			decoded.Description = UnescapeText(prop.value)
+		case "LOCATION":
+			decoded.Location = UnescapeText(prop.value)
+		case "GEO":
+			decoded.Latitude, decoded.Longitude, err = parseGeo(prop.value)
+		case "STATUS":
+			decoded.Status = strings.ToUpper(prop.value)
+		case "RRULE":
+			decoded.RRule = prop.value
+			if _, parseErr := recurrence.Parse(prop.value); parseErr != nil {
+				err = parseErr
+			}
+		case "DTSTART":
+			decoded.Start, err = parseTime(prop)
+			hasStart = err == nil
+		case "DTSTAMP":
+			decoded.Stamp, err = parseTime(prop)
+		case "RECURRENCE-ID":
+			var t time.Time
+			if t, err = parseTime(prop); err == nil {
+				decoded.RecurrenceID = &t
+			}
+		case "EXDATE":
+			for _, value := range strings.Split(prop.value, ",") {
+				var t time.Time
+				t, err = parseTime(property{name: prop.name, params: prop.params, value: value})
+				if err != nil {
+					break
+				}
+				decoded.ExDates = append(decoded.ExDates, t)
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", prop.name, err))
+		}
+	}
+
+	if !hasStart && len(errs) == 0 {
+		errs = append(errs, errors.New("DTSTART is required"))
+	}
+	decoded.Err = errors.Join(errs...)
+	return decoded
+}
+
+// parseGeo parses a GEO value such as "41.0369;28.985"
+func parseGeo(value string) (*float64, *float64, error) {
+	latText, lngText, ok := strings.Cut(value, ";")
+	lat, latErr := strconv.ParseFloat(latText, 64)
+	lng, lngErr := strconv.ParseFloat(lngText, 64)
+	if !ok || latErr != nil || lngErr != nil {
+		return nil, nil, fmt.Errorf("invalid GEO %q", value)
+	}
+	return &lat, &lng, nil
+}
+
+// parseTime parses a DATE or DATE-TIME value, honouring the TZID parameter.
+// Floating times without a TZID are interpreted as UTC.
+func parseTime(prop property) (time.Time, error) {
+	value := prop.value
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, time.UTC)
+	}
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse("20060102T150405Z", value)
+	}
+
+	location := time.UTC
+	if tzid := prop.params["TZID"]; tzid != "" {
+		var err error
+		if location, err = time.LoadLocation(tzid); err != nil {
+			return time.Time{}, fmt.Errorf("unknown time zone %q", tzid)
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, nil
+}
+
+// UnescapeText reverses EscapeText
+func UnescapeText(value string) string {
+	if !strings.Contains(value, `\`) {
+		return value
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,77 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode_RoundTrip(t *testing.T) {
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	lat, lng := 41.0369, 28.985
+	cal := Calendar{Events: []Event{{
+		UID:         "1@example",
+		Summary:     "Go meetup, weekly",
+		Description: strings.Repeat("long line; ", 20),
+		Location:    "Istanbul",
+		Latitude:    &lat,
+		Longitude:   &lng,
+		Start:       start,
+		Stamp:       start,
+		RRule:       "FREQ=WEEKLY;COUNT=4",
+		ExDates:     []time.Time{start.AddDate(0, 0, 7)},
+	}}}
+
+	decoded, err := Decode(strings.NewReader(cal.Encode()))
+	require.NoError(t, err)
+	require.Len(t, decoded, 1)
+	require.NoError(t, decoded[0].Err)
+	assert.Equal(t, cal.Events[0], decoded[0].Event)
+}
+
+func TestDecode_TimeZonesAndBadEvents(t *testing.T) {
+	doc := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"UID:tz",
+		"SUMMARY:Folded",
+		"  summary",
+		`DTSTART;TZID="Europe/Istanbul":20250107T180000`,
+		"BEGIN:VALARM",
+		"DTSTART:20000101T000000Z",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:bad",
+		"SUMMARY:No start",
+		"RRULE:FREQ=HOURLY",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\n")
+
+	decoded, err := Decode(strings.NewReader(doc))
+	require.NoError(t, err)
+	require.Len(t, decoded, 2)
+
+	require.NoError(t, decoded[0].Err)
+	assert.Equal(t, "Folded summary", decoded[0].Summary)
+	assert.Equal(t, time.Date(2025, time.January, 7, 15, 0, 0, 0, time.UTC), decoded[0].Start.UTC())
+
+	assert.Equal(t, "bad", decoded[1].UID)
+	assert.ErrorContains(t, decoded[1].Err, "RRULE")
+}
+
+func TestDecode_InvalidDocument(t *testing.T) {
+	for _, doc := range []string{
+		"",
+		"BEGIN:VEVENT\nEND:VEVENT",
+		"BEGIN:VCALENDAR\nBEGIN:VEVENT\nEND:VCALENDAR",
+		"BEGIN:VCALENDAR\nnot a content line\nEND:VCALENDAR",
+	} {
+		_, err := Decode(strings.NewReader(doc))
+		assert.ErrorIs(t, err, ErrInvalidCalendar, doc)
+	}
+}
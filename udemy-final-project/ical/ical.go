@@ -0,0 +1,138 @@
+// Package ical provides encoding and decoding of events as RFC 5545 iCalendar documents.
+package ical
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+)
+
+// ContentType is the MIME type of iCalendar documents
+const ContentType = "text/calendar; charset=utf-8"
+
+// ProductID identifies this application in generated calendars
+const ProductID = "-//udemy-go-tryout//Event Management API//EN"
+
+// maxLineOctets is the RFC 5545 content line length limit, excluding CRLF
+const maxLineOctets = 75
+
+// Statuses for the VEVENT STATUS property
+const (
+	StatusConfirmed = "CONFIRMED"
+	StatusTentative = "TENTATIVE"
+	StatusCancelled = "CANCELLED"
+)
+
+// Calendar is a VCALENDAR containing events
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Event is a single VEVENT
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Location     string
+	Latitude     *float64 // GEO, set together with Longitude
+	Longitude    *float64
+	Start        time.Time
+	RRule        string
+	ExDates      []time.Time
+	RecurrenceID *time.Time
+	Status       string
+	Stamp        time.Time
+}
+
+// Encode renders the calendar as an iCalendar document
+func (c *Calendar) Encode() string {
+	var b strings.Builder
+	writeLine(&b, "BEGIN:VCALENDAR")
+	writeLine(&b, "VERSION:2.0")
+	writeLine(&b, "PRODID:"+ProductID)
+	writeLine(&b, "CALSCALE:GREGORIAN")
+	writeLine(&b, "METHOD:PUBLISH")
+	if c.Name != "" {
+		writeLine(&b, "X-WR-CALNAME:"+EscapeText(c.Name))
+	}
+	for _, e := range c.Events {
+		e.encode(&b)
+	}
+	writeLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+func (e *Event) encode(b *strings.Builder) {
+	stamp := e.Stamp
+	if stamp.IsZero() {
+		stamp = time.Now()
+	}
+	writeLine(b, "BEGIN:VEVENT")
+	writeLine(b, "UID:"+e.UID)
+	writeLine(b, "DTSTAMP:"+recurrence.FormatDateTime(stamp))
+	writeLine(b, "DTSTART:"+recurrence.FormatDateTime(e.Start))
+	if e.RecurrenceID != nil {
+		writeLine(b, "RECURRENCE-ID:"+recurrence.FormatDateTime(*e.RecurrenceID))
+	}
+	if e.RRule != "" {
+		writeLine(b, "RRULE:"+e.RRule)
+	}
+	if len(e.ExDates) > 0 {
+		dates := make([]string, len(e.ExDates))
+		for i, ex := range e.ExDates {
+			dates[i] = recurrence.FormatDateTime(ex)
+		}
+		writeLine(b, "EXDATE:"+strings.Join(dates, ","))
+	}
+	writeLine(b, "SUMMARY:"+EscapeText(e.Summary))
+	if e.Description != "" {
+		writeLine(b, "DESCRIPTION:"+EscapeText(e.Description))
+	}
+	if e.Location != "" {
+		writeLine(b, "LOCATION:"+EscapeText(e.Location))
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		writeLine(b, "GEO:"+strconv.FormatFloat(*e.Latitude, 'f', -1, 64)+";"+strconv.FormatFloat(*e.Longitude, 'f', -1, 64))
+	}
+	if e.Status != "" {
+		writeLine(b, "STATUS:"+e.Status)
+	}
+	writeLine(b, "END:VEVENT")
+}
+
+// EscapeText escapes a TEXT property value
+func EscapeText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+// writeLine writes a content line folded at 75 octets, never splitting a
+// multi-byte UTF-8 sequence
+func writeLine(b *strings.Builder, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space that counts towards the limit
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func isRuneStart(c byte) bool {
+	return c&0xC0 != 0x80
+}
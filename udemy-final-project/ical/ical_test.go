@@ -0,0 +1,33 @@
+package ical
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeText(t *testing.T) {
+	assert.Equal(t, `a\, b\; c\\d\nnext`, EscapeText("a, b; c\\d\nnext"))
+}
+
+func TestEncode_FoldsLongLines(t *testing.T) {
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	cal := Calendar{Events: []Event{{
+		UID:         "1@example",
+		Summary:     "Go meetup",
+		Description: strings.Repeat("ü", 60),
+		Start:       start,
+		Stamp:       start,
+		RRule:       "FREQ=WEEKLY;COUNT=4",
+	}}}
+
+	out := cal.Encode()
+	assert.True(t, strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n"))
+	assert.Contains(t, out, "DTSTART:20250107T180000Z\r\n")
+	assert.Contains(t, out, "RRULE:FREQ=WEEKLY;COUNT=4\r\n")
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		assert.LessOrEqual(t, len(line), maxLineOctets, line)
+	}
+}
@@ -3,67 +3,260 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strconv"
+	"time"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
 	"github.com/segmentio/kafka-go"
 )
 
+// Retry settings for failed handlers
+const (
+	DefaultMaxAttempts = 5
+	defaultBaseBackoff = 200 * time.Millisecond
+	defaultMaxBackoff  = 10 * time.Second
+)
+
+// Headers added to messages sent to the dead-letter topic
+const (
+	HeaderError             = "x-error"
+	HeaderAttempts          = "x-attempts"
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	HeaderFailedAt          = "x-failed-at"
+)
+
+// Handler processes a consumed event change. Legacy JSON messages are
+// converted to an envelope first, so handlers see a single format. A returned
+// error is retried with backoff; once the attempts are used up the message
+// goes to the dead-letter topic.
+type Handler func(ctx context.Context, envelope *eventpb.EventEnvelope) error
+
+// messageWriter is the part of kafka.Writer used to dead-letter messages
+type messageWriter interface {
+	WriteMessages(ctx context.Context, messages ...kafka.Message) error
+}
+
 // Consumer handles consuming messages from Kafka
 type Consumer struct {
-	reader *kafka.Reader
-	topic  string
+	reader      *kafka.Reader
+	deadLetters messageWriter
+	topic       string
+	handlers    map[string]Handler
+	maxAttempts int
+	baseBackoff time.Duration
+	maxBackoff  time.Duration
 }
 
-// NewConsumer creates a new Kafka consumer instance
-func NewConsumer() (*Consumer, error) {
-	brokers := []string{getEnv("KAFKA_BROKERS", "localhost:9092")}
-	topic := "events"
+// ConsumerOption configures the reader of a Consumer
+type ConsumerOption func(*kafka.ReaderConfig)
+
+// WithGroupID sets the consumer group instead of the configured group ID.
+// Consumers in different groups each receive every message.
+func WithGroupID(groupID string) ConsumerOption {
+	return func(readerConfig *kafka.ReaderConfig) {
+		readerConfig.GroupID = groupID
+	}
+}
 
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  brokers,
-		Topic:    topic,
-		GroupID:  "event-consumer-group",
+// WithLatestOffset makes a group without committed offsets start at the end
+// of the topic instead of replaying it from the beginning
+func WithLatestOffset() ConsumerOption {
+	return func(readerConfig *kafka.ReaderConfig) {
+		readerConfig.StartOffset = kafka.LastOffset
+	}
+}
+
+// WithLowLatency returns fetched messages as soon as any are available,
+// instead of waiting to batch 10KB, for consumers feeding live clients
+func WithLowLatency() ConsumerOption {
+	return func(readerConfig *kafka.ReaderConfig) {
+		readerConfig.MinBytes = 1
+		readerConfig.MaxWait = 500 * time.Millisecond
+	}
+}
+
+// NewConsumer creates a new Kafka consumer instance reading the configured
+// topic in the configured group. Poison messages are sent to the dead-letter
+// topic.
+func NewConsumer(cfg config.Kafka, options ...ConsumerOption) (*Consumer, error) {
+	readerConfig := kafka.ReaderConfig{
+		Brokers:  cfg.Brokers,
+		Topic:    cfg.Topic,
+		GroupID:  cfg.GroupID,
 		MinBytes: 10e3, // 10KB
 		MaxBytes: 10e6, // 10MB
-	})
+		// Offsets are committed explicitly once a message has been handled
+		CommitInterval: 0,
+	}
+	for _, option := range options {
+		option(&readerConfig)
+	}
+	reader := kafka.NewReader(readerConfig)
+	deadLetters := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Topic:                  cfg.DLQTopic,
+		Balancer:               &kafka.Hash{},
+		AllowAutoTopicCreation: true,
+	}
 
 	return &Consumer{
-		reader: reader,
-		topic:  topic,
+		reader:      reader,
+		deadLetters: deadLetters,
+		topic:       cfg.Topic,
+		handlers:    map[string]Handler{},
+		maxAttempts: DefaultMaxAttempts,
+		baseBackoff: defaultBaseBackoff,
+		maxBackoff:  defaultMaxBackoff,
 	}, nil
 }
 
-// StartConsuming begins consuming messages from Kafka in a continuous loop
-func (c *Consumer) StartConsuming() {
+// Handle registers the handler for messages with the given action, replacing
+// any handler registered before. Messages without a handler are skipped.
+func (c *Consumer) Handle(action string, handler Handler) {
+	c.handlers[action] = handler
+}
+
+// StartConsuming consumes messages until ctx is cancelled. Each message's
+// offset is committed only after it was handled or dead-lettered, so a
+// message interrupted by shutdown is consumed again on the next start.
+func (c *Consumer) StartConsuming(ctx context.Context) {
 	log.Println("Kafka consumer started, waiting for messages...")
 
+	failures := 0
 	for {
-		m, err := c.reader.ReadMessage(context.Background())
+		m, err := c.reader.FetchMessage(ctx)
 		if err != nil {
+			if ctx.Err() != nil {
+				log.Println("Kafka consumer stopped")
+				return
+			}
+			failures++
 			log.Printf("Consumer error: %v", err)
+			if !sleep(ctx, c.backoff(failures)) {
+				return
+			}
 			continue
 		}
+		failures = 0
 
-		c.processMessage(&m)
+		if err := c.processMessage(ctx, m); err != nil {
+			// Only cancellation stops processing; the message is left uncommitted
+			log.Printf("Kafka consumer stopped before handling offset %d: %v", m.Offset, err)
+			return
+		}
+		if err := c.reader.CommitMessages(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to commit offset %d: %v", m.Offset, err)
+		}
 	}
 }
 
-func (c *Consumer) processMessage(msg *kafka.Message) {
-	var eventMessage EventMessage
-	err := json.Unmarshal(msg.Value, &eventMessage)
+// processMessage runs the handler for a message, retrying failures with
+// backoff and dead-lettering the message once the attempts are used up.
+// It only returns an error when ctx is cancelled.
+func (c *Consumer) processMessage(ctx context.Context, msg kafka.Message) error {
+	envelope, err := DecodeMessage(msg)
 	if err != nil {
-		log.Printf("Failed to unmarshal message: %v", err)
-		return
+		return c.deadLetter(ctx, msg, err, 1)
 	}
 
-	log.Printf("Received event: Action=%s, Event=%+v", eventMessage.Action, eventMessage.Event)
+	handler, ok := c.handlers[envelope.Type]
+	if !ok {
+		log.Printf("No handler for action %q, skipping offset %d", envelope.Type, msg.Offset)
+		return nil
+	}
+
+	for attempt := 1; attempt <= c.maxAttempts; attempt++ {
+		if err = runHandler(ctx, handler, envelope); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("Handler for action %q failed (attempt %d/%d): %v", envelope.Type, attempt, c.maxAttempts, err)
+		if attempt < c.maxAttempts && !sleep(ctx, c.backoff(attempt)) {
+			return ctx.Err()
+		}
+	}
+	return c.deadLetter(ctx, msg, err, c.maxAttempts)
+}
+
+// runHandler calls handler, turning a panic into an error
+func runHandler(ctx context.Context, handler Handler, envelope *eventpb.EventEnvelope) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return handler(ctx, envelope)
+}
+
+// deadLetter copies msg to the dead-letter topic with headers describing the
+// failure. Writing is retried until it succeeds or ctx is cancelled, because
+// the original offset must not be committed before the copy exists.
+func (c *Consumer) deadLetter(ctx context.Context, msg kafka.Message, cause error, attempts int) error {
+	headers := append([]kafka.Header{}, msg.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: HeaderError, Value: []byte(cause.Error())},
+		kafka.Header{Key: HeaderAttempts, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: HeaderOriginalTopic, Value: []byte(msg.Topic)},
+		kafka.Header{Key: HeaderOriginalPartition, Value: []byte(strconv.Itoa(msg.Partition))},
+		kafka.Header{Key: HeaderOriginalOffset, Value: []byte(strconv.FormatInt(msg.Offset, 10))},
+		kafka.Header{Key: HeaderFailedAt, Value: []byte(time.Now().UTC().Format(time.RFC3339))},
+	)
+	deadLetter := kafka.Message{Key: msg.Key, Value: msg.Value, Headers: headers}
 
-	// Here you can add logic to handle the event, e.g., send notifications, update analytics, etc.
-	// For this demo, we'll just log it.
+	for failures := 1; ; failures++ {
+		err := c.deadLetters.WriteMessages(ctx, deadLetter)
+		if err == nil {
+			log.Printf("Dead-lettered offset %d after %d attempts: %v", msg.Offset, attempts, cause)
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("Failed to dead-letter offset %d: %v", msg.Offset, err)
+		if !sleep(ctx, c.backoff(failures)) {
+			return ctx.Err()
+		}
+	}
 }
 
-// Close closes the Kafka consumer and releases resources
+// backoff returns the delay after the given number of consecutive failures,
+// doubling from the base delay up to the maximum
+func (c *Consumer) backoff(failures int) time.Duration {
+	delay := c.baseBackoff
+	for i := 1; i < failures && delay < c.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, c.maxBackoff)
+}
+
+// sleep waits for d and reports false if ctx was cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// Close closes the Kafka consumer and its dead-letter writer
 func (c *Consumer) Close() error {
-	return c.reader.Close()
+	var writerErr error
+	if closer, ok := c.deadLetters.(interface{ Close() error }); ok {
+		writerErr = closer.Close()
+	}
+	return errors.Join(c.reader.Close(), writerErr)
 }
@@ -0,0 +1,138 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingWriter struct {
+	messages []kafka.Message
+}
+
+func (w *recordingWriter) WriteMessages(_ context.Context, messages ...kafka.Message) error {
+	w.messages = append(w.messages, messages...)
+	return nil
+}
+
+func testConsumer() (*Consumer, *recordingWriter) {
+	writer := &recordingWriter{}
+	return &Consumer{
+		deadLetters: writer,
+		handlers:    map[string]Handler{},
+		maxAttempts: 3,
+		baseBackoff: time.Millisecond,
+		maxBackoff:  time.Millisecond,
+	}, writer
+}
+
+var createdMessage = kafka.Message{
+	Topic:     "events",
+	Partition: 2,
+	Offset:    42,
+	Key:       []byte("created-1"),
+	Value:     []byte(`{"action":"created","event":{"id":1,"name":"Meetup"}}`),
+}
+
+func TestConsumer_DispatchesByAction(t *testing.T) {
+	consumer, writer := testConsumer()
+	var name string
+	consumer.Handle("created", func(_ context.Context, envelope *eventpb.EventEnvelope) error {
+		name = envelope.GetEvent().GetName()
+		return nil
+	})
+	consumer.Handle("deleted", func(context.Context, *eventpb.EventEnvelope) error {
+		t.Fatal("handler for another action called")
+		return nil
+	})
+
+	require.NoError(t, consumer.processMessage(context.Background(), createdMessage))
+	assert.Equal(t, "Meetup", name)
+	assert.Empty(t, writer.messages)
+
+	// Actions without a handler are skipped
+	consumer, writer = testConsumer()
+	require.NoError(t, consumer.processMessage(context.Background(), createdMessage))
+	assert.Empty(t, writer.messages)
+}
+
+func TestConsumer_RetriesThenDeadLetters(t *testing.T) {
+	consumer, writer := testConsumer()
+	calls := 0
+	consumer.Handle("created", func(context.Context, *eventpb.EventEnvelope) error {
+		calls++
+		if calls == 2 {
+			panic("boom")
+		}
+		return errors.New("downstream unavailable")
+	})
+
+	require.NoError(t, consumer.processMessage(context.Background(), createdMessage))
+	assert.Equal(t, 3, calls)
+	require.Len(t, writer.messages, 1)
+
+	dead := writer.messages[0]
+	assert.Equal(t, createdMessage.Key, dead.Key)
+	assert.Equal(t, createdMessage.Value, dead.Value)
+	assert.Equal(t, "downstream unavailable", header(dead, HeaderError))
+	assert.Equal(t, "3", header(dead, HeaderAttempts))
+	assert.Equal(t, "events", header(dead, HeaderOriginalTopic))
+	assert.Equal(t, "2", header(dead, HeaderOriginalPartition))
+	assert.Equal(t, "42", header(dead, HeaderOriginalOffset))
+	assert.NotEmpty(t, header(dead, HeaderFailedAt))
+
+	// A recovering handler is not dead-lettered
+	consumer, writer = testConsumer()
+	calls = 0
+	consumer.Handle("created", func(context.Context, *eventpb.EventEnvelope) error {
+		calls++
+		if calls < 3 {
+			return errors.New("try again")
+		}
+		return nil
+	})
+	require.NoError(t, consumer.processMessage(context.Background(), createdMessage))
+	assert.Empty(t, writer.messages)
+}
+
+func TestConsumer_PoisonMessage(t *testing.T) {
+	consumer, writer := testConsumer()
+	consumer.Handle("created", func(context.Context, *eventpb.EventEnvelope) error {
+		t.Fatal("handler called for undecodable message")
+		return nil
+	})
+
+	poison := kafka.Message{Topic: "events", Offset: 7, Value: []byte("not json")}
+	require.NoError(t, consumer.processMessage(context.Background(), poison))
+	require.Len(t, writer.messages, 1)
+	assert.Equal(t, "1", header(writer.messages[0], HeaderAttempts))
+	assert.Contains(t, header(writer.messages[0], HeaderError), "failed to unmarshal message")
+}
+
+func TestConsumer_CancelledWhileRetrying(t *testing.T) {
+	consumer, writer := testConsumer()
+	ctx, cancel := context.WithCancel(context.Background())
+	consumer.Handle("created", func(context.Context, *eventpb.EventEnvelope) error {
+		cancel()
+		return errors.New("interrupted")
+	})
+
+	err := consumer.processMessage(ctx, createdMessage)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, writer.messages)
+}
+
+func TestConsumer_Backoff(t *testing.T) {
+	consumer := &Consumer{baseBackoff: 200 * time.Millisecond, maxBackoff: time.Second}
+	assert.Equal(t, 200*time.Millisecond, consumer.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, consumer.backoff(2))
+	assert.Equal(t, 800*time.Millisecond, consumer.backoff(3))
+	assert.Equal(t, time.Second, consumer.backoff(4))
+	assert.Equal(t, time.Second, consumer.backoff(30))
+}
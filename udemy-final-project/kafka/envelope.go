@@ -0,0 +1,115 @@
+package kafka
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/segmentio/kafka-go"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Envelope schema versions
+const (
+	// SchemaVersion is the version of the EventEnvelope published by this service
+	SchemaVersion = 1
+	// LegacySchemaVersion marks envelopes decoded from legacy JSON messages
+	LegacySchemaVersion = 0
+)
+
+// HeaderContentType identifies how a message value is encoded. Messages
+// without it are legacy JSON.
+const HeaderContentType = "content-type"
+
+// ContentTypeProtobuf is the content type of protobuf-encoded envelopes
+const ContentTypeProtobuf = "application/x-protobuf; messageType=event.EventEnvelope"
+
+// Message formats the outbox relay can publish
+const (
+	FormatProtobuf = "protobuf"
+	FormatJSON     = "json"
+)
+
+// ErrUnsupportedSchemaVersion is returned for envelopes newer than this service understands
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event envelope schema version")
+
+// NewEnvelope wraps an event change in a versioned envelope. The producer
+// name is filled in when the envelope is published.
+func NewEnvelope(eventType, id string, occurredAt time.Time, traceID string, event *eventpb.Event) *eventpb.EventEnvelope {
+	return &eventpb.EventEnvelope{
+		Type:          eventType,
+		SchemaVersion: SchemaVersion,
+		EventId:       id,
+		OccurredAt:    timestamppb.New(occurredAt),
+		TraceId:       traceID,
+		Event:         event,
+	}
+}
+
+// encodeEnvelope builds the Kafka message carrying envelope
+func encodeEnvelope(key string, envelope *eventpb.EventEnvelope) (kafka.Message, error) {
+	value, err := proto.Marshal(envelope)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+	return kafka.Message{
+		Key:     []byte(key),
+		Value:   value,
+		Headers: []kafka.Header{{Key: HeaderContentType, Value: []byte(ContentTypeProtobuf)}},
+	}, nil
+}
+
+// DecodeMessage decodes a consumed message into an envelope. Messages with the
+// protobuf content type are decoded as EventEnvelope. Any other message is read
+// as a legacy JSON EventMessage: its event is converted to the protobuf Event,
+// the schema version is LegacySchemaVersion and the Kafka timestamp is used as
+// the time the change occurred.
+func DecodeMessage(msg kafka.Message) (*eventpb.EventEnvelope, error) {
+	if header(msg, HeaderContentType) == ContentTypeProtobuf {
+		var envelope eventpb.EventEnvelope
+		if err := proto.Unmarshal(msg.Value, &envelope); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal envelope: %w", err)
+		}
+		if envelope.SchemaVersion > SchemaVersion {
+			return nil, fmt.Errorf("%w: %d", ErrUnsupportedSchemaVersion, envelope.SchemaVersion)
+		}
+		return &envelope, nil
+	}
+
+	var legacy struct {
+		Action string          `json:"action"`
+		Event  json.RawMessage `json:"event"`
+	}
+	if err := json.Unmarshal(msg.Value, &legacy); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	envelope := &eventpb.EventEnvelope{
+		Type:          legacy.Action,
+		SchemaVersion: LegacySchemaVersion,
+	}
+	if !msg.Time.IsZero() {
+		envelope.OccurredAt = timestamppb.New(msg.Time)
+	}
+	if len(legacy.Event) > 0 && string(legacy.Event) != "null" {
+		var event models.Event
+		if err := json.Unmarshal(legacy.Event, &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+		}
+		envelope.Event = event.ToProto()
+	}
+	return envelope, nil
+}
+
+// header returns the value of the first header with the given key
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,65 @@
+package kafka
+
+import (
+	"testing"
+	"time"
+
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestDecodeMessage_Protobuf(t *testing.T) {
+	occurredAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	envelope := NewEnvelope("published", "msg-1", occurredAt, "4bf92f3577b34da6a3ce929d0e0e4736",
+		&eventpb.Event{Id: 7, Name: "Meetup", Status: "published"})
+	envelope.Producer = "event-api"
+
+	msg, err := encodeEnvelope("7", envelope)
+	require.NoError(t, err)
+	assert.Equal(t, ContentTypeProtobuf, header(msg, HeaderContentType))
+
+	decoded, err := DecodeMessage(msg)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(envelope, decoded))
+	assert.EqualValues(t, SchemaVersion, decoded.SchemaVersion)
+	assert.Equal(t, occurredAt, decoded.OccurredAt.AsTime())
+}
+
+func TestDecodeMessage_Legacy(t *testing.T) {
+	msg := kafka.Message{
+		Time: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC),
+		Value: []byte(`{"action":"created","event":{"id":1,"name":"Meetup","date_time":"2030-02-01T18:00:00Z",
+			"user_id":3,"status":"draft","User":{},"Registrations":null}}`),
+	}
+
+	envelope, err := DecodeMessage(msg)
+	require.NoError(t, err)
+	assert.Equal(t, "created", envelope.Type)
+	assert.EqualValues(t, LegacySchemaVersion, envelope.SchemaVersion)
+	assert.Equal(t, msg.Time, envelope.OccurredAt.AsTime())
+	assert.Equal(t, int64(1), envelope.Event.Id)
+	assert.Equal(t, "Meetup", envelope.Event.Name)
+	assert.Equal(t, int64(3), envelope.Event.UserId)
+	assert.Equal(t, "draft", envelope.Event.Status)
+	assert.Equal(t, time.Date(2030, 2, 1, 18, 0, 0, 0, time.UTC), envelope.Event.DateTime.AsTime())
+}
+
+func TestDecodeMessage_Rejects(t *testing.T) {
+	newer, err := encodeEnvelope("created-1", &eventpb.EventEnvelope{Type: "created", SchemaVersion: SchemaVersion + 1})
+	require.NoError(t, err)
+	_, err = DecodeMessage(newer)
+	assert.ErrorIs(t, err, ErrUnsupportedSchemaVersion)
+
+	garbled := kafka.Message{
+		Value:   []byte("not protobuf"),
+		Headers: []kafka.Header{{Key: HeaderContentType, Value: []byte(ContentTypeProtobuf)}},
+	}
+	_, err = DecodeMessage(garbled)
+	assert.Error(t, err)
+
+	_, err = DecodeMessage(kafka.Message{Value: []byte("not json")})
+	assert.Error(t, err)
+}
@@ -3,9 +3,14 @@ package kafka
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"strconv"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
 	"github.com/segmentio/kafka-go"
 )
 
@@ -13,32 +18,52 @@ import (
 type Producer struct {
 	writer *kafka.Writer
 	topic  string
+	name   string
 }
 
-// EventMessage represents the structure of messages sent to Kafka
+// EventMessage is the legacy JSON message format, superseded by the
+// protobuf EventEnvelope
 type EventMessage struct {
 	Action string      `json:"action"`
 	Event  interface{} `json:"event"`
 }
 
-// NewProducer creates a new Kafka producer instance
-func NewProducer() (*Producer, error) {
-	brokers := []string{getEnv("KAFKA_BROKERS", "localhost:9092")}
-	topic := "events"
-
+// NewProducer creates a new Kafka producer instance writing to the configured
+// topic. Envelopes name the producer after the configured producer name.
+func NewProducer(cfg config.Kafka) (*Producer, error) {
 	writer := &kafka.Writer{
-		Addr:     kafka.TCP(brokers...),
-		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
+		Addr:     kafka.TCP(cfg.Brokers...),
+		Topic:    cfg.Topic,
+		Balancer: &kafka.Hash{}, // the changes of an event go to one partition, in order
 	}
 
 	return &Producer{
 		writer: writer,
-		topic:  topic,
+		topic:  cfg.Topic,
+		name:   cfg.ProducerName,
 	}, nil
 }
 
-// PublishEvent sends an event message to Kafka with the specified action and event ID
+// PublishEnvelope sends a versioned event envelope to Kafka encoded as protobuf
+func (p *Producer) PublishEnvelope(envelope *eventpb.EventEnvelope) error {
+	if envelope.Producer == "" {
+		envelope.Producer = p.name
+	}
+	eventID := strconv.FormatInt(envelope.GetEvent().GetId(), 10)
+	message, err := encodeEnvelope(eventID, envelope)
+	if err != nil {
+		return fmt.Errorf("%w: %w", models.ErrUndeliverable, err)
+	}
+
+	if err := p.write(message); err != nil {
+		return err
+	}
+
+	log.Printf("Published event to Kafka: %s for event ID %s (schema v%d)", envelope.Type, eventID, envelope.SchemaVersion)
+	return nil
+}
+
+// PublishEvent sends a legacy JSON event message to Kafka with the specified action and event ID
 func (p *Producer) PublishEvent(action string, eventID string, event interface{}) error {
 	message := EventMessage{
 		Action: action,
@@ -47,19 +72,14 @@ func (p *Producer) PublishEvent(action string, eventID string, event interface{}
 
 	jsonMessage, err := json.Marshal(message)
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", models.ErrUndeliverable, err)
 	}
 
-	key := action + "-" + eventID // Combine action and eventID for partitioning
-
-	err = p.writer.WriteMessages(context.Background(),
-		kafka.Message{
-			Key:   []byte(key),
-			Value: jsonMessage,
-		},
-	)
+	err = p.write(kafka.Message{
+		Key:   []byte(eventID), // keeps the changes of an event on one partition
+		Value: jsonMessage,
+	})
 	if err != nil {
-		log.Printf("Failed to send message to Kafka: %v", err)
 		return err
 	}
 
@@ -67,14 +87,27 @@ func (p *Producer) PublishEvent(action string, eventID string, event interface{}
 	return nil
 }
 
-// Close closes the Kafka producer and releases resources
-func (p *Producer) Close() error {
-	return p.writer.Close()
+// write sends a message to Kafka. A message the broker rejects as too large
+// is reported as models.ErrUndeliverable, since sending it again cannot help;
+// any other error, such as the broker being unreachable, is worth retrying.
+func (p *Producer) write(message kafka.Message) error {
+	err := p.writer.WriteMessages(context.Background(), message)
+	if err == nil {
+		return nil
+	}
+	log.Printf("Failed to send message to Kafka: %v", err)
+	var writeErrors kafka.WriteErrors
+	if errors.As(err, &writeErrors) && len(writeErrors) == 1 {
+		err = writeErrors[0] // one message is written at a time
+	}
+	if errors.Is(err, kafka.MessageSizeTooLarge) {
+		return fmt.Errorf("%w: %w", models.ErrUndeliverable, err)
+	}
+	return err
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
+// Close flushes the messages being written, then closes the Kafka producer
+// and releases resources
+func (p *Producer) Close() error {
+	return p.writer.Close()
 }
@@ -2,19 +2,42 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
-	"net"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/di"
 	_ "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/docs" // This is required for swagger
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/auth"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/grpc/interceptor"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/kafka"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/middlewares"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
 	authpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/auth"
 	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/routes"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/server"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/worker"
+	"github.com/samber/do/v2"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/reflection"
 )
@@ -22,75 +45,165 @@ import (
 var container *di.Container
 
 func main() {
+	// "migrate" changes the schema and exits instead of serving
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	// "outbox" manages the queued event changes and exits
+	if len(os.Args) > 1 && os.Args[1] == "outbox" {
+		if err := runOutbox(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Settings come from the defaults, the -config file, the environment and
+	// the flags, in increasing precedence
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatalf("Invalid configuration:\n%v", err)
+	}
+	log.Printf("Configuration:\n%s", cfg)
+
+	// Fail fast on a misconfigured JWT key instead of on the first login
+	if err := security.Configure(cfg.Auth); err != nil {
+		log.Fatalf("Failed to load JWT keys: %v", err)
+	}
+
 	log.Println("Initializing database...")
-	db.InitDB()
+	db.InitDB(cfg.Database)
 	log.Println("Database initialized")
 
 	// Initialize DI container
 	log.Println("Initializing DI container...")
-	container = di.NewContainer()
+	container = di.NewContainer(cfg)
 	log.Println("DI container initialized")
 
-	// Start Kafka consumer in a goroutine
-	log.Println("Starting Kafka consumer...")
-	go startKafkaConsumer()
+	// Give the admin role to the existing users with an admin email
+	if err := container.GetUserService().PromoteAdmins(); err != nil {
+		log.Fatalf("Failed to promote admins: %v", err)
+	}
 
-	// Start gRPC server in a goroutine
-	log.Println("Starting gRPC server...")
-	go startGRPCServer()
+	// Shut down on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Start REST server
+	// The servers and workers are registered with the services they use, so
+	// they are shut down before them
+	do.ProvideNamed(container.Injector, "restServer", newRESTServer)
+	do.ProvideNamed(container.Injector, "grpcServer", newGRPCServer)
+	do.ProvideNamed(container.Injector, "workers", startWorkers)
+
+	log.Println("Starting gRPC server...")
+	grpcServer, err := do.InvokeNamed[*server.GRPC](container.Injector, "grpcServer")
+	if err != nil {
+		log.Fatalf("Failed to start gRPC server: %v", err)
+	}
 	log.Println("Starting REST server...")
-	startRESTServer()
+	restServer, err := do.InvokeNamed[*server.HTTP](container.Injector, "restServer")
+	if err != nil {
+		log.Fatalf("Failed to start REST server: %v", err)
+	}
+	do.MustInvokeNamed[*worker.Group](container.Injector, "workers")
+
+	stopped := make(chan error, 2)
+	go func() {
+		log.Printf("gRPC server starting on %s", grpcServer.Addr())
+		stopped <- grpcServer.Serve()
+	}()
+	go func() {
+		log.Printf("REST server starting on %s", restServer.Addr())
+		stopped <- restServer.Serve()
+	}()
+
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down...")
+	case err := <-stopped:
+		log.Printf("Server stopped unexpectedly, shutting down: %v", err)
+		exitCode = 1
+	}
+	stop()
+
+	// Drain the servers, stop the workers, flush the Kafka producer and close
+	// the database, giving up after the shutdown timeout
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	if err := container.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Shutdown incomplete: %v", err)
+		exitCode = 1
+	} else {
+		log.Println("Shutdown complete")
+	}
+	cancel()
+	os.Exit(exitCode)
 }
 
-func startRESTServer() {
-	server := gin.Default()
+// newRESTServer listens on the REST address for the REST API
+func newRESTServer(i do.Injector) (*server.HTTP, error) {
+	engine := gin.Default()
 
 	// Add CORS middleware for Swagger UI
-	server.Use(func(c *gin.Context) {
+	engine.Use(func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Content-Type, Authorization, traceparent")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
 			return
 		}
 		c.Next()
 	})
+	engine.Use(middlewares.Trace)
 
 	// Initialize services in routes
-	userService := container.GetUserService()
-	eventService := container.GetEventService()
-	authService := container.GetAuthService()
-	routes.InitServices(userService, eventService, authService)
-
-	routes.SetupRoutes(server)
-	log.Println("REST server starting on :8080")
-	if err := server.Run(":8080"); err != nil {
-		log.Fatalf("Failed to start REST server: %v", err)
-	}
+	userService := do.MustInvokeNamed[services.UserService](i, "userService")
+	eventService := do.MustInvokeNamed[services.EventService](i, "eventService")
+	authService := do.MustInvokeNamed[services.AuthService](i, "authService")
+	calendarService := do.MustInvokeNamed[services.CalendarService](i, "calendarService")
+	webhookService := do.MustInvokeNamed[services.WebhookService](i, "webhookService")
+	authPolicy := do.MustInvokeNamed[*policy.Policy](i, "policy")
+	broadcaster := do.MustInvokeNamed[*stream.Broadcaster](i, "broadcaster")
+	routes.InitServices(userService, eventService, authService, calendarService, webhookService, authPolicy, broadcaster)
+
+	routes.SetupRoutes(engine)
+	return server.ListenHTTP(do.MustInvokeNamed[*config.Config](i, "config").Server.RESTAddr, engine, broadcaster)
 }
 
-func startGRPCServer() {
-	log.Println("Creating gRPC listener...")
-	lis, err := net.Listen("tcp", "localhost:50051")
-	if err != nil {
-		log.Fatalf("Failed to listen: %v", err)
+// newGRPCServer listens on the gRPC address for the gRPC services
+func newGRPCServer(i do.Injector) (*server.GRPC, error) {
+	// Take the trace of each call, authenticate it as its method requires,
+	// then report domain errors with their status codes
+	methods := interceptor.Methods{
+		"/grpc.reflection.v1.ServerReflection/":      interceptor.Public,
+		"/grpc.reflection.v1alpha.ServerReflection/": interceptor.Public,
 	}
-	log.Println("gRPC listener created successfully")
-
-	grpcServer := grpc.NewServer()
+	maps.Copy(methods, auth.Methods)
+	maps.Copy(methods, event.Methods)
+	authInterceptor := interceptor.NewAuth(methods, models.ValidateAccessToken)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(interceptor.UnaryTrace(), interceptor.UnaryErrors(), authInterceptor.Unary()),
+		grpc.ChainStreamInterceptor(interceptor.StreamTrace(), interceptor.StreamErrors(), authInterceptor.Stream()),
+	)
 	log.Println("gRPC server created")
 
 	// Get services from DI container
-	userService := container.GetUserService()
-	eventService := container.GetEventService()
-	authService := container.GetAuthService()
+	userService := do.MustInvokeNamed[services.UserService](i, "userService")
+	eventService := do.MustInvokeNamed[services.EventService](i, "eventService")
+	authService := do.MustInvokeNamed[services.AuthService](i, "authService")
+	calendarService := do.MustInvokeNamed[services.CalendarService](i, "calendarService")
+	broadcaster := do.MustInvokeNamed[*stream.Broadcaster](i, "broadcaster")
 
 	// Create gRPC servers with DI
-	authServer := auth.NewAuthServer(userService, authService)
-	eventServer := event.NewEventServer(eventService)
+	authPolicy := do.MustInvokeNamed[*policy.Policy](i, "policy")
+	authServer := auth.NewAuthServer(userService, authService, authPolicy)
+	eventServer := event.NewEventServer(eventService, calendarService, authPolicy, broadcaster)
 
 	authpb.RegisterAuthServiceServer(grpcServer, authServer)
 	eventpb.RegisterEventServiceServer(grpcServer, eventServer)
@@ -100,14 +213,51 @@ func startGRPCServer() {
 	reflection.Register(grpcServer)
 	log.Println("gRPC reflection enabled")
 
-	log.Println("gRPC server starting on :50051")
-	if err := grpcServer.Serve(lis); err != nil {
-		log.Fatalf("Failed to serve gRPC: %v", err)
+	return server.ListenGRPC(do.MustInvokeNamed[*config.Config](i, "config").Server.GRPCAddr, grpcServer, broadcaster)
+}
+
+// startWorkers starts the background workers. Without Kafka the outbox relay
+// feeds the live event stream and webhooks directly.
+func startWorkers(i do.Injector) (*worker.Group, error) {
+	cfg := do.MustInvokeNamed[*config.Config](i, "config")
+	webhookService := do.MustInvokeNamed[services.WebhookService](i, "webhookService")
+	workers := worker.NewGroup()
+	if cfg.Kafka.Enabled {
+		broadcaster := do.MustInvokeNamed[*stream.Broadcaster](i, "broadcaster")
+		workers.Go("Kafka consumer", func(ctx context.Context) {
+			startKafkaConsumer(ctx, cfg.Kafka)
+		})
+		workers.Go("Kafka stream consumer", func(ctx context.Context) {
+			startStreamConsumer(ctx, cfg.Kafka, broadcaster)
+		})
+		workers.Go("Kafka webhook consumer", func(ctx context.Context) {
+			startWebhookConsumer(ctx, cfg.Kafka, webhookService)
+		})
 	}
+
+	// Deliver queued event changes to Kafka
+	workers.Go("outbox relay", do.MustInvokeNamed[services.OutboxRelay](i, "outboxRelay").Run)
+
+	// Send queued changes to webhooks
+	workers.Go("webhook delivery worker", webhookService.Run)
+
+	// Complete published events once their last occurrence has started
+	eventService := do.MustInvokeNamed[services.EventService](i, "eventService")
+	workers.Go("event completion job", func(ctx context.Context) {
+		startEventCompleter(ctx, cfg.Events.CompletionInterval, eventService)
+	})
+
+	// Remove expired refresh tokens and denylist entries
+	authService := do.MustInvokeNamed[services.AuthService](i, "authService")
+	workers.Go("token purger", func(ctx context.Context) {
+		startTokenPurger(ctx, authService)
+	})
+	return workers, nil
 }
 
-func startKafkaConsumer() {
-	consumer, err := kafka.NewConsumer()
+// startKafkaConsumer consumes the events topic until ctx is cancelled
+func startKafkaConsumer(ctx context.Context, cfg config.Kafka) {
+	consumer, err := kafka.NewConsumer(cfg)
 	if err != nil {
 		log.Printf("Failed to create Kafka consumer: %v", err)
 		return
@@ -118,7 +268,234 @@ func startKafkaConsumer() {
 		}
 	}()
 
-	consumer.StartConsuming()
+	for _, action := range slices.Concat(models.EventActions, models.RegistrationActions) {
+		consumer.Handle(action, logEventMessage)
+	}
+
+	consumer.StartConsuming(ctx)
+}
+
+// startStreamConsumer feeds the live event stream from the events topic until
+// ctx is cancelled. Every instance needs every change, so each reads in its own
+// stream consumer group (by default "event-stream-" and the host name),
+// starting from the latest message when the group is new.
+func startStreamConsumer(ctx context.Context, cfg config.Kafka, broadcaster *stream.Broadcaster) {
+	consumer, err := kafka.NewConsumer(cfg, kafka.WithGroupID(cfg.StreamGroupID), kafka.WithLatestOffset(), kafka.WithLowLatency())
+	if err != nil {
+		log.Printf("Failed to create Kafka stream consumer: %v", err)
+		return
+	}
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			log.Printf("Error closing Kafka stream consumer: %v", err)
+		}
+	}()
+
+	for _, action := range models.EventActions {
+		consumer.Handle(action, func(_ context.Context, envelope *eventpb.EventEnvelope) error {
+			broadcaster.Publish(envelope)
+			return nil
+		})
+	}
+
+	consumer.StartConsuming(ctx)
+}
+
+// startWebhookConsumer queues the changes of the events topic for webhooks
+// until ctx is cancelled. The instances share the webhook consumer group
+// (by default "webhook-dispatcher"), so each change is queued once; a change
+// consumed again is not queued twice.
+func startWebhookConsumer(ctx context.Context, cfg config.Kafka, webhookService services.WebhookService) {
+	consumer, err := kafka.NewConsumer(cfg, kafka.WithGroupID(cfg.WebhookGroupID))
+	if err != nil {
+		log.Printf("Failed to create Kafka webhook consumer: %v", err)
+		return
+	}
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			log.Printf("Error closing Kafka webhook consumer: %v", err)
+		}
+	}()
+
+	for _, action := range models.WebhookActions {
+		consumer.Handle(action, func(ctx context.Context, envelope *eventpb.EventEnvelope) error {
+			return webhookService.EnqueueChange(db.GetDB().WithContext(ctx), envelope)
+		})
+	}
+
+	consumer.StartConsuming(ctx)
+}
+
+// logEventMessage logs each consumed event change
+func logEventMessage(_ context.Context, envelope *eventpb.EventEnvelope) error {
+	event := envelope.GetEvent()
+	log.Printf("Event %s (schema v%d, trace %s): ID=%d Name=%q Status=%s",
+		envelope.GetType(), envelope.GetSchemaVersion(), envelope.GetTraceId(), event.GetId(), event.GetName(), event.GetStatus())
+	return nil
+}
+
+// startEventCompleter marks ended events as completed every interval until
+// ctx is cancelled
+func startEventCompleter(ctx context.Context, interval time.Duration, eventService services.EventService) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		completed, err := eventService.CompleteEndedEvents()
+		if err != nil {
+			log.Printf("Failed to complete ended events: %v", err)
+		}
+		if len(completed) > 0 {
+			log.Printf("Completed %d ended events", len(completed))
+		}
+	}
+}
+
+// startTokenPurger hourly deletes refresh tokens and revoked access tokens
+// that have expired, until ctx is cancelled
+func startTokenPurger(ctx context.Context, authService services.AuthService) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := authService.PurgeExpiredTokens(); err != nil {
+			log.Printf("Failed to purge expired tokens: %v", err)
+		}
+	}
+}
+
+// migrateUsage describes the migrate subcommand
+const migrateUsage = `usage: event-api migrate <command> [flags]
+
+commands:
+  up        apply the pending migrations
+  down [n]  revert the last n applied migrations (default 1)
+  status    list the migrations and whether they are applied
+  redo      revert the last applied migration and apply it again
+
+The flags, file and environment variables select the database as when serving.`
+
+// runMigrate runs a migrate subcommand with the given arguments. The
+// migration lock makes it safe to run while replicas are starting.
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+	command, args := args[0], args[1:]
+	steps := 1
+	switch command {
+	case "up", "status", "redo":
+	case "down":
+		if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n < 1 {
+				return fmt.Errorf("migrate down: %q is not a positive number of migrations", args[0])
+			}
+			steps, args = n, args[1:]
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q\n\n%s", command, migrateUsage)
+	}
+
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Println(migrateUsage)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := db.Connect(cfg.Database); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	migrator, err := db.GetMigrator()
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err == nil && len(applied) == 0 {
+			log.Println("No pending migrations")
+		}
+		return err
+	case "down":
+		_, err := migrator.Down(ctx, steps)
+		return err
+	case "redo":
+		_, err := migrator.Redo(ctx)
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	out := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "MIGRATION\tSTATE\tAPPLIED AT")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if status.AppliedAt != nil {
+			appliedAt = status.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(out, "%s\t%s\t%s\n", status.Migration, status.State, appliedAt)
+	}
+	return out.Flush()
+}
+
+// outboxUsage describes the outbox subcommand
+const outboxUsage = `usage: event-api outbox requeue [id...] [flags]
+
+commands:
+  requeue [id...]  deliver the failed messages with the given IDs again, or
+                   every failed message when no ID is given
+
+The flags, file and environment variables select the database as when serving.`
+
+// runOutbox runs an outbox subcommand with the given arguments. Requeued
+// messages are picked up by the relays of the running instances.
+func runOutbox(args []string) error {
+	if len(args) == 0 || args[0] != "requeue" {
+		return errors.New(outboxUsage)
+	}
+	args = args[1:]
+	var ids []int64
+	for len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		id, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("outbox requeue: %q is not a message ID", args[0])
+		}
+		ids, args = append(ids, id), args[1:]
+	}
+
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Println(outboxUsage)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	if err := db.Connect(cfg.Database); err != nil {
+		return fmt.Errorf("failed to connect to database: %w", err)
+	}
+	requeued, err := models.RequeueFailedOutbox(ids...)
+	if err != nil {
+		return err
+	}
+	log.Printf("Requeued %d failed outbox messages", requeued)
+	return nil
 }
 
 // Event Management API
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/tracing"
+)
+
+// Trace puts the trace ID of the traceparent header in the request's context,
+// starting a new trace when the header is missing, so the changes made by the
+// request are published with it
+func Trace(context *gin.Context) {
+	ctx := tracing.FromTraceparent(context.Request.Context(), context.GetHeader(tracing.Header))
+	context.Request = context.Request.WithContext(ctx)
+	context.Next()
+}
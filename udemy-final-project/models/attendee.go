@@ -0,0 +1,183 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// attendeeOrder is the sort order of attendee page tokens: registration order
+const attendeeOrder = "registered_at"
+
+// ErrInvalidRegistrationStatus is returned when filtering attendees by an unknown status
+var ErrInvalidRegistrationStatus = errors.New("status must be confirmed or waitlisted")
+
+// AttendeeQuery pages through the registrations of an event in the order they were made
+type AttendeeQuery struct {
+	EventID   int64
+	Status    string // RegistrationStatusConfirmed or RegistrationStatusWaitlisted; empty matches both
+	PageSize  int    // defaults to DefaultPageSize, capped at MaxPageSize
+	PageToken string
+}
+
+// Attendee is a registration for an event, as its owner sees it
+type Attendee struct {
+	RegistrationID int64  `json:"registration_id" example:"7"`
+	UserID         int64  `json:"user_id" example:"3"`
+	Email          string `json:"email" example:"user@example.com"`
+	Status         string `json:"status" example:"confirmed"`
+	// OccurrenceStart is the registered occurrence of a recurring event; nil for the whole series
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+	Scope           string     `json:"scope,omitempty" example:"series"`
+	RegisteredAt    time.Time  `json:"registered_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// AttendeePage is a single page of the attendees of an event
+type AttendeePage struct {
+	Attendees []Attendee `json:"attendees"`
+	// NextPageToken fetches the following page with the same query; empty on the last page
+	NextPageToken string `json:"next_page_token,omitempty" example:"eyJzIjoicmVnaXN0ZXJlZF9hdCIi..."`
+}
+
+// NewAttendee returns the attendee of a registration loaded with its user
+func NewAttendee(registration Registration) Attendee {
+	return Attendee{
+		RegistrationID:  registration.ID,
+		UserID:          registration.UserID,
+		Email:           registration.User.Email,
+		Status:          registration.Status,
+		OccurrenceStart: registration.OccurrenceStart,
+		Scope:           registration.Scope,
+		RegisteredAt:    registration.CreatedAt,
+		UpdatedAt:       registration.UpdatedAt,
+	}
+}
+
+// normalize validates the query, applies defaults and decodes the page token
+func (q *AttendeeQuery) normalize() (*pageCursor, error) {
+	switch q.Status {
+	case "", RegistrationStatusConfirmed, RegistrationStatusWaitlisted:
+	default:
+		return nil, ErrInvalidRegistrationStatus
+	}
+	switch {
+	case q.PageSize < 0:
+		return nil, ErrInvalidPageSize
+	case q.PageSize == 0:
+		q.PageSize = DefaultPageSize
+	case q.PageSize > MaxPageSize:
+		q.PageSize = MaxPageSize
+	}
+
+	if q.PageToken == "" {
+		return nil, nil
+	}
+	cursor, err := decodePageCursor(q.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.SortBy != attendeeOrder {
+		return nil, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// page returns the first PageSize registrations, given up to one more, as attendees
+func (q AttendeeQuery) page(registrations []Registration) *AttendeePage {
+	page := &AttendeePage{Attendees: []Attendee{}}
+	if len(registrations) > q.PageSize {
+		registrations = registrations[:q.PageSize]
+		last := registrations[q.PageSize-1]
+		page.NextPageToken = pageCursor{SortBy: attendeeOrder, DateTime: last.CreatedAt, ID: last.ID}.encode()
+	}
+	for _, registration := range registrations {
+		page.Attendees = append(page.Attendees, NewAttendee(registration))
+	}
+	return page
+}
+
+// ListEventAttendees returns a page of the registrations of an event with the
+// emails of their users, in the order they were made
+func ListEventAttendees(gormDB *gorm.DB, query AttendeeQuery) (*AttendeePage, error) {
+	cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	tx := gormDB.Joins("User").Where("registrations.event_id = ? AND registrations.status <> ?",
+		query.EventID, RegistrationStatusCancelled)
+	if query.Status != "" {
+		tx = tx.Where("registrations.status = ?", query.Status)
+	}
+	if cursor != nil {
+		tx = tx.Where("registrations.created_at > ? OR (registrations.created_at = ? AND registrations.id > ?)",
+			cursor.DateTime, cursor.DateTime, cursor.ID)
+	}
+	var registrations []Registration
+	err = tx.Order("registrations.created_at, registrations.id").
+		Limit(query.PageSize + 1).
+		Find(&registrations).Error
+	if err != nil {
+		return nil, err
+	}
+	return query.page(registrations), nil
+}
+
+// ListEventAttendeesIn pages through registrations loaded with their users
+// like ListEventAttendees, for registrations already in memory
+func ListEventAttendeesIn(registrations []Registration, query AttendeeQuery) (*AttendeePage, error) {
+	cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+
+	var matching []Registration
+	for _, registration := range registrations {
+		if registration.EventID != query.EventID || registration.Status == RegistrationStatusCancelled ||
+			(query.Status != "" && registration.Status != query.Status) {
+			continue
+		}
+		if cursor != nil && (registration.CreatedAt.Before(cursor.DateTime) ||
+			(registration.CreatedAt.Equal(cursor.DateTime) && registration.ID <= cursor.ID)) {
+			continue
+		}
+		matching = append(matching, registration)
+	}
+	slices.SortFunc(matching, func(a, b Registration) int {
+		return cmp.Or(a.CreatedAt.Compare(b.CreatedAt), cmp.Compare(a.ID, b.ID))
+	})
+	if len(matching) > query.PageSize+1 {
+		matching = matching[:query.PageSize+1]
+	}
+	return query.page(matching), nil
+}
+
+// RemoveAttendee removes a registration for an event on behalf of its owner
+// and promotes waitlisted users into the seats it frees. The removal is queued
+// to the outbox as RegistrationActionRemoved. It returns
+// ErrRegistrationNotFound when the event has no such registration.
+func RemoveAttendee(gormDB *gorm.DB, eventID, registrationID int64) error {
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+		var removed Registration
+		err = tx.Where("id = ? AND event_id = ? AND status <> ?", registrationID, event.ID, RegistrationStatusCancelled).
+			First(&removed).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRegistrationNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if err := cancelRegistration(tx, removed, RegistrationActionRemoved); err != nil {
+			return err
+		}
+		return promoteWaitlisted(tx, event)
+	})
+}
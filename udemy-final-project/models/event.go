@@ -2,100 +2,566 @@
 package models
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
 	"time"
 
-	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Registration statuses
+const (
+	RegistrationStatusConfirmed  = "confirmed"
+	RegistrationStatusWaitlisted = "waitlisted"
+	// RegistrationStatusCancelled is kept by registrations cancelled by their
+	// user or removed by the owner, so their history outlives them
+	RegistrationStatusCancelled = "cancelled"
+)
+
+var (
+	// ErrEventNotFound is returned when a registration targets an event that does not exist
+	ErrEventNotFound = errors.New("event not found")
+	// ErrAlreadyRegistered is returned when a user registers for an occurrence
+	// their registrations for the event already cover
+	ErrAlreadyRegistered = errors.New("already registered for this event")
+	// ErrRegistrationNotFound is returned when cancelling a registration that does not exist or was cancelled
+	ErrRegistrationNotFound = errors.New("registration not found")
 )
 
 // Event represents an event in the system
 type Event struct {
-	ID            int64          `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
-	Name          string         `json:"name" gorm:"not null" binding:"required" example:"Sample Event"`
-	Description   string         `json:"description" gorm:"not null" binding:"required" example:"This is a sample event"`
-	Location      string         `json:"location" gorm:"not null" binding:"required" example:"Sample Location"`
-	DateTime      time.Time      `json:"date_time" gorm:"not null" binding:"required" example:"2023-10-10T10:00:00Z"`
-	UserID        int64          `json:"user_id,omitempty" gorm:"not null" example:"1"`
-	User          User           `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
-	Registrations []Registration `gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE" json:"-"`
+	ID          int64       `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	Name        string      `json:"name" gorm:"not null" binding:"required" example:"Sample Event"`
+	Description string      `json:"description" gorm:"not null" binding:"required" example:"This is a sample event"`
+	Location    string      `json:"location" gorm:"not null" binding:"required" example:"Sample Location"`
+	DateTime    time.Time   `json:"date_time" gorm:"not null" binding:"required" example:"2023-10-10T10:00:00Z"`
+	UserID      int64       `json:"user_id,omitempty" gorm:"not null" example:"1"`
+	Capacity    int         `json:"capacity" gorm:"not null;default:0" binding:"min=0" example:"50"`
+	RRule       string      `json:"rrule,omitempty" gorm:"column:rrule" example:"FREQ=WEEKLY;BYDAY=TU;COUNT=10"`
+	ExDates     []time.Time `json:"exdates,omitempty" gorm:"column:exdates;type:text;serializer:json"`
+	// Latitude and Longitude are the optional WGS 84 coordinates of the venue
+	Latitude  *float64 `json:"latitude,omitempty" example:"41.0369"`
+	Longitude *float64 `json:"longitude,omitempty" example:"28.9850"`
+	Address   Address  `json:"address,omitzero" gorm:"embedded;embeddedPrefix:address_"`
+	// Status is the lifecycle state: draft, published, cancelled or completed
+	Status       string     `json:"status" gorm:"not null;default:published" example:"published"`
+	CancelReason string     `json:"cancel_reason,omitempty" example:"Venue unavailable"`
+	PublishedAt  *time.Time `json:"published_at,omitempty"`
+	CancelledAt  *time.Time `json:"cancelled_at,omitempty"`
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	// OccurrenceStart is the original start of an expanded occurrence; it is never stored
+	OccurrenceStart *time.Time     `json:"occurrence_start,omitempty" gorm:"-"`
+	User            User           `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	Registrations   []Registration `gorm:"foreignKey:EventID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 // Registration represents a user's registration for an event
 type Registration struct {
-	ID      int64 `gorm:"primaryKey;autoIncrement"`
-	UserID  int64 `gorm:"not null"`
-	EventID int64 `gorm:"not null"`
-	User    User  `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
-	Event   Event `gorm:"foreignKey:EventID;constraint:OnDelete:SET NULL" json:"-"`
+	ID      int64  `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID  int64  `json:"user_id" gorm:"not null"`
+	EventID int64  `json:"event_id" gorm:"not null"`
+	Status  string `json:"status" gorm:"not null;default:confirmed" example:"confirmed"`
+	// OccurrenceStart selects a single occurrence of a recurring event; nil covers the whole series
+	OccurrenceStart *time.Time `json:"occurrence_start,omitempty"`
+	Scope           string     `json:"scope,omitempty" example:"series"`
+	// CreatedAt is when the registration was made, or made again after it was cancelled
+	CreatedAt time.Time `json:"created_at"`
+	// UpdatedAt is when the status or occurrence last changed
+	UpdatedAt time.Time `json:"updated_at"`
+	// History lists the statuses the registration has had, oldest first. It
+	// is only loaded when the registration is looked up for its user.
+	History []RegistrationChange `json:"history,omitempty" gorm:"foreignKey:RegistrationID"`
+	User    User                 `gorm:"foreignKey:UserID;constraint:OnDelete:SET NULL" json:"-"`
+	Event   Event                `gorm:"foreignKey:EventID;constraint:OnDelete:SET NULL" json:"-"`
+}
+
+// RegistrationChange is an entry of the status history of a registration
+type RegistrationChange struct {
+	ID             int64     `json:"-" gorm:"primaryKey;autoIncrement"`
+	RegistrationID int64     `json:"-" gorm:"not null"`
+	Status         string    `json:"status" example:"waitlisted"`
+	ChangedAt      time.Time `json:"changed_at" gorm:"autoCreateTime"`
+}
+
+// Address is the structured postal address of an event venue
+type Address struct {
+	Street     string `json:"street,omitempty" example:"Istiklal Cd. 1"`
+	City       string `json:"city,omitempty" example:"Istanbul"`
+	Region     string `json:"region,omitempty" example:"Beyoglu"`
+	PostalCode string `json:"postal_code,omitempty" example:"34433"`
+	Country    string `json:"country,omitempty" example:"TR"`
 }
 
 // CreateEventRequest represents the request payload for creating an event
 type CreateEventRequest struct {
-	Name        string    `json:"name" binding:"required"`
-	Description string    `json:"description" binding:"required"`
-	Location    string    `json:"location" binding:"required"`
-	DateTime    time.Time `json:"date_time" binding:"required"`
+	Name        string    `json:"name" binding:"required" example:"Sample Event"`
+	Description string    `json:"description" binding:"required" example:"This is a sample event"`
+	Location    string    `json:"location" binding:"required" example:"Sample Location"`
+	DateTime    time.Time `json:"date_time" binding:"required" example:"2023-10-10T10:00:00Z"`
+	// Capacity 0 means unlimited; an update without it keeps the current capacity
+	Capacity *int `json:"capacity,omitempty" binding:"omitempty,min=0" example:"50"`
+	// RRule and ExDates make the event recurring (RFC 5545 RRULE/EXDATE)
+	RRule   string      `json:"rrule,omitempty" example:"FREQ=WEEKLY;BYDAY=TU;COUNT=10"`
+	ExDates []time.Time `json:"exdates,omitempty"`
+	// Latitude and Longitude must be given together
+	Latitude  *float64 `json:"latitude,omitempty" example:"41.0369"`
+	Longitude *float64 `json:"longitude,omitempty" example:"28.9850"`
+	Address   Address  `json:"address,omitzero"`
+	// Status is the initial status, draft (default) or published
+	Status string `json:"status,omitempty" binding:"omitempty,oneof=draft published" example:"draft"`
+}
+
+// CapacityOr returns the requested capacity, or current when none was given
+func (r CreateEventRequest) CapacityOr(current int) int {
+	if r.Capacity == nil {
+		return current
+	}
+	return *r.Capacity
+}
+
+// eventColumns lists the columns written when an event is created or updated
+var eventColumns = []string{
+	"Name", "Description", "Location", "DateTime", "UserID", "Capacity", "RRule", "ExDates",
+	"Latitude", "Longitude",
+	"address_street", "address_city", "address_region", "address_postal_code", "address_country",
+}
+
+// statusColumns lists the lifecycle columns written when an event is created.
+// Updates never change them; status changes go through the transitions.
+var statusColumns = []string{"Status", "PublishedAt"}
+
+// PrepareCreate normalizes a new event before it is stored. Events without a
+// status start as drafts; published ones get their publication time.
+func (e *Event) PrepareCreate() error {
+	if err := e.NormalizeRecurrence(); err != nil {
+		return err
+	}
+	switch e.Status {
+	case "":
+		e.Status = EventStatusDraft
+	case EventStatusDraft:
+	case EventStatusPublished:
+		if e.PublishedAt == nil {
+			now := time.Now().UTC()
+			e.PublishedAt = &now
+		}
+	default:
+		return fmt.Errorf("%w: events are created as draft or published", ErrInvalidStatus)
+	}
+	return nil
 }
 
-// Save creates a new event in the database
-func (e *Event) Save() error {
-	gormDB := db.GetDB()
-	return gormDB.Select("Name", "Description", "Location", "DateTime", "UserID").Create(e).Error
+// Save creates a new event in the database and queues its created message.
+// Events without a status start as drafts.
+func (e *Event) Save(gormDB *gorm.DB) error {
+	if err := e.PrepareCreate(); err != nil {
+		return err
+	}
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select(append(slices.Clone(eventColumns), statusColumns...)).Create(e).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionCreated, *e)
+	})
 }
 
 // GetAllEvents retrieves all events from the database
-func GetAllEvents() ([]Event, error) {
-	gormDB := db.GetDB()
+func GetAllEvents(gormDB *gorm.DB) ([]Event, error) {
 	var events []Event
 	err := gormDB.Find(&events).Error
 	return events, err
 }
 
-// GetEventByID retrieves a specific event by its ID
-func GetEventByID(id string) (*Event, error) {
-	gormDB := db.GetDB()
+// GetEventByID retrieves a specific event by its ID. It returns
+// ErrEventNotFound when no event has the ID.
+func GetEventByID(gormDB *gorm.DB, id int64) (*Event, error) {
+	var event Event
+	err := gormDB.Where("id = ?", id).First(&event).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ParseEventID parses an event ID given as a string. IDs that are not
+// numbers cannot name an event, so they are reported as not found.
+func ParseEventID(id string) (int64, error) {
+	eventID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, ErrEventNotFound
+	}
+	return eventID, nil
+}
+
+// Update modifies an existing event in the database and queues its updated
+// message. Raising the capacity promotes waitlisted users into the newly
+// available seats.
+func (e *Event) Update(gormDB *gorm.DB) error {
+	if err := e.NormalizeRecurrence(); err != nil {
+		return err
+	}
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(e).Select(eventColumns).Updates(e).Error; err != nil {
+			return err
+		}
+		locked, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		if err := promoteWaitlisted(tx, locked); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionUpdated, *locked)
+	})
+}
+
+// DeleteEvent removes an event from the database by its ID and queues its
+// deleted message. Its registrations and overrides go with it.
+func DeleteEvent(gormDB *gorm.DB, id int64) error {
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.Delete(&Event{}, event.ID).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionDeleted, *event)
+	})
+}
+
+// Register creates a registration for a user to attend this event, or the
+// part of its series selected by target. When the selected occurrences are
+// full the registration is placed on the waitlist instead. The event row is
+// locked for the duration of the transaction so concurrent registrations
+// cannot oversell the last seat. The registration is queued to the outbox.
+// It returns ErrAlreadyRegistered when the user's registrations for the
+// event already cover one of the selected occurrences. Registering again for
+// what a cancelled registration selected reactivates it, continuing its history.
+func (e Event) Register(gormDB *gorm.DB, userID int64, target OccurrenceTarget) (*Registration, error) {
+	var registration Registration
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		confirmed, err := confirmedRegistrations(tx, event.ID)
+		if err != nil {
+			return err
+		}
+		registration, err = event.NewRegistration(userID, target, confirmed)
+		if err != nil {
+			return err
+		}
+		var existing []Registration
+		if err := tx.Where("event_id = ? AND user_id = ?", event.ID, userID).Find(&existing).Error; err != nil {
+			return err
+		}
+		if registration.Duplicates(existing) {
+			return ErrAlreadyRegistered
+		}
+		if previous := registration.Reactivates(existing); previous != nil {
+			registration.ID = previous.ID
+			registration.CreatedAt = time.Now().UTC()
+			registration.UpdatedAt = registration.CreatedAt
+			err := tx.Model(&Registration{}).Where("id = ?", previous.ID).Updates(map[string]any{
+				"status":     registration.Status,
+				"scope":      registration.Scope,
+				"created_at": registration.CreatedAt,
+				"updated_at": registration.UpdatedAt,
+			}).Error
+			if err != nil {
+				return err
+			}
+		} else if err := tx.Create(&registration).Error; err != nil {
+			// The unique indexes catch what the check above cannot see
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return ErrAlreadyRegistered
+			}
+			return err
+		}
+		if err := recordStatus(tx, registration); err != nil {
+			return err
+		}
+		return enqueueRegistration(tx, RegistrationActionCreated, registration)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+// NewRegistration builds the registration of userID for the part of the
+// event selected by target, given the confirmed registrations of the event.
+// It is waitlisted when the selected occurrences are full. Only published
+// events are open for registration.
+func (e Event) NewRegistration(userID int64, target OccurrenceTarget, confirmed []Registration) (Registration, error) {
+	if e.Status != EventStatusPublished {
+		return Registration{}, ErrRegistrationClosed
+	}
+	if err := e.ValidateTarget(target); err != nil {
+		return Registration{}, err
+	}
+	registration := Registration{
+		UserID:          userID,
+		EventID:         e.ID,
+		Status:          RegistrationStatusConfirmed,
+		OccurrenceStart: target.Start,
+		Scope:           target.scope(),
+	}
+	if !e.HasSeat(confirmed, registration) {
+		registration.Status = RegistrationStatusWaitlisted
+	}
+	return registration, nil
+}
+
+// CancelEventRegistration cancels a user's registration for an event (or for
+// the occurrence starting at occurrence) and promotes the first waitlisted
+// user if a confirmed seat was freed. The registration is kept with the
+// cancelled status at the end of its history. Each change is queued to the
+// outbox. It returns ErrRegistrationNotFound when there was nothing to cancel.
+func CancelEventRegistration(gormDB *gorm.DB, userID, eventID int64, occurrence *time.Time) error {
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, eventID)
+		if err != nil {
+			return err
+		}
+		query := tx.Where("user_id = ? AND event_id = ? AND status <> ?", userID, event.ID, RegistrationStatusCancelled)
+		if occurrence != nil {
+			query = query.Where("occurrence_start = ?", *occurrence)
+		} else {
+			query = query.Where("occurrence_start IS NULL")
+		}
+		var active []Registration
+		if err := query.Order("id").Find(&active).Error; err != nil {
+			return err
+		}
+		if len(active) == 0 {
+			return ErrRegistrationNotFound
+		}
+		for _, registration := range active {
+			if err := cancelRegistration(tx, registration, RegistrationActionCancelled); err != nil {
+				return err
+			}
+		}
+		return promoteWaitlisted(tx, event)
+	})
+}
+
+// cancelRegistration moves registration to the cancelled status, records it
+// in its history and queues action to the outbox
+func cancelRegistration(tx *gorm.DB, registration Registration, action string) error {
+	registration.Status = RegistrationStatusCancelled
+	registration.UpdatedAt = time.Now().UTC()
+	err := tx.Model(&Registration{}).Where("id = ?", registration.ID).Updates(map[string]any{
+		"status":     registration.Status,
+		"updated_at": registration.UpdatedAt,
+	}).Error
+	if err != nil {
+		return err
+	}
+	if err := recordStatus(tx, registration); err != nil {
+		return err
+	}
+	return enqueueRegistration(tx, action, registration)
+}
+
+// Reactivates returns the cancelled registration, among those of the same user
+// for the same event, that selected the same occurrence start, or nil. Its row
+// is reused so each user keeps one registration per selection.
+func (r Registration) Reactivates(registrations []Registration) *Registration {
+	for _, other := range registrations {
+		if other.Status != RegistrationStatusCancelled {
+			continue
+		}
+		if (r.OccurrenceStart == nil && other.OccurrenceStart == nil) ||
+			(r.OccurrenceStart != nil && other.OccurrenceStart != nil && r.OccurrenceStart.Equal(*other.OccurrenceStart)) {
+			return &other
+		}
+	}
+	return nil
+}
+
+// GetWaitlistPosition returns the 1-based position of a waitlisted registration
+func GetWaitlistPosition(gormDB *gorm.DB, registration *Registration) (int64, error) {
+	var ahead int64
+	err := gormDB.Model(&Registration{}).
+		Where("event_id = ? AND status = ?", registration.EventID, RegistrationStatusWaitlisted).
+		Where("created_at < ? OR (created_at = ? AND id < ?)", registration.CreatedAt, registration.CreatedAt, registration.ID).
+		Count(&ahead).Error
+	return ahead + 1, err
+}
+
+// lockEvent loads an event with a row-level lock held until the transaction ends
+func lockEvent(tx *gorm.DB, id int64) (*Event, error) {
 	var event Event
-	err := gormDB.First(&event, id).Error
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&event).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, nil
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEventNotFound
 		}
 		return nil, err
 	}
 	return &event, nil
 }
 
-// Update modifies an existing event in the database
-func (e *Event) Update() error {
-	gormDB := db.GetDB()
-	return gormDB.Model(e).Select("Name", "Description", "Location", "DateTime", "UserID").Updates(e).Error
+func confirmedRegistrations(tx *gorm.DB, eventID int64) ([]Registration, error) {
+	var registrations []Registration
+	err := tx.Where("event_id = ? AND status = ?", eventID, RegistrationStatusConfirmed).
+		Find(&registrations).Error
+	return registrations, err
+}
+
+// recordStatus appends the current status of registration to its history
+func recordStatus(tx *gorm.DB, registration Registration) error {
+	return tx.Create(&RegistrationChange{RegistrationID: registration.ID, Status: registration.Status}).Error
+}
+
+// Covers reports whether the registration holds a seat at the occurrence starting at t
+func (r Registration) Covers(t time.Time) bool {
+	switch {
+	case r.OccurrenceStart == nil:
+		return true
+	case r.Scope == ScopeFollowing:
+		return !t.Before(*r.OccurrenceStart)
+	default:
+		return r.OccurrenceStart.Equal(t)
+	}
+}
+
+// Duplicates reports whether the registration covers an occurrence that one of
+// the registrations, those of the same user for the same event, already covers.
+// Cancelled registrations cover nothing.
+func (r Registration) Duplicates(registrations []Registration) bool {
+	for _, other := range registrations {
+		if other.Status == RegistrationStatusCancelled {
+			continue
+		}
+		if r.OccurrenceStart == nil || other.OccurrenceStart == nil ||
+			r.Covers(*other.OccurrenceStart) || other.Covers(*r.OccurrenceStart) {
+			return true
+		}
+	}
+	return false
+}
+
+// seatsTaken returns the highest number of confirmed registrations holding a
+// seat at any occurrence the candidate registration would cover
+func seatsTaken(confirmed []Registration, candidate Registration) int {
+	var points []time.Time
+	if candidate.OccurrenceStart != nil {
+		points = append(points, *candidate.OccurrenceStart)
+	}
+	taken := 0
+	for _, r := range confirmed {
+		if r.OccurrenceStart == nil {
+			taken++
+		} else if candidate.Covers(*r.OccurrenceStart) {
+			points = append(points, *r.OccurrenceStart)
+		}
+	}
+	for _, point := range points {
+		count := 0
+		for _, r := range confirmed {
+			if r.Covers(point) {
+				count++
+			}
+		}
+		if count > taken {
+			taken = count
+		}
+	}
+	return taken
 }
 
-// DeleteEvent removes an event from the database by its ID
-func DeleteEvent(id string) error {
-	gormDB := db.GetDB()
-	return gormDB.Delete(&Event{}, id).Error
+// HasSeat reports whether the candidate registration fits within the
+// capacity, given the confirmed registrations. Capacity 0 is unlimited.
+func (e Event) HasSeat(confirmed []Registration, candidate Registration) bool {
+	return e.Capacity == 0 || seatsTaken(confirmed, candidate) < e.Capacity
 }
 
-// Register creates a registration for a user to attend this event
-func (e Event) Register(userID int64) error {
-	gormDB := db.GetDB()
-	registration := Registration{UserID: userID, EventID: e.ID}
-	return gormDB.Create(&registration).Error
+// Promotable returns the waitlisted registrations, in waitlist order, that
+// free seats can be given to, confirmed
+func (e Event) Promotable(confirmed, waitlisted []Registration) []Registration {
+	confirmed = slices.Clone(confirmed)
+	var promoted []Registration
+	for _, registration := range waitlisted {
+		if !e.HasSeat(confirmed, registration) {
+			continue
+		}
+		registration.Status = RegistrationStatusConfirmed
+		confirmed = append(confirmed, registration)
+		promoted = append(promoted, registration)
+	}
+	return promoted
 }
 
-// CancelEventRegistration removes a user's registration for an event
-func (e Event) CancelEventRegistration(userID int64, eventID string) error {
-	gormDB := db.GetDB()
-	return gormDB.Where("user_id = ? AND event_id = ?", userID, eventID).Delete(&Registration{}).Error
+// promoteWaitlisted fills free seats from the waitlist in FIFO order and
+// queues a message for each promotion. The caller must hold the event lock.
+func promoteWaitlisted(tx *gorm.DB, event *Event) error {
+	var waitlisted []Registration
+	err := tx.Where("event_id = ? AND status = ?", event.ID, RegistrationStatusWaitlisted).
+		Order("created_at, id").
+		Find(&waitlisted).Error
+	if err != nil || len(waitlisted) == 0 {
+		return err
+	}
+
+	confirmed, err := confirmedRegistrations(tx, event.ID)
+	if err != nil {
+		return err
+	}
+	for _, registration := range event.Promotable(confirmed, waitlisted) {
+		if err := tx.Model(&Registration{}).Where("id = ?", registration.ID).
+			Update("status", RegistrationStatusConfirmed).Error; err != nil {
+			return err
+		}
+		if err := recordStatus(tx, registration); err != nil {
+			return err
+		}
+		if err := enqueueRegistration(tx, RegistrationActionPromoted, registration); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// GetRegistrationsByUserID retrieves all events a user is registered for
-func GetRegistrationsByUserID(userID int64) ([]Event, error) {
-	gormDB := db.GetDB()
+// GetRegistrationsByUserID retrieves all events a user is registered for, each
+// once however many of its occurrences the user is registered for
+func GetRegistrationsByUserID(gormDB *gorm.DB, userID int64) ([]Event, error) {
 	var events []Event
-	err := gormDB.Joins("JOIN registrations r ON events.id = r.event_id").
-		Where("r.user_id = ?", userID).
+	registered := gormDB.Model(&Registration{}).Select("event_id").
+		Where("user_id = ? AND status <> ?", userID, RegistrationStatusCancelled)
+	err := gormDB.Where("id IN (?)", registered).
+		Order("id").
 		Find(&events).Error
 	return events, err
 }
+
+// GetUserEventRegistrations retrieves a user's registrations for an event with
+// their status history, oldest first. Cancelled registrations are included.
+func GetUserEventRegistrations(gormDB *gorm.DB, userID, eventID int64) ([]Registration, error) {
+	var registrations []Registration
+	err := gormDB.Preload("History", func(tx *gorm.DB) *gorm.DB { return tx.Order("id") }).
+		Where("user_id = ? AND event_id = ?", userID, eventID).
+		Order("created_at, id").
+		Find(&registrations).Error
+	return registrations, err
+}
+
+// GetRegistrationDetailsByUserID retrieves a user's registrations that were
+// not cancelled together with their events
+func GetRegistrationDetailsByUserID(gormDB *gorm.DB, userID int64) ([]Registration, error) {
+	var registrations []Registration
+	err := gormDB.Preload("Event").
+		Where("user_id = ? AND status <> ?", userID, RegistrationStatusCancelled).
+		Order("created_at, id").
+		Find(&registrations).Error
+	return registrations, err
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"cmp"
+	"errors"
+	"math"
+	"slices"
+
+	"gorm.io/gorm"
+)
+
+// earthRadiusKm is the mean radius of the Earth used for haversine distances
+const earthRadiusKm = 6371.0
+
+// MaxNearbyRadiusKm bounds the radius of a nearby search
+const MaxNearbyRadiusKm = 500.0
+
+// Errors returned for invalid coordinates and nearby queries
+var (
+	ErrInvalidCoordinates = errors.New("latitude must be within [-90, 90] and longitude within [-180, 180], and both must be given together")
+	ErrInvalidRadius      = errors.New("radius_km must be greater than 0 and at most 500")
+)
+
+// NearbyQuery finds events within RadiusKm of a point
+type NearbyQuery struct {
+	Latitude  float64
+	Longitude float64
+	RadiusKm  float64
+	PageSize  int   // defaults to DefaultPageSize, capped at MaxPageSize
+	ViewerID  int64 // drafts are only found by their owner
+}
+
+// NearbyEvent is an event with its distance from the query point
+type NearbyEvent struct {
+	Event
+	DistanceKm float64 `json:"distance_km" example:"1.42"`
+}
+
+// ValidateCoordinates checks that an optional latitude/longitude pair is
+// either absent or a valid point
+func ValidateCoordinates(latitude, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil || !validPoint(*latitude, *longitude) {
+		return ErrInvalidCoordinates
+	}
+	return nil
+}
+
+func validPoint(latitude, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+// HaversineKm returns the great-circle distance between two points in kilometres
+func HaversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	dLat := radians(lat2 - lat1)
+	dLng := radians(lng2 - lng1)
+	a := math.Pow(math.Sin(dLat/2), 2) + math.Cos(radians(lat1))*math.Cos(radians(lat2))*math.Pow(math.Sin(dLng/2), 2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// boundingBox is a latitude/longitude rectangle containing every point within
+// a radius. Near the antimeridian the longitude span wraps around, so it is
+// split into up to two ranges.
+type boundingBox struct {
+	MinLat, MaxLat float64
+	LngRanges      [][2]float64
+}
+
+// newBoundingBox returns the box enclosing the circle of radiusKm around a point
+func newBoundingBox(latitude, longitude, radiusKm float64) boundingBox {
+	dLat := radiusKm / earthRadiusKm * 180 / math.Pi
+	box := boundingBox{MinLat: latitude - dLat, MaxLat: latitude + dLat}
+	if box.MinLat <= -90 || box.MaxLat >= 90 {
+		// The circle contains a pole, so every longitude is in range
+		box.MinLat, box.MaxLat = math.Max(box.MinLat, -90), math.Min(box.MaxLat, 90)
+		box.LngRanges = [][2]float64{{-180, 180}}
+		return box
+	}
+
+	dLng := math.Asin(math.Min(1, math.Sin(radians(dLat))/math.Cos(radians(latitude)))) * 180 / math.Pi
+	minLng, maxLng := longitude-dLng, longitude+dLng
+	switch {
+	case minLng < -180:
+		box.LngRanges = [][2]float64{{minLng + 360, 180}, {-180, maxLng}}
+	case maxLng > 180:
+		box.LngRanges = [][2]float64{{minLng, 180}, {-180, maxLng - 360}}
+	default:
+		box.LngRanges = [][2]float64{{minLng, maxLng}}
+	}
+	return box
+}
+
+// nearbyRow is a distance computed by the database
+type nearbyRow struct {
+	ID         int64
+	DistanceKm float64
+}
+
+// normalize validates the query and applies the default page size
+func (q *NearbyQuery) normalize() error {
+	if !validPoint(q.Latitude, q.Longitude) {
+		return ErrInvalidCoordinates
+	}
+	if q.RadiusKm <= 0 || q.RadiusKm > MaxNearbyRadiusKm {
+		return ErrInvalidRadius
+	}
+	switch {
+	case q.PageSize < 0:
+		return ErrInvalidPageSize
+	case q.PageSize == 0:
+		q.PageSize = DefaultPageSize
+	case q.PageSize > MaxPageSize:
+		q.PageSize = MaxPageSize
+	}
+	return nil
+}
+
+// GetNearbyEvents returns the events within the query radius, nearest first.
+// A bounding-box prefilter on the indexed latitude/longitude columns narrows
+// the candidates before the exact haversine distance is computed, in SQL on
+// PostgreSQL and in Go on other databases.
+func GetNearbyEvents(gormDB *gorm.DB, query NearbyQuery) ([]NearbyEvent, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+	box := newBoundingBox(query.Latitude, query.Longitude, query.RadiusKm)
+	lngFilter := gormDB.Where("longitude BETWEEN ? AND ?", box.LngRanges[0][0], box.LngRanges[0][1])
+	for _, lngRange := range box.LngRanges[1:] {
+		lngFilter = lngFilter.Or("longitude BETWEEN ? AND ?", lngRange[0], lngRange[1])
+	}
+	if gormDB.Dialector.Name() != "postgres" {
+		var candidates []Event
+		err := visibleTo(gormDB, query.ViewerID).
+			Where("latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat).
+			Where(lngFilter).
+			Find(&candidates).Error
+		if err != nil {
+			return nil, err
+		}
+		return nearest(candidates, query), nil
+	}
+
+	candidates := visibleTo(gormDB.Table("events"), query.ViewerID).
+		Select(`id, 2 * ? * asin(least(1, sqrt(
+			power(sin(radians(latitude - ?) / 2), 2) +
+			cos(radians(?)) * cos(radians(latitude)) * power(sin(radians(longitude - ?) / 2), 2)
+		))) AS distance_km`, earthRadiusKm, query.Latitude, query.Latitude, query.Longitude).
+		Where("latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat).
+		Where(lngFilter)
+
+	var rows []nearbyRow
+	err := gormDB.Table("(?) AS nearby", candidates).
+		Where("distance_km <= ?", query.RadiusKm).
+		Order("distance_km, id").
+		Limit(query.PageSize).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return []NearbyEvent{}, nil
+	}
+
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var events []Event
+	if err := gormDB.Find(&events, ids).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Event, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+
+	nearby := make([]NearbyEvent, 0, len(rows))
+	for _, row := range rows {
+		if event, ok := byID[row.ID]; ok {
+			nearby = append(nearby, NearbyEvent{Event: event, DistanceKm: row.DistanceKm})
+		}
+	}
+	return nearby, nil
+}
+
+// NearbyEventsIn is GetNearbyEvents over events held in memory
+func NearbyEventsIn(events []Event, query NearbyQuery) ([]NearbyEvent, error) {
+	if err := query.normalize(); err != nil {
+		return nil, err
+	}
+	var visible []Event
+	for _, e := range events {
+		if e.VisibleTo(query.ViewerID) {
+			visible = append(visible, e)
+		}
+	}
+	return nearest(visible, query), nil
+}
+
+// nearest returns the events with a location within the query radius,
+// nearest first and then by ID, up to the page size
+func nearest(events []Event, query NearbyQuery) []NearbyEvent {
+	nearby := []NearbyEvent{}
+	for _, e := range events {
+		if e.Latitude == nil || e.Longitude == nil {
+			continue
+		}
+		distance := HaversineKm(query.Latitude, query.Longitude, *e.Latitude, *e.Longitude)
+		if distance <= query.RadiusKm {
+			nearby = append(nearby, NearbyEvent{Event: e, DistanceKm: distance})
+		}
+	}
+	slices.SortFunc(nearby, func(a, b NearbyEvent) int {
+		return cmp.Or(cmp.Compare(a.DistanceKm, b.DistanceKm), cmp.Compare(a.ID, b.ID))
+	})
+	if len(nearby) > query.PageSize {
+		nearby = nearby[:query.PageSize]
+	}
+	return nearby
+}
@@ -0,0 +1,201 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+	"gorm.io/gorm"
+)
+
+// Event lifecycle statuses
+const (
+	EventStatusDraft     = "draft"
+	EventStatusPublished = "published"
+	EventStatusCancelled = "cancelled"
+	EventStatusCompleted = "completed"
+)
+
+// maxCancelReasonLength bounds the reason given when an event is cancelled
+const maxCancelReasonLength = 500
+
+var (
+	// ErrInvalidTransition is returned when an event cannot move to the requested status
+	ErrInvalidTransition = errors.New("invalid event status transition")
+	// ErrInvalidStatus is returned for an unknown event status
+	ErrInvalidStatus = errors.New("status must be one of draft, published, cancelled or completed")
+	// ErrCancelReasonRequired is returned when an event is cancelled without a reason
+	ErrCancelReasonRequired = errors.New("a cancellation reason of at most 500 characters is required")
+	// ErrRegistrationClosed is returned when registering for an event that is not published
+	ErrRegistrationClosed = errors.New("registration is only open for published events")
+)
+
+// CancelEventRequest represents the request payload for cancelling an event
+type CancelEventRequest struct {
+	Reason string `json:"reason" binding:"required" example:"Venue unavailable"`
+}
+
+// eventTransitions lists the statuses each status may move to. Cancelled and
+// completed are final.
+var eventTransitions = map[string][]string{
+	EventStatusDraft:     {EventStatusPublished, EventStatusCancelled},
+	EventStatusPublished: {EventStatusCancelled, EventStatusCompleted},
+}
+
+// ValidateEventStatus checks that status is a known lifecycle status
+func ValidateEventStatus(status string) error {
+	switch status {
+	case EventStatusDraft, EventStatusPublished, EventStatusCancelled, EventStatusCompleted:
+		return nil
+	default:
+		return ErrInvalidStatus
+	}
+}
+
+// CanTransition reports whether an event may move from one status to another
+func CanTransition(from, to string) bool {
+	return slices.Contains(eventTransitions[from], to)
+}
+
+// VisibleTo reports whether the event can be seen by viewerID. Drafts are
+// only visible to their owner; viewerID 0 is an anonymous viewer.
+func (e Event) VisibleTo(viewerID int64) bool {
+	return e.Status != EventStatusDraft || (viewerID != 0 && e.UserID == viewerID)
+}
+
+// visibleTo restricts tx to the events viewerID can see
+func visibleTo(tx *gorm.DB, viewerID int64) *gorm.DB {
+	if viewerID == 0 {
+		return tx.Where("events.status <> ?", EventStatusDraft)
+	}
+	return tx.Where("(events.status <> ? OR events.user_id = ?)", EventStatusDraft, viewerID)
+}
+
+// EndedBy reports whether the last occurrence of the event started before
+// now. Recurring events without COUNT or UNTIL never end.
+func (e Event) EndedBy(now time.Time) bool {
+	if !e.IsRecurring() {
+		return e.DateTime.Before(now)
+	}
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return false
+	}
+	return rule.EndsBefore(e.DateTime, now, e.ExDates)
+}
+
+// lifecycleColumns lists the columns written by a status transition
+var lifecycleColumns = []string{"Status", "CancelReason", "PublishedAt", "CancelledAt", "CompletedAt"}
+
+// Transition returns the event moved to status at now. Cancelling requires a
+// reason, which is kept so attendees can be told why.
+func (e Event) Transition(status, reason string, now time.Time) (Event, error) {
+	if status == EventStatusCancelled {
+		var err error
+		if reason, err = cancelReason(reason); err != nil {
+			return e, err
+		}
+	}
+	if !CanTransition(e.Status, status) {
+		return e, fmt.Errorf("%w: %s event cannot become %s", ErrInvalidTransition, e.Status, status)
+	}
+	e.Status = status
+	switch status {
+	case EventStatusPublished:
+		e.PublishedAt = &now
+	case EventStatusCancelled:
+		e.CancelReason = reason
+		e.CancelledAt = &now
+	case EventStatusCompleted:
+		e.CompletedAt = &now
+	}
+	return e, nil
+}
+
+// cancelReason trims a cancellation reason and checks that it is given and not too long
+func cancelReason(reason string) (string, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len(reason) > maxCancelReasonLength {
+		return "", ErrCancelReasonRequired
+	}
+	return reason, nil
+}
+
+// Publish makes a draft event visible to everyone and open for registration
+func (e *Event) Publish(gormDB *gorm.DB) error {
+	return e.transition(gormDB, EventStatusPublished, "")
+}
+
+// Cancel cancels a draft or published event. Existing registrations are kept
+// so attendees can be told why, but no new registrations are accepted.
+func (e *Event) Cancel(gormDB *gorm.DB, reason string) error {
+	if _, err := cancelReason(reason); err != nil {
+		return err
+	}
+	return e.transition(gormDB, EventStatusCancelled, reason)
+}
+
+// transition moves the event to status under a row lock, reloads it and
+// queues the message for the transition, whose action is the new status
+func (e *Event) transition(gormDB *gorm.DB, status, reason string) error {
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		changed, err := event.Transition(status, reason, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(&Event{}).Where("id = ?", event.ID).Select(lifecycleColumns).Updates(&changed).Error; err != nil {
+			return err
+		}
+		if err := tx.First(e, event.ID).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, status, *e)
+	})
+}
+
+// CompleteEndedEvents marks every published event whose last occurrence
+// started before now as completed, queueing a completed message for each,
+// and returns the events it changed
+func CompleteEndedEvents(gormDB *gorm.DB, now time.Time) ([]Event, error) {
+	var candidates []Event
+	err := gormDB.Where("status = ? AND date_time < ?", EventStatusPublished, now).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var completed []Event
+	for _, event := range candidates {
+		if !event.EndedBy(now) {
+			continue
+		}
+		changed := false
+		err := gormDB.Transaction(func(tx *gorm.DB) error {
+			// The status guard skips events cancelled since they were read
+			result := tx.Model(&Event{}).
+				Where("id = ? AND status = ?", event.ID, EventStatusPublished).
+				Updates(map[string]any{"status": EventStatusCompleted, "completed_at": now})
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+			event.Status = EventStatusCompleted
+			event.CompletedAt = &now
+			changed = true
+			return enqueueEvent(tx, EventActionCompleted, event)
+		})
+		if err != nil {
+			return completed, err
+		}
+		if changed {
+			completed = append(completed, event)
+		}
+	}
+	return completed, nil
+}
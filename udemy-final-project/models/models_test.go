@@ -1,19 +1,27 @@
 package models
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 func TestUser_Save(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user
 	user := User{
@@ -22,25 +30,25 @@ func TestUser_Save(t *testing.T) {
 	}
 
 	// Test Save method
-	err := user.Save()
+	err := user.Save(testDB)
 	require.NoError(t, err)
 	assert.NotZero(t, user.ID)
 }
 
 func TestGetUserByEmail(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user
 	testUser := User{
 		Email:    "test@example.com",
 		Password: "testpassword",
 	}
-	err := testUser.Save()
+	err := testUser.Save(testDB)
 	require.NoError(t, err)
 
 	// Test GetUserByEmail
-	user, err := GetUserByEmail("test@example.com")
+	user, err := GetUserByEmail(testDB, "test@example.com")
 	require.NoError(t, err)
 	assert.NotNil(t, user)
 	assert.Equal(t, "test@example.com", user.Email)
@@ -48,37 +56,37 @@ func TestGetUserByEmail(t *testing.T) {
 
 func TestVerifyUserCredentials(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user
 	testUser := User{
 		Email:    "test@example.com",
 		Password: "testpassword",
 	}
-	err := testUser.Save()
+	err := testUser.Save(testDB)
 	require.NoError(t, err)
 
 	// Test valid credentials
-	user, err := VerifyUserCredentials("test@example.com", "testpassword")
+	user, err := VerifyUserCredentials(testDB, "test@example.com", "testpassword")
 	require.NoError(t, err)
 	assert.NotNil(t, user)
 
 	// Test invalid credentials
-	user, err = VerifyUserCredentials("test@example.com", "wrongpassword")
+	user, err = VerifyUserCredentials(testDB, "test@example.com", "wrongpassword")
 	require.NoError(t, err)
 	assert.Nil(t, user)
 }
 
 func TestEvent_Save(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user first
 	testUser := User{
 		Email:    "test@example.com",
 		Password: "testpassword",
 	}
-	err := testUser.Save()
+	err := testUser.Save(testDB)
 	require.NoError(t, err)
 
 	// Create test event
@@ -91,21 +99,21 @@ func TestEvent_Save(t *testing.T) {
 	}
 
 	// Test Save method
-	err = event.Save()
+	err = event.Save(testDB)
 	require.NoError(t, err)
 	assert.NotZero(t, event.ID)
 }
 
 func TestGetAllEvents(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user
 	testUser := User{
 		Email:    "test@example.com",
 		Password: "testpassword",
 	}
-	err := testUser.Save()
+	err := testUser.Save(testDB)
 	require.NoError(t, err)
 
 	// Create test event
@@ -116,11 +124,11 @@ func TestGetAllEvents(t *testing.T) {
 		DateTime:    time.Now().Add(24 * time.Hour),
 		UserID:      testUser.ID,
 	}
-	err = event.Save()
+	err = event.Save(testDB)
 	require.NoError(t, err)
 
 	// Test GetAllEvents
-	events, err := GetAllEvents()
+	events, err := GetAllEvents(testDB)
 	require.NoError(t, err)
 	assert.NotEmpty(t, events)
 
@@ -134,16 +142,53 @@ func TestGetAllEvents(t *testing.T) {
 	assert.True(t, found, "Created event not found in GetAllEvents result")
 }
 
+func TestListEvents(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	testUser := User{
+		Email:    "test@example.com",
+		Password: "testpassword",
+	}
+	require.NoError(t, testUser.Save(testDB))
+
+	for _, name := range []string{"Charlie", "Alpha", "Bravo"} {
+		event := Event{
+			Name:        name,
+			Description: "Test Description",
+			Location:    "Test Location",
+			DateTime:    time.Now().Add(24 * time.Hour),
+			UserID:      testUser.ID,
+		}
+		require.NoError(t, event.Save(testDB))
+	}
+
+	// New events are drafts, which only their owner can list
+	query := EventQuery{UserID: testUser.ID, ViewerID: testUser.ID, Location: "test loc", SortBy: SortByName, PageSize: 2}
+	first, err := ListEvents(testDB, query)
+	require.NoError(t, err)
+	require.Len(t, first.Events, 2)
+	assert.Equal(t, "Alpha", first.Events[0].Name)
+	assert.Equal(t, "Bravo", first.Events[1].Name)
+	require.NotEmpty(t, first.NextPageToken)
+
+	query.PageToken = first.NextPageToken
+	second, err := ListEvents(testDB, query)
+	require.NoError(t, err)
+	require.Len(t, second.Events, 1)
+	assert.Equal(t, "Charlie", second.Events[0].Name)
+	assert.Empty(t, second.NextPageToken)
+}
+
 func TestGetEventByID(t *testing.T) {
 	// Setup test database
-	setupTestDB(t)
+	testDB := setupTestDB(t)
 
 	// Create test user
 	testUser := User{
 		Email:    "test@example.com",
 		Password: "testpassword",
 	}
-	err := testUser.Save()
+	err := testUser.Save(testDB)
 	require.NoError(t, err)
 
 	// Create test event
@@ -154,49 +199,699 @@ func TestGetEventByID(t *testing.T) {
 		DateTime:    time.Now().Add(24 * time.Hour),
 		UserID:      testUser.ID,
 	}
-	err = event.Save()
+	err = event.Save(testDB)
 	require.NoError(t, err)
 
 	// Test GetEventByID
-	retrievedEvent, err := GetEventByID(fmt.Sprintf("%d", event.ID))
+	retrievedEvent, err := GetEventByID(testDB, event.ID)
 	require.NoError(t, err)
 	assert.NotNil(t, retrievedEvent)
 	assert.Equal(t, event.ID, retrievedEvent.ID)
 }
 
-// Helper function to setup test database
+func TestEvent_RegisterWaitlistAndPromotion(t *testing.T) {
+	// Setup test database
+	testDB := setupTestDB(t)
+
+	// Create the owner and two attendees
+	var users []User
+	for _, email := range []string{"test-owner@example.com", "test-first@example.com", "test-second@example.com"} {
+		user := User{Email: email, Password: "testpassword"}
+		require.NoError(t, user.Save(testDB))
+		users = append(users, user)
+	}
+
+	// Create an event with a single seat
+	event := Event{
+		Name:        "Test Event",
+		Description: "Test Description",
+		Location:    "Test Location",
+		DateTime:    time.Now().Add(24 * time.Hour),
+		UserID:      users[0].ID,
+		Capacity:    1,
+		Status:      EventStatusPublished,
+	}
+	require.NoError(t, event.Save(testDB))
+
+	// First attendee takes the seat, second is waitlisted
+	first, err := event.Register(testDB, users[1].ID, OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, RegistrationStatusConfirmed, first.Status)
+
+	second, err := event.Register(testDB, users[2].ID, OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, RegistrationStatusWaitlisted, second.Status)
+
+	position, err := GetWaitlistPosition(testDB, second)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), position)
+
+	// Cancelling the confirmed seat promotes the waitlisted attendee
+	err = CancelEventRegistration(testDB, users[1].ID, event.ID, nil)
+	require.NoError(t, err)
+
+	var promoted Registration
+	require.NoError(t, testDB.First(&promoted, second.ID).Error)
+	assert.Equal(t, RegistrationStatusConfirmed, promoted.Status)
+
+	// The unique indexes reject a second registration the check missed
+	duplicate := Registration{UserID: users[2].ID, EventID: event.ID, Status: RegistrationStatusConfirmed}
+	assert.ErrorIs(t, testDB.Create(&duplicate).Error, gorm.ErrDuplicatedKey)
+}
+
+func TestRegistration_Duplicates(t *testing.T) {
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	next := start.AddDate(0, 0, 7)
+	series := Registration{Scope: ScopeSeries}
+	first := Registration{OccurrenceStart: &start, Scope: ScopeOccurrence}
+	second := Registration{OccurrenceStart: &next, Scope: ScopeOccurrence}
+	following := Registration{OccurrenceStart: &next, Scope: ScopeFollowing}
+
+	assert.False(t, first.Duplicates(nil))
+	assert.True(t, series.Duplicates([]Registration{second}))
+	assert.True(t, second.Duplicates([]Registration{series}))
+	assert.False(t, second.Duplicates([]Registration{first}))
+	assert.True(t, second.Duplicates([]Registration{first, second}))
+	assert.True(t, second.Duplicates([]Registration{following}))
+	assert.False(t, first.Duplicates([]Registration{following}), "earlier occurrences are not covered")
+	assert.True(t, following.Duplicates([]Registration{second}))
+}
+
+func TestEvent_Occurrences(t *testing.T) {
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	event := Event{
+		ID:       1,
+		Name:     "Weekly Meetup",
+		DateTime: start,
+		RRule:    "FREQ=WEEKLY;COUNT=4",
+		ExDates:  []time.Time{start.AddDate(0, 0, 7)},
+	}
+	overrides := []OccurrenceOverride{{
+		EventID:         1,
+		OccurrenceStart: start.AddDate(0, 0, 14),
+		Name:            "Weekly Meetup (moved)",
+		DateTime:        start.AddDate(0, 0, 15),
+	}}
+
+	occurrences, err := event.Occurrences(start, start.AddDate(0, 1, 0), overrides)
+	require.NoError(t, err)
+	require.Len(t, occurrences, 3)
+
+	assert.Equal(t, start, occurrences[0].DateTime)
+	assert.Equal(t, "Weekly Meetup (moved)", occurrences[1].Name)
+	assert.Equal(t, start.AddDate(0, 0, 15), occurrences[1].DateTime)
+	assert.Equal(t, start.AddDate(0, 0, 14), *occurrences[1].OccurrenceStart)
+	assert.Equal(t, start.AddDate(0, 0, 21), occurrences[2].DateTime)
+}
+
+func TestPageOccurrences(t *testing.T) {
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	weekly := Event{ID: 1, Name: "Meetup", Location: "Istanbul", DateTime: start, RRule: "FREQ=WEEKLY;COUNT=3"}
+	occurrences, err := weekly.Occurrences(start, start.AddDate(0, 1, 0), nil)
+	require.NoError(t, err)
+	occurrences = append(occurrences,
+		Event{ID: 2, Name: "Conference", Location: "Ankara", DateTime: start.Add(time.Hour)},
+		Event{ID: 3, Name: "Workshop", Location: "istanbul", DateTime: start.AddDate(0, 0, 1)},
+	)
+
+	query := EventQuery{From: start, To: start.AddDate(0, 1, 0), Location: "ISTANBUL", Descending: true, PageSize: 2}
+	cursor, err := query.normalize()
+	require.NoError(t, err)
+
+	var names []string
+	var starts []time.Time
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3)
+		page := pageOccurrences(occurrences, query, cursor)
+		for _, e := range page.Events {
+			names = append(names, e.Name)
+			starts = append(starts, e.DateTime)
+		}
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+		cursor, err = query.normalize()
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []string{"Meetup", "Meetup", "Workshop", "Meetup"}, names)
+	assert.Equal(t, []time.Time{start.AddDate(0, 0, 14), start.AddDate(0, 0, 7), start.AddDate(0, 0, 1), start}, starts)
+
+	query.SortBy = SortByName
+	_, err = query.normalize()
+	assert.ErrorIs(t, err, ErrInvalidPageToken)
+}
+
+func TestRankEvents(t *testing.T) {
+	events := []Event{
+		{ID: 1, Name: "Cooking class", Description: "Learn Go <fast> at the Go meetup", Location: "Ankara"},
+		{ID: 2, Name: "Go Meetup", Description: "Monthly meetup", Location: "Istanbul"},
+		{ID: 3, Name: "Golang workshop", Description: "Hands-on", Location: "Izmir"},
+		{ID: 4, Name: "Yoga", Description: "Morning session", Location: "Istanbul"},
+	}
+
+	results := RankEvents(events, "go MEET")
+	require.Len(t, results, 2)
+	assert.Equal(t, int64(2), results[0].Event.ID, "name matches outrank description matches")
+	assert.Equal(t, int64(1), results[1].Event.ID)
+	assert.Greater(t, results[0].Rank, results[1].Rank)
+
+	assert.Equal(t, "<mark>Go</mark> <mark>Meetup</mark>", results[0].Highlights["name"])
+	assert.Equal(t, "Monthly <mark>meetup</mark>", results[0].Highlights["description"])
+	assert.NotContains(t, results[0].Highlights, "location")
+	assert.Equal(t, "Learn <mark>Go</mark> &lt;fast&gt; at the <mark>Go</mark> <mark>meetup</mark>", results[1].Highlights["description"])
+
+	assert.Empty(t, RankEvents(events, "  !! "))
+	assert.Len(t, RankEvents(events, "istanbul"), 2)
+}
+
+func TestHaversineAndBoundingBox(t *testing.T) {
+	// Istanbul (Taksim) to Ankara (Kizilay) is about 350 km
+	assert.InDelta(t, 350, HaversineKm(41.0369, 28.9850, 39.9208, 32.8541), 5)
+	assert.Zero(t, HaversineKm(41, 29, 41, 29))
+
+	box := newBoundingBox(41.0369, 28.9850, 10)
+	require.Len(t, box.LngRanges, 1)
+	assert.InDelta(t, 10/111.195, box.MaxLat-41.0369, 1e-3)
+	// Points on the circle must fall inside the box
+	for _, bearing := range []float64{0, 90, 180, 270} {
+		lat, lng := destination(41.0369, 28.9850, bearing, 10)
+		assert.True(t, lat >= box.MinLat && lat <= box.MaxLat, "latitude at bearing %v", bearing)
+		assert.True(t, lng >= box.LngRanges[0][0] && lng <= box.LngRanges[0][1], "longitude at bearing %v", bearing)
+	}
+
+	// Near the antimeridian the longitude range wraps
+	box = newBoundingBox(-17.7, 179.95, 20)
+	require.Len(t, box.LngRanges, 2)
+	assert.Equal(t, 180.0, box.LngRanges[0][1])
+	assert.Equal(t, -180.0, box.LngRanges[1][0])
+
+	// A circle containing a pole covers every longitude
+	box = newBoundingBox(89.9, 0, 50)
+	assert.Equal(t, [][2]float64{{-180, 180}}, box.LngRanges)
+	assert.Equal(t, 90.0, box.MaxLat)
+
+	assert.NoError(t, ValidateCoordinates(nil, nil))
+	lat, lng := 91.0, 10.0
+	assert.ErrorIs(t, ValidateCoordinates(&lat, &lng), ErrInvalidCoordinates)
+	assert.ErrorIs(t, ValidateCoordinates(nil, &lng), ErrInvalidCoordinates)
+}
+
+// destination returns the point distanceKm from a start point along a bearing in degrees
+func destination(lat, lng, bearing, distanceKm float64) (float64, float64) {
+	d := distanceKm / earthRadiusKm
+	lat1, lng1, b := radians(lat), radians(lng), radians(bearing)
+	lat2 := math.Asin(math.Sin(lat1)*math.Cos(d) + math.Cos(lat1)*math.Sin(d)*math.Cos(b))
+	lng2 := lng1 + math.Atan2(math.Sin(b)*math.Sin(d)*math.Cos(lat1), math.Cos(d)-math.Sin(lat1)*math.Sin(lat2))
+	return lat2 * 180 / math.Pi, lng2 * 180 / math.Pi
+}
+
+// setupTestDB opens an empty SQLite database for a test. It also becomes the
+// global database used by the sessions, which are not given one.
 func setupTestDB(t *testing.T) *gorm.DB {
-	// Initialize database connection if not already done
-	if db.GetDB() == nil {
-		db.InitDB()
+	testDB, err := db.OpenSQLite(filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := testDB.DB()
+		_ = sqlDB.Close()
+	})
+	db.DB = testDB
+
+	// Hashing at the production cost would make every test take seconds
+	auth := config.Default().Auth
+	auth.BcryptCost = bcrypt.MinCost
+	auth.EphemeralSigningKey = true
+	require.NoError(t, security.Configure(auth))
+	return testDB
+}
+
+func TestEventLifecycleRules(t *testing.T) {
+	assert.True(t, CanTransition(EventStatusDraft, EventStatusPublished))
+	assert.True(t, CanTransition(EventStatusDraft, EventStatusCancelled))
+	assert.True(t, CanTransition(EventStatusPublished, EventStatusCompleted))
+	assert.False(t, CanTransition(EventStatusDraft, EventStatusCompleted))
+	assert.False(t, CanTransition(EventStatusPublished, EventStatusDraft))
+	assert.False(t, CanTransition(EventStatusCancelled, EventStatusPublished))
+	assert.False(t, CanTransition(EventStatusCompleted, EventStatusCancelled))
+
+	draft := Event{UserID: 7, Status: EventStatusDraft}
+	assert.True(t, draft.VisibleTo(7))
+	assert.False(t, draft.VisibleTo(8))
+	assert.False(t, draft.VisibleTo(0))
+	assert.True(t, Event{UserID: 7, Status: EventStatusCancelled}.VisibleTo(0))
+
+	start := time.Date(2025, time.January, 7, 18, 0, 0, 0, time.UTC)
+	oneOff := Event{DateTime: start}
+	assert.False(t, oneOff.EndedBy(start))
+	assert.True(t, oneOff.EndedBy(start.Add(time.Minute)))
+
+	series := Event{DateTime: start, RRule: "FREQ=WEEKLY;COUNT=3"}
+	assert.False(t, series.EndedBy(start.AddDate(0, 0, 14)))
+	assert.True(t, series.EndedBy(start.AddDate(0, 0, 14).Add(time.Minute)))
+
+	endless := Event{DateTime: start, RRule: "FREQ=WEEKLY"}
+	assert.False(t, endless.EndedBy(start.AddDate(10, 0, 0)))
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	assert.Equal(t, time.Second, OutboxBackoff(1))
+	assert.Equal(t, 2*time.Second, OutboxBackoff(2))
+	assert.Equal(t, 8*time.Second, OutboxBackoff(4))
+	assert.Equal(t, 5*time.Minute, OutboxBackoff(10))
+	assert.Equal(t, 5*time.Minute, OutboxBackoff(1000))
+}
+
+// queueOutbox queues a change of each event ID and returns the messages
+func queueOutbox(t *testing.T, testDB *gorm.DB, eventIDs ...int64) []OutboxMessage {
+	t.Helper()
+	messages := make([]OutboxMessage, len(eventIDs))
+	for i, id := range eventIDs {
+		message, err := NewEventMessage(context.Background(), EventActionUpdated, Event{ID: id})
+		require.NoError(t, err)
+		require.NoError(t, testDB.Create(&message).Error)
+		messages[i] = message
 	}
+	return messages
+}
 
-	// Clean up test data before each test
-	cleanupTestData(t)
+// outboxMessage reloads the message with id
+func outboxMessage(t *testing.T, testDB *gorm.DB, id int64) OutboxMessage {
+	t.Helper()
+	var message OutboxMessage
+	require.NoError(t, testDB.First(&message, id).Error)
+	return message
+}
 
-	return db.GetDB()
+func TestRelayOutbox_Delivers(t *testing.T) {
+	testDB := setupTestDB(t)
+	queued := queueOutbox(t, testDB, 1, 2, 1)
+
+	var sent, committed []int64
+	delivered, err := RelayOutbox(10, OutboxHandler{
+		Send: func(message OutboxMessage) error {
+			assert.NotNil(t, message.ClaimedUntil, "messages are claimed before they are sent")
+			sent = append(sent, message.ID)
+			return nil
+		},
+		Delivered: func(message OutboxMessage) {
+			assert.NotNil(t, outboxMessage(t, testDB, message.ID).DeliveredAt, "delivery is recorded first")
+			committed = append(committed, message.ID)
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, delivered)
+	assert.Equal(t, []int64{queued[0].ID, queued[1].ID, queued[2].ID}, sent)
+	assert.Equal(t, sent, committed)
+	for _, message := range queued {
+		stored := outboxMessage(t, testDB, message.ID)
+		assert.NotNil(t, stored.DeliveredAt)
+		assert.Nil(t, stored.ClaimedUntil)
+	}
+
+	delivered, err = RelayOutbox(10, OutboxHandler{Send: func(OutboxMessage) error {
+		t.Fatal("delivered messages are not sent again")
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+}
+
+func TestRelayOutbox_SendFailure(t *testing.T) {
+	testDB := setupTestDB(t)
+	queued := queueOutbox(t, testDB, 1, 2)
+
+	failure := errors.New("broker unavailable")
+	delivered, err := RelayOutbox(10, OutboxHandler{
+		Send:      func(OutboxMessage) error { return failure },
+		Delivered: func(OutboxMessage) { t.Error("failed messages are not delivered") },
+	})
+	require.ErrorIs(t, err, failure)
+	assert.Zero(t, delivered)
+
+	first := outboxMessage(t, testDB, queued[0].ID)
+	assert.Equal(t, 1, first.Attempts)
+	assert.Equal(t, failure.Error(), first.LastError)
+	assert.True(t, first.NextAttemptAt.After(time.Now()), "the retry waits for the backoff")
+	assert.Nil(t, first.FailedAt)
+	assert.Nil(t, first.ClaimedUntil)
+	second := outboxMessage(t, testDB, queued[1].ID)
+	assert.Zero(t, second.Attempts, "later messages wait for the failed one")
+	assert.Nil(t, second.ClaimedUntil, "the claims of unsent messages are released")
+
+	delivered, err = RelayOutbox(10, OutboxHandler{Send: func(OutboxMessage) error {
+		t.Fatal("nothing is sent before the retry is due")
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	// Once the retry is due both are delivered in order
+	require.NoError(t, testDB.Model(&first).Update("next_attempt_at", time.Now().UTC()).Error)
+	var sent []int64
+	delivered, err = RelayOutbox(10, OutboxHandler{Send: func(message OutboxMessage) error {
+		sent = append(sent, message.ID)
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []int64{queued[0].ID, queued[1].ID}, sent)
 }
 
-// Helper function to clean up test data
-func cleanupTestData(t *testing.T) {
-	testDB := db.GetDB()
+func TestRelayOutbox_PoisonMessage(t *testing.T) {
+	testDB := setupTestDB(t)
+	queued := queueOutbox(t, testDB, 1, 2)
+	poison := queued[0].ID
 
-	// Delete test data in correct order to respect foreign key constraints
-	// 1. Delete registrations first (no dependencies)
-	err := testDB.Exec("DELETE FROM registrations WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'test%')").Error
-	if err != nil {
-		t.Logf("Warning: Failed to clean up registrations: %v", err)
+	failure := fmt.Errorf("%w: message too large", ErrUndeliverable)
+	send := func(message OutboxMessage) error {
+		if message.ID == poison {
+			return failure
+		}
+		return nil
 	}
+	delivered, err := RelayOutbox(10, OutboxHandler{Send: send})
+	require.ErrorIs(t, err, ErrUndeliverable)
+	assert.Zero(t, delivered)
+	stored := outboxMessage(t, testDB, poison)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.NotNil(t, stored.FailedAt, "a message that cannot be delivered is given up on at once")
+	assert.Nil(t, stored.DeliveredAt)
+
+	// The failed message no longer holds back the ones behind it
+	delivered, err = RelayOutbox(10, OutboxHandler{Send: send})
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.NotNil(t, outboxMessage(t, testDB, queued[1].ID).DeliveredAt)
+
+	// Once requeued, it is delivered again
+	requeued, err := RequeueFailedOutbox(queued[1].ID)
+	require.NoError(t, err)
+	assert.Zero(t, requeued, "only failed messages are requeued")
+	requeued, err = RequeueFailedOutbox()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+	stored = outboxMessage(t, testDB, poison)
+	assert.Nil(t, stored.FailedAt)
+	assert.Zero(t, stored.Attempts)
+
+	var sent []int64
+	delivered, err = RelayOutbox(10, OutboxHandler{Send: func(message OutboxMessage) error {
+		sent = append(sent, message.ID)
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []int64{poison}, sent)
+	assert.NotNil(t, outboxMessage(t, testDB, poison).DeliveredAt)
+}
+
+func TestRelayOutbox_RecoversAfterOutage(t *testing.T) {
+	testDB := setupTestDB(t)
+	queued := queueOutbox(t, testDB, 1, 2)
+
+	// Hours of a broker outage: the capped retries never give the message up
+	outage := errors.New("dial tcp kafka:9092: connect: connection refused")
+	for attempt := 1; attempt <= 100; attempt++ {
+		delivered, err := RelayOutbox(10, OutboxHandler{Send: func(OutboxMessage) error { return outage }})
+		require.ErrorIs(t, err, outage)
+		assert.Zero(t, delivered)
+		stored := outboxMessage(t, testDB, queued[0].ID)
+		require.Nil(t, stored.FailedAt, "attempt %d", attempt)
+		assert.False(t, stored.NextAttemptAt.After(time.Now().Add(OutboxBackoff(attempt))))
+		require.NoError(t, testDB.Model(&stored).Update("next_attempt_at", time.Now().UTC()).Error)
+	}
+	stored := outboxMessage(t, testDB, queued[0].ID)
+	assert.Equal(t, 100, stored.Attempts)
+	assert.Equal(t, 5*time.Minute, OutboxBackoff(stored.Attempts), "the backoff is capped")
+
+	// When the broker is back every change is delivered, in order
+	var sent []int64
+	delivered, err := RelayOutbox(10, OutboxHandler{Send: func(message OutboxMessage) error {
+		sent = append(sent, message.ID)
+		return nil
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, delivered)
+	assert.Equal(t, []int64{queued[0].ID, queued[1].ID}, sent)
+}
+
+func TestRelayOutbox_RecordsWithDelivery(t *testing.T) {
+	testDB := setupTestDB(t)
+	owner := saveUser(t, testDB, "webhook-owner@example.com")
+	saveWebhook(t, owner, true)
+	queued := queueOutbox(t, testDB, 1)
 
-	// 2. Delete events (depends on users)
-	err = testDB.Exec("DELETE FROM events WHERE user_id IN (SELECT id FROM users WHERE email LIKE 'test%')").Error
-	if err != nil {
-		t.Logf("Warning: Failed to clean up events: %v", err)
+	failure := errors.New("webhook lookup failed")
+	record := func(tx *gorm.DB, message OutboxMessage) error {
+		_, err := EnqueueWebhookDeliveries(tx, owner, message.Action, message.MessageID, message.EventID, []byte(message.Payload))
+		return errors.Join(err, failure)
 	}
+	delivered, err := RelayOutbox(10, OutboxHandler{Record: record})
+	require.ErrorIs(t, err, failure)
+	assert.Zero(t, delivered)
+	stored := outboxMessage(t, testDB, queued[0].ID)
+	assert.Nil(t, stored.DeliveredAt, "the delivery is rolled back with the record")
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Empty(t, webhookDeliveries(t, testDB, queued[0].MessageID), "the record is rolled back with the delivery")
+
+	failure = nil
+	require.NoError(t, testDB.Model(&stored).Update("next_attempt_at", time.Now().UTC()).Error)
+	delivered, err = RelayOutbox(10, OutboxHandler{Record: record})
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.NotNil(t, outboxMessage(t, testDB, queued[0].ID).DeliveredAt)
+	assert.Len(t, webhookDeliveries(t, testDB, queued[0].MessageID), 1)
+}
+
+// saveUser saves a user with email and returns its ID
+func saveUser(t *testing.T, testDB *gorm.DB, email string) int64 {
+	t.Helper()
+	user := User{Email: email, Password: "testpassword"}
+	require.NoError(t, user.Save(testDB))
+	return user.ID
+}
+
+// saveWebhook subscribes a webhook of userID to actions, or to every action
+func saveWebhook(t *testing.T, userID int64, active bool, actions ...string) Webhook {
+	t.Helper()
+	webhook := Webhook{UserID: userID, URL: "https://example.com/hooks", Actions: actions, Active: active}
+	require.NoError(t, webhook.Save())
+	return webhook
+}
+
+// webhookDeliveries loads the deliveries of the change with messageID
+func webhookDeliveries(t *testing.T, testDB *gorm.DB, messageID string) []WebhookDelivery {
+	t.Helper()
+	var deliveries []WebhookDelivery
+	require.NoError(t, testDB.Where("message_id = ?", messageID).Order("webhook_id").Find(&deliveries).Error)
+	return deliveries
+}
 
-	// 3. Delete users last (after events are deleted)
-	err = testDB.Exec("DELETE FROM users WHERE email LIKE 'test%'").Error
-	if err != nil {
-		t.Logf("Warning: Failed to clean up users: %v", err)
+func TestEnqueueWebhookDeliveries(t *testing.T) {
+	testDB := setupTestDB(t)
+	owner := saveUser(t, testDB, "webhook-owner@example.com")
+	other := saveUser(t, testDB, "webhook-other@example.com")
+	everything := saveWebhook(t, owner, true)
+	registrations := saveWebhook(t, owner, true, RegistrationActionCreated)
+	saveWebhook(t, owner, false)
+	saveWebhook(t, other, true)
+
+	// Only the owner's active webhooks subscribed to the action get the change
+	queued, err := EnqueueWebhookDeliveries(testDB, owner, EventActionCreated, "change-1", 5, []byte(`{"id":"change-1"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 1, queued)
+	deliveries := webhookDeliveries(t, testDB, "change-1")
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, everything.ID, deliveries[0].WebhookID)
+	assert.Equal(t, WebhookDeliveryPending, deliveries[0].Status)
+	assert.Equal(t, int64(5), deliveries[0].EventID)
+	assert.Equal(t, `{"id":"change-1"}`, deliveries[0].Payload)
+	assert.False(t, deliveries[0].NextAttemptAt.After(time.Now()), "new deliveries are due at once")
+
+	queued, err = EnqueueWebhookDeliveries(testDB, owner, RegistrationActionCreated, "change-2", 5, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, queued)
+	deliveries = webhookDeliveries(t, testDB, "change-2")
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, []int64{everything.ID, registrations.ID}, []int64{deliveries[0].WebhookID, deliveries[1].WebhookID})
+
+	// A change consumed again is not queued twice
+	queued, err = EnqueueWebhookDeliveries(testDB, owner, RegistrationActionCreated, "change-2", 5, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Zero(t, queued)
+	assert.Len(t, webhookDeliveries(t, testDB, "change-2"), 2)
+
+	queued, err = EnqueueWebhookDeliveries(testDB, other+1, EventActionCreated, "change-3", 6, []byte(`{}`))
+	require.NoError(t, err)
+	assert.Zero(t, queued, "users without webhooks get nothing")
+}
+
+func TestClaimWebhookDeliveries(t *testing.T) {
+	testDB := setupTestDB(t)
+	owner := saveUser(t, testDB, "webhook-owner@example.com")
+	webhook := saveWebhook(t, owner, true)
+	for _, id := range []string{"change-1", "change-2", "change-3"} {
+		_, err := EnqueueWebhookDeliveries(testDB, owner, EventActionUpdated, id, 5, []byte(`{}`))
+		require.NoError(t, err)
 	}
+	later := webhookDeliveries(t, testDB, "change-3")[0]
+	require.NoError(t, testDB.Model(&later).Update("next_attempt_at", time.Now().UTC().Add(time.Hour)).Error)
+
+	// Due deliveries are claimed oldest first, with their webhook
+	claimed, err := ClaimWebhookDeliveries(1, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "change-1", claimed[0].MessageID)
+	assert.Equal(t, webhook.URL, claimed[0].Webhook.URL)
+	assert.Equal(t, webhook.Secret, claimed[0].Webhook.Secret)
+	leased := webhookDeliveries(t, testDB, "change-1")[0]
+	assert.True(t, leased.NextAttemptAt.After(time.Now().Add(50*time.Second)), "the claim postpones the next attempt by the lease")
+
+	claimed, err = ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1, "claimed deliveries and those not due are skipped")
+	assert.Equal(t, "change-2", claimed[0].MessageID)
+
+	claimed, err = ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestWebhookDelivery_RecordAttempt(t *testing.T) {
+	testDB := setupTestDB(t)
+	owner := saveUser(t, testDB, "webhook-owner@example.com")
+	saveWebhook(t, owner, true)
+	for _, id := range []string{"change-1", "change-2"} {
+		_, err := EnqueueWebhookDeliveries(testDB, owner, EventActionUpdated, id, 5, []byte(`{}`))
+		require.NoError(t, err)
+	}
+	claimed, err := ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+
+	// A failed attempt is retried after the backoff
+	failure := errors.New("unexpected status 503 Service Unavailable")
+	failing := &claimed[0]
+	require.NoError(t, failing.RecordAttempt(503, failure, 2))
+	stored := webhookDeliveries(t, testDB, "change-1")[0]
+	assert.Equal(t, WebhookDeliveryPending, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, 503, stored.LastStatusCode)
+	assert.Equal(t, failure.Error(), stored.LastError)
+	assert.WithinDuration(t, time.Now().Add(WebhookBackoff(1)), stored.NextAttemptAt, 5*time.Second)
+	assert.Nil(t, stored.DeliveredAt)
+
+	retry, err := ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, retry, "the retry waits for the backoff")
+	require.NoError(t, testDB.Model(&stored).Update("next_attempt_at", time.Now().UTC()).Error)
+	retry, err = ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, retry, 1)
+	assert.Equal(t, 1, retry[0].Attempts)
+
+	// The last attempt failing gives the delivery up
+	require.NoError(t, retry[0].RecordAttempt(0, errors.New("connection refused"), 2))
+	stored = webhookDeliveries(t, testDB, "change-1")[0]
+	assert.Equal(t, WebhookDeliveryFailed, stored.Status)
+	assert.Equal(t, 2, stored.Attempts)
+	assert.Zero(t, stored.LastStatusCode)
+	assert.Equal(t, "connection refused", stored.LastError)
+	require.NoError(t, testDB.Model(&stored).Update("next_attempt_at", time.Now().UTC()).Error)
+	retry, err = ClaimWebhookDeliveries(10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, retry, "failed deliveries are not claimed again")
+
+	// A successful attempt clears the error
+	require.NoError(t, claimed[1].RecordAttempt(204, nil, 2))
+	stored = webhookDeliveries(t, testDB, "change-2")[0]
+	assert.Equal(t, WebhookDeliverySucceeded, stored.Status)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Equal(t, 204, stored.LastStatusCode)
+	assert.Empty(t, stored.LastError)
+	assert.NotNil(t, stored.DeliveredAt)
+}
+
+func TestRefreshSession_RotationAndReuse(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	user := User{Email: "test-refresh@example.com", Password: "testpassword"}
+	require.NoError(t, user.Save(testDB))
+
+	login, err := StartSession(&user)
+	require.NoError(t, err)
+	claims, err := ValidateAccessToken(login.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+
+	// Refreshing rotates the refresh token
+	refreshed, err := RefreshSession(login.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+	_, err = ValidateAccessToken(refreshed.AccessToken)
+	require.NoError(t, err)
+
+	// Reusing the old refresh token revokes the whole session
+	_, err = RefreshSession(login.RefreshToken)
+	assert.ErrorIs(t, err, ErrRefreshTokenReused)
+	_, err = RefreshSession(refreshed.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = ValidateAccessToken(login.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = ValidateAccessToken(refreshed.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+
+	_, err = RefreshSession("unknown")
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+	_, err = ValidateAccessToken("not a token")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestEndSession(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	user := User{Email: "test-logout@example.com", Password: "testpassword"}
+	require.NoError(t, user.Save(testDB))
+
+	session, err := StartSession(&user)
+	require.NoError(t, err)
+	claims, err := ValidateAccessToken(session.AccessToken)
+	require.NoError(t, err)
+
+	require.NoError(t, EndSession(claims))
+	_, err = ValidateAccessToken(session.AccessToken)
+	assert.ErrorIs(t, err, ErrTokenRevoked)
+	_, err = RefreshSession(session.RefreshToken)
+	assert.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestUserRoles(t *testing.T) {
+	testDB := setupTestDB(t)
+
+	user := User{Email: "test-roles@example.com", Password: "testpassword"}
+	require.NoError(t, user.Save(testDB))
+	assert.Equal(t, policy.RoleUser, user.Role)
+
+	updated, err := SetUserRole(testDB, user.ID, policy.RoleOrganizer)
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleOrganizer, updated.Role)
+	assert.Empty(t, updated.Password)
+
+	_, err = SetUserRole(testDB, user.ID, "superuser")
+	assert.ErrorIs(t, err, ErrInvalidRole)
+	_, err = SetUserRole(testDB, -1, policy.RoleAdmin)
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	promoted, err := PromoteAdmins(testDB, []string{"TEST-Roles@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), promoted)
+
+	// The role is carried by the access token
+	user.Role = policy.RoleAdmin
+	session, err := StartSession(&user)
+	require.NoError(t, err)
+	claims, err := ValidateAccessToken(session.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleAdmin, claims.Role)
 }
@@ -0,0 +1,329 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/tracing"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Actions published to the events topic when an event changes
+const (
+	EventActionCreated   = "created"
+	EventActionUpdated   = "updated"
+	EventActionDeleted   = "deleted"
+	EventActionPublished = "published"
+	EventActionCancelled = "cancelled"
+	EventActionCompleted = "completed"
+)
+
+// EventActions are the actions of all event changes
+var EventActions = []string{
+	EventActionCreated,
+	EventActionUpdated,
+	EventActionDeleted,
+	EventActionPublished,
+	EventActionCancelled,
+	EventActionCompleted,
+}
+
+// Actions published to the events topic when a registration changes
+const (
+	RegistrationActionCreated   = "registration_created"
+	RegistrationActionCancelled = "registration_cancelled"
+	// RegistrationActionPromoted is published when a waitlisted registration gets a seat
+	RegistrationActionPromoted = "registration_promoted"
+	// RegistrationActionRemoved is published when the owner of an event removes an attendee
+	RegistrationActionRemoved = "registration_removed"
+)
+
+// RegistrationActions are the actions of all registration changes
+var RegistrationActions = []string{
+	RegistrationActionCreated,
+	RegistrationActionCancelled,
+	RegistrationActionPromoted,
+	RegistrationActionRemoved,
+}
+
+// Retry delays for outbox messages that could not be delivered
+const (
+	outboxBaseBackoff = time.Second
+	outboxMaxBackoff  = 5 * time.Minute
+)
+
+// ErrUndeliverable marks a delivery error that retrying cannot fix, such as a
+// message that cannot be encoded. Such a message is marked failed at once;
+// any other error is retried, however long it takes.
+var ErrUndeliverable = errors.New("outbox message cannot be delivered")
+
+// outboxClaimLease is how long a relay holds the messages it claimed. A relay
+// that stops before marking them lets another take them over after it.
+const outboxClaimLease = time.Minute
+
+// OutboxMessage is a change waiting to be published to Kafka. It is written in
+// the same transaction as the change itself, so a committed change is
+// published eventually unless it keeps failing, and a rolled back one never is.
+type OutboxMessage struct {
+	ID            int64  `gorm:"primaryKey;autoIncrement"`
+	Action        string `gorm:"not null"`
+	EventID       int64  `gorm:"not null"`
+	Payload       string `gorm:"type:text;not null"` // the event as JSON
+	Registration  string `gorm:"type:text"`          // the registration as JSON, for registration changes
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string
+	MessageID     string    // unique ID published with the message, kept on redelivery
+	TraceID       string    // W3C trace ID of the request that made the change
+	NextAttemptAt time.Time `gorm:"not null"`
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	ClaimedUntil  *time.Time // a relay is sending the message until then
+	FailedAt      *time.Time // set when the message was given up on
+}
+
+// NewEventMessage returns the outbox message recording that action happened
+// to event, in the trace carried by ctx or a new one
+func NewEventMessage(ctx context.Context, action string, event Event) (OutboxMessage, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	return OutboxMessage{
+		MessageID:     rand.Text(),
+		TraceID:       tracing.IDOrNew(ctx),
+		Action:        action,
+		EventID:       event.ID,
+		Payload:       string(payload),
+		NextAttemptAt: time.Now().UTC(),
+	}, nil
+}
+
+// NewRegistrationMessage returns the outbox message recording that action
+// happened to registration of event, in the trace carried by ctx or a new one
+func NewRegistrationMessage(ctx context.Context, action string, event Event, registration Registration) (OutboxMessage, error) {
+	message, err := NewEventMessage(ctx, action, event)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	registrationPayload, err := json.Marshal(registration)
+	if err != nil {
+		return OutboxMessage{}, err
+	}
+	message.Registration = string(registrationPayload)
+	return message, nil
+}
+
+// enqueueEvent records that action happened to event, as part of tx. The
+// message is in the trace carried by the context of tx.
+func enqueueEvent(tx *gorm.DB, action string, event Event) error {
+	message, err := NewEventMessage(tx.Statement.Context, action, event)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&message).Error
+}
+
+// enqueueRegistration records that action happened to registration, as part
+// of tx. The message carries the registration's event as it is within tx.
+func enqueueRegistration(tx *gorm.DB, action string, registration Registration) error {
+	var event Event
+	if err := tx.First(&event, registration.EventID).Error; err != nil {
+		return err
+	}
+	message, err := NewRegistrationMessage(tx.Statement.Context, action, event, registration)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&message).Error
+}
+
+// enqueueEventByID reloads an event within tx and records that action happened to it
+func enqueueEventByID(tx *gorm.DB, action string, id int64) error {
+	var event Event
+	if err := tx.First(&event, id).Error; err != nil {
+		return err
+	}
+	return enqueueEvent(tx, action, event)
+}
+
+// OutboxBackoff returns how long to wait before the next delivery attempt
+// after attempts failed ones. The delay doubles with each failure up to five minutes.
+func OutboxBackoff(attempts int) time.Duration {
+	return exponentialBackoff(outboxBaseBackoff, outboxMaxBackoff, attempts)
+}
+
+// exponentialBackoff returns base after the first failed attempt, doubling
+// with each further failure up to limit
+func exponentialBackoff(base, limit time.Duration, attempts int) time.Duration {
+	backoff := base
+	for i := 1; i < attempts && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+// OutboxHandler delivers the messages relayed by RelayOutbox
+type OutboxHandler struct {
+	// Send, when set, publishes a message. It runs outside any transaction,
+	// and a message whose delivery could not be recorded is sent again.
+	Send func(OutboxMessage) error
+	// Record, when set, runs in the transaction marking a message delivered,
+	// for changes that must commit or roll back together with it. A failure
+	// counts as a failed delivery.
+	Record func(tx *gorm.DB, message OutboxMessage) error
+	// Delivered, when set, is called once the delivery of a message is
+	// committed, for side effects that must not happen for a message that
+	// is still pending or sent again
+	Delivered func(OutboxMessage)
+}
+
+// RelayOutbox hands up to limit undelivered messages, oldest first, to the
+// handler and marks each delivered once it was sent. The messages are claimed in a
+// short transaction first, so no row stays locked while send waits on the
+// network and concurrent relays never hold the same message. Delivery stops
+// at the first failure so the topic keeps the order of the changes; the
+// failed message is retried after OutboxBackoff, for as long as the failure
+// lasts. Only a message failing with ErrUndeliverable is marked failed and
+// skipped, so it no longer holds back the messages behind it.
+func RelayOutbox(limit int, handler OutboxHandler) (delivered int, err error) {
+	gormDB := db.GetDB()
+	messages, err := claimOutbox(gormDB, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, message := range messages {
+		if handler.Send != nil {
+			if sendErr := handler.Send(message); sendErr != nil {
+				err = failOutbox(gormDB, message, sendErr)
+				return delivered, errors.Join(err, releaseOutbox(gormDB, messages[i+1:]))
+			}
+		}
+		var recordErr error
+		err := gormDB.Transaction(func(tx *gorm.DB) error {
+			err := tx.Model(&OutboxMessage{}).Where("id = ?", message.ID).Updates(map[string]any{
+				"delivered_at":  time.Now().UTC(),
+				"claimed_until": nil,
+			}).Error
+			if err != nil || handler.Record == nil {
+				return err
+			}
+			recordErr = handler.Record(tx, message)
+			return recordErr
+		})
+		if recordErr != nil {
+			err = failOutbox(gormDB, message, recordErr)
+			return delivered, errors.Join(err, releaseOutbox(gormDB, messages[i+1:]))
+		}
+		if err != nil {
+			return delivered, errors.Join(err, releaseOutbox(gormDB, messages[i+1:]))
+		}
+		if handler.Delivered != nil {
+			handler.Delivered(message)
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// claimOutbox claims up to limit undelivered messages, oldest first, for
+// outboxClaimLease. A message waiting for its retry, or claimed by another
+// relay, holds back the messages after it to keep the order.
+func claimOutbox(gormDB *gorm.DB, limit int) ([]OutboxMessage, error) {
+	var claimed []OutboxMessage
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		var messages []OutboxMessage
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("delivered_at IS NULL AND failed_at IS NULL").
+			Order("id").
+			Limit(limit).
+			Find(&messages).Error
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		until := now.Add(outboxClaimLease)
+		var ids []int64
+		for _, message := range messages {
+			if message.NextAttemptAt.After(now) || (message.ClaimedUntil != nil && message.ClaimedUntil.After(now)) {
+				break
+			}
+			message.ClaimedUntil = &until
+			claimed = append(claimed, message)
+			ids = append(ids, message.ID)
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&OutboxMessage{}).Where("id IN ?", ids).Update("claimed_until", until).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// failOutbox records a failed delivery of message and schedules its retry,
+// or marks it failed when cause is ErrUndeliverable. It returns cause,
+// saying so when the message was given up on.
+func failOutbox(gormDB *gorm.DB, message OutboxMessage, cause error) error {
+	now := time.Now().UTC()
+	attempts := message.Attempts + 1
+	updates := map[string]any{
+		"attempts":        attempts,
+		"last_error":      cause.Error(),
+		"next_attempt_at": now.Add(OutboxBackoff(attempts)),
+		"claimed_until":   nil,
+	}
+	if errors.Is(cause, ErrUndeliverable) {
+		updates["failed_at"] = now
+		cause = fmt.Errorf("giving up on outbox message %d: %w", message.ID, cause)
+	}
+	if err := gormDB.Model(&OutboxMessage{}).Where("id = ?", message.ID).Updates(updates).Error; err != nil {
+		return errors.Join(cause, err)
+	}
+	return cause
+}
+
+// releaseOutbox gives up the claims on messages that were not attempted
+func releaseOutbox(gormDB *gorm.DB, messages []OutboxMessage) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	ids := make([]int64, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+	}
+	return gormDB.Model(&OutboxMessage{}).Where("id IN ?", ids).Update("claimed_until", nil).Error
+}
+
+// RequeueFailedOutbox queues the failed messages with the given IDs, or every
+// failed message when none are given, to be delivered again at once. They
+// are published after the messages that were delivered while they waited.
+func RequeueFailedOutbox(ids ...int64) (int64, error) {
+	query := db.GetDB().Model(&OutboxMessage{}).Where("failed_at IS NOT NULL AND delivered_at IS NULL")
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Updates(map[string]any{
+		"failed_at":       nil,
+		"claimed_until":   nil,
+		"attempts":        0,
+		"next_attempt_at": time.Now().UTC(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeDeliveredOutbox deletes messages delivered before the given time
+func PurgeDeliveredOutbox(before time.Time) (int64, error) {
+	gormDB := db.GetDB()
+	result := gormDB.Where("delivered_at < ?", before).Delete(&OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,162 @@
+package models
+
+import (
+	"time"
+
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ToProto converts the event to its protobuf message, as returned by the gRPC
+// API and published to Kafka
+func (e Event) ToProto() *eventpb.Event {
+	protoEvent := &eventpb.Event{
+		Id:           e.ID,
+		Name:         e.Name,
+		Description:  e.Description,
+		Location:     e.Location,
+		DateTime:     timestamppb.New(e.DateTime),
+		UserId:       e.UserID,
+		Capacity:     int32(e.Capacity),
+		Rrule:        e.RRule,
+		Status:       e.Status,
+		CancelReason: e.CancelReason,
+		PublishedAt:  protoTimestamp(e.PublishedAt),
+		CancelledAt:  protoTimestamp(e.CancelledAt),
+		CompletedAt:  protoTimestamp(e.CompletedAt),
+	}
+	for _, exdate := range e.ExDates {
+		protoEvent.Exdates = append(protoEvent.Exdates, timestamppb.New(exdate))
+	}
+	if e.OccurrenceStart != nil {
+		protoEvent.OccurrenceStart = timestamppb.New(*e.OccurrenceStart)
+	}
+	if e.Latitude != nil && e.Longitude != nil {
+		protoEvent.Latitude = e.Latitude
+		protoEvent.Longitude = e.Longitude
+	}
+	if e.Address != (Address{}) {
+		protoEvent.Address = &eventpb.Address{
+			Street:     e.Address.Street,
+			City:       e.Address.City,
+			Region:     e.Address.Region,
+			PostalCode: e.Address.PostalCode,
+			Country:    e.Address.Country,
+		}
+	}
+	return protoEvent
+}
+
+// protoTimestamp converts an optional time to a protobuf timestamp
+func protoTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+// EventFromProto converts a protobuf event, such as the event of a consumed
+// Kafka envelope, back to the model
+func EventFromProto(p *eventpb.Event) Event {
+	event := Event{
+		ID:           p.GetId(),
+		Name:         p.GetName(),
+		Description:  p.GetDescription(),
+		Location:     p.GetLocation(),
+		UserID:       p.GetUserId(),
+		Capacity:     int(p.GetCapacity()),
+		RRule:        p.GetRrule(),
+		Latitude:     p.Latitude,
+		Longitude:    p.Longitude,
+		Status:       p.GetStatus(),
+		CancelReason: p.GetCancelReason(),
+		PublishedAt:  modelTime(p.GetPublishedAt()),
+		CancelledAt:  modelTime(p.GetCancelledAt()),
+		CompletedAt:  modelTime(p.GetCompletedAt()),
+	}
+	if p.GetDateTime() != nil {
+		event.DateTime = p.GetDateTime().AsTime()
+	}
+	for _, exdate := range p.GetExdates() {
+		event.ExDates = append(event.ExDates, exdate.AsTime())
+	}
+	event.OccurrenceStart = modelTime(p.GetOccurrenceStart())
+	if address := p.GetAddress(); address != nil {
+		event.Address = Address{
+			Street:     address.GetStreet(),
+			City:       address.GetCity(),
+			Region:     address.GetRegion(),
+			PostalCode: address.GetPostalCode(),
+			Country:    address.GetCountry(),
+		}
+	}
+	return event
+}
+
+// ToProto converts the registration to its protobuf message, as published to Kafka
+func (r Registration) ToProto() *eventpb.Registration {
+	registration := &eventpb.Registration{
+		Id:              r.ID,
+		UserId:          r.UserID,
+		EventId:         r.EventID,
+		Status:          r.Status,
+		OccurrenceStart: protoTimestamp(r.OccurrenceStart),
+		Scope:           r.Scope,
+		CreatedAt:       timestamppb.New(r.CreatedAt),
+	}
+	if !r.UpdatedAt.IsZero() {
+		registration.UpdatedAt = timestamppb.New(r.UpdatedAt)
+	}
+	for _, change := range r.History {
+		registration.History = append(registration.History, &eventpb.RegistrationChange{
+			Status:    change.Status,
+			ChangedAt: timestamppb.New(change.ChangedAt),
+		})
+	}
+	return registration
+}
+
+// ToProto converts the attendee to its protobuf message
+func (a Attendee) ToProto() *eventpb.Attendee {
+	attendee := &eventpb.Attendee{
+		RegistrationId:  a.RegistrationID,
+		UserId:          a.UserID,
+		Email:           a.Email,
+		Status:          a.Status,
+		OccurrenceStart: protoTimestamp(a.OccurrenceStart),
+		Scope:           a.Scope,
+		RegisteredAt:    timestamppb.New(a.RegisteredAt),
+	}
+	if !a.UpdatedAt.IsZero() {
+		attendee.UpdatedAt = timestamppb.New(a.UpdatedAt)
+	}
+	return attendee
+}
+
+// RegistrationFromProto converts a protobuf registration back to the model
+func RegistrationFromProto(p *eventpb.Registration) Registration {
+	registration := Registration{
+		ID:              p.GetId(),
+		UserID:          p.GetUserId(),
+		EventID:         p.GetEventId(),
+		Status:          p.GetStatus(),
+		OccurrenceStart: modelTime(p.GetOccurrenceStart()),
+		Scope:           p.GetScope(),
+	}
+	if p.GetCreatedAt() != nil {
+		registration.CreatedAt = p.GetCreatedAt().AsTime()
+	}
+	if p.GetUpdatedAt() != nil {
+		registration.UpdatedAt = p.GetUpdatedAt().AsTime()
+	}
+	return registration
+}
+
+// modelTime converts an optional protobuf timestamp to a time
+func modelTime(t *timestamppb.Timestamp) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := t.AsTime()
+	return &converted
+}
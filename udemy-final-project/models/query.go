@@ -0,0 +1,308 @@
+package models
+
+import (
+	"cmp"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sort orders for event listings
+const (
+	SortByDateTime = "date_time"
+	SortByName     = "name"
+)
+
+// Page sizes for event listings
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// Errors returned for invalid listing queries
+var (
+	ErrInvalidPageToken = errors.New("invalid page token")
+	ErrInvalidSort      = errors.New("sort must be date_time or name")
+	ErrInvalidPageSize  = errors.New("page size must not be negative")
+)
+
+// EventQuery selects, orders and pages through events. When From and To are
+// both set, recurring events are expanded into their occurrences within
+// [From, To) and the filters apply to each occurrence.
+type EventQuery struct {
+	From       time.Time
+	To         time.Time
+	Location   string // case-insensitive substring match
+	UserID     int64  // owner; 0 matches every owner
+	Status     string // lifecycle status; empty matches every status the viewer can see
+	ViewerID   int64  // user the listing is for; drafts are only listed for their owner
+	SortBy     string // SortByDateTime (default) or SortByName
+	Descending bool
+	PageSize   int // defaults to DefaultPageSize, capped at MaxPageSize
+	PageToken  string
+}
+
+// EventPage is a single page of an event listing
+type EventPage struct {
+	Events []Event `json:"events"`
+	// NextPageToken fetches the following page with the same query; empty on the last page
+	NextPageToken string `json:"next_page_token,omitempty" example:"eyJzIjoiZGF0ZV90aW1lIi..."`
+}
+
+// pageCursor is the position after the last event of a page. It is encoded
+// into the opaque page token and carries the sort order it was issued for.
+type pageCursor struct {
+	SortBy     string     `json:"s"`
+	Descending bool       `json:"d,omitempty"`
+	Name       string     `json:"n,omitempty"`
+	DateTime   time.Time  `json:"t"`
+	ID         int64      `json:"i"`
+	Occurrence *time.Time `json:"o,omitempty"`
+}
+
+func (c pageCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodePageCursor(token string) (*pageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &cursor, nil
+}
+
+// Windowed reports whether the query expands recurring events into occurrences
+func (q EventQuery) Windowed() bool {
+	return !q.From.IsZero() || !q.To.IsZero()
+}
+
+// normalize validates the query, applies defaults and decodes the page token
+func (q *EventQuery) normalize() (*pageCursor, error) {
+	if q.Windowed() && (!q.To.After(q.From) || q.To.Sub(q.From) > MaxOccurrenceWindow) {
+		return nil, ErrInvalidWindow
+	}
+	if q.Status != "" {
+		if err := ValidateEventStatus(q.Status); err != nil {
+			return nil, err
+		}
+	}
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortByDateTime
+	case SortByDateTime, SortByName:
+	default:
+		return nil, ErrInvalidSort
+	}
+	switch {
+	case q.PageSize < 0:
+		return nil, ErrInvalidPageSize
+	case q.PageSize == 0:
+		q.PageSize = DefaultPageSize
+	case q.PageSize > MaxPageSize:
+		q.PageSize = MaxPageSize
+	}
+
+	if q.PageToken == "" {
+		return nil, nil
+	}
+	cursor, err := decodePageCursor(q.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.SortBy != q.SortBy || cursor.Descending != q.Descending {
+		return nil, ErrInvalidPageToken
+	}
+	return cursor, nil
+}
+
+// cursorFor returns the cursor positioned at e
+func (q EventQuery) cursorFor(e Event) pageCursor {
+	cursor := pageCursor{SortBy: q.SortBy, Descending: q.Descending, DateTime: e.DateTime, ID: e.ID, Occurrence: e.OccurrenceStart}
+	if q.SortBy == SortByName {
+		cursor.Name = e.Name
+		cursor.DateTime = time.Time{}
+	}
+	return cursor
+}
+
+// compare orders two cursors by the sort key, then by ID and occurrence so
+// that the order is total
+func (q EventQuery) compare(a, b pageCursor) int {
+	c := 0
+	if q.SortBy == SortByName {
+		c = strings.Compare(a.Name, b.Name)
+	} else {
+		c = a.DateTime.Compare(b.DateTime)
+	}
+	if c == 0 {
+		c = cmp.Compare(a.ID, b.ID)
+	}
+	if c == 0 {
+		c = occurrenceTime(a.Occurrence).Compare(occurrenceTime(b.Occurrence))
+	}
+	if q.Descending {
+		return -c
+	}
+	return c
+}
+
+func occurrenceTime(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+// filter restricts tx to the events the query selects by owner, status and visibility
+func (q EventQuery) filter(tx *gorm.DB) *gorm.DB {
+	tx = visibleTo(tx, q.ViewerID)
+	if q.UserID != 0 {
+		tx = tx.Where("user_id = ?", q.UserID)
+	}
+	if q.Status != "" {
+		tx = tx.Where("status = ?", q.Status)
+	}
+	return tx
+}
+
+// matches reports whether the query's owner, status and visibility filters
+// select e, as filter does
+func (q EventQuery) matches(e Event) bool {
+	return e.VisibleTo(q.ViewerID) &&
+		(q.UserID == 0 || e.UserID == q.UserID) &&
+		(q.Status == "" || e.Status == q.Status)
+}
+
+// ListEvents returns a page of events matching the query
+func ListEvents(gormDB *gorm.DB, query EventQuery) (*EventPage, error) {
+	cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	if query.Windowed() {
+		return listOccurrences(gormDB, query, cursor)
+	}
+
+	tx := query.filter(gormDB.Model(&Event{}))
+	if query.Location != "" {
+		tx = tx.Where("LOWER(location) LIKE ? ESCAPE '\\'", "%"+escapeLike(strings.ToLower(query.Location))+"%")
+	}
+
+	column, direction, op := "date_time", "ASC", ">"
+	if query.SortBy == SortByName {
+		column = "name"
+	}
+	if query.Descending {
+		direction, op = "DESC", "<"
+	}
+	if cursor != nil {
+		var value any = cursor.DateTime
+		if query.SortBy == SortByName {
+			value = cursor.Name
+		}
+		tx = tx.Where("("+column+" "+op+" ? OR ("+column+" = ? AND id "+op+" ?))", value, value, cursor.ID)
+	}
+
+	var events []Event
+	err = tx.Order(column + " " + direction).Order("id " + direction).
+		Limit(query.PageSize + 1).
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return query.page(events), nil
+}
+
+// listOccurrences pages through the occurrences within the query window.
+// Occurrences only exist after expansion, so filtering past the owner and
+// status and ordering happen in memory; the window is bounded by MaxOccurrenceWindow.
+func listOccurrences(gormDB *gorm.DB, query EventQuery, cursor *pageCursor) (*EventPage, error) {
+	tx := query.filter(occurrenceCandidates(gormDB, query.From, query.To))
+	var events []Event
+	if err := tx.Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	occurrences, err := expandOccurrences(gormDB, events, query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	return pageOccurrences(occurrences, query, cursor), nil
+}
+
+// ListEventsIn is ListEvents over events held in memory, with overrides
+// holding the stored occurrence overrides
+func ListEventsIn(events []Event, overrides []OccurrenceOverride, query EventQuery) (*EventPage, error) {
+	cursor, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	var matching []Event
+	for _, e := range events {
+		if !query.matches(e) {
+			continue
+		}
+		if !query.Windowed() {
+			matching = append(matching, e)
+			continue
+		}
+		occurrences, err := e.Occurrences(query.From, query.To, overrides)
+		if err != nil {
+			return nil, err
+		}
+		matching = append(matching, occurrences...)
+	}
+	return pageOccurrences(matching, query, cursor), nil
+}
+
+// pageOccurrences filters, sorts and slices expanded occurrences, or events
+// listed in memory
+func pageOccurrences(occurrences []Event, query EventQuery, cursor *pageCursor) *EventPage {
+	location := strings.ToLower(query.Location)
+	var matching []Event
+	for _, o := range occurrences {
+		if location != "" && !strings.Contains(strings.ToLower(o.Location), location) {
+			continue
+		}
+		if cursor != nil && query.compare(query.cursorFor(o), *cursor) <= 0 {
+			continue
+		}
+		matching = append(matching, o)
+	}
+	sort.SliceStable(matching, func(i, j int) bool {
+		return query.compare(query.cursorFor(matching[i]), query.cursorFor(matching[j])) < 0
+	})
+	if len(matching) > query.PageSize+1 {
+		matching = matching[:query.PageSize+1]
+	}
+	return query.page(matching)
+}
+
+// page trims a result fetched with one extra row and sets the next page token
+// when that extra row shows there is more to come
+func (q EventQuery) page(events []Event) *EventPage {
+	page := &EventPage{Events: events}
+	if page.Events == nil {
+		page.Events = []Event{}
+	}
+	if len(events) > q.PageSize {
+		page.Events = events[:q.PageSize]
+		page.NextPageToken = q.cursorFor(page.Events[q.PageSize-1]).encode()
+	}
+	return page
+}
+
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
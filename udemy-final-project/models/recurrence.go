@@ -0,0 +1,565 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+	"gorm.io/gorm"
+)
+
+// Scopes selecting which part of a recurring series an operation applies to
+const (
+	ScopeOccurrence = "occurrence"
+	ScopeFollowing  = "following"
+	ScopeSeries     = "series"
+)
+
+// MaxOccurrenceWindow bounds how far recurring events are expanded in a single read
+const MaxOccurrenceWindow = 366 * 24 * time.Hour
+
+var (
+	// ErrOccurrenceNotFound is returned when a timestamp is not an occurrence of the series
+	ErrOccurrenceNotFound = errors.New("occurrence not found in event series")
+	// ErrNotRecurring is returned when an occurrence is selected on a non-recurring event
+	ErrNotRecurring = errors.New("event is not recurring")
+	// ErrInvalidWindow is returned when an expansion window is empty or too large
+	ErrInvalidWindow = errors.New("to must be after from and the window must not exceed 366 days")
+	// ErrInvalidScope is returned for an unknown or incomplete occurrence scope
+	ErrInvalidScope = errors.New("scope must be one of occurrence, following or series, and occurrence/following require an occurrence start")
+)
+
+// OccurrenceTarget selects a single occurrence, an occurrence and all that
+// follow it, or (when Start is nil) the whole series
+type OccurrenceTarget struct {
+	Start *time.Time
+	Scope string
+}
+
+// OccurrenceOverride stores edits made to a single occurrence of a recurring event
+type OccurrenceOverride struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	EventID         int64     `gorm:"not null"`
+	OccurrenceStart time.Time `gorm:"not null"`
+	Name            string    `gorm:"not null"`
+	Description     string    `gorm:"not null"`
+	Location        string    `gorm:"not null"`
+	DateTime        time.Time `gorm:"not null"`
+}
+
+// NewOccurrenceTarget validates a scope/start pair received from a client.
+// An empty scope defaults to the single occurrence when a start is given and
+// to the whole series otherwise.
+func NewOccurrenceTarget(start *time.Time, scope string) (OccurrenceTarget, error) {
+	target := OccurrenceTarget{Start: start, Scope: scope}
+	switch scope {
+	case "":
+		target.Scope = target.scope()
+	case ScopeOccurrence, ScopeFollowing:
+		if start == nil {
+			return target, ErrInvalidScope
+		}
+	case ScopeSeries:
+		target.Start = nil
+	default:
+		return target, ErrInvalidScope
+	}
+	return target, nil
+}
+
+func (t OccurrenceTarget) scope() string {
+	switch {
+	case t.Start == nil:
+		return ScopeSeries
+	case t.Scope == "":
+		return ScopeOccurrence
+	default:
+		return t.Scope
+	}
+}
+
+// IsSeries reports whether the target selects the whole series
+func (t OccurrenceTarget) IsSeries() bool {
+	return t.Start == nil
+}
+
+// ValidateRecurrence checks that an RRULE value is well formed
+func ValidateRecurrence(rrule string) error {
+	if rrule == "" {
+		return nil
+	}
+	_, err := recurrence.Parse(rrule)
+	return err
+}
+
+// IsRecurring reports whether the event has a recurrence rule
+func (e *Event) IsRecurring() bool {
+	return e.RRule != ""
+}
+
+// NormalizeRecurrence rewrites the recurrence rule in its canonical form and
+// drops the excluded dates of events that do not recur
+func (e *Event) NormalizeRecurrence() error {
+	if !e.IsRecurring() {
+		e.ExDates = nil
+		return nil
+	}
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return err
+	}
+	e.RRule = rule.String()
+	return nil
+}
+
+// OccurrenceIndex returns the position of start within the series, or
+// ErrOccurrenceNotFound if it is not a (non-excluded) occurrence
+func (e *Event) OccurrenceIndex(start time.Time) (int, error) {
+	if !e.IsRecurring() {
+		return 0, ErrNotRecurring
+	}
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return 0, err
+	}
+	index := rule.Index(e.DateTime, start)
+	if index < 0 {
+		return 0, ErrOccurrenceNotFound
+	}
+	for _, ex := range e.ExDates {
+		if ex.Equal(start) {
+			return 0, ErrOccurrenceNotFound
+		}
+	}
+	return index, nil
+}
+
+// ValidateTarget checks that a target selecting occurrences names an
+// occurrence of the series
+func (e *Event) ValidateTarget(target OccurrenceTarget) error {
+	if target.IsSeries() {
+		return nil
+	}
+	_, err := e.OccurrenceIndex(*target.Start)
+	return err
+}
+
+// Occurrences expands the event into the occurrences starting within
+// [from, to), applying any per-occurrence overrides. A non-recurring event
+// yields itself when its start falls inside the window.
+func (e Event) Occurrences(from, to time.Time, overrides []OccurrenceOverride) ([]Event, error) {
+	if !e.IsRecurring() {
+		if e.DateTime.Before(from) || !e.DateTime.Before(to) {
+			return nil, nil
+		}
+		return []Event{e}, nil
+	}
+
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return nil, err
+	}
+	var occurrences []Event
+	for _, start := range rule.Between(e.DateTime, from, to, e.ExDates) {
+		occurrences = append(occurrences, e.Occurrence(start, overrides))
+	}
+	return occurrences, nil
+}
+
+// Occurrence returns the occurrence of the series starting at start, with
+// its override applied if overrides has one
+func (e Event) Occurrence(start time.Time, overrides []OccurrenceOverride) Event {
+	occurrence := e
+	occurrence.ExDates = nil
+	occurrence.OccurrenceStart = &start
+	occurrence.DateTime = start
+	for _, override := range overrides {
+		if override.EventID == e.ID && override.OccurrenceStart.Equal(start) {
+			occurrence.Name = override.Name
+			occurrence.Description = override.Description
+			occurrence.Location = override.Location
+			occurrence.DateTime = override.DateTime
+		}
+	}
+	return occurrence
+}
+
+// NewOverride returns the override storing changes to the occurrence of the
+// series starting at start
+func (e Event) NewOverride(start time.Time, changes Event) OccurrenceOverride {
+	return OccurrenceOverride{
+		EventID:         e.ID,
+		OccurrenceStart: start,
+		Name:            changes.Name,
+		Description:     changes.Description,
+		Location:        changes.Location,
+		DateTime:        changes.DateTime,
+	}
+}
+
+// occurrenceCandidates restricts tx to the events that may have an
+// occurrence starting within [from, to)
+func occurrenceCandidates(tx *gorm.DB, from, to time.Time) *gorm.DB {
+	return tx.Where("((rrule IS NULL OR rrule = '') AND date_time >= ? AND date_time < ?) OR (rrule <> '' AND date_time < ?)",
+		from, to, to)
+}
+
+// expandOccurrences expands events into their occurrences starting within
+// [from, to), applying stored overrides, ordered by start time
+func expandOccurrences(gormDB *gorm.DB, events []Event, from, to time.Time) ([]Event, error) {
+	var recurringIDs []int64
+	for _, e := range events {
+		if e.IsRecurring() {
+			recurringIDs = append(recurringIDs, e.ID)
+		}
+	}
+	var overrides []OccurrenceOverride
+	if len(recurringIDs) > 0 {
+		err := gormDB.Where("event_id IN ? AND occurrence_start >= ? AND occurrence_start < ?", recurringIDs, from, to).
+			Find(&overrides).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var occurrences []Event
+	for _, e := range events {
+		expanded, err := e.Occurrences(from, to, overrides)
+		if err != nil {
+			return nil, err
+		}
+		occurrences = append(occurrences, expanded...)
+	}
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].DateTime.Before(occurrences[j].DateTime)
+	})
+	return occurrences, nil
+}
+
+// UpdateOccurrence edits a single occurrence of the series by storing an
+// override for it
+func (e Event) UpdateOccurrence(gormDB *gorm.DB, start time.Time, changes Event) (*Event, error) {
+	var updated Event
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := event.OccurrenceIndex(start); err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND occurrence_start = ?", event.ID, start).
+			Delete(&OccurrenceOverride{}).Error; err != nil {
+			return err
+		}
+		override := event.NewOverride(start, changes)
+		if err := tx.Create(&override).Error; err != nil {
+			return err
+		}
+
+		updated = event.Occurrence(start, []OccurrenceOverride{override})
+		return enqueueEvent(tx, EventActionUpdated, updated)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SeriesSplit is a series split at one of its occurrences so that the changes
+// apply to it and every occurrence after it
+type SeriesSplit struct {
+	// Original is the series ended just before Start
+	Original Event
+	// Following is the new series carrying the changes. When InPlace it has
+	// the ID of the original and replaces it.
+	Following Event
+	// InPlace is set when Start is the first occurrence, so the whole series changes
+	InPlace bool
+	Start   time.Time
+	// Shift moves the occurrences of the original onto those of the new series
+	Shift time.Duration
+}
+
+// Split plans applying changes to the occurrence starting at start and every
+// occurrence after it. Excluded dates after start move to the new series.
+func (e Event) Split(start time.Time, changes Event) (*SeriesSplit, error) {
+	index, err := e.OccurrenceIndex(start)
+	if err != nil {
+		return nil, err
+	}
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return nil, err
+	}
+
+	following := *rule
+	if rule.Count > 0 {
+		following.Count = rule.Count - index
+	}
+	split := &SeriesSplit{Original: e, Start: start, InPlace: index == 0}
+	split.Following = Event{
+		Name:        changes.Name,
+		Description: changes.Description,
+		Location:    changes.Location,
+		DateTime:    changes.DateTime,
+		UserID:      e.UserID,
+		Capacity:    changes.Capacity,
+		RRule:       following.String(),
+		Latitude:    changes.Latitude,
+		Longitude:   changes.Longitude,
+		Address:     changes.Address,
+		Status:      e.Status,
+		PublishedAt: e.PublishedAt,
+	}
+	if changes.RRule != "" {
+		split.Following.RRule = changes.RRule
+	}
+	if err := split.Following.NormalizeRecurrence(); err != nil {
+		return nil, err
+	}
+
+	if split.InPlace {
+		split.Following.ID = e.ID
+		split.Following.ExDates = e.ExDates
+		return split, nil
+	}
+	split.Shift = changes.DateTime.Sub(start)
+	var kept []time.Time
+	for _, ex := range e.ExDates {
+		if ex.Before(start) {
+			kept = append(kept, ex)
+		} else {
+			split.Following.ExDates = append(split.Following.ExDates, ex.Add(split.Shift))
+		}
+	}
+	rule.Count = 0
+	rule.Until = start.Add(-time.Second)
+	split.Original.RRule = rule.String()
+	split.Original.ExDates = kept
+	return split, nil
+}
+
+// MoveOverride returns the override moved onto the new series, or nil when
+// it belongs to an occurrence before the split
+func (s SeriesSplit) MoveOverride(override OccurrenceOverride) *OccurrenceOverride {
+	if override.OccurrenceStart.Before(s.Start) {
+		return nil
+	}
+	override.EventID = s.Following.ID
+	override.OccurrenceStart = override.OccurrenceStart.Add(s.Shift)
+	return &override
+}
+
+// MoveRegistration returns what becomes of a registration of the original
+// series: a registration of an occurrence at or after the split moves to the
+// new series (moved), and one covering the whole remaining series is copied
+// to it (copied). Registrations of earlier occurrences stay.
+func (s SeriesSplit) MoveRegistration(registration Registration) (moved, copied *Registration) {
+	switch {
+	case registration.OccurrenceStart != nil && !registration.OccurrenceStart.Before(s.Start):
+		start := registration.OccurrenceStart.Add(s.Shift)
+		registration.EventID = s.Following.ID
+		registration.OccurrenceStart = &start
+		return &registration, nil
+	case registration.Status == RegistrationStatusCancelled:
+		return nil, nil
+	case registration.OccurrenceStart == nil || registration.Scope == ScopeFollowing:
+		return nil, &Registration{
+			UserID:    registration.UserID,
+			EventID:   s.Following.ID,
+			Status:    registration.Status,
+			Scope:     ScopeSeries,
+			CreatedAt: registration.CreatedAt,
+		}
+	}
+	return nil, nil
+}
+
+// SplitSeries applies changes to the occurrence starting at start and every
+// occurrence after it. The original series is ended just before start and a
+// new series carrying the changes is created; overrides and registrations for
+// the affected occurrences move to the new series. Splitting at the first
+// occurrence updates the whole series in place.
+func (e Event) SplitSeries(gormDB *gorm.DB, start time.Time, changes Event) (*Event, error) {
+	var created Event
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		original, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		split, err := original.Split(start, changes)
+		if err != nil {
+			return err
+		}
+		created = split.Following
+
+		if split.InPlace {
+			if err := tx.Model(&created).Select(eventColumns).Updates(&created).Error; err != nil {
+				return err
+			}
+			if err := promoteWaitlisted(tx, &created); err != nil {
+				return err
+			}
+			return enqueueEventByID(tx, EventActionUpdated, created.ID)
+		}
+
+		if err := tx.Select(append(slices.Clone(eventColumns), statusColumns...)).Create(&created).Error; err != nil {
+			return err
+		}
+		split.Following.ID = created.ID
+		if err := tx.Model(&split.Original).Select("RRule", "ExDates").Updates(&split.Original).Error; err != nil {
+			return err
+		}
+		if err := moveFollowing(tx, *split); err != nil {
+			return err
+		}
+		if err := promoteWaitlisted(tx, &created); err != nil {
+			return err
+		}
+		if err := enqueueEvent(tx, EventActionUpdated, split.Original); err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionCreated, created)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// moveFollowing re-homes the overrides and registrations of the occurrences
+// after a split onto the new series
+func moveFollowing(tx *gorm.DB, split SeriesSplit) error {
+	var overrides []OccurrenceOverride
+	if err := tx.Where("event_id = ? AND occurrence_start >= ?", split.Original.ID, split.Start).Find(&overrides).Error; err != nil {
+		return err
+	}
+	for _, override := range overrides {
+		moved := split.MoveOverride(override)
+		err := tx.Model(&OccurrenceOverride{}).Where("id = ?", override.ID).Updates(map[string]any{
+			"event_id":         moved.EventID,
+			"occurrence_start": moved.OccurrenceStart,
+		}).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	var registrations []Registration
+	if err := tx.Where("event_id = ?", split.Original.ID).Find(&registrations).Error; err != nil {
+		return err
+	}
+	for _, registration := range registrations {
+		moved, copied := split.MoveRegistration(registration)
+		if moved != nil {
+			err := tx.Model(&Registration{}).Where("id = ?", registration.ID).Updates(map[string]any{
+				"event_id":         moved.EventID,
+				"occurrence_start": *moved.OccurrenceStart,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+		if copied != nil {
+			if err := tx.Create(copied).Error; err != nil {
+				return err
+			}
+			if err := recordStatus(tx, *copied); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// CancelOccurrence removes the occurrence starting at start from the series
+// by adding it to EXDATE, discarding its override and registrations
+func (e Event) CancelOccurrence(gormDB *gorm.DB, start time.Time) error {
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := event.OccurrenceIndex(start); err != nil {
+			return err
+		}
+		event.ExDates = append(event.ExDates, start)
+		if err := tx.Model(event).Select("ExDates").Updates(event).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND occurrence_start = ?", event.ID, start).
+			Delete(&OccurrenceOverride{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND occurrence_start = ? AND scope = ?", event.ID, start, ScopeOccurrence).
+			Delete(&Registration{}).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionUpdated, *event)
+	})
+}
+
+// EndBefore returns the series ended just before the occurrence starting at
+// start. It reports first=true, changing nothing, when start is the first
+// occurrence, so no occurrence would be left.
+func (e Event) EndBefore(start time.Time) (ended Event, first bool, err error) {
+	index, err := e.OccurrenceIndex(start)
+	if err != nil {
+		return e, false, err
+	}
+	if index == 0 {
+		return e, true, nil
+	}
+	rule, err := recurrence.Parse(e.RRule)
+	if err != nil {
+		return e, false, err
+	}
+	rule.Count = 0
+	rule.Until = start.Add(-time.Second)
+	e.RRule = rule.String()
+	return e, false, nil
+}
+
+// TruncateSeries ends the series just before the occurrence starting at
+// start, discarding overrides and registrations for the removed occurrences.
+// It reports deleted=true when start is the first occurrence, in which case
+// nothing is changed and the caller should delete the whole event instead.
+func (e Event) TruncateSeries(gormDB *gorm.DB, start time.Time) (deleted bool, err error) {
+	err = gormDB.Transaction(func(tx *gorm.DB) error {
+		event, err := lockEvent(tx, e.ID)
+		if err != nil {
+			return err
+		}
+		ended, first, err := event.EndBefore(start)
+		if err != nil || first {
+			deleted = first
+			return err
+		}
+		if err := tx.Model(&ended).Select("RRule").Updates(&ended).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND occurrence_start >= ?", event.ID, start).
+			Delete(&OccurrenceOverride{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("event_id = ? AND occurrence_start >= ?", event.ID, start).
+			Delete(&Registration{}).Error; err != nil {
+			return err
+		}
+		return enqueueEvent(tx, EventActionUpdated, ended)
+	})
+	return deleted, err
+}
+
+// GetOccurrenceOverrides retrieves every stored override for the given events
+func GetOccurrenceOverrides(gormDB *gorm.DB, eventIDs []int64) ([]OccurrenceOverride, error) {
+	if len(eventIDs) == 0 {
+		return nil, nil
+	}
+	var overrides []OccurrenceOverride
+	err := gormDB.Where("event_id IN ?", eventIDs).Order("occurrence_start").Find(&overrides).Error
+	return overrides, err
+}
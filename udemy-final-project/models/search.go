@@ -0,0 +1,324 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// Markers placed around matched terms before the highlight is HTML-escaped.
+// They are private-use code points so they cannot collide with event text.
+const (
+	highlightStart = "\ue000"
+	highlightStop  = "\ue001"
+)
+
+// snippetWords is the number of words kept around the first match in a description snippet
+const snippetWords = 35
+
+// ErrEmptySearch is returned when a search query has no searchable terms
+var ErrEmptySearch = errors.New("search query must contain at least one word")
+
+// Relevance weights of the searchable fields, matching the tsvector weights A, B and C
+var searchWeights = map[string]float64{
+	"name":        1.0,
+	"location":    0.4,
+	"description": 0.2,
+}
+
+// SearchQuery is a full-text search over event name, description and location
+type SearchQuery struct {
+	Text      string
+	PageSize  int // defaults to DefaultPageSize, capped at MaxPageSize
+	PageToken string
+	ViewerID  int64 // drafts are only found by their owner
+}
+
+// SearchResult is an event matching a search, with its relevance and the
+// matched fields highlighted
+type SearchResult struct {
+	Event Event   `json:"event"`
+	Rank  float64 `json:"rank" example:"0.6079"`
+	// Highlights maps each matching field to its HTML-escaped text with matches wrapped in <mark>
+	Highlights map[string]string `json:"highlights"`
+}
+
+// SearchPage is a single page of search results, most relevant first
+type SearchPage struct {
+	Results       []SearchResult `json:"results"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+}
+
+// searchCursor is the position of the next page of a search
+type searchCursor struct {
+	Text   string `json:"q"`
+	Offset int    `json:"o"`
+}
+
+// SearchEvents runs a full-text search. On PostgreSQL it uses the
+// search_vector column and its GIN index; other databases fall back to
+// ranking every event in memory with RankEvents.
+func SearchEvents(gormDB *gorm.DB, query SearchQuery) (*SearchPage, error) {
+	offset, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	var results []SearchResult
+	if gormDB.Dialector.Name() == "postgres" {
+		results, err = searchPostgres(gormDB, query.Text, query.ViewerID, offset, query.PageSize+1)
+	} else {
+		var events []Event
+		if err := visibleTo(gormDB, query.ViewerID).Find(&events).Error; err != nil {
+			return nil, err
+		}
+		results = rankedSlice(events, query.Text, offset, query.PageSize+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return query.page(results, offset), nil
+}
+
+// SearchEventsIn is SearchEvents over events held in memory
+func SearchEventsIn(events []Event, query SearchQuery) (*SearchPage, error) {
+	offset, err := query.normalize()
+	if err != nil {
+		return nil, err
+	}
+	var visible []Event
+	for _, e := range events {
+		if e.VisibleTo(query.ViewerID) {
+			visible = append(visible, e)
+		}
+	}
+	return query.page(rankedSlice(visible, query.Text, offset, query.PageSize+1), offset), nil
+}
+
+// normalize validates the query, applies the default page size and returns
+// the offset of the page token
+func (q *SearchQuery) normalize() (int, error) {
+	if len(searchTerms(q.Text)) == 0 {
+		return 0, ErrEmptySearch
+	}
+	switch {
+	case q.PageSize < 0:
+		return 0, ErrInvalidPageSize
+	case q.PageSize == 0:
+		q.PageSize = DefaultPageSize
+	case q.PageSize > MaxPageSize:
+		q.PageSize = MaxPageSize
+	}
+
+	if q.PageToken == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(q.PageToken)
+	var cursor searchCursor
+	if err != nil || json.Unmarshal(data, &cursor) != nil || cursor.Text != q.Text || cursor.Offset < 0 {
+		return 0, ErrInvalidPageToken
+	}
+	return cursor.Offset, nil
+}
+
+// page trims results fetched with one extra row and sets the next page token
+// when that extra row shows there is more to come
+func (q SearchQuery) page(results []SearchResult, offset int) *SearchPage {
+	page := &SearchPage{Results: results}
+	if page.Results == nil {
+		page.Results = []SearchResult{}
+	}
+	if len(results) > q.PageSize {
+		page.Results = results[:q.PageSize]
+		data, _ := json.Marshal(searchCursor{Text: q.Text, Offset: offset + q.PageSize})
+		page.NextPageToken = base64.RawURLEncoding.EncodeToString(data)
+	}
+	return page
+}
+
+// searchRow is a ranked match read from PostgreSQL
+type searchRow struct {
+	ID                   int64
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+	LocationHighlight    string
+}
+
+func searchPostgres(gormDB *gorm.DB, text string, viewerID int64, offset, limit int) ([]SearchResult, error) {
+	options := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=%d, MinWords=%d",
+		highlightStart, highlightStop, snippetWords, snippetWords/2)
+
+	var rows []searchRow
+	err := gormDB.Raw(`
+		SELECT id,
+			ts_rank(search_vector, q) AS rank,
+			ts_headline('english', name, q, @options) AS name_highlight,
+			ts_headline('english', description, q, @options) AS description_highlight,
+			ts_headline('english', location, q, @options) AS location_highlight
+		FROM events, websearch_to_tsquery('english', @text) AS q
+		WHERE search_vector @@ q AND (status <> @draft OR user_id = @viewer)
+		ORDER BY rank DESC, id
+		LIMIT @limit OFFSET @offset`,
+		map[string]any{
+			"options": options, "text": text, "limit": limit, "offset": offset,
+			"draft": EventStatusDraft, "viewer": viewerID,
+		},
+	).Scan(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+
+	ids := make([]int64, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+	var events []Event
+	if err := gormDB.Find(&events, ids).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]Event, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+
+	results := make([]SearchResult, 0, len(rows))
+	for _, row := range rows {
+		event, ok := byID[row.ID]
+		if !ok {
+			continue // deleted between the two queries
+		}
+		highlights := map[string]string{}
+		for field, marked := range map[string]string{
+			"name":        row.NameHighlight,
+			"description": row.DescriptionHighlight,
+			"location":    row.LocationHighlight,
+		} {
+			if strings.Contains(marked, highlightStart) {
+				highlights[field] = formatHighlight(marked)
+			}
+		}
+		results = append(results, SearchResult{Event: event, Rank: row.Rank, Highlights: highlights})
+	}
+	return results, nil
+}
+
+// rankedSlice ranks events with RankEvents and returns limit results from offset
+func rankedSlice(events []Event, text string, offset, limit int) []SearchResult {
+	results := RankEvents(events, text)
+	if offset >= len(results) {
+		return nil
+	}
+	results = results[offset:]
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// RankEvents is the pure-Go equivalent of the PostgreSQL search. Every word
+// of text must match, as a prefix, a word of the event's name, description or
+// location. Results are ordered by relevance, then by ID.
+func RankEvents(events []Event, text string) []SearchResult {
+	terms := searchTerms(text)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	var results []SearchResult
+	for _, event := range events {
+		fields := map[string]string{
+			"name":        event.Name,
+			"description": event.Description,
+			"location":    event.Location,
+		}
+		matched := make(map[string]bool, len(terms))
+		rank := 0.0
+		highlights := map[string]string{}
+		for field, value := range fields {
+			words := searchTerms(value)
+			hits := 0
+			for _, word := range words {
+				hit := false
+				for _, term := range terms {
+					if strings.HasPrefix(word, term) {
+						matched[term] = true
+						hit = true
+					}
+				}
+				if hit {
+					hits++
+				}
+			}
+			if hits == 0 {
+				continue
+			}
+			rank += searchWeights[field] * float64(hits) / (1 + math.Log(float64(len(words))))
+			highlights[field] = formatHighlight(markMatches(value, terms, field == "description"))
+		}
+		if len(matched) < len(terms) {
+			continue
+		}
+		results = append(results, SearchResult{Event: event, Rank: rank, Highlights: highlights})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Event.ID < results[j].Event.ID
+	})
+	return results
+}
+
+// searchTerms splits text into lower-case words
+func searchTerms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// markMatches wraps the words of value that match a term in highlight
+// markers. When snippet is set, long text is cut down to the words around the
+// first match, as ts_headline does.
+func markMatches(value string, terms []string, snippet bool) string {
+	words := strings.Fields(value)
+	first := -1
+	for i, word := range words {
+		for _, part := range searchTerms(word) {
+			if matchesAny(part, terms) {
+				words[i] = highlightStart + word + highlightStop
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+	if snippet && len(words) > snippetWords && first >= 0 {
+		start := max(0, min(first-snippetWords/3, len(words)-snippetWords))
+		words = words[start : start+snippetWords]
+	}
+	return strings.Join(words, " ")
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHighlight HTML-escapes marked text and turns the markers into <mark> tags
+func formatHighlight(marked string) string {
+	return strings.NewReplacer(highlightStart, "<mark>", highlightStop, "</mark>").Replace(html.EscapeString(marked))
+}
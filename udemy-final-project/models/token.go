@@ -0,0 +1,230 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// refreshTokenTTL is how long a refresh token can be exchanged for new tokens
+const refreshTokenTTL = 30 * 24 * time.Hour
+
+var (
+	// ErrInvalidRefreshToken is returned for unknown, expired or revoked refresh tokens
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	// ErrRefreshTokenReused is returned when a refresh token is used twice. The
+	// whole session is revoked, since the token may have been stolen.
+	ErrRefreshTokenReused = errors.New("refresh token was already used, session revoked")
+	// ErrInvalidToken is returned for access tokens that are malformed, expired or wrongly signed
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrTokenRevoked is returned for access tokens revoked by logout or reuse detection
+	ErrTokenRevoked = errors.New("token has been revoked")
+)
+
+// RefreshToken is one refresh token of a session. Every refresh replaces the
+// token with a new one in the same family; only the hash of the token is stored.
+type RefreshToken struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement"`
+	UserID          int64     `gorm:"not null"`
+	FamilyID        string    `gorm:"not null"` // shared by all tokens of a session
+	TokenHash       string    `gorm:"not null"`
+	AccessTokenID   string    // jti of the access token issued with this token
+	AccessExpiresAt time.Time // when that access token expires
+	ExpiresAt       time.Time `gorm:"not null"`
+	CreatedAt       time.Time
+	UsedAt          *time.Time // set once the token was exchanged
+	RevokedAt       *time.Time
+}
+
+// RevokedToken is a denylisted access token, kept until it expires
+type RevokedToken struct {
+	ID        string    `gorm:"primaryKey"` // the jti claim
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
+
+// TokenPair is an access token with the refresh token that renews it
+type TokenPair struct {
+	AccessToken  string    `json:"token" example:"eyJhbGciOiJIUzI1NiIs..."`
+	RefreshToken string    `json:"refresh_token" example:"Yz3n2pQ0rLh6v8sW1xKb9tUe4mJc7aDf5gHi0kNo2qR"`
+	ExpiresAt    time.Time `json:"expires_at" example:"2030-01-01T10:15:00Z"`
+}
+
+// RefreshRequest represents the request payload for refreshing tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required" example:"Yz3n2pQ0rLh6v8sW1xKb9tUe4mJc7aDf5gHi0kNo2qR"`
+}
+
+// StartSession issues an access token and a refresh token starting a new session for user
+func StartSession(user *User) (*TokenPair, error) {
+	gormDB := db.GetDB()
+	var pair *TokenPair
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		pair, err = issueTokens(tx, user, rand.Text())
+		return err
+	})
+	return pair, err
+}
+
+// RefreshSession exchanges a refresh token for a new token pair of the same
+// session. Each refresh token can be used once; using it again revokes the
+// session and every access token issued in it.
+func RefreshSession(refreshToken string) (*TokenPair, error) {
+	gormDB := db.GetDB()
+	var pair *TokenPair
+	reused := false
+	err := gormDB.Transaction(func(tx *gorm.DB) error {
+		var token RefreshToken
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", security.HashOpaqueToken(refreshToken)).
+			First(&token).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		if token.RevokedAt != nil || !token.ExpiresAt.After(now) {
+			return ErrInvalidRefreshToken
+		}
+		if token.UsedAt != nil {
+			// Commit the revocation, then report the reuse
+			reused = true
+			return revokeFamily(tx, token.FamilyID, now)
+		}
+
+		if err := tx.Model(&RefreshToken{}).Where("id = ?", token.ID).Update("used_at", now).Error; err != nil {
+			return err
+		}
+		var user User
+		if err := tx.First(&user, token.UserID).Error; err != nil {
+			return err
+		}
+		pair, err = issueTokens(tx, &user, token.FamilyID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if reused {
+		return nil, ErrRefreshTokenReused
+	}
+	return pair, nil
+}
+
+// EndSession revokes the access token with the given claims and the session
+// it belongs to, if any
+func EndSession(claims *security.Claims) error {
+	if claims.ID == "" {
+		return nil
+	}
+	gormDB := db.GetDB()
+	return gormDB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		if err := denyToken(tx, claims.ID, claims.ExpiresAt); err != nil {
+			return err
+		}
+		var token RefreshToken
+		err := tx.Where("access_token_id = ? AND user_id = ?", claims.ID, claims.UserID).First(&token).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		return revokeFamily(tx, token.FamilyID, now)
+	})
+}
+
+// ValidateAccessToken verifies an access token and checks that it has not
+// been revoked. Rejected tokens return ErrInvalidToken or ErrTokenRevoked.
+func ValidateAccessToken(tokenString string) (*security.Claims, error) {
+	claims, err := security.ParseToken(tokenString)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if claims.ID == "" {
+		return claims, nil
+	}
+	gormDB := db.GetDB()
+	var count int64
+	if err := gormDB.Model(&RevokedToken{}).Where("id = ?", claims.ID).Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count > 0 {
+		return nil, ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// PurgeExpiredTokens deletes refresh tokens and denylist entries that expired
+// before the given time and can no longer be used
+func PurgeExpiredTokens(before time.Time) error {
+	gormDB := db.GetDB()
+	if err := gormDB.Where("expires_at < ?", before).Delete(&RevokedToken{}).Error; err != nil {
+		return err
+	}
+	return gormDB.Where("expires_at < ?", before).Delete(&RefreshToken{}).Error
+}
+
+// issueTokens creates an access token and a refresh token in the given family
+func issueTokens(tx *gorm.DB, user *User, familyID string) (*TokenPair, error) {
+	accessToken, claims, err := security.IssueToken(user.Email, user.ID, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	err = tx.Create(&RefreshToken{
+		UserID:          user.ID,
+		FamilyID:        familyID,
+		TokenHash:       hash,
+		AccessTokenID:   claims.ID,
+		AccessExpiresAt: claims.ExpiresAt,
+		ExpiresAt:       time.Now().UTC().Add(refreshTokenTTL),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    claims.ExpiresAt,
+	}, nil
+}
+
+// revokeFamily revokes every refresh token of a session and denylists the
+// access tokens issued with them that have not expired yet
+func revokeFamily(tx *gorm.DB, familyID string, now time.Time) error {
+	var tokens []RefreshToken
+	if err := tx.Where("family_id = ?", familyID).Find(&tokens).Error; err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if token.AccessTokenID != "" && token.AccessExpiresAt.After(now) {
+			if err := denyToken(tx, token.AccessTokenID, token.AccessExpiresAt); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Model(&RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", now).Error
+}
+
+// denyToken adds an access token to the denylist until it expires
+func denyToken(tx *gorm.DB, id string, expiresAt time.Time) error {
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&RevokedToken{ID: id, ExpiresAt: expiresAt}).Error
+}
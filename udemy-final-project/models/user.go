@@ -1,24 +1,42 @@
 package models
 
 import (
+	"errors"
 	"log"
+	"strings"
 
-	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrInvalidRole is returned when setting a role other than user, organizer or admin
+	ErrInvalidRole = errors.New("role must be one of user, organizer or admin")
+	// ErrUserNotFound is returned when changing a user that does not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrEmailTaken is returned when registering an email that already has a user
+	ErrEmailTaken = errors.New("a user with this email already exists")
+)
+
 // User represents a user in the system
 type User struct {
 	ID       int64  `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
 	Email    string `json:"email" gorm:"unique;not null" binding:"required,email" example:"user@example.com"`
 	Password string `json:"password" gorm:"not null" binding:"required,min=6" example:"password123"`
+	// Role is user, organizer or admin, see the policy package
+	Role string `json:"role" gorm:"not null;default:user" example:"user"`
+	// CalendarTokenHash is the SHA-256 hash of the user's calendar feed token
+	CalendarTokenHash string `json:"-" gorm:"index"`
 }
 
-// Save creates a new user in the database with hashed password
-func (u *User) Save() error {
-	db := db.GetDB()
+// SetRoleRequest represents the request payload for changing a user's role
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required" example:"organizer"`
+}
 
+// PrepareCreate hashes the password of a new user and defaults its role
+func (u *User) PrepareCreate() error {
 	// Log the email and password
 	log.Printf("Attempting to register user: %s", u.Email)
 	log.Printf("User password: %s", u.Password)
@@ -32,16 +50,28 @@ func (u *User) Save() error {
 	log.Printf("Hashed password: %s", hashedPassword)
 
 	u.Password = hashedPassword
+	if u.Role == "" {
+		u.Role = policy.RoleUser
+	}
+	return nil
+}
 
-	return db.Create(u).Error
+// Save creates a new user in the database with hashed password
+func (u *User) Save(gormDB *gorm.DB) error {
+	if err := u.PrepareCreate(); err != nil {
+		return err
+	}
+	err := gormDB.Create(u).Error
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return ErrEmailTaken
+	}
+	return err
 }
 
 // GetUserByEmail retrieves a user by their email address
-func GetUserByEmail(email string) (*User, error) {
-	db := db.GetDB()
-
+func GetUserByEmail(gormDB *gorm.DB, email string) (*User, error) {
 	var user User
-	err := db.Where("email = ?", email).First(&user).Error
+	err := gormDB.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, nil // User not found
@@ -52,8 +82,8 @@ func GetUserByEmail(email string) (*User, error) {
 }
 
 // VerifyUserCredentials checks if the provided email and password match a user in the database
-func VerifyUserCredentials(email, password string) (*User, error) {
-	user, err := GetUserByEmail(email)
+func VerifyUserCredentials(gormDB *gorm.DB, email, password string) (*User, error) {
+	user, err := GetUserByEmail(gormDB, email)
 	if err != nil {
 		return nil, err
 	}
@@ -68,3 +98,65 @@ func VerifyUserCredentials(email, password string) (*User, error) {
 
 	return user, nil
 }
+
+// SetCalendarTokenHash replaces the user's calendar feed token hash, invalidating any previous token
+func SetCalendarTokenHash(gormDB *gorm.DB, userID int64, hash string) error {
+	result := gormDB.Model(&User{}).Where("id = ?", userID).Update("calendar_token_hash", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// GetUserByCalendarTokenHash retrieves a user by ID only if the calendar token hash matches
+func GetUserByCalendarTokenHash(gormDB *gorm.DB, userID int64, hash string) (*User, error) {
+	var user User
+	err := gormDB.Where("id = ? AND calendar_token_hash = ? AND calendar_token_hash <> ''", userID, hash).First(&user).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil // User not found or token mismatch
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUserRole changes the role of a user and returns the updated user without
+// its password hash. The new role is carried by the access tokens issued after
+// the change.
+func SetUserRole(gormDB *gorm.DB, userID int64, role string) (*User, error) {
+	if !policy.ValidRole(role) {
+		return nil, ErrInvalidRole
+	}
+	result := gormDB.Model(&User{}).Where("id = ?", userID).Update("role", role)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrUserNotFound
+	}
+	var user User
+	if err := gormDB.Omit("password").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// PromoteAdmins gives the admin role to the users with the given emails that
+// exist, ignoring case, and returns how many users were promoted
+func PromoteAdmins(gormDB *gorm.DB, emails []string) (int64, error) {
+	if len(emails) == 0 {
+		return 0, nil
+	}
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	result := gormDB.Model(&User{}).
+		Where("LOWER(email) IN ? AND role <> ?", lowered, policy.RoleAdmin).
+		Update("role", policy.RoleAdmin)
+	return result.RowsAffected, result.Error
+}
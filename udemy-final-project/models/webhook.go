@@ -0,0 +1,354 @@
+package models
+
+import (
+	"crypto/rand"
+	"errors"
+	"net/url"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Retry delays for webhook deliveries that failed
+const (
+	webhookBaseBackoff = 10 * time.Second
+	webhookMaxBackoff  = time.Hour
+)
+
+// WebhookActions are the actions a webhook can subscribe to
+var WebhookActions = slices.Concat(EventActions, RegistrationActions)
+
+var (
+	// ErrWebhookNotFound is returned for webhooks that do not exist or belong to another user
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrWebhookDeliveryNotFound is returned for deliveries that do not exist or belong to another webhook
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	// ErrInvalidWebhookURL is returned for webhook URLs that are not absolute http or https URLs
+	ErrInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrInvalidWebhookAction is returned when subscribing to an unknown action
+	ErrInvalidWebhookAction = errors.New("unknown webhook action")
+)
+
+// Webhook is a user's subscription to the changes of the events they own.
+// Each change is POSTed to URL as a WebhookPayload signed with Secret.
+type Webhook struct {
+	ID     int64  `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	UserID int64  `json:"user_id" gorm:"not null" example:"1"`
+	URL    string `json:"url" gorm:"not null" example:"https://example.com/hooks/events"`
+	// Secret signs the deliveries; it is only returned when the webhook is created
+	Secret string `json:"-" gorm:"not null"`
+	// Actions limits the changes delivered; empty subscribes to every action
+	Actions   []string  `json:"actions,omitempty" gorm:"type:text;serializer:json" example:"created,registration_created"`
+	Active    bool      `json:"active" gorm:"not null" example:"true"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WebhookRequest represents the request payload for creating or updating a webhook
+type WebhookRequest struct {
+	URL     string   `json:"url" binding:"required" example:"https://example.com/hooks/events"`
+	Actions []string `json:"actions,omitempty" example:"created,registration_created"`
+	// Active defaults to true
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+// WebhookDelivery is one change sent, or to be sent, to a webhook. Together
+// the deliveries of a webhook are its delivery log.
+type WebhookDelivery struct {
+	ID        int64  `json:"id" gorm:"primaryKey;autoIncrement" example:"1"`
+	WebhookID int64  `json:"webhook_id" gorm:"not null" example:"1"`
+	MessageID string `json:"message_id" gorm:"not null"` // ID of the change, the same on redelivery
+	Action    string `json:"action" gorm:"not null" example:"created"`
+	EventID   int64  `json:"event_id" gorm:"not null" example:"1"`
+	Payload   string `json:"-" gorm:"type:text;not null"` // the WebhookPayload as JSON
+	// Status is pending until the delivery succeeds or its attempts are used up
+	Status         string     `json:"status" gorm:"not null;default:pending" example:"succeeded"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0" example:"1"`
+	LastStatusCode int        `json:"last_status_code,omitempty" example:"200"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at" gorm:"not null"`
+	RedeliveryOf   *int64     `json:"redelivery_of,omitempty"` // the delivery this one sends again
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	Webhook        Webhook    `gorm:"foreignKey:WebhookID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// WebhookPayload is the JSON body POSTed to webhooks
+type WebhookPayload struct {
+	ID         string     `json:"id"` // ID of the change, the same on redelivery
+	Action     string     `json:"action" example:"registration_created"`
+	OccurredAt *time.Time `json:"occurred_at,omitempty"`
+	Event      Event      `json:"event"`
+	// Registration is set for registration changes
+	Registration *Registration `json:"registration,omitempty"`
+}
+
+// validate checks the URL and actions of a webhook
+func (w *Webhook) validate() error {
+	parsed, err := url.Parse(w.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+	for _, action := range w.Actions {
+		if !slices.Contains(WebhookActions, action) {
+			return ErrInvalidWebhookAction
+		}
+	}
+	return nil
+}
+
+// Subscribed reports whether the webhook delivers changes with the given action
+func (w *Webhook) Subscribed(action string) bool {
+	return len(w.Actions) == 0 || slices.Contains(w.Actions, action)
+}
+
+// Save creates the webhook with a new random secret
+func (w *Webhook) Save() error {
+	if err := w.validate(); err != nil {
+		return err
+	}
+	w.Secret = "whsec_" + rand.Text()
+	return db.GetDB().Create(w).Error
+}
+
+// Update changes the URL, actions and active flag of a webhook of its user
+func (w *Webhook) Update() error {
+	if err := w.validate(); err != nil {
+		return err
+	}
+	result := db.GetDB().Model(&Webhook{}).
+		Where("id = ? AND user_id = ?", w.ID, w.UserID).
+		Select("URL", "Actions", "Active", "UpdatedAt").
+		Updates(w)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+// parseWebhookID parses a webhook ID, reporting malformed IDs as not found
+func parseWebhookID(id string) (int64, error) {
+	webhookID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return 0, ErrWebhookNotFound
+	}
+	return webhookID, nil
+}
+
+// GetWebhook retrieves a webhook of a user
+func GetWebhook(userID int64, id string) (*Webhook, error) {
+	webhookID, err := parseWebhookID(id)
+	if err != nil {
+		return nil, err
+	}
+	var webhook Webhook
+	err = db.GetDB().Where("id = ? AND user_id = ?", webhookID, userID).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// ListWebhooks retrieves the webhooks of a user, oldest first
+func ListWebhooks(userID int64) ([]Webhook, error) {
+	webhooks := []Webhook{}
+	err := db.GetDB().Where("user_id = ?", userID).Order("id").Find(&webhooks).Error
+	return webhooks, err
+}
+
+// DeleteWebhook deletes a webhook of a user together with its delivery log
+func DeleteWebhook(userID int64, id string) error {
+	webhook, err := GetWebhook(userID, id)
+	if err != nil {
+		return err
+	}
+	return db.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", webhook.ID).Delete(&WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&Webhook{}, webhook.ID).Error
+	})
+}
+
+// EnqueueWebhookDeliveries queues a change of an event owned by ownerID for
+// each of the owner's active webhooks subscribed to its action, as part of
+// tx. A change queued before, as when a message is consumed again, is not
+// queued twice.
+func EnqueueWebhookDeliveries(tx *gorm.DB, ownerID int64, action, messageID string, eventID int64, payload []byte) (int, error) {
+	var webhooks []Webhook
+	if err := tx.Where("user_id = ? AND active", ownerID).Find(&webhooks).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	var deliveries []WebhookDelivery
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(action) {
+			continue
+		}
+		deliveries = append(deliveries, WebhookDelivery{
+			WebhookID:     webhook.ID,
+			MessageID:     messageID,
+			Action:        action,
+			EventID:       eventID,
+			Payload:       string(payload),
+			Status:        WebhookDeliveryPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&deliveries)
+	return int(result.RowsAffected), result.Error
+}
+
+// ClaimWebhookDeliveries returns up to limit pending deliveries that are due,
+// with their webhooks, and postpones their next attempt by lease so other
+// workers skip them while they are sent. Deliveries locked by another worker
+// are skipped rather than waited for.
+func ClaimWebhookDeliveries(limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	err := db.GetDB().Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		var ids []int64
+		err := tx.Model(&WebhookDelivery{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", WebhookDeliveryPending, now).
+			Order("next_attempt_at, id").
+			Limit(limit).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Model(&WebhookDelivery{}).Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error; err != nil {
+			return err
+		}
+		return tx.Preload("Webhook").Where("id IN ?", ids).Order("id").Find(&deliveries).Error
+	})
+	return deliveries, err
+}
+
+// WebhookBackoff returns how long to wait before the next delivery attempt
+// after attempts failed ones. The delay starts at ten seconds and doubles
+// with each failure up to an hour.
+func WebhookBackoff(attempts int) time.Duration {
+	return exponentialBackoff(webhookBaseBackoff, webhookMaxBackoff, attempts)
+}
+
+// RecordAttempt records the outcome of sending the delivery: the HTTP status
+// code received, if any, and the error when the attempt failed. A failed
+// delivery is retried after WebhookBackoff until maxAttempts were made.
+func (d *WebhookDelivery) RecordAttempt(statusCode int, attemptErr error, maxAttempts int) error {
+	now := time.Now().UTC()
+	d.Attempts++
+	d.LastStatusCode = statusCode
+	d.LastError = ""
+	switch {
+	case attemptErr == nil:
+		d.Status = WebhookDeliverySucceeded
+		d.DeliveredAt = &now
+	case d.Attempts >= maxAttempts:
+		d.Status = WebhookDeliveryFailed
+		d.LastError = attemptErr.Error()
+	default:
+		d.LastError = attemptErr.Error()
+		d.NextAttemptAt = now.Add(WebhookBackoff(d.Attempts))
+	}
+	return db.GetDB().Model(&WebhookDelivery{}).Where("id = ?", d.ID).Updates(map[string]any{
+		"status":           d.Status,
+		"attempts":         d.Attempts,
+		"last_status_code": d.LastStatusCode,
+		"last_error":       d.LastError,
+		"next_attempt_at":  d.NextAttemptAt,
+		"delivered_at":     d.DeliveredAt,
+	}).Error
+}
+
+// ListWebhookDeliveries retrieves the delivery log of a webhook of a user,
+// newest first. limit defaults to DefaultPageSize and is capped at MaxPageSize.
+func ListWebhookDeliveries(userID int64, webhookID string, limit int) ([]WebhookDelivery, error) {
+	switch {
+	case limit < 0:
+		return nil, ErrInvalidPageSize
+	case limit == 0:
+		limit = DefaultPageSize
+	case limit > MaxPageSize:
+		limit = MaxPageSize
+	}
+	webhook, err := GetWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []WebhookDelivery{}
+	err = db.GetDB().Where("webhook_id = ?", webhook.ID).
+		Order("id DESC").
+		Limit(limit).
+		Find(&deliveries).Error
+	return deliveries, err
+}
+
+// RedeliverWebhook queues a delivery of a webhook of a user to be sent again
+// at once. The original stays in the log; the new delivery refers to it.
+func RedeliverWebhook(userID int64, webhookID, deliveryID string) (*WebhookDelivery, error) {
+	webhook, err := GetWebhook(userID, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(deliveryID, 10, 64)
+	if err != nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	gormDB := db.GetDB()
+	var original WebhookDelivery
+	err = gormDB.Where("id = ? AND webhook_id = ?", id, webhook.ID).First(&original).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	redelivery := WebhookDelivery{
+		WebhookID:     webhook.ID,
+		MessageID:     original.MessageID,
+		Action:        original.Action,
+		EventID:       original.EventID,
+		Payload:       original.Payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: time.Now().UTC(),
+		RedeliveryOf:  &original.ID,
+	}
+	if err := gormDB.Create(&redelivery).Error; err != nil {
+		return nil, err
+	}
+	return &redelivery, nil
+}
+
+// PurgeWebhookDeliveries deletes the deliveries that succeeded or failed
+// before the given time
+func PurgeWebhookDeliveries(before time.Time) (int64, error) {
+	result := db.GetDB().
+		Where("status <> ? AND created_at < ?", WebhookDeliveryPending, before).
+		Delete(&WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,140 @@
+// Package policy decides which actions a user may perform. The REST and gRPC
+// transports both ask the same Policy, so authorization rules live in one place.
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Roles a user can have. Every user has the user role unless promoted.
+const (
+	RoleUser      = "user"
+	RoleOrganizer = "organizer"
+	RoleAdmin     = "admin"
+)
+
+// Action is something a subject asks to do
+type Action string
+
+// Actions checked by the policy
+const (
+	ActionCreateEvent    Action = "create_event"
+	ActionImportEvents   Action = "import_events"
+	ActionUpdateEvent    Action = "update_event"
+	ActionDeleteEvent    Action = "delete_event"
+	ActionPublishEvent   Action = "publish_event"
+	ActionCancelEvent    Action = "cancel_event"
+	ActionManageCalendar Action = "manage_calendar"
+	ActionManageRoles    Action = "manage_roles"
+	ActionManageWebhooks Action = "manage_webhooks"
+	// ActionManageAttendees covers listing, exporting and removing the attendees of an event
+	ActionManageAttendees Action = "manage_attendees"
+)
+
+// actions lists every known action, in the order of the constants above
+var actions = []Action{
+	ActionCreateEvent, ActionImportEvents, ActionUpdateEvent, ActionDeleteEvent,
+	ActionPublishEvent, ActionCancelEvent, ActionManageCalendar, ActionManageRoles,
+	ActionManageWebhooks, ActionManageAttendees,
+}
+
+// ErrForbidden is returned when the subject may not perform an action
+var ErrForbidden = errors.New("permission denied")
+
+// Subject is the authenticated user asking to perform an action
+type Subject struct {
+	UserID int64
+	Role   string
+}
+
+// Resource is what an action is performed on. OwnerID is the user owning an
+// event, or the user whose calendar or role is managed.
+type Resource struct {
+	OwnerID int64
+}
+
+// Policy holds the authorization rules:
+//   - admins may perform every action, on any resource
+//   - events are updated, deleted, published and cancelled by their owner, who
+//     also manages their attendees
+//   - calendar feed tokens are managed by their user only, since a token grants
+//     read access to the user's calendar
+//   - webhooks are managed by their user
+//   - roles are managed by admins
+//   - organizer-only actions are denied to plain users
+type Policy struct {
+	organizerOnly map[Action]bool
+}
+
+// New creates a policy restricting the given actions to organizers and admins
+func New(organizerOnly ...Action) *Policy {
+	p := &Policy{organizerOnly: map[Action]bool{}}
+	for _, action := range organizerOnly {
+		p.organizerOnly[action] = true
+	}
+	return p
+}
+
+// Parse creates a policy restricting the named actions, such as
+// "create_event" and "import_events", to organizers and admins. By default
+// every user may create events.
+func Parse(names []string) (*Policy, error) {
+	var organizerOnly []Action
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		action := Action(name)
+		if !slices.Contains(actions, action) {
+			return nil, fmt.Errorf("unknown action %q", name)
+		}
+		organizerOnly = append(organizerOnly, action)
+	}
+	return New(organizerOnly...), nil
+}
+
+// ValidRole reports whether role is one of the known roles
+func ValidRole(role string) bool {
+	return role == RoleUser || role == RoleOrganizer || role == RoleAdmin
+}
+
+// Authorize returns nil if subject may perform action on resource and
+// ErrForbidden otherwise. Unknown actions are denied.
+func (p *Policy) Authorize(subject Subject, action Action, resource Resource) error {
+	role := subject.role()
+	if role == RoleAdmin && action != ActionManageCalendar {
+		return nil
+	}
+	if p.organizerOnly[action] && role != RoleOrganizer && role != RoleAdmin {
+		return ErrForbidden
+	}
+
+	switch action {
+	case ActionCreateEvent, ActionImportEvents:
+		return nil
+	case ActionUpdateEvent, ActionDeleteEvent, ActionPublishEvent, ActionCancelEvent, ActionManageCalendar,
+		ActionManageWebhooks, ActionManageAttendees:
+		if subject.UserID != 0 && subject.UserID == resource.OwnerID {
+			return nil
+		}
+	}
+	return ErrForbidden
+}
+
+// Allowed reports whether subject may perform action on resource
+func (p *Policy) Allowed(subject Subject, action Action, resource Resource) bool {
+	return p.Authorize(subject, action, resource) == nil
+}
+
+// role returns the subject's role, treating unknown and missing roles as the
+// user role. Tokens issued before roles existed carry no role.
+func (s Subject) role() string {
+	if ValidRole(s.Role) {
+		return s.Role
+	}
+	return RoleUser
+}
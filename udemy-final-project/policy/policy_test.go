@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_EventActions(t *testing.T) {
+	p := New()
+	owner := Subject{UserID: 1, Role: RoleUser}
+	other := Subject{UserID: 2, Role: RoleUser}
+	organizer := Subject{UserID: 3, Role: RoleOrganizer}
+	admin := Subject{UserID: 4, Role: RoleAdmin}
+	event := Resource{OwnerID: 1}
+
+	for _, action := range []Action{ActionUpdateEvent, ActionDeleteEvent, ActionPublishEvent, ActionCancelEvent, ActionManageAttendees} {
+		t.Run(string(action), func(t *testing.T) {
+			assert.NoError(t, p.Authorize(owner, action, event))
+			assert.ErrorIs(t, p.Authorize(other, action, event), ErrForbidden)
+			assert.ErrorIs(t, p.Authorize(organizer, action, event), ErrForbidden)
+			assert.NoError(t, p.Authorize(admin, action, event))
+		})
+	}
+
+	// Every role may create events by default
+	for _, subject := range []Subject{owner, organizer, admin, {UserID: 5}} {
+		assert.NoError(t, p.Authorize(subject, ActionCreateEvent, Resource{}))
+		assert.NoError(t, p.Authorize(subject, ActionImportEvents, Resource{}))
+	}
+}
+
+func TestPolicy_OrganizerOnly(t *testing.T) {
+	p := New(ActionCreateEvent, ActionPublishEvent)
+	user := Subject{UserID: 1, Role: RoleUser}
+	organizer := Subject{UserID: 2, Role: RoleOrganizer}
+	admin := Subject{UserID: 3, Role: RoleAdmin}
+
+	assert.ErrorIs(t, p.Authorize(user, ActionCreateEvent, Resource{}), ErrForbidden)
+	assert.NoError(t, p.Authorize(organizer, ActionCreateEvent, Resource{}))
+	assert.NoError(t, p.Authorize(admin, ActionCreateEvent, Resource{}))
+	assert.NoError(t, p.Authorize(user, ActionImportEvents, Resource{}))
+
+	// Owning the event is not enough for a restricted action
+	assert.ErrorIs(t, p.Authorize(user, ActionPublishEvent, Resource{OwnerID: 1}), ErrForbidden)
+	assert.NoError(t, p.Authorize(organizer, ActionPublishEvent, Resource{OwnerID: 2}))
+	assert.ErrorIs(t, p.Authorize(organizer, ActionPublishEvent, Resource{OwnerID: 1}), ErrForbidden)
+	assert.NoError(t, p.Authorize(user, ActionUpdateEvent, Resource{OwnerID: 1}))
+}
+
+func TestPolicy_CalendarAndRoles(t *testing.T) {
+	p := New()
+	user := Subject{UserID: 1, Role: RoleUser}
+	admin := Subject{UserID: 2, Role: RoleAdmin}
+
+	assert.NoError(t, p.Authorize(user, ActionManageCalendar, Resource{OwnerID: 1}))
+	assert.ErrorIs(t, p.Authorize(user, ActionManageCalendar, Resource{OwnerID: 2}), ErrForbidden)
+	// Admins do not get other users' calendar feed tokens
+	assert.ErrorIs(t, p.Authorize(admin, ActionManageCalendar, Resource{OwnerID: 1}), ErrForbidden)
+
+	assert.ErrorIs(t, p.Authorize(user, ActionManageRoles, Resource{OwnerID: 1}), ErrForbidden)
+	assert.NoError(t, p.Authorize(admin, ActionManageRoles, Resource{OwnerID: 1}))
+
+	assert.NoError(t, p.Authorize(user, ActionManageWebhooks, Resource{OwnerID: 1}))
+	assert.ErrorIs(t, p.Authorize(user, ActionManageWebhooks, Resource{OwnerID: 2}), ErrForbidden)
+	assert.ErrorIs(t, New(ActionManageWebhooks).Authorize(user, ActionManageWebhooks, Resource{OwnerID: 1}), ErrForbidden)
+}
+
+func TestPolicy_UnknownRolesAndActions(t *testing.T) {
+	p := New()
+
+	// Unknown roles are treated as the user role
+	forged := Subject{UserID: 1, Role: "superuser"}
+	assert.ErrorIs(t, p.Authorize(forged, ActionDeleteEvent, Resource{OwnerID: 2}), ErrForbidden)
+	assert.ErrorIs(t, p.Authorize(forged, ActionManageRoles, Resource{}), ErrForbidden)
+
+	assert.ErrorIs(t, p.Authorize(Subject{UserID: 1}, Action("drop_tables"), Resource{OwnerID: 1}), ErrForbidden)
+	// Anonymous subjects own nothing
+	assert.ErrorIs(t, p.Authorize(Subject{}, ActionUpdateEvent, Resource{}), ErrForbidden)
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]string{" create_event", " import_events ", ""})
+	require.NoError(t, err)
+	assert.False(t, p.Allowed(Subject{UserID: 1}, ActionCreateEvent, Resource{}))
+	assert.False(t, p.Allowed(Subject{UserID: 1}, ActionImportEvents, Resource{}))
+	assert.True(t, p.Allowed(Subject{UserID: 1, Role: RoleOrganizer}, ActionCreateEvent, Resource{}))
+
+	_, err = Parse([]string{"create_event", "launch_rockets"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+// Package problem writes REST API errors as RFC 7807 problem details
+// (application/problem+json) and translates the domain errors of the
+// services to HTTP status codes.
+package problem
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// Details is an RFC 7807 problem details object. Reason is an extension
+// member carrying the machine-readable reason of domain errors, the same
+// reason gRPC clients receive in google.rpc.ErrorInfo.
+type Details struct {
+	Type     string `json:"type" example:"about:blank"`
+	Title    string `json:"title" example:"Not Found"`
+	Status   int    `json:"status" example:"404"`
+	Detail   string `json:"detail,omitempty" example:"event not found"`
+	Instance string `json:"instance,omitempty" example:"/events/42"`
+	Reason   string `json:"reason,omitempty" example:"EVENT_NOT_FOUND"`
+}
+
+// kindStatuses gives the HTTP status of each kind of domain error.
+// ErrAlreadyExists is a conflict, so it needs no entry of its own.
+var kindStatuses = []struct {
+	kind   error
+	status int
+}{
+	{services.ErrNotFound, http.StatusNotFound},
+	{services.ErrForbidden, http.StatusForbidden},
+	{services.ErrConflict, http.StatusConflict},
+	{services.ErrValidation, http.StatusBadRequest},
+	{services.ErrUnauthenticated, http.StatusUnauthorized},
+}
+
+// New creates the problem details of a request failing with status
+func New(c *gin.Context, status int, detail string) Details {
+	return Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+	}
+}
+
+// FromError creates the problem details of err. Domain errors, including the
+// known errors of the models, get the status of their kind; any other error
+// is logged and reported as an internal error, without its message.
+func FromError(c *gin.Context, err error) Details {
+	if domainErr, ok := services.AsError(err); ok {
+		for _, kind := range kindStatuses {
+			if errors.Is(domainErr.Kind, kind.kind) {
+				details := New(c, kind.status, domainErr.Message)
+				details.Reason = domainErr.Reason
+				return details
+			}
+		}
+	}
+	log.Printf("%s %s: internal error: %v", c.Request.Method, c.Request.URL.Path, err)
+	return New(c, http.StatusInternalServerError, "internal server error")
+}
+
+// Write responds with a problem of the given status and detail
+func Write(c *gin.Context, status int, detail string) {
+	Render(c, New(c, status, detail))
+}
+
+// Error responds with the problem details of err
+func Error(c *gin.Context, err error) {
+	Render(c, FromError(c, err))
+}
+
+// Abort responds with a problem of the given status and detail and stops the
+// remaining handlers, for use in middleware
+func Abort(c *gin.Context, status int, detail string) {
+	c.Abort()
+	Write(c, status, detail)
+}
+
+// AbortError responds with the problem details of err and stops the
+// remaining handlers, for use in middleware
+func AbortError(c *gin.Context, err error) {
+	c.Abort()
+	Error(c, err)
+}
+
+// Render writes problem details as the response
+func Render(c *gin.Context, details Details) {
+	c.Header("Content-Type", ContentType)
+	c.JSON(details.Status, details)
+}
@@ -0,0 +1,87 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// respond runs handler for a request to path and returns the response
+func respond(t *testing.T, path string, handler gin.HandlerFunc) (*httptest.ResponseRecorder, Details) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET(path, handler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var details Details
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &details))
+	return recorder, details
+}
+
+func TestError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		reason string
+	}{
+		{models.ErrEventNotFound, http.StatusNotFound, "EVENT_NOT_FOUND"},
+		{services.Forbidden("PERMISSION_DENIED", "not yours"), http.StatusForbidden, "PERMISSION_DENIED"},
+		{models.ErrEmailTaken, http.StatusConflict, "EMAIL_TAKEN"},
+		{models.ErrAlreadyRegistered, http.StatusConflict, "ALREADY_REGISTERED"},
+		{models.ErrRegistrationNotFound, http.StatusNotFound, "REGISTRATION_NOT_FOUND"},
+		{models.ErrInvalidTransition, http.StatusConflict, "INVALID_TRANSITION"},
+		{models.ErrInvalidScope, http.StatusBadRequest, "INVALID_SCOPE"},
+		{models.ErrTokenRevoked, http.StatusUnauthorized, "TOKEN_REVOKED"},
+	}
+	for _, test := range tests {
+		t.Run(test.reason, func(t *testing.T) {
+			recorder, details := respond(t, "/events/42", func(c *gin.Context) { Error(c, test.err) })
+			assert.Equal(t, test.status, recorder.Code)
+			assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+			assert.Equal(t, Details{
+				Type:     "about:blank",
+				Title:    http.StatusText(test.status),
+				Status:   test.status,
+				Detail:   test.err.Error(),
+				Instance: "/events/42",
+				Reason:   test.reason,
+			}, details)
+		})
+	}
+}
+
+func TestError_Internal(t *testing.T) {
+	recorder, details := respond(t, "/events", func(c *gin.Context) {
+		Error(c, errors.New("dial tcp 10.0.0.1:5432: connection refused"))
+	})
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	// The cause is logged, not sent to the client
+	assert.Equal(t, "internal server error", details.Detail)
+	assert.Empty(t, details.Reason)
+}
+
+func TestAbort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	reached := false
+	router.GET("/private", func(c *gin.Context) {
+		Abort(c, http.StatusUnauthorized, "Authorization header is required")
+	}, func(c *gin.Context) { reached = true })
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/private", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.False(t, reached)
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.8
-// 	protoc        v3.21.12
+// 	protoc        v4.25.3
 // source: proto/event.proto
 
 package event
@@ -22,33 +22,2089 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// RecurrenceScope selects which occurrences of a recurring event an operation applies to
+type RecurrenceScope int32
+
+const (
+	RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED RecurrenceScope = 0 // occurrence when occurrence_start is set, series otherwise
+	RecurrenceScope_RECURRENCE_SCOPE_OCCURRENCE  RecurrenceScope = 1
+	RecurrenceScope_RECURRENCE_SCOPE_FOLLOWING   RecurrenceScope = 2
+	RecurrenceScope_RECURRENCE_SCOPE_SERIES      RecurrenceScope = 3
+)
+
+// Enum value maps for RecurrenceScope.
+var (
+	RecurrenceScope_name = map[int32]string{
+		0: "RECURRENCE_SCOPE_UNSPECIFIED",
+		1: "RECURRENCE_SCOPE_OCCURRENCE",
+		2: "RECURRENCE_SCOPE_FOLLOWING",
+		3: "RECURRENCE_SCOPE_SERIES",
+	}
+	RecurrenceScope_value = map[string]int32{
+		"RECURRENCE_SCOPE_UNSPECIFIED": 0,
+		"RECURRENCE_SCOPE_OCCURRENCE":  1,
+		"RECURRENCE_SCOPE_FOLLOWING":   2,
+		"RECURRENCE_SCOPE_SERIES":      3,
+	}
+)
+
+func (x RecurrenceScope) Enum() *RecurrenceScope {
+	p := new(RecurrenceScope)
+	*p = x
+	return p
+}
+
+func (x RecurrenceScope) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecurrenceScope) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_event_proto_enumTypes[0].Descriptor()
+}
+
+func (RecurrenceScope) Type() protoreflect.EnumType {
+	return &file_proto_event_proto_enumTypes[0]
+}
+
+func (x RecurrenceScope) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecurrenceScope.Descriptor instead.
+func (RecurrenceScope) EnumDescriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{0}
+}
+
+// EventSort selects the field events are ordered by
+type EventSort int32
+
+const (
+	EventSort_EVENT_SORT_UNSPECIFIED EventSort = 0 // date_time
+	EventSort_EVENT_SORT_DATE_TIME   EventSort = 1
+	EventSort_EVENT_SORT_NAME        EventSort = 2
+)
+
+// Enum value maps for EventSort.
+var (
+	EventSort_name = map[int32]string{
+		0: "EVENT_SORT_UNSPECIFIED",
+		1: "EVENT_SORT_DATE_TIME",
+		2: "EVENT_SORT_NAME",
+	}
+	EventSort_value = map[string]int32{
+		"EVENT_SORT_UNSPECIFIED": 0,
+		"EVENT_SORT_DATE_TIME":   1,
+		"EVENT_SORT_NAME":        2,
+	}
+)
+
+func (x EventSort) Enum() *EventSort {
+	p := new(EventSort)
+	*p = x
+	return p
+}
+
+func (x EventSort) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventSort) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_event_proto_enumTypes[1].Descriptor()
+}
+
+func (EventSort) Type() protoreflect.EnumType {
+	return &file_proto_event_proto_enumTypes[1]
+}
+
+func (x EventSort) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventSort.Descriptor instead.
+func (EventSort) EnumDescriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{1}
+}
+
 type Event struct {
+	state           protoimpl.MessageState   `protogen:"open.v1"`
+	Id              int64                    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description     string                   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Location        string                   `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	DateTime        *timestamppb.Timestamp   `protobuf:"bytes,5,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	UserId          int64                    `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Capacity        int32                    `protobuf:"varint,7,opt,name=capacity,proto3" json:"capacity,omitempty"` // 0 means unlimited
+	Rrule           string                   `protobuf:"bytes,8,opt,name=rrule,proto3" json:"rrule,omitempty"`        // RFC 5545 recurrence rule, empty for one-off events
+	Exdates         []*timestamppb.Timestamp `protobuf:"bytes,9,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp   `protobuf:"bytes,10,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"` // set on expanded occurrences
+	Latitude        *float64                 `protobuf:"fixed64,11,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`                              // WGS 84, set together with longitude
+	Longitude       *float64                 `protobuf:"fixed64,12,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	Address         *Address                 `protobuf:"bytes,13,opt,name=address,proto3" json:"address,omitempty"`
+	Status          string                   `protobuf:"bytes,14,opt,name=status,proto3" json:"status,omitempty"` // "draft", "published", "cancelled" or "completed"
+	CancelReason    string                   `protobuf:"bytes,15,opt,name=cancel_reason,json=cancelReason,proto3" json:"cancel_reason,omitempty"`
+	PublishedAt     *timestamppb.Timestamp   `protobuf:"bytes,16,opt,name=published_at,json=publishedAt,proto3" json:"published_at,omitempty"`
+	CancelledAt     *timestamppb.Timestamp   `protobuf:"bytes,17,opt,name=cancelled_at,json=cancelledAt,proto3" json:"cancelled_at,omitempty"`
+	CompletedAt     *timestamppb.Timestamp   `protobuf:"bytes,18,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_proto_event_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Event) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Event) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Event) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *Event) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Event) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *Event) GetRrule() string {
+	if x != nil {
+		return x.Rrule
+	}
+	return ""
+}
+
+func (x *Event) GetExdates() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.Exdates
+	}
+	return nil
+}
+
+func (x *Event) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *Event) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *Event) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *Event) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *Event) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Event) GetCancelReason() string {
+	if x != nil {
+		return x.CancelReason
+	}
+	return ""
+}
+
+func (x *Event) GetPublishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PublishedAt
+	}
+	return nil
+}
+
+func (x *Event) GetCancelledAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CancelledAt
+	}
+	return nil
+}
+
+func (x *Event) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+// Address is the structured postal address of an event venue
+type Address struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Street        string                 `protobuf:"bytes,1,opt,name=street,proto3" json:"street,omitempty"`
+	City          string                 `protobuf:"bytes,2,opt,name=city,proto3" json:"city,omitempty"`
+	Region        string                 `protobuf:"bytes,3,opt,name=region,proto3" json:"region,omitempty"`
+	PostalCode    string                 `protobuf:"bytes,4,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Country       string                 `protobuf:"bytes,5,opt,name=country,proto3" json:"country,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_proto_event_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Address) GetStreet() string {
+	if x != nil {
+		return x.Street
+	}
+	return ""
+}
+
+func (x *Address) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Address) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Address) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Address) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+// Registration is a user's registration for an event
+type Registration struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId          int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	EventId         int64                  `protobuf:"varint,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Status          string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`                                          // "confirmed", "waitlisted" or "cancelled"
+	OccurrenceStart *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"` // set when a single occurrence is registered for
+	Scope           string                 `protobuf:"bytes,6,opt,name=scope,proto3" json:"scope,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // when the status or occurrence last changed
+	History         []*RegistrationChange  `protobuf:"bytes,9,rep,name=history,proto3" json:"history,omitempty"`                      // the statuses it has had, oldest first; only set by GetRegistrations
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Registration) Reset() {
+	*x = Registration{}
+	mi := &file_proto_event_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Registration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Registration) ProtoMessage() {}
+
+func (x *Registration) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Registration.ProtoReflect.Descriptor instead.
+func (*Registration) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Registration) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Registration) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Registration) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *Registration) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Registration) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *Registration) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Registration) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Registration) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Registration) GetHistory() []*RegistrationChange {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
+// RegistrationChange is an entry of the status history of a registration
+type RegistrationChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	ChangedAt     *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=changed_at,json=changedAt,proto3" json:"changed_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegistrationChange) Reset() {
+	*x = RegistrationChange{}
+	mi := &file_proto_event_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegistrationChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegistrationChange) ProtoMessage() {}
+
+func (x *RegistrationChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegistrationChange.ProtoReflect.Descriptor instead.
+func (*RegistrationChange) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegistrationChange) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RegistrationChange) GetChangedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ChangedAt
+	}
+	return nil
+}
+
+// EventEnvelope is the versioned message published to Kafka when an event or
+// one of its registrations changes
+type EventEnvelope struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Type  string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"` // the change: "created", "updated", "deleted", "published", "cancelled", "completed",
+	// "registration_created", "registration_cancelled", "registration_promoted" or "registration_removed"
+	SchemaVersion uint32                 `protobuf:"varint,2,opt,name=schema_version,json=schemaVersion,proto3" json:"schema_version,omitempty"` // 0 for messages decoded from the legacy JSON format
+	EventId       string                 `protobuf:"bytes,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`                    // unique ID of this change, the same on redelivery
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Producer      string                 `protobuf:"bytes,5,opt,name=producer,proto3" json:"producer,omitempty"`              // name of the service that published the change
+	TraceId       string                 `protobuf:"bytes,6,opt,name=trace_id,json=traceId,proto3" json:"trace_id,omitempty"` // W3C trace ID correlating the change with its request
+	Event         *Event                 `protobuf:"bytes,7,opt,name=event,proto3" json:"event,omitempty"`                    // the event after the change
+	Registration  *Registration          `protobuf:"bytes,8,opt,name=registration,proto3" json:"registration,omitempty"`      // the registration after the change, for registration changes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventEnvelope) Reset() {
+	*x = EventEnvelope{}
+	mi := &file_proto_event_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventEnvelope) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventEnvelope) ProtoMessage() {}
+
+func (x *EventEnvelope) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventEnvelope.ProtoReflect.Descriptor instead.
+func (*EventEnvelope) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EventEnvelope) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *EventEnvelope) GetSchemaVersion() uint32 {
+	if x != nil {
+		return x.SchemaVersion
+	}
+	return 0
+}
+
+func (x *EventEnvelope) GetEventId() string {
+	if x != nil {
+		return x.EventId
+	}
+	return ""
+}
+
+func (x *EventEnvelope) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *EventEnvelope) GetProducer() string {
+	if x != nil {
+		return x.Producer
+	}
+	return ""
+}
+
+func (x *EventEnvelope) GetTraceId() string {
+	if x != nil {
+		return x.TraceId
+	}
+	return ""
+}
+
+func (x *EventEnvelope) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *EventEnvelope) GetRegistration() *Registration {
+	if x != nil {
+		return x.Registration
+	}
+	return nil
+}
+
+// When both from and to are set, recurring events are expanded into occurrences within [from, to)
+type GetEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Location      string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`            // case-insensitive substring match
+	UserId        int64                  `protobuf:"varint,4,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // owner, 0 for any
+	SortBy        EventSort              `protobuf:"varint,5,opt,name=sort_by,json=sortBy,proto3,enum=event.EventSort" json:"sort_by,omitempty"`
+	Descending    bool                   `protobuf:"varint,6,opt,name=descending,proto3" json:"descending,omitempty"`
+	PageSize      int32                  `protobuf:"varint,7,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // default 20, max 100
+	PageToken     string                 `protobuf:"bytes,8,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token from the previous response
+	Status        string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`                        // lifecycle status, empty for any; drafts are only listed for their owner
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventsRequest) Reset() {
+	*x = GetEventsRequest{}
+	mi := &file_proto_event_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventsRequest) ProtoMessage() {}
+
+func (x *GetEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventsRequest.ProtoReflect.Descriptor instead.
+func (*GetEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetEventsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *GetEventsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *GetEventsRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *GetEventsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *GetEventsRequest) GetSortBy() EventSort {
+	if x != nil {
+		return x.SortBy
+	}
+	return EventSort_EVENT_SORT_UNSPECIFIED
+}
+
+func (x *GetEventsRequest) GetDescending() bool {
+	if x != nil {
+		return x.Descending
+	}
+	return false
+}
+
+func (x *GetEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *GetEventsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetEventsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventsResponse) Reset() {
+	*x = GetEventsResponse{}
+	mi := &file_proto_event_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventsResponse) ProtoMessage() {}
+
+func (x *GetEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventsResponse.ProtoReflect.Descriptor instead.
+func (*GetEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetEventsResponse) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetEventsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventRequest) Reset() {
+	*x = GetEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventRequest) ProtoMessage() {}
+
+func (x *GetEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventRequest.ProtoReflect.Descriptor instead.
+func (*GetEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventResponse) Reset() {
+	*x = GetEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventResponse) ProtoMessage() {}
+
+func (x *GetEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventResponse.ProtoReflect.Descriptor instead.
+func (*GetEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type CreateEventRequest struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Name          string                   `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                   `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Location      string                   `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
+	DateTime      *timestamppb.Timestamp   `protobuf:"bytes,4,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Capacity      int32                    `protobuf:"varint,5,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Rrule         string                   `protobuf:"bytes,6,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	Exdates       []*timestamppb.Timestamp `protobuf:"bytes,7,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	Latitude      *float64                 `protobuf:"fixed64,8,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude     *float64                 `protobuf:"fixed64,9,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	Address       *Address                 `protobuf:"bytes,10,opt,name=address,proto3" json:"address,omitempty"`
+	Status        string                   `protobuf:"bytes,11,opt,name=status,proto3" json:"status,omitempty"` // "draft" (default) or "published"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEventRequest) Reset() {
+	*x = CreateEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEventRequest) ProtoMessage() {}
+
+func (x *CreateEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEventRequest.ProtoReflect.Descriptor instead.
+func (*CreateEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CreateEventRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *CreateEventRequest) GetCapacity() int32 {
+	if x != nil {
+		return x.Capacity
+	}
+	return 0
+}
+
+func (x *CreateEventRequest) GetRrule() string {
+	if x != nil {
+		return x.Rrule
+	}
+	return ""
+}
+
+func (x *CreateEventRequest) GetExdates() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.Exdates
+	}
+	return nil
+}
+
+func (x *CreateEventRequest) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *CreateEventRequest) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *CreateEventRequest) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+func (x *CreateEventRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type CreateEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEventResponse) Reset() {
+	*x = CreateEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEventResponse) ProtoMessage() {}
+
+func (x *CreateEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEventResponse.ProtoReflect.Descriptor instead.
+func (*CreateEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *CreateEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type UpdateEventRequest struct {
+	state           protoimpl.MessageState   `protogen:"open.v1"`
+	Id              int64                    `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name            string                   `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description     string                   `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Location        string                   `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	DateTime        *timestamppb.Timestamp   `protobuf:"bytes,5,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Capacity        *int32                   `protobuf:"varint,6,opt,name=capacity,proto3,oneof" json:"capacity,omitempty"` // 0 means unlimited; omitted keeps the current capacity
+	Rrule           string                   `protobuf:"bytes,7,opt,name=rrule,proto3" json:"rrule,omitempty"`
+	Exdates         []*timestamppb.Timestamp `protobuf:"bytes,8,rep,name=exdates,proto3" json:"exdates,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp   `protobuf:"bytes,9,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"`
+	Scope           RecurrenceScope          `protobuf:"varint,10,opt,name=scope,proto3,enum=event.RecurrenceScope" json:"scope,omitempty"`
+	Latitude        *float64                 `protobuf:"fixed64,11,opt,name=latitude,proto3,oneof" json:"latitude,omitempty"`
+	Longitude       *float64                 `protobuf:"fixed64,12,opt,name=longitude,proto3,oneof" json:"longitude,omitempty"`
+	Address         *Address                 `protobuf:"bytes,13,opt,name=address,proto3" json:"address,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateEventRequest) Reset() {
+	*x = UpdateEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEventRequest) ProtoMessage() {}
+
+func (x *UpdateEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEventRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetDateTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DateTime
+	}
+	return nil
+}
+
+func (x *UpdateEventRequest) GetCapacity() int32 {
+	if x != nil && x.Capacity != nil {
+		return *x.Capacity
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetRrule() string {
+	if x != nil {
+		return x.Rrule
+	}
+	return ""
+}
+
+func (x *UpdateEventRequest) GetExdates() []*timestamppb.Timestamp {
+	if x != nil {
+		return x.Exdates
+	}
+	return nil
+}
+
+func (x *UpdateEventRequest) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *UpdateEventRequest) GetScope() RecurrenceScope {
+	if x != nil {
+		return x.Scope
+	}
+	return RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED
+}
+
+func (x *UpdateEventRequest) GetLatitude() float64 {
+	if x != nil && x.Latitude != nil {
+		return *x.Latitude
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetLongitude() float64 {
+	if x != nil && x.Longitude != nil {
+		return *x.Longitude
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetAddress() *Address {
+	if x != nil {
+		return x.Address
+	}
+	return nil
+}
+
+type UpdateEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEventResponse) Reset() {
+	*x = UpdateEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEventResponse) ProtoMessage() {}
+
+func (x *UpdateEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEventResponse.ProtoReflect.Descriptor instead.
+func (*UpdateEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *UpdateEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type DeleteEventRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"`
+	Scope           RecurrenceScope        `protobuf:"varint,3,opt,name=scope,proto3,enum=event.RecurrenceScope" json:"scope,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeleteEventRequest) Reset() {
+	*x = DeleteEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventRequest) ProtoMessage() {}
+
+func (x *DeleteEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventRequest.ProtoReflect.Descriptor instead.
+func (*DeleteEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteEventRequest) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *DeleteEventRequest) GetScope() RecurrenceScope {
+	if x != nil {
+		return x.Scope
+	}
+	return RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED
+}
+
+type DeleteEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEventResponse) Reset() {
+	*x = DeleteEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventResponse) ProtoMessage() {}
+
+func (x *DeleteEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventResponse.ProtoReflect.Descriptor instead.
+func (*DeleteEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{14}
+}
+
+type RegisterForEventRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EventId         int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"`
+	Scope           RecurrenceScope        `protobuf:"varint,3,opt,name=scope,proto3,enum=event.RecurrenceScope" json:"scope,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RegisterForEventRequest) Reset() {
+	*x = RegisterForEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterForEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterForEventRequest) ProtoMessage() {}
+
+func (x *RegisterForEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterForEventRequest.ProtoReflect.Descriptor instead.
+func (*RegisterForEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *RegisterForEventRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *RegisterForEventRequest) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *RegisterForEventRequest) GetScope() RecurrenceScope {
+	if x != nil {
+		return x.Scope
+	}
+	return RecurrenceScope_RECURRENCE_SCOPE_UNSPECIFIED
+}
+
+type RegisterForEventResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Status           string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // "confirmed" or "waitlisted"
+	WaitlistPosition int64                  `protobuf:"varint,2,opt,name=waitlist_position,json=waitlistPosition,proto3" json:"waitlist_position,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *RegisterForEventResponse) Reset() {
+	*x = RegisterForEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterForEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterForEventResponse) ProtoMessage() {}
+
+func (x *RegisterForEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterForEventResponse.ProtoReflect.Descriptor instead.
+func (*RegisterForEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RegisterForEventResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *RegisterForEventResponse) GetWaitlistPosition() int64 {
+	if x != nil {
+		return x.WaitlistPosition
+	}
+	return 0
+}
+
+type CancelRegistrationRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	EventId         int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CancelRegistrationRequest) Reset() {
+	*x = CancelRegistrationRequest{}
+	mi := &file_proto_event_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRegistrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRegistrationRequest) ProtoMessage() {}
+
+func (x *CancelRegistrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRegistrationRequest.ProtoReflect.Descriptor instead.
+func (*CancelRegistrationRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *CancelRegistrationRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *CancelRegistrationRequest) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+type CancelRegistrationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRegistrationResponse) Reset() {
+	*x = CancelRegistrationResponse{}
+	mi := &file_proto_event_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRegistrationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRegistrationResponse) ProtoMessage() {}
+
+func (x *CancelRegistrationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRegistrationResponse.ProtoReflect.Descriptor instead.
+func (*CancelRegistrationResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{18}
+}
+
+// GetRegistrationsRequest asks for the caller's own registrations for an event
+type GetRegistrationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistrationsRequest) Reset() {
+	*x = GetRegistrationsRequest{}
+	mi := &file_proto_event_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistrationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistrationsRequest) ProtoMessage() {}
+
+func (x *GetRegistrationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistrationsRequest.ProtoReflect.Descriptor instead.
+func (*GetRegistrationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetRegistrationsRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type GetRegistrationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Registrations []*Registration        `protobuf:"bytes,1,rep,name=registrations,proto3" json:"registrations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRegistrationsResponse) Reset() {
+	*x = GetRegistrationsResponse{}
+	mi := &file_proto_event_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRegistrationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRegistrationsResponse) ProtoMessage() {}
+
+func (x *GetRegistrationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRegistrationsResponse.ProtoReflect.Descriptor instead.
+func (*GetRegistrationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetRegistrationsResponse) GetRegistrations() []*Registration {
+	if x != nil {
+		return x.Registrations
+	}
+	return nil
+}
+
+// ListEventAttendeesRequest pages through the registrations for an event owned by the caller
+type ListEventAttendeesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                        // "confirmed" or "waitlisted"; empty lists both
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // default 20, max 100
+	PageToken     string                 `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token from the previous page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventAttendeesRequest) Reset() {
+	*x = ListEventAttendeesRequest{}
+	mi := &file_proto_event_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventAttendeesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventAttendeesRequest) ProtoMessage() {}
+
+func (x *ListEventAttendeesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventAttendeesRequest.ProtoReflect.Descriptor instead.
+func (*ListEventAttendeesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ListEventAttendeesRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *ListEventAttendeesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListEventAttendeesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListEventAttendeesRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Attendee is a registration for an event, as its owner sees it
+type Attendee struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RegistrationId  int64                  `protobuf:"varint,1,opt,name=registration_id,json=registrationId,proto3" json:"registration_id,omitempty"`
+	UserId          int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Email           string                 `protobuf:"bytes,3,opt,name=email,proto3" json:"email,omitempty"`
+	Status          string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	OccurrenceStart *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=occurrence_start,json=occurrenceStart,proto3" json:"occurrence_start,omitempty"` // set when a single occurrence is registered for
+	Scope           string                 `protobuf:"bytes,6,opt,name=scope,proto3" json:"scope,omitempty"`
+	RegisteredAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=registered_at,json=registeredAt,proto3" json:"registered_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Attendee) Reset() {
+	*x = Attendee{}
+	mi := &file_proto_event_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attendee) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attendee) ProtoMessage() {}
+
+func (x *Attendee) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attendee.ProtoReflect.Descriptor instead.
+func (*Attendee) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Attendee) GetRegistrationId() int64 {
+	if x != nil {
+		return x.RegistrationId
+	}
+	return 0
+}
+
+func (x *Attendee) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Attendee) GetEmail() string {
+	if x != nil {
+		return x.Email
+	}
+	return ""
+}
+
+func (x *Attendee) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Attendee) GetOccurrenceStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurrenceStart
+	}
+	return nil
+}
+
+func (x *Attendee) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Attendee) GetRegisteredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.RegisteredAt
+	}
+	return nil
+}
+
+func (x *Attendee) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ListEventAttendeesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Attendees     []*Attendee            `protobuf:"bytes,1,rep,name=attendees,proto3" json:"attendees,omitempty"`                                // in the order they registered
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventAttendeesResponse) Reset() {
+	*x = ListEventAttendeesResponse{}
+	mi := &file_proto_event_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventAttendeesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventAttendeesResponse) ProtoMessage() {}
+
+func (x *ListEventAttendeesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventAttendeesResponse.ProtoReflect.Descriptor instead.
+func (*ListEventAttendeesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListEventAttendeesResponse) GetAttendees() []*Attendee {
+	if x != nil {
+		return x.Attendees
+	}
+	return nil
+}
+
+func (x *ListEventAttendeesResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// RemoveAttendeeRequest removes a registration for an event owned by the caller
+type RemoveAttendeeRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	EventId        int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	RegistrationId int64                  `protobuf:"varint,2,opt,name=registration_id,json=registrationId,proto3" json:"registration_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RemoveAttendeeRequest) Reset() {
+	*x = RemoveAttendeeRequest{}
+	mi := &file_proto_event_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveAttendeeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveAttendeeRequest) ProtoMessage() {}
+
+func (x *RemoveAttendeeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveAttendeeRequest.ProtoReflect.Descriptor instead.
+func (*RemoveAttendeeRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RemoveAttendeeRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *RemoveAttendeeRequest) GetRegistrationId() int64 {
+	if x != nil {
+		return x.RegistrationId
+	}
+	return 0
+}
+
+type RemoveAttendeeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveAttendeeResponse) Reset() {
+	*x = RemoveAttendeeResponse{}
+	mi := &file_proto_event_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveAttendeeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveAttendeeResponse) ProtoMessage() {}
+
+func (x *RemoveAttendeeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveAttendeeResponse.ProtoReflect.Descriptor instead.
+func (*RemoveAttendeeResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{25}
+}
+
+type GetUserRegistrationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRegistrationsRequest) Reset() {
+	*x = GetUserRegistrationsRequest{}
+	mi := &file_proto_event_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRegistrationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRegistrationsRequest) ProtoMessage() {}
+
+func (x *GetUserRegistrationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRegistrationsRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRegistrationsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{26}
+}
+
+type GetUserRegistrationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRegistrationsResponse) Reset() {
+	*x = GetUserRegistrationsResponse{}
+	mi := &file_proto_event_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRegistrationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRegistrationsResponse) ProtoMessage() {}
+
+func (x *GetUserRegistrationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRegistrationsResponse.ProtoReflect.Descriptor instead.
+func (*GetUserRegistrationsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetUserRegistrationsResponse) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+// ImportEventsRequest carries a chunk of an iCalendar (.ics) document. Chunks are
+// concatenated in order; dry_run is taken from the first message.
+type ImportEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	DryRun        bool                   `protobuf:"varint,2,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportEventsRequest) Reset() {
+	*x = ImportEventsRequest{}
+	mi := &file_proto_event_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportEventsRequest) ProtoMessage() {}
+
+func (x *ImportEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportEventsRequest.ProtoReflect.Descriptor instead.
+func (*ImportEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ImportEventsRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *ImportEventsRequest) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+type ImportResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Location      string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
-	DateTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
-	UserId        int64                  `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Index         int32                  `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"` // position of the VEVENT in the document
+	Uid           string                 `protobuf:"bytes,2,opt,name=uid,proto3" json:"uid,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Status        string                 `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"` // "created", "valid" (dry run) or "failed"
+	Event         *Event                 `protobuf:"bytes,5,opt,name=event,proto3" json:"event,omitempty"`
+	Error         string                 `protobuf:"bytes,6,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *Event) Reset() {
-	*x = Event{}
-	mi := &file_proto_event_proto_msgTypes[0]
+func (x *ImportResult) Reset() {
+	*x = ImportResult{}
+	mi := &file_proto_event_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *Event) String() string {
+func (x *ImportResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Event) ProtoMessage() {}
+func (*ImportResult) ProtoMessage() {}
 
-func (x *Event) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[0]
+func (x *ImportResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -59,74 +2115,78 @@ func (x *Event) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Event.ProtoReflect.Descriptor instead.
-func (*Event) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{0}
+// Deprecated: Use ImportResult.ProtoReflect.Descriptor instead.
+func (*ImportResult) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *Event) GetId() int64 {
+func (x *ImportResult) GetIndex() int32 {
 	if x != nil {
-		return x.Id
+		return x.Index
 	}
 	return 0
 }
 
-func (x *Event) GetName() string {
+func (x *ImportResult) GetUid() string {
 	if x != nil {
-		return x.Name
+		return x.Uid
 	}
 	return ""
 }
 
-func (x *Event) GetDescription() string {
+func (x *ImportResult) GetName() string {
 	if x != nil {
-		return x.Description
+		return x.Name
 	}
 	return ""
 }
 
-func (x *Event) GetLocation() string {
+func (x *ImportResult) GetStatus() string {
 	if x != nil {
-		return x.Location
+		return x.Status
 	}
 	return ""
 }
 
-func (x *Event) GetDateTime() *timestamppb.Timestamp {
+func (x *ImportResult) GetEvent() *Event {
 	if x != nil {
-		return x.DateTime
+		return x.Event
 	}
 	return nil
 }
 
-func (x *Event) GetUserId() int64 {
+func (x *ImportResult) GetError() string {
 	if x != nil {
-		return x.UserId
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-type GetEventsRequest struct {
+type ImportEventsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ImportResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	Created       int32                  `protobuf:"varint,2,opt,name=created,proto3" json:"created,omitempty"`
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEventsRequest) Reset() {
-	*x = GetEventsRequest{}
-	mi := &file_proto_event_proto_msgTypes[1]
+func (x *ImportEventsResponse) Reset() {
+	*x = ImportEventsResponse{}
+	mi := &file_proto_event_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEventsRequest) String() string {
+func (x *ImportEventsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEventsRequest) ProtoMessage() {}
+func (*ImportEventsResponse) ProtoMessage() {}
 
-func (x *GetEventsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[1]
+func (x *ImportEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -137,77 +2197,63 @@ func (x *GetEventsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEventsRequest.ProtoReflect.Descriptor instead.
-func (*GetEventsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{1}
-}
-
-type GetEventsResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *GetEventsResponse) Reset() {
-	*x = GetEventsResponse{}
-	mi := &file_proto_event_proto_msgTypes[2]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+// Deprecated: Use ImportEventsResponse.ProtoReflect.Descriptor instead.
+func (*ImportEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *GetEventsResponse) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ImportEventsResponse) GetResults() []*ImportResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
 }
 
-func (*GetEventsResponse) ProtoMessage() {}
-
-func (x *GetEventsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[2]
+func (x *ImportEventsResponse) GetCreated() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Created
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use GetEventsResponse.ProtoReflect.Descriptor instead.
-func (*GetEventsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{2}
+func (x *ImportEventsResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
 }
 
-func (x *GetEventsResponse) GetEvents() []*Event {
+func (x *ImportEventsResponse) GetDryRun() bool {
 	if x != nil {
-		return x.Events
+		return x.DryRun
 	}
-	return nil
+	return false
 }
 
-type GetEventRequest struct {
+type SearchEventsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`                          // every word must match name, location or description
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`   // default 20, max 100
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"` // next_page_token from the previous response
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEventRequest) Reset() {
-	*x = GetEventRequest{}
-	mi := &file_proto_event_proto_msgTypes[3]
+func (x *SearchEventsRequest) Reset() {
+	*x = SearchEventsRequest{}
+	mi := &file_proto_event_proto_msgTypes[31]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEventRequest) String() string {
+func (x *SearchEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEventRequest) ProtoMessage() {}
+func (*SearchEventsRequest) ProtoMessage() {}
 
-func (x *GetEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[3]
+func (x *SearchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[31]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -218,40 +2264,57 @@ func (x *GetEventRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEventRequest.ProtoReflect.Descriptor instead.
-func (*GetEventRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{3}
+// Deprecated: Use SearchEventsRequest.ProtoReflect.Descriptor instead.
+func (*SearchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{31}
 }
 
-func (x *GetEventRequest) GetId() int64 {
+func (x *SearchEventsRequest) GetQuery() string {
 	if x != nil {
-		return x.Id
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
 	}
 	return 0
 }
 
-type GetEventResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+func (x *SearchEventsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SearchResult struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Event *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Rank  float64                `protobuf:"fixed64,2,opt,name=rank,proto3" json:"rank,omitempty"`
+	// Matching fields ("name", "description", "location"), HTML-escaped with matches wrapped in <mark>
+	Highlights    map[string]string `protobuf:"bytes,3,rep,name=highlights,proto3" json:"highlights,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetEventResponse) Reset() {
-	*x = GetEventResponse{}
-	mi := &file_proto_event_proto_msgTypes[4]
+func (x *SearchResult) Reset() {
+	*x = SearchResult{}
+	mi := &file_proto_event_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetEventResponse) String() string {
+func (x *SearchResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetEventResponse) ProtoMessage() {}
+func (*SearchResult) ProtoMessage() {}
 
-func (x *GetEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[4]
+func (x *SearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -262,43 +2325,55 @@ func (x *GetEventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetEventResponse.ProtoReflect.Descriptor instead.
-func (*GetEventResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use SearchResult.ProtoReflect.Descriptor instead.
+func (*SearchResult) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *GetEventResponse) GetEvent() *Event {
+func (x *SearchResult) GetEvent() *Event {
 	if x != nil {
 		return x.Event
 	}
 	return nil
 }
 
-type CreateEventRequest struct {
+func (x *SearchResult) GetRank() float64 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *SearchResult) GetHighlights() map[string]string {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+type SearchEventsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
-	Location      string                 `protobuf:"bytes,3,opt,name=location,proto3" json:"location,omitempty"`
-	DateTime      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Results       []*SearchResult        `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`                                    // most relevant first
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // empty on the last page
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateEventRequest) Reset() {
-	*x = CreateEventRequest{}
-	mi := &file_proto_event_proto_msgTypes[5]
+func (x *SearchEventsResponse) Reset() {
+	*x = SearchEventsResponse{}
+	mi := &file_proto_event_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateEventRequest) String() string {
+func (x *SearchEventsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateEventRequest) ProtoMessage() {}
+func (*SearchEventsResponse) ProtoMessage() {}
 
-func (x *CreateEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[5]
+func (x *SearchEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -309,61 +2384,50 @@ func (x *CreateEventRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateEventRequest.ProtoReflect.Descriptor instead.
-func (*CreateEventRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{5}
-}
-
-func (x *CreateEventRequest) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
+// Deprecated: Use SearchEventsResponse.ProtoReflect.Descriptor instead.
+func (*SearchEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *CreateEventRequest) GetDescription() string {
+func (x *SearchEventsResponse) GetResults() []*SearchResult {
 	if x != nil {
-		return x.Description
+		return x.Results
 	}
-	return ""
+	return nil
 }
 
-func (x *CreateEventRequest) GetLocation() string {
+func (x *SearchEventsResponse) GetNextPageToken() string {
 	if x != nil {
-		return x.Location
+		return x.NextPageToken
 	}
 	return ""
 }
 
-func (x *CreateEventRequest) GetDateTime() *timestamppb.Timestamp {
-	if x != nil {
-		return x.DateTime
-	}
-	return nil
-}
-
-type CreateEventResponse struct {
+type GetNearbyEventsRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	RadiusKm      float64                `protobuf:"fixed64,3,opt,name=radius_km,json=radiusKm,proto3" json:"radius_km,omitempty"` // greater than 0, at most 500
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`  // default 20, max 100
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CreateEventResponse) Reset() {
-	*x = CreateEventResponse{}
-	mi := &file_proto_event_proto_msgTypes[6]
+func (x *GetNearbyEventsRequest) Reset() {
+	*x = GetNearbyEventsRequest{}
+	mi := &file_proto_event_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CreateEventResponse) String() string {
+func (x *GetNearbyEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateEventResponse) ProtoMessage() {}
+func (*GetNearbyEventsRequest) ProtoMessage() {}
 
-func (x *CreateEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[6]
+func (x *GetNearbyEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -374,44 +2438,62 @@ func (x *CreateEventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateEventResponse.ProtoReflect.Descriptor instead.
-func (*CreateEventResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{6}
+// Deprecated: Use GetNearbyEventsRequest.ProtoReflect.Descriptor instead.
+func (*GetNearbyEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *CreateEventResponse) GetEvent() *Event {
+func (x *GetNearbyEventsRequest) GetLatitude() float64 {
 	if x != nil {
-		return x.Event
+		return x.Latitude
 	}
-	return nil
+	return 0
 }
 
-type UpdateEventRequest struct {
+func (x *GetNearbyEventsRequest) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *GetNearbyEventsRequest) GetRadiusKm() float64 {
+	if x != nil {
+		return x.RadiusKm
+	}
+	return 0
+}
+
+func (x *GetNearbyEventsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type NearbyEvent struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
-	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
-	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Location      string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
-	DateTime      *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=date_time,json=dateTime,proto3" json:"date_time,omitempty"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	DistanceKm    float64                `protobuf:"fixed64,2,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEventRequest) Reset() {
-	*x = UpdateEventRequest{}
-	mi := &file_proto_event_proto_msgTypes[7]
+func (x *NearbyEvent) Reset() {
+	*x = NearbyEvent{}
+	mi := &file_proto_event_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEventRequest) String() string {
+func (x *NearbyEvent) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEventRequest) ProtoMessage() {}
+func (*NearbyEvent) ProtoMessage() {}
 
-func (x *UpdateEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[7]
+func (x *NearbyEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -422,68 +2504,47 @@ func (x *UpdateEventRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEventRequest.ProtoReflect.Descriptor instead.
-func (*UpdateEventRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{7}
-}
-
-func (x *UpdateEventRequest) GetId() int64 {
-	if x != nil {
-		return x.Id
-	}
-	return 0
-}
-
-func (x *UpdateEventRequest) GetName() string {
-	if x != nil {
-		return x.Name
-	}
-	return ""
-}
-
-func (x *UpdateEventRequest) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
+// Deprecated: Use NearbyEvent.ProtoReflect.Descriptor instead.
+func (*NearbyEvent) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *UpdateEventRequest) GetLocation() string {
+func (x *NearbyEvent) GetEvent() *Event {
 	if x != nil {
-		return x.Location
+		return x.Event
 	}
-	return ""
+	return nil
 }
 
-func (x *UpdateEventRequest) GetDateTime() *timestamppb.Timestamp {
+func (x *NearbyEvent) GetDistanceKm() float64 {
 	if x != nil {
-		return x.DateTime
+		return x.DistanceKm
 	}
-	return nil
+	return 0
 }
 
-type UpdateEventResponse struct {
+type GetNearbyEventsResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Events        []*NearbyEvent         `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"` // nearest first
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateEventResponse) Reset() {
-	*x = UpdateEventResponse{}
-	mi := &file_proto_event_proto_msgTypes[8]
+func (x *GetNearbyEventsResponse) Reset() {
+	*x = GetNearbyEventsResponse{}
+	mi := &file_proto_event_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateEventResponse) String() string {
+func (x *GetNearbyEventsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateEventResponse) ProtoMessage() {}
+func (*GetNearbyEventsResponse) ProtoMessage() {}
 
-func (x *UpdateEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[8]
+func (x *GetNearbyEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -494,40 +2555,40 @@ func (x *UpdateEventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateEventResponse.ProtoReflect.Descriptor instead.
-func (*UpdateEventResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use GetNearbyEventsResponse.ProtoReflect.Descriptor instead.
+func (*GetNearbyEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{36}
 }
 
-func (x *UpdateEventResponse) GetEvent() *Event {
+func (x *GetNearbyEventsResponse) GetEvents() []*NearbyEvent {
 	if x != nil {
-		return x.Event
+		return x.Events
 	}
 	return nil
 }
 
-type DeleteEventRequest struct {
+type PublishEventRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteEventRequest) Reset() {
-	*x = DeleteEventRequest{}
-	mi := &file_proto_event_proto_msgTypes[9]
+func (x *PublishEventRequest) Reset() {
+	*x = PublishEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteEventRequest) String() string {
+func (x *PublishEventRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteEventRequest) ProtoMessage() {}
+func (*PublishEventRequest) ProtoMessage() {}
 
-func (x *DeleteEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[9]
+func (x *PublishEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -538,39 +2599,40 @@ func (x *DeleteEventRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteEventRequest.ProtoReflect.Descriptor instead.
-func (*DeleteEventRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use PublishEventRequest.ProtoReflect.Descriptor instead.
+func (*PublishEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{37}
 }
 
-func (x *DeleteEventRequest) GetId() int64 {
+func (x *PublishEventRequest) GetId() int64 {
 	if x != nil {
 		return x.Id
 	}
 	return 0
 }
 
-type DeleteEventResponse struct {
+type PublishEventResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteEventResponse) Reset() {
-	*x = DeleteEventResponse{}
-	mi := &file_proto_event_proto_msgTypes[10]
+func (x *PublishEventResponse) Reset() {
+	*x = PublishEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[38]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteEventResponse) String() string {
+func (x *PublishEventResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteEventResponse) ProtoMessage() {}
+func (*PublishEventResponse) ProtoMessage() {}
 
-func (x *DeleteEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[10]
+func (x *PublishEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[38]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -581,33 +2643,41 @@ func (x *DeleteEventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteEventResponse.ProtoReflect.Descriptor instead.
-func (*DeleteEventResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{10}
+// Deprecated: Use PublishEventResponse.ProtoReflect.Descriptor instead.
+func (*PublishEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{38}
 }
 
-type RegisterForEventRequest struct {
+func (x *PublishEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type CancelEventRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"` // required, at most 500 characters
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterForEventRequest) Reset() {
-	*x = RegisterForEventRequest{}
-	mi := &file_proto_event_proto_msgTypes[11]
+func (x *CancelEventRequest) Reset() {
+	*x = CancelEventRequest{}
+	mi := &file_proto_event_proto_msgTypes[39]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterForEventRequest) String() string {
+func (x *CancelEventRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterForEventRequest) ProtoMessage() {}
+func (*CancelEventRequest) ProtoMessage() {}
 
-func (x *RegisterForEventRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[11]
+func (x *CancelEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[39]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -618,39 +2688,47 @@ func (x *RegisterForEventRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterForEventRequest.ProtoReflect.Descriptor instead.
-func (*RegisterForEventRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use CancelEventRequest.ProtoReflect.Descriptor instead.
+func (*CancelEventRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{39}
 }
 
-func (x *RegisterForEventRequest) GetEventId() int64 {
+func (x *CancelEventRequest) GetId() int64 {
 	if x != nil {
-		return x.EventId
+		return x.Id
 	}
 	return 0
 }
 
-type RegisterForEventResponse struct {
+func (x *CancelEventRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type CancelEventResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *Event                 `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RegisterForEventResponse) Reset() {
-	*x = RegisterForEventResponse{}
-	mi := &file_proto_event_proto_msgTypes[12]
+func (x *CancelEventResponse) Reset() {
+	*x = CancelEventResponse{}
+	mi := &file_proto_event_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RegisterForEventResponse) String() string {
+func (x *CancelEventResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RegisterForEventResponse) ProtoMessage() {}
+func (*CancelEventResponse) ProtoMessage() {}
 
-func (x *RegisterForEventResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[12]
+func (x *CancelEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -661,33 +2739,50 @@ func (x *RegisterForEventResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RegisterForEventResponse.ProtoReflect.Descriptor instead.
-func (*RegisterForEventResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use CancelEventResponse.ProtoReflect.Descriptor instead.
+func (*CancelEventResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{40}
 }
 
-type CancelRegistrationRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+func (x *CancelEventResponse) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Filters are combined: a change is sent when it matches all of those set.
+// Changes of drafts are only sent to their owner.
+type WatchEventsRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	EventIds []int64                `protobuf:"varint,1,rep,packed,name=event_ids,json=eventIds,proto3" json:"event_ids,omitempty"` // only changes of these events
+	UserId   int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`              // only changes of events owned by this user
+	Actions  []string               `protobuf:"bytes,3,rep,name=actions,proto3" json:"actions,omitempty"`                           // only these actions: "created", "updated", "deleted", "published", "cancelled" or "completed"
+	// Resume after the change with this cursor, from a change or heartbeat.
+	// Cursors are only resumable on the server instance that issued them, for as
+	// long as it runs and keeps the change in its history: they are not durable,
+	// so resuming after a restart or on another replica always starts with a
+	// Resync.
+	Cursor        string `protobuf:"bytes,4,opt,name=cursor,proto3" json:"cursor,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelRegistrationRequest) Reset() {
-	*x = CancelRegistrationRequest{}
-	mi := &file_proto_event_proto_msgTypes[13]
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_proto_event_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelRegistrationRequest) String() string {
+func (x *WatchEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelRegistrationRequest) ProtoMessage() {}
+func (*WatchEventsRequest) ProtoMessage() {}
 
-func (x *CancelRegistrationRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[13]
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -698,39 +2793,66 @@ func (x *CancelRegistrationRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelRegistrationRequest.ProtoReflect.Descriptor instead.
-func (*CancelRegistrationRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{41}
 }
 
-func (x *CancelRegistrationRequest) GetEventId() int64 {
+func (x *WatchEventsRequest) GetEventIds() []int64 {
 	if x != nil {
-		return x.EventId
+		return x.EventIds
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
 	}
 	return 0
 }
 
-type CancelRegistrationResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+func (x *WatchEventsRequest) GetActions() []string {
+	if x != nil {
+		return x.Actions
+	}
+	return nil
+}
+
+func (x *WatchEventsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+type WatchEventsResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*WatchEventsResponse_Change
+	//	*WatchEventsResponse_Heartbeat
+	//	*WatchEventsResponse_Resync
+	Message       isWatchEventsResponse_Message `protobuf_oneof:"message"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *CancelRegistrationResponse) Reset() {
-	*x = CancelRegistrationResponse{}
-	mi := &file_proto_event_proto_msgTypes[14]
+func (x *WatchEventsResponse) Reset() {
+	*x = WatchEventsResponse{}
+	mi := &file_proto_event_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CancelRegistrationResponse) String() string {
+func (x *WatchEventsResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CancelRegistrationResponse) ProtoMessage() {}
+func (*WatchEventsResponse) ProtoMessage() {}
 
-func (x *CancelRegistrationResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[14]
+func (x *WatchEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -741,32 +2863,92 @@ func (x *CancelRegistrationResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CancelRegistrationResponse.ProtoReflect.Descriptor instead.
-func (*CancelRegistrationResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use WatchEventsResponse.ProtoReflect.Descriptor instead.
+func (*WatchEventsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{42}
 }
 
-type GetUserRegistrationsRequest struct {
+func (x *WatchEventsResponse) GetMessage() isWatchEventsResponse_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *WatchEventsResponse) GetChange() *EventChange {
+	if x != nil {
+		if x, ok := x.Message.(*WatchEventsResponse_Change); ok {
+			return x.Change
+		}
+	}
+	return nil
+}
+
+func (x *WatchEventsResponse) GetHeartbeat() *Heartbeat {
+	if x != nil {
+		if x, ok := x.Message.(*WatchEventsResponse_Heartbeat); ok {
+			return x.Heartbeat
+		}
+	}
+	return nil
+}
+
+func (x *WatchEventsResponse) GetResync() *Resync {
+	if x != nil {
+		if x, ok := x.Message.(*WatchEventsResponse_Resync); ok {
+			return x.Resync
+		}
+	}
+	return nil
+}
+
+type isWatchEventsResponse_Message interface {
+	isWatchEventsResponse_Message()
+}
+
+type WatchEventsResponse_Change struct {
+	Change *EventChange `protobuf:"bytes,1,opt,name=change,proto3,oneof"`
+}
+
+type WatchEventsResponse_Heartbeat struct {
+	Heartbeat *Heartbeat `protobuf:"bytes,2,opt,name=heartbeat,proto3,oneof"`
+}
+
+type WatchEventsResponse_Resync struct {
+	Resync *Resync `protobuf:"bytes,3,opt,name=resync,proto3,oneof"`
+}
+
+func (*WatchEventsResponse_Change) isWatchEventsResponse_Message() {}
+
+func (*WatchEventsResponse_Heartbeat) isWatchEventsResponse_Message() {}
+
+func (*WatchEventsResponse_Resync) isWatchEventsResponse_Message() {}
+
+type EventChange struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cursor        string                 `protobuf:"bytes,1,opt,name=cursor,proto3" json:"cursor,omitempty"` // resume point after this change
+	Action        string                 `protobuf:"bytes,2,opt,name=action,proto3" json:"action,omitempty"`
+	OccurredAt    *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=occurred_at,json=occurredAt,proto3" json:"occurred_at,omitempty"`
+	Event         *Event                 `protobuf:"bytes,4,opt,name=event,proto3" json:"event,omitempty"` // the event after the change
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserRegistrationsRequest) Reset() {
-	*x = GetUserRegistrationsRequest{}
-	mi := &file_proto_event_proto_msgTypes[15]
+func (x *EventChange) Reset() {
+	*x = EventChange{}
+	mi := &file_proto_event_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserRegistrationsRequest) String() string {
+func (x *EventChange) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserRegistrationsRequest) ProtoMessage() {}
+func (*EventChange) ProtoMessage() {}
 
-func (x *GetUserRegistrationsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[15]
+func (x *EventChange) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -777,33 +2959,63 @@ func (x *GetUserRegistrationsRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserRegistrationsRequest.ProtoReflect.Descriptor instead.
-func (*GetUserRegistrationsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{15}
+// Deprecated: Use EventChange.ProtoReflect.Descriptor instead.
+func (*EventChange) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{43}
 }
 
-type GetUserRegistrationsResponse struct {
+func (x *EventChange) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *EventChange) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *EventChange) GetOccurredAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.OccurredAt
+	}
+	return nil
+}
+
+func (x *EventChange) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+// Heartbeat is sent periodically while no change is sent
+type Heartbeat struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	Time          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=time,proto3" json:"time,omitempty"`
+	Cursor        string                 `protobuf:"bytes,2,opt,name=cursor,proto3" json:"cursor,omitempty"` // resume point after the changes seen so far, including filtered ones
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUserRegistrationsResponse) Reset() {
-	*x = GetUserRegistrationsResponse{}
-	mi := &file_proto_event_proto_msgTypes[16]
+func (x *Heartbeat) Reset() {
+	*x = Heartbeat{}
+	mi := &file_proto_event_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUserRegistrationsResponse) String() string {
+func (x *Heartbeat) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUserRegistrationsResponse) ProtoMessage() {}
+func (*Heartbeat) ProtoMessage() {}
 
-func (x *GetUserRegistrationsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_event_proto_msgTypes[16]
+func (x *Heartbeat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -814,64 +3026,312 @@ func (x *GetUserRegistrationsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUserRegistrationsResponse.ProtoReflect.Descriptor instead.
-func (*GetUserRegistrationsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_event_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use Heartbeat.ProtoReflect.Descriptor instead.
+func (*Heartbeat) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{44}
 }
 
-func (x *GetUserRegistrationsResponse) GetEvents() []*Event {
+func (x *Heartbeat) GetTime() *timestamppb.Timestamp {
 	if x != nil {
-		return x.Events
+		return x.Time
 	}
 	return nil
 }
 
+func (x *Heartbeat) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// Resync is sent first when the cursor can no longer be resumed from (it left
+// the history, or was issued by another instance or before a restart); the
+// client should reload the events it shows
+type Resync struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resync) Reset() {
+	*x = Resync{}
+	mi := &file_proto_event_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resync) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resync) ProtoMessage() {}
+
+func (x *Resync) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_event_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resync.ProtoReflect.Descriptor instead.
+func (*Resync) Descriptor() ([]byte, []int) {
+	return file_proto_event_proto_rawDescGZIP(), []int{45}
+}
+
 var File_proto_event_proto protoreflect.FileDescriptor
 
 const file_proto_event_proto_rawDesc = "" +
 	"\n" +
-	"\x11proto/event.proto\x12\x05event\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbb\x01\n" +
+	"\x11proto/event.proto\x12\x05event\x1a\x1fgoogle/protobuf/timestamp.proto\"\xed\x05\n" +
 	"\x05Event\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
 	"\blocation\x18\x04 \x01(\tR\blocation\x127\n" +
 	"\tdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x17\n" +
-	"\auser_id\x18\x06 \x01(\x03R\x06userId\"\x12\n" +
-	"\x10GetEventsRequest\"9\n" +
+	"\auser_id\x18\x06 \x01(\x03R\x06userId\x12\x1a\n" +
+	"\bcapacity\x18\a \x01(\x05R\bcapacity\x12\x14\n" +
+	"\x05rrule\x18\b \x01(\tR\x05rrule\x124\n" +
+	"\aexdates\x18\t \x03(\v2\x1a.google.protobuf.TimestampR\aexdates\x12E\n" +
+	"\x10occurrence_start\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12\x1f\n" +
+	"\blatitude\x18\v \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\f \x01(\x01H\x01R\tlongitude\x88\x01\x01\x12(\n" +
+	"\aaddress\x18\r \x01(\v2\x0e.event.AddressR\aaddress\x12\x16\n" +
+	"\x06status\x18\x0e \x01(\tR\x06status\x12#\n" +
+	"\rcancel_reason\x18\x0f \x01(\tR\fcancelReason\x12=\n" +
+	"\fpublished_at\x18\x10 \x01(\v2\x1a.google.protobuf.TimestampR\vpublishedAt\x12=\n" +
+	"\fcancelled_at\x18\x11 \x01(\v2\x1a.google.protobuf.TimestampR\vcancelledAt\x12=\n" +
+	"\fcompleted_at\x18\x12 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAtB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitude\"\x88\x01\n" +
+	"\aAddress\x12\x16\n" +
+	"\x06street\x18\x01 \x01(\tR\x06street\x12\x12\n" +
+	"\x04city\x18\x02 \x01(\tR\x04city\x12\x16\n" +
+	"\x06region\x18\x03 \x01(\tR\x06region\x12\x1f\n" +
+	"\vpostal_code\x18\x04 \x01(\tR\n" +
+	"postalCode\x12\x18\n" +
+	"\acountry\x18\x05 \x01(\tR\acountry\"\xf2\x02\n" +
+	"\fRegistration\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\x03R\aeventId\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12E\n" +
+	"\x10occurrence_start\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12\x14\n" +
+	"\x05scope\x18\x06 \x01(\tR\x05scope\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x123\n" +
+	"\ahistory\x18\t \x03(\v2\x19.event.RegistrationChangeR\ahistory\"g\n" +
+	"\x12RegistrationChange\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"changed_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\tchangedAt\"\xb6\x02\n" +
+	"\rEventEnvelope\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12%\n" +
+	"\x0eschema_version\x18\x02 \x01(\rR\rschemaVersion\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\tR\aeventId\x12;\n" +
+	"\voccurred_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\x1a\n" +
+	"\bproducer\x18\x05 \x01(\tR\bproducer\x12\x19\n" +
+	"\btrace_id\x18\x06 \x01(\tR\atraceId\x12\"\n" +
+	"\x05event\x18\a \x01(\v2\f.event.EventR\x05event\x127\n" +
+	"\fregistration\x18\b \x01(\v2\x13.event.RegistrationR\fregistration\"\xc2\x02\n" +
+	"\x10GetEventsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x1a\n" +
+	"\blocation\x18\x03 \x01(\tR\blocation\x12\x17\n" +
+	"\auser_id\x18\x04 \x01(\x03R\x06userId\x12)\n" +
+	"\asort_by\x18\x05 \x01(\x0e2\x10.event.EventSortR\x06sortBy\x12\x1e\n" +
+	"\n" +
+	"descending\x18\x06 \x01(\bR\n" +
+	"descending\x12\x1b\n" +
+	"\tpage_size\x18\a \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\b \x01(\tR\tpageToken\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\"a\n" +
 	"\x11GetEventsResponse\x12$\n" +
-	"\x06events\x18\x01 \x03(\v2\f.event.EventR\x06events\"!\n" +
+	"\x06events\x18\x01 \x03(\v2\f.event.EventR\x06events\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"!\n" +
 	"\x0fGetEventRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\"6\n" +
 	"\x10GetEventResponse\x12\"\n" +
-	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"\x9f\x01\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"\xa8\x03\n" +
 	"\x12CreateEventRequest\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
 	"\blocation\x18\x03 \x01(\tR\blocation\x127\n" +
-	"\tdate_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\"9\n" +
+	"\tdate_time\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x1a\n" +
+	"\bcapacity\x18\x05 \x01(\x05R\bcapacity\x12\x14\n" +
+	"\x05rrule\x18\x06 \x01(\tR\x05rrule\x124\n" +
+	"\aexdates\x18\a \x03(\v2\x1a.google.protobuf.TimestampR\aexdates\x12\x1f\n" +
+	"\blatitude\x18\b \x01(\x01H\x00R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\t \x01(\x01H\x01R\tlongitude\x88\x01\x01\x12(\n" +
+	"\aaddress\x18\n" +
+	" \x01(\v2\x0e.event.AddressR\aaddress\x12\x16\n" +
+	"\x06status\x18\v \x01(\tR\x06statusB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitude\"9\n" +
 	"\x13CreateEventResponse\x12\"\n" +
-	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"\xaf\x01\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"\xa7\x04\n" +
 	"\x12UpdateEventRequest\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
 	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
 	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x1a\n" +
 	"\blocation\x18\x04 \x01(\tR\blocation\x127\n" +
-	"\tdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\"9\n" +
+	"\tdate_time\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bdateTime\x12\x1f\n" +
+	"\bcapacity\x18\x06 \x01(\x05H\x00R\bcapacity\x88\x01\x01\x12\x14\n" +
+	"\x05rrule\x18\a \x01(\tR\x05rrule\x124\n" +
+	"\aexdates\x18\b \x03(\v2\x1a.google.protobuf.TimestampR\aexdates\x12E\n" +
+	"\x10occurrence_start\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12,\n" +
+	"\x05scope\x18\n" +
+	" \x01(\x0e2\x16.event.RecurrenceScopeR\x05scope\x12\x1f\n" +
+	"\blatitude\x18\v \x01(\x01H\x01R\blatitude\x88\x01\x01\x12!\n" +
+	"\tlongitude\x18\f \x01(\x01H\x02R\tlongitude\x88\x01\x01\x12(\n" +
+	"\aaddress\x18\r \x01(\v2\x0e.event.AddressR\aaddressB\v\n" +
+	"\t_capacityB\v\n" +
+	"\t_latitudeB\f\n" +
+	"\n" +
+	"_longitude\"9\n" +
 	"\x13UpdateEventResponse\x12\"\n" +
-	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"$\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"\x99\x01\n" +
 	"\x12DeleteEventRequest\x12\x0e\n" +
-	"\x02id\x18\x01 \x01(\x03R\x02id\"\x15\n" +
-	"\x13DeleteEventResponse\"4\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12E\n" +
+	"\x10occurrence_start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12,\n" +
+	"\x05scope\x18\x03 \x01(\x0e2\x16.event.RecurrenceScopeR\x05scope\"\x15\n" +
+	"\x13DeleteEventResponse\"\xa9\x01\n" +
 	"\x17RegisterForEventRequest\x12\x19\n" +
-	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"\x1a\n" +
-	"\x18RegisterForEventResponse\"6\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12E\n" +
+	"\x10occurrence_start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12,\n" +
+	"\x05scope\x18\x03 \x01(\x0e2\x16.event.RecurrenceScopeR\x05scope\"_\n" +
+	"\x18RegisterForEventResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12+\n" +
+	"\x11waitlist_position\x18\x02 \x01(\x03R\x10waitlistPosition\"}\n" +
 	"\x19CancelRegistrationRequest\x12\x19\n" +
-	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"\x1c\n" +
-	"\x1aCancelRegistrationResponse\"\x1d\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12E\n" +
+	"\x10occurrence_start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\"\x1c\n" +
+	"\x1aCancelRegistrationResponse\"4\n" +
+	"\x17GetRegistrationsRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"U\n" +
+	"\x18GetRegistrationsResponse\x129\n" +
+	"\rregistrations\x18\x01 \x03(\v2\x13.event.RegistrationR\rregistrations\"\x8a\x01\n" +
+	"\x19ListEventAttendeesRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\"\xd3\x02\n" +
+	"\bAttendee\x12'\n" +
+	"\x0fregistration_id\x18\x01 \x01(\x03R\x0eregistrationId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x14\n" +
+	"\x05email\x18\x03 \x01(\tR\x05email\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12E\n" +
+	"\x10occurrence_start\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x0foccurrenceStart\x12\x14\n" +
+	"\x05scope\x18\x06 \x01(\tR\x05scope\x12?\n" +
+	"\rregistered_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\fregisteredAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"s\n" +
+	"\x1aListEventAttendeesResponse\x12-\n" +
+	"\tattendees\x18\x01 \x03(\v2\x0f.event.AttendeeR\tattendees\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"[\n" +
+	"\x15RemoveAttendeeRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\x12'\n" +
+	"\x0fregistration_id\x18\x02 \x01(\x03R\x0eregistrationId\"\x18\n" +
+	"\x16RemoveAttendeeResponse\"\x1d\n" +
 	"\x1bGetUserRegistrationsRequest\"D\n" +
 	"\x1cGetUserRegistrationsResponse\x12$\n" +
-	"\x06events\x18\x01 \x03(\v2\f.event.EventR\x06events2\xee\x04\n" +
+	"\x06events\x18\x01 \x03(\v2\f.event.EventR\x06events\"D\n" +
+	"\x13ImportEventsRequest\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x17\n" +
+	"\adry_run\x18\x02 \x01(\bR\x06dryRun\"\x9c\x01\n" +
+	"\fImportResult\x12\x14\n" +
+	"\x05index\x18\x01 \x01(\x05R\x05index\x12\x10\n" +
+	"\x03uid\x18\x02 \x01(\tR\x03uid\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x16\n" +
+	"\x06status\x18\x04 \x01(\tR\x06status\x12\"\n" +
+	"\x05event\x18\x05 \x01(\v2\f.event.EventR\x05event\x12\x14\n" +
+	"\x05error\x18\x06 \x01(\tR\x05error\"\x90\x01\n" +
+	"\x14ImportEventsResponse\x12-\n" +
+	"\aresults\x18\x01 \x03(\v2\x13.event.ImportResultR\aresults\x12\x18\n" +
+	"\acreated\x18\x02 \x01(\x05R\acreated\x12\x16\n" +
+	"\x06failed\x18\x03 \x01(\x05R\x06failed\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\"g\n" +
+	"\x13SearchEventsRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xca\x01\n" +
+	"\fSearchResult\x12\"\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\x12\x12\n" +
+	"\x04rank\x18\x02 \x01(\x01R\x04rank\x12C\n" +
+	"\n" +
+	"highlights\x18\x03 \x03(\v2#.event.SearchResult.HighlightsEntryR\n" +
+	"highlights\x1a=\n" +
+	"\x0fHighlightsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"m\n" +
+	"\x14SearchEventsResponse\x12-\n" +
+	"\aresults\x18\x01 \x03(\v2\x13.event.SearchResultR\aresults\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x8c\x01\n" +
+	"\x16GetNearbyEventsRequest\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x1b\n" +
+	"\tradius_km\x18\x03 \x01(\x01R\bradiusKm\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"R\n" +
+	"\vNearbyEvent\x12\"\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\x12\x1f\n" +
+	"\vdistance_km\x18\x02 \x01(\x01R\n" +
+	"distanceKm\"E\n" +
+	"\x17GetNearbyEventsResponse\x12*\n" +
+	"\x06events\x18\x01 \x03(\v2\x12.event.NearbyEventR\x06events\"%\n" +
+	"\x13PublishEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\":\n" +
+	"\x14PublishEventResponse\x12\"\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"<\n" +
+	"\x12CancelEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"9\n" +
+	"\x13CancelEventResponse\x12\"\n" +
+	"\x05event\x18\x01 \x01(\v2\f.event.EventR\x05event\"|\n" +
+	"\x12WatchEventsRequest\x12\x1b\n" +
+	"\tevent_ids\x18\x01 \x03(\x03R\beventIds\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aactions\x18\x03 \x03(\tR\aactions\x12\x16\n" +
+	"\x06cursor\x18\x04 \x01(\tR\x06cursor\"\xa9\x01\n" +
+	"\x13WatchEventsResponse\x12,\n" +
+	"\x06change\x18\x01 \x01(\v2\x12.event.EventChangeH\x00R\x06change\x120\n" +
+	"\theartbeat\x18\x02 \x01(\v2\x10.event.HeartbeatH\x00R\theartbeat\x12'\n" +
+	"\x06resync\x18\x03 \x01(\v2\r.event.ResyncH\x00R\x06resyncB\t\n" +
+	"\amessage\"\x9e\x01\n" +
+	"\vEventChange\x12\x16\n" +
+	"\x06cursor\x18\x01 \x01(\tR\x06cursor\x12\x16\n" +
+	"\x06action\x18\x02 \x01(\tR\x06action\x12;\n" +
+	"\voccurred_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"occurredAt\x12\"\n" +
+	"\x05event\x18\x04 \x01(\v2\f.event.EventR\x05event\"S\n" +
+	"\tHeartbeat\x12.\n" +
+	"\x04time\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04time\x12\x16\n" +
+	"\x06cursor\x18\x02 \x01(\tR\x06cursor\"\b\n" +
+	"\x06Resync*\x91\x01\n" +
+	"\x0fRecurrenceScope\x12 \n" +
+	"\x1cRECURRENCE_SCOPE_UNSPECIFIED\x10\x00\x12\x1f\n" +
+	"\x1bRECURRENCE_SCOPE_OCCURRENCE\x10\x01\x12\x1e\n" +
+	"\x1aRECURRENCE_SCOPE_FOLLOWING\x10\x02\x12\x1b\n" +
+	"\x17RECURRENCE_SCOPE_SERIES\x10\x03*V\n" +
+	"\tEventSort\x12\x1a\n" +
+	"\x16EVENT_SORT_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14EVENT_SORT_DATE_TIME\x10\x01\x12\x13\n" +
+	"\x0fEVENT_SORT_NAME\x10\x022\xaa\n" +
+	"\n" +
 	"\fEventService\x12>\n" +
 	"\tGetEvents\x12\x17.event.GetEventsRequest\x1a\x18.event.GetEventsResponse\x12;\n" +
 	"\bGetEvent\x12\x16.event.GetEventRequest\x1a\x17.event.GetEventResponse\x12D\n" +
@@ -879,8 +3339,17 @@ const file_proto_event_proto_rawDesc = "" +
 	"\vUpdateEvent\x12\x19.event.UpdateEventRequest\x1a\x1a.event.UpdateEventResponse\x12D\n" +
 	"\vDeleteEvent\x12\x19.event.DeleteEventRequest\x1a\x1a.event.DeleteEventResponse\x12S\n" +
 	"\x10RegisterForEvent\x12\x1e.event.RegisterForEventRequest\x1a\x1f.event.RegisterForEventResponse\x12Y\n" +
-	"\x12CancelRegistration\x12 .event.CancelRegistrationRequest\x1a!.event.CancelRegistrationResponse\x12_\n" +
-	"\x14GetUserRegistrations\x12\".event.GetUserRegistrationsRequest\x1a#.event.GetUserRegistrationsResponseBJZHgithub.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/eventb\x06proto3"
+	"\x12CancelRegistration\x12 .event.CancelRegistrationRequest\x1a!.event.CancelRegistrationResponse\x12S\n" +
+	"\x10GetRegistrations\x12\x1e.event.GetRegistrationsRequest\x1a\x1f.event.GetRegistrationsResponse\x12Y\n" +
+	"\x12ListEventAttendees\x12 .event.ListEventAttendeesRequest\x1a!.event.ListEventAttendeesResponse\x12M\n" +
+	"\x0eRemoveAttendee\x12\x1c.event.RemoveAttendeeRequest\x1a\x1d.event.RemoveAttendeeResponse\x12_\n" +
+	"\x14GetUserRegistrations\x12\".event.GetUserRegistrationsRequest\x1a#.event.GetUserRegistrationsResponse\x12I\n" +
+	"\fImportEvents\x12\x1a.event.ImportEventsRequest\x1a\x1b.event.ImportEventsResponse(\x01\x12G\n" +
+	"\fSearchEvents\x12\x1a.event.SearchEventsRequest\x1a\x1b.event.SearchEventsResponse\x12P\n" +
+	"\x0fGetNearbyEvents\x12\x1d.event.GetNearbyEventsRequest\x1a\x1e.event.GetNearbyEventsResponse\x12G\n" +
+	"\fPublishEvent\x12\x1a.event.PublishEventRequest\x1a\x1b.event.PublishEventResponse\x12D\n" +
+	"\vCancelEvent\x12\x19.event.CancelEventRequest\x1a\x1a.event.CancelEventResponse\x12F\n" +
+	"\vWatchEvents\x12\x19.event.WatchEventsRequest\x1a\x1a.event.WatchEventsResponse0\x01BJZHgithub.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/eventb\x06proto3"
 
 var (
 	file_proto_event_proto_rawDescOnce sync.Once
@@ -894,57 +3363,156 @@ func file_proto_event_proto_rawDescGZIP() []byte {
 	return file_proto_event_proto_rawDescData
 }
 
-var file_proto_event_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_proto_event_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_event_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_proto_event_proto_goTypes = []any{
-	(*Event)(nil),                        // 0: event.Event
-	(*GetEventsRequest)(nil),             // 1: event.GetEventsRequest
-	(*GetEventsResponse)(nil),            // 2: event.GetEventsResponse
-	(*GetEventRequest)(nil),              // 3: event.GetEventRequest
-	(*GetEventResponse)(nil),             // 4: event.GetEventResponse
-	(*CreateEventRequest)(nil),           // 5: event.CreateEventRequest
-	(*CreateEventResponse)(nil),          // 6: event.CreateEventResponse
-	(*UpdateEventRequest)(nil),           // 7: event.UpdateEventRequest
-	(*UpdateEventResponse)(nil),          // 8: event.UpdateEventResponse
-	(*DeleteEventRequest)(nil),           // 9: event.DeleteEventRequest
-	(*DeleteEventResponse)(nil),          // 10: event.DeleteEventResponse
-	(*RegisterForEventRequest)(nil),      // 11: event.RegisterForEventRequest
-	(*RegisterForEventResponse)(nil),     // 12: event.RegisterForEventResponse
-	(*CancelRegistrationRequest)(nil),    // 13: event.CancelRegistrationRequest
-	(*CancelRegistrationResponse)(nil),   // 14: event.CancelRegistrationResponse
-	(*GetUserRegistrationsRequest)(nil),  // 15: event.GetUserRegistrationsRequest
-	(*GetUserRegistrationsResponse)(nil), // 16: event.GetUserRegistrationsResponse
-	(*timestamppb.Timestamp)(nil),        // 17: google.protobuf.Timestamp
+	(RecurrenceScope)(0),                 // 0: event.RecurrenceScope
+	(EventSort)(0),                       // 1: event.EventSort
+	(*Event)(nil),                        // 2: event.Event
+	(*Address)(nil),                      // 3: event.Address
+	(*Registration)(nil),                 // 4: event.Registration
+	(*RegistrationChange)(nil),           // 5: event.RegistrationChange
+	(*EventEnvelope)(nil),                // 6: event.EventEnvelope
+	(*GetEventsRequest)(nil),             // 7: event.GetEventsRequest
+	(*GetEventsResponse)(nil),            // 8: event.GetEventsResponse
+	(*GetEventRequest)(nil),              // 9: event.GetEventRequest
+	(*GetEventResponse)(nil),             // 10: event.GetEventResponse
+	(*CreateEventRequest)(nil),           // 11: event.CreateEventRequest
+	(*CreateEventResponse)(nil),          // 12: event.CreateEventResponse
+	(*UpdateEventRequest)(nil),           // 13: event.UpdateEventRequest
+	(*UpdateEventResponse)(nil),          // 14: event.UpdateEventResponse
+	(*DeleteEventRequest)(nil),           // 15: event.DeleteEventRequest
+	(*DeleteEventResponse)(nil),          // 16: event.DeleteEventResponse
+	(*RegisterForEventRequest)(nil),      // 17: event.RegisterForEventRequest
+	(*RegisterForEventResponse)(nil),     // 18: event.RegisterForEventResponse
+	(*CancelRegistrationRequest)(nil),    // 19: event.CancelRegistrationRequest
+	(*CancelRegistrationResponse)(nil),   // 20: event.CancelRegistrationResponse
+	(*GetRegistrationsRequest)(nil),      // 21: event.GetRegistrationsRequest
+	(*GetRegistrationsResponse)(nil),     // 22: event.GetRegistrationsResponse
+	(*ListEventAttendeesRequest)(nil),    // 23: event.ListEventAttendeesRequest
+	(*Attendee)(nil),                     // 24: event.Attendee
+	(*ListEventAttendeesResponse)(nil),   // 25: event.ListEventAttendeesResponse
+	(*RemoveAttendeeRequest)(nil),        // 26: event.RemoveAttendeeRequest
+	(*RemoveAttendeeResponse)(nil),       // 27: event.RemoveAttendeeResponse
+	(*GetUserRegistrationsRequest)(nil),  // 28: event.GetUserRegistrationsRequest
+	(*GetUserRegistrationsResponse)(nil), // 29: event.GetUserRegistrationsResponse
+	(*ImportEventsRequest)(nil),          // 30: event.ImportEventsRequest
+	(*ImportResult)(nil),                 // 31: event.ImportResult
+	(*ImportEventsResponse)(nil),         // 32: event.ImportEventsResponse
+	(*SearchEventsRequest)(nil),          // 33: event.SearchEventsRequest
+	(*SearchResult)(nil),                 // 34: event.SearchResult
+	(*SearchEventsResponse)(nil),         // 35: event.SearchEventsResponse
+	(*GetNearbyEventsRequest)(nil),       // 36: event.GetNearbyEventsRequest
+	(*NearbyEvent)(nil),                  // 37: event.NearbyEvent
+	(*GetNearbyEventsResponse)(nil),      // 38: event.GetNearbyEventsResponse
+	(*PublishEventRequest)(nil),          // 39: event.PublishEventRequest
+	(*PublishEventResponse)(nil),         // 40: event.PublishEventResponse
+	(*CancelEventRequest)(nil),           // 41: event.CancelEventRequest
+	(*CancelEventResponse)(nil),          // 42: event.CancelEventResponse
+	(*WatchEventsRequest)(nil),           // 43: event.WatchEventsRequest
+	(*WatchEventsResponse)(nil),          // 44: event.WatchEventsResponse
+	(*EventChange)(nil),                  // 45: event.EventChange
+	(*Heartbeat)(nil),                    // 46: event.Heartbeat
+	(*Resync)(nil),                       // 47: event.Resync
+	nil,                                  // 48: event.SearchResult.HighlightsEntry
+	(*timestamppb.Timestamp)(nil),        // 49: google.protobuf.Timestamp
 }
 var file_proto_event_proto_depIdxs = []int32{
-	17, // 0: event.Event.date_time:type_name -> google.protobuf.Timestamp
-	0,  // 1: event.GetEventsResponse.events:type_name -> event.Event
-	0,  // 2: event.GetEventResponse.event:type_name -> event.Event
-	17, // 3: event.CreateEventRequest.date_time:type_name -> google.protobuf.Timestamp
-	0,  // 4: event.CreateEventResponse.event:type_name -> event.Event
-	17, // 5: event.UpdateEventRequest.date_time:type_name -> google.protobuf.Timestamp
-	0,  // 6: event.UpdateEventResponse.event:type_name -> event.Event
-	0,  // 7: event.GetUserRegistrationsResponse.events:type_name -> event.Event
-	1,  // 8: event.EventService.GetEvents:input_type -> event.GetEventsRequest
-	3,  // 9: event.EventService.GetEvent:input_type -> event.GetEventRequest
-	5,  // 10: event.EventService.CreateEvent:input_type -> event.CreateEventRequest
-	7,  // 11: event.EventService.UpdateEvent:input_type -> event.UpdateEventRequest
-	9,  // 12: event.EventService.DeleteEvent:input_type -> event.DeleteEventRequest
-	11, // 13: event.EventService.RegisterForEvent:input_type -> event.RegisterForEventRequest
-	13, // 14: event.EventService.CancelRegistration:input_type -> event.CancelRegistrationRequest
-	15, // 15: event.EventService.GetUserRegistrations:input_type -> event.GetUserRegistrationsRequest
-	2,  // 16: event.EventService.GetEvents:output_type -> event.GetEventsResponse
-	4,  // 17: event.EventService.GetEvent:output_type -> event.GetEventResponse
-	6,  // 18: event.EventService.CreateEvent:output_type -> event.CreateEventResponse
-	8,  // 19: event.EventService.UpdateEvent:output_type -> event.UpdateEventResponse
-	10, // 20: event.EventService.DeleteEvent:output_type -> event.DeleteEventResponse
-	12, // 21: event.EventService.RegisterForEvent:output_type -> event.RegisterForEventResponse
-	14, // 22: event.EventService.CancelRegistration:output_type -> event.CancelRegistrationResponse
-	16, // 23: event.EventService.GetUserRegistrations:output_type -> event.GetUserRegistrationsResponse
-	16, // [16:24] is the sub-list for method output_type
-	8,  // [8:16] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	49, // 0: event.Event.date_time:type_name -> google.protobuf.Timestamp
+	49, // 1: event.Event.exdates:type_name -> google.protobuf.Timestamp
+	49, // 2: event.Event.occurrence_start:type_name -> google.protobuf.Timestamp
+	3,  // 3: event.Event.address:type_name -> event.Address
+	49, // 4: event.Event.published_at:type_name -> google.protobuf.Timestamp
+	49, // 5: event.Event.cancelled_at:type_name -> google.protobuf.Timestamp
+	49, // 6: event.Event.completed_at:type_name -> google.protobuf.Timestamp
+	49, // 7: event.Registration.occurrence_start:type_name -> google.protobuf.Timestamp
+	49, // 8: event.Registration.created_at:type_name -> google.protobuf.Timestamp
+	49, // 9: event.Registration.updated_at:type_name -> google.protobuf.Timestamp
+	5,  // 10: event.Registration.history:type_name -> event.RegistrationChange
+	49, // 11: event.RegistrationChange.changed_at:type_name -> google.protobuf.Timestamp
+	49, // 12: event.EventEnvelope.occurred_at:type_name -> google.protobuf.Timestamp
+	2,  // 13: event.EventEnvelope.event:type_name -> event.Event
+	4,  // 14: event.EventEnvelope.registration:type_name -> event.Registration
+	49, // 15: event.GetEventsRequest.from:type_name -> google.protobuf.Timestamp
+	49, // 16: event.GetEventsRequest.to:type_name -> google.protobuf.Timestamp
+	1,  // 17: event.GetEventsRequest.sort_by:type_name -> event.EventSort
+	2,  // 18: event.GetEventsResponse.events:type_name -> event.Event
+	2,  // 19: event.GetEventResponse.event:type_name -> event.Event
+	49, // 20: event.CreateEventRequest.date_time:type_name -> google.protobuf.Timestamp
+	49, // 21: event.CreateEventRequest.exdates:type_name -> google.protobuf.Timestamp
+	3,  // 22: event.CreateEventRequest.address:type_name -> event.Address
+	2,  // 23: event.CreateEventResponse.event:type_name -> event.Event
+	49, // 24: event.UpdateEventRequest.date_time:type_name -> google.protobuf.Timestamp
+	49, // 25: event.UpdateEventRequest.exdates:type_name -> google.protobuf.Timestamp
+	49, // 26: event.UpdateEventRequest.occurrence_start:type_name -> google.protobuf.Timestamp
+	0,  // 27: event.UpdateEventRequest.scope:type_name -> event.RecurrenceScope
+	3,  // 28: event.UpdateEventRequest.address:type_name -> event.Address
+	2,  // 29: event.UpdateEventResponse.event:type_name -> event.Event
+	49, // 30: event.DeleteEventRequest.occurrence_start:type_name -> google.protobuf.Timestamp
+	0,  // 31: event.DeleteEventRequest.scope:type_name -> event.RecurrenceScope
+	49, // 32: event.RegisterForEventRequest.occurrence_start:type_name -> google.protobuf.Timestamp
+	0,  // 33: event.RegisterForEventRequest.scope:type_name -> event.RecurrenceScope
+	49, // 34: event.CancelRegistrationRequest.occurrence_start:type_name -> google.protobuf.Timestamp
+	4,  // 35: event.GetRegistrationsResponse.registrations:type_name -> event.Registration
+	49, // 36: event.Attendee.occurrence_start:type_name -> google.protobuf.Timestamp
+	49, // 37: event.Attendee.registered_at:type_name -> google.protobuf.Timestamp
+	49, // 38: event.Attendee.updated_at:type_name -> google.protobuf.Timestamp
+	24, // 39: event.ListEventAttendeesResponse.attendees:type_name -> event.Attendee
+	2,  // 40: event.GetUserRegistrationsResponse.events:type_name -> event.Event
+	2,  // 41: event.ImportResult.event:type_name -> event.Event
+	31, // 42: event.ImportEventsResponse.results:type_name -> event.ImportResult
+	2,  // 43: event.SearchResult.event:type_name -> event.Event
+	48, // 44: event.SearchResult.highlights:type_name -> event.SearchResult.HighlightsEntry
+	34, // 45: event.SearchEventsResponse.results:type_name -> event.SearchResult
+	2,  // 46: event.NearbyEvent.event:type_name -> event.Event
+	37, // 47: event.GetNearbyEventsResponse.events:type_name -> event.NearbyEvent
+	2,  // 48: event.PublishEventResponse.event:type_name -> event.Event
+	2,  // 49: event.CancelEventResponse.event:type_name -> event.Event
+	45, // 50: event.WatchEventsResponse.change:type_name -> event.EventChange
+	46, // 51: event.WatchEventsResponse.heartbeat:type_name -> event.Heartbeat
+	47, // 52: event.WatchEventsResponse.resync:type_name -> event.Resync
+	49, // 53: event.EventChange.occurred_at:type_name -> google.protobuf.Timestamp
+	2,  // 54: event.EventChange.event:type_name -> event.Event
+	49, // 55: event.Heartbeat.time:type_name -> google.protobuf.Timestamp
+	7,  // 56: event.EventService.GetEvents:input_type -> event.GetEventsRequest
+	9,  // 57: event.EventService.GetEvent:input_type -> event.GetEventRequest
+	11, // 58: event.EventService.CreateEvent:input_type -> event.CreateEventRequest
+	13, // 59: event.EventService.UpdateEvent:input_type -> event.UpdateEventRequest
+	15, // 60: event.EventService.DeleteEvent:input_type -> event.DeleteEventRequest
+	17, // 61: event.EventService.RegisterForEvent:input_type -> event.RegisterForEventRequest
+	19, // 62: event.EventService.CancelRegistration:input_type -> event.CancelRegistrationRequest
+	21, // 63: event.EventService.GetRegistrations:input_type -> event.GetRegistrationsRequest
+	23, // 64: event.EventService.ListEventAttendees:input_type -> event.ListEventAttendeesRequest
+	26, // 65: event.EventService.RemoveAttendee:input_type -> event.RemoveAttendeeRequest
+	28, // 66: event.EventService.GetUserRegistrations:input_type -> event.GetUserRegistrationsRequest
+	30, // 67: event.EventService.ImportEvents:input_type -> event.ImportEventsRequest
+	33, // 68: event.EventService.SearchEvents:input_type -> event.SearchEventsRequest
+	36, // 69: event.EventService.GetNearbyEvents:input_type -> event.GetNearbyEventsRequest
+	39, // 70: event.EventService.PublishEvent:input_type -> event.PublishEventRequest
+	41, // 71: event.EventService.CancelEvent:input_type -> event.CancelEventRequest
+	43, // 72: event.EventService.WatchEvents:input_type -> event.WatchEventsRequest
+	8,  // 73: event.EventService.GetEvents:output_type -> event.GetEventsResponse
+	10, // 74: event.EventService.GetEvent:output_type -> event.GetEventResponse
+	12, // 75: event.EventService.CreateEvent:output_type -> event.CreateEventResponse
+	14, // 76: event.EventService.UpdateEvent:output_type -> event.UpdateEventResponse
+	16, // 77: event.EventService.DeleteEvent:output_type -> event.DeleteEventResponse
+	18, // 78: event.EventService.RegisterForEvent:output_type -> event.RegisterForEventResponse
+	20, // 79: event.EventService.CancelRegistration:output_type -> event.CancelRegistrationResponse
+	22, // 80: event.EventService.GetRegistrations:output_type -> event.GetRegistrationsResponse
+	25, // 81: event.EventService.ListEventAttendees:output_type -> event.ListEventAttendeesResponse
+	27, // 82: event.EventService.RemoveAttendee:output_type -> event.RemoveAttendeeResponse
+	29, // 83: event.EventService.GetUserRegistrations:output_type -> event.GetUserRegistrationsResponse
+	32, // 84: event.EventService.ImportEvents:output_type -> event.ImportEventsResponse
+	35, // 85: event.EventService.SearchEvents:output_type -> event.SearchEventsResponse
+	38, // 86: event.EventService.GetNearbyEvents:output_type -> event.GetNearbyEventsResponse
+	40, // 87: event.EventService.PublishEvent:output_type -> event.PublishEventResponse
+	42, // 88: event.EventService.CancelEvent:output_type -> event.CancelEventResponse
+	44, // 89: event.EventService.WatchEvents:output_type -> event.WatchEventsResponse
+	73, // [73:90] is the sub-list for method output_type
+	56, // [56:73] is the sub-list for method input_type
+	56, // [56:56] is the sub-list for extension type_name
+	56, // [56:56] is the sub-list for extension extendee
+	0,  // [0:56] is the sub-list for field type_name
 }
 
 func init() { file_proto_event_proto_init() }
@@ -952,18 +3520,27 @@ func file_proto_event_proto_init() {
 	if File_proto_event_proto != nil {
 		return
 	}
+	file_proto_event_proto_msgTypes[0].OneofWrappers = []any{}
+	file_proto_event_proto_msgTypes[9].OneofWrappers = []any{}
+	file_proto_event_proto_msgTypes[11].OneofWrappers = []any{}
+	file_proto_event_proto_msgTypes[42].OneofWrappers = []any{
+		(*WatchEventsResponse_Change)(nil),
+		(*WatchEventsResponse_Heartbeat)(nil),
+		(*WatchEventsResponse_Resync)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_event_proto_rawDesc), len(file_proto_event_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   17,
+			NumEnums:      2,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_event_proto_goTypes,
 		DependencyIndexes: file_proto_event_proto_depIdxs,
+		EnumInfos:         file_proto_event_proto_enumTypes,
 		MessageInfos:      file_proto_event_proto_msgTypes,
 	}.Build()
 	File_proto_event_proto = out.File
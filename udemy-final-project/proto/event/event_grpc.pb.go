@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go-grpc. DO NOT EDIT.
 // versions:
 // - protoc-gen-go-grpc v1.5.1
-// - protoc             v3.21.12
+// - protoc             v4.25.3
 // source: proto/event.proto
 
 package event
@@ -26,7 +26,16 @@ const (
 	EventService_DeleteEvent_FullMethodName          = "/event.EventService/DeleteEvent"
 	EventService_RegisterForEvent_FullMethodName     = "/event.EventService/RegisterForEvent"
 	EventService_CancelRegistration_FullMethodName   = "/event.EventService/CancelRegistration"
+	EventService_GetRegistrations_FullMethodName     = "/event.EventService/GetRegistrations"
+	EventService_ListEventAttendees_FullMethodName   = "/event.EventService/ListEventAttendees"
+	EventService_RemoveAttendee_FullMethodName       = "/event.EventService/RemoveAttendee"
 	EventService_GetUserRegistrations_FullMethodName = "/event.EventService/GetUserRegistrations"
+	EventService_ImportEvents_FullMethodName         = "/event.EventService/ImportEvents"
+	EventService_SearchEvents_FullMethodName         = "/event.EventService/SearchEvents"
+	EventService_GetNearbyEvents_FullMethodName      = "/event.EventService/GetNearbyEvents"
+	EventService_PublishEvent_FullMethodName         = "/event.EventService/PublishEvent"
+	EventService_CancelEvent_FullMethodName          = "/event.EventService/CancelEvent"
+	EventService_WatchEvents_FullMethodName          = "/event.EventService/WatchEvents"
 )
 
 // EventServiceClient is the client API for EventService service.
@@ -40,7 +49,16 @@ type EventServiceClient interface {
 	DeleteEvent(ctx context.Context, in *DeleteEventRequest, opts ...grpc.CallOption) (*DeleteEventResponse, error)
 	RegisterForEvent(ctx context.Context, in *RegisterForEventRequest, opts ...grpc.CallOption) (*RegisterForEventResponse, error)
 	CancelRegistration(ctx context.Context, in *CancelRegistrationRequest, opts ...grpc.CallOption) (*CancelRegistrationResponse, error)
+	GetRegistrations(ctx context.Context, in *GetRegistrationsRequest, opts ...grpc.CallOption) (*GetRegistrationsResponse, error)
+	ListEventAttendees(ctx context.Context, in *ListEventAttendeesRequest, opts ...grpc.CallOption) (*ListEventAttendeesResponse, error)
+	RemoveAttendee(ctx context.Context, in *RemoveAttendeeRequest, opts ...grpc.CallOption) (*RemoveAttendeeResponse, error)
 	GetUserRegistrations(ctx context.Context, in *GetUserRegistrationsRequest, opts ...grpc.CallOption) (*GetUserRegistrationsResponse, error)
+	ImportEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEventsRequest, ImportEventsResponse], error)
+	SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error)
+	GetNearbyEvents(ctx context.Context, in *GetNearbyEventsRequest, opts ...grpc.CallOption) (*GetNearbyEventsResponse, error)
+	PublishEvent(ctx context.Context, in *PublishEventRequest, opts ...grpc.CallOption) (*PublishEventResponse, error)
+	CancelEvent(ctx context.Context, in *CancelEventRequest, opts ...grpc.CallOption) (*CancelEventResponse, error)
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error)
 }
 
 type eventServiceClient struct {
@@ -121,6 +139,36 @@ func (c *eventServiceClient) CancelRegistration(ctx context.Context, in *CancelR
 	return out, nil
 }
 
+func (c *eventServiceClient) GetRegistrations(ctx context.Context, in *GetRegistrationsRequest, opts ...grpc.CallOption) (*GetRegistrationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRegistrationsResponse)
+	err := c.cc.Invoke(ctx, EventService_GetRegistrations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) ListEventAttendees(ctx context.Context, in *ListEventAttendeesRequest, opts ...grpc.CallOption) (*ListEventAttendeesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListEventAttendeesResponse)
+	err := c.cc.Invoke(ctx, EventService_ListEventAttendees_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) RemoveAttendee(ctx context.Context, in *RemoveAttendeeRequest, opts ...grpc.CallOption) (*RemoveAttendeeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveAttendeeResponse)
+	err := c.cc.Invoke(ctx, EventService_RemoveAttendee_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *eventServiceClient) GetUserRegistrations(ctx context.Context, in *GetUserRegistrationsRequest, opts ...grpc.CallOption) (*GetUserRegistrationsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetUserRegistrationsResponse)
@@ -131,6 +179,78 @@ func (c *eventServiceClient) GetUserRegistrations(ctx context.Context, in *GetUs
 	return out, nil
 }
 
+func (c *eventServiceClient) ImportEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportEventsRequest, ImportEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[0], EventService_ImportEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportEventsRequest, ImportEventsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_ImportEventsClient = grpc.ClientStreamingClient[ImportEventsRequest, ImportEventsResponse]
+
+func (c *eventServiceClient) SearchEvents(ctx context.Context, in *SearchEventsRequest, opts ...grpc.CallOption) (*SearchEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_SearchEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) GetNearbyEvents(ctx context.Context, in *GetNearbyEventsRequest, opts ...grpc.CallOption) (*GetNearbyEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNearbyEventsResponse)
+	err := c.cc.Invoke(ctx, EventService_GetNearbyEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) PublishEvent(ctx context.Context, in *PublishEventRequest, opts ...grpc.CallOption) (*PublishEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PublishEventResponse)
+	err := c.cc.Invoke(ctx, EventService_PublishEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) CancelEvent(ctx context.Context, in *CancelEventRequest, opts ...grpc.CallOption) (*CancelEventResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelEventResponse)
+	err := c.cc.Invoke(ctx, EventService_CancelEvent_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eventServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &EventService_ServiceDesc.Streams[1], EventService_WatchEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchEventsRequest, WatchEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_WatchEventsClient = grpc.ServerStreamingClient[WatchEventsResponse]
+
 // EventServiceServer is the server API for EventService service.
 // All implementations must embed UnimplementedEventServiceServer
 // for forward compatibility.
@@ -142,7 +262,16 @@ type EventServiceServer interface {
 	DeleteEvent(context.Context, *DeleteEventRequest) (*DeleteEventResponse, error)
 	RegisterForEvent(context.Context, *RegisterForEventRequest) (*RegisterForEventResponse, error)
 	CancelRegistration(context.Context, *CancelRegistrationRequest) (*CancelRegistrationResponse, error)
+	GetRegistrations(context.Context, *GetRegistrationsRequest) (*GetRegistrationsResponse, error)
+	ListEventAttendees(context.Context, *ListEventAttendeesRequest) (*ListEventAttendeesResponse, error)
+	RemoveAttendee(context.Context, *RemoveAttendeeRequest) (*RemoveAttendeeResponse, error)
 	GetUserRegistrations(context.Context, *GetUserRegistrationsRequest) (*GetUserRegistrationsResponse, error)
+	ImportEvents(grpc.ClientStreamingServer[ImportEventsRequest, ImportEventsResponse]) error
+	SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error)
+	GetNearbyEvents(context.Context, *GetNearbyEventsRequest) (*GetNearbyEventsResponse, error)
+	PublishEvent(context.Context, *PublishEventRequest) (*PublishEventResponse, error)
+	CancelEvent(context.Context, *CancelEventRequest) (*CancelEventResponse, error)
+	WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error
 	mustEmbedUnimplementedEventServiceServer()
 }
 
@@ -174,9 +303,36 @@ func (UnimplementedEventServiceServer) RegisterForEvent(context.Context, *Regist
 func (UnimplementedEventServiceServer) CancelRegistration(context.Context, *CancelRegistrationRequest) (*CancelRegistrationResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CancelRegistration not implemented")
 }
+func (UnimplementedEventServiceServer) GetRegistrations(context.Context, *GetRegistrationsRequest) (*GetRegistrationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRegistrations not implemented")
+}
+func (UnimplementedEventServiceServer) ListEventAttendees(context.Context, *ListEventAttendeesRequest) (*ListEventAttendeesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEventAttendees not implemented")
+}
+func (UnimplementedEventServiceServer) RemoveAttendee(context.Context, *RemoveAttendeeRequest) (*RemoveAttendeeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveAttendee not implemented")
+}
 func (UnimplementedEventServiceServer) GetUserRegistrations(context.Context, *GetUserRegistrationsRequest) (*GetUserRegistrationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserRegistrations not implemented")
 }
+func (UnimplementedEventServiceServer) ImportEvents(grpc.ClientStreamingServer[ImportEventsRequest, ImportEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportEvents not implemented")
+}
+func (UnimplementedEventServiceServer) SearchEvents(context.Context, *SearchEventsRequest) (*SearchEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchEvents not implemented")
+}
+func (UnimplementedEventServiceServer) GetNearbyEvents(context.Context, *GetNearbyEventsRequest) (*GetNearbyEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNearbyEvents not implemented")
+}
+func (UnimplementedEventServiceServer) PublishEvent(context.Context, *PublishEventRequest) (*PublishEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishEvent not implemented")
+}
+func (UnimplementedEventServiceServer) CancelEvent(context.Context, *CancelEventRequest) (*CancelEventResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelEvent not implemented")
+}
+func (UnimplementedEventServiceServer) WatchEvents(*WatchEventsRequest, grpc.ServerStreamingServer[WatchEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
 func (UnimplementedEventServiceServer) mustEmbedUnimplementedEventServiceServer() {}
 func (UnimplementedEventServiceServer) testEmbeddedByValue()                      {}
 
@@ -324,6 +480,60 @@ func _EventService_CancelRegistration_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_GetRegistrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRegistrationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetRegistrations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetRegistrations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetRegistrations(ctx, req.(*GetRegistrationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_ListEventAttendees_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventAttendeesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).ListEventAttendees(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_ListEventAttendees_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).ListEventAttendees(ctx, req.(*ListEventAttendeesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_RemoveAttendee_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveAttendeeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).RemoveAttendee(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_RemoveAttendee_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).RemoveAttendee(ctx, req.(*RemoveAttendeeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _EventService_GetUserRegistrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetUserRegistrationsRequest)
 	if err := dec(in); err != nil {
@@ -342,6 +552,96 @@ func _EventService_GetUserRegistrations_Handler(srv interface{}, ctx context.Con
 	return interceptor(ctx, in, info, handler)
 }
 
+func _EventService_ImportEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(EventServiceServer).ImportEvents(&grpc.GenericServerStream[ImportEventsRequest, ImportEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_ImportEventsServer = grpc.ClientStreamingServer[ImportEventsRequest, ImportEventsResponse]
+
+func _EventService_SearchEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).SearchEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_SearchEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).SearchEvents(ctx, req.(*SearchEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_GetNearbyEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNearbyEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).GetNearbyEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_GetNearbyEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).GetNearbyEvents(ctx, req.(*GetNearbyEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_PublishEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).PublishEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_PublishEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).PublishEvent(ctx, req.(*PublishEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_CancelEvent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelEventRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(EventServiceServer).CancelEvent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: EventService_CancelEvent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(EventServiceServer).CancelEvent(ctx, req.(*CancelEventRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _EventService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(EventServiceServer).WatchEvents(m, &grpc.GenericServerStream[WatchEventsRequest, WatchEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type EventService_WatchEventsServer = grpc.ServerStreamingServer[WatchEventsResponse]
+
 // EventService_ServiceDesc is the grpc.ServiceDesc for EventService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -377,11 +677,50 @@ var EventService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "CancelRegistration",
 			Handler:    _EventService_CancelRegistration_Handler,
 		},
+		{
+			MethodName: "GetRegistrations",
+			Handler:    _EventService_GetRegistrations_Handler,
+		},
+		{
+			MethodName: "ListEventAttendees",
+			Handler:    _EventService_ListEventAttendees_Handler,
+		},
+		{
+			MethodName: "RemoveAttendee",
+			Handler:    _EventService_RemoveAttendee_Handler,
+		},
 		{
 			MethodName: "GetUserRegistrations",
 			Handler:    _EventService_GetUserRegistrations_Handler,
 		},
+		{
+			MethodName: "SearchEvents",
+			Handler:    _EventService_SearchEvents_Handler,
+		},
+		{
+			MethodName: "GetNearbyEvents",
+			Handler:    _EventService_GetNearbyEvents_Handler,
+		},
+		{
+			MethodName: "PublishEvent",
+			Handler:    _EventService_PublishEvent_Handler,
+		},
+		{
+			MethodName: "CancelEvent",
+			Handler:    _EventService_CancelEvent_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportEvents",
+			Handler:       _EventService_ImportEvents_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _EventService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/event.proto",
 }
@@ -0,0 +1,480 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// (RRULE/EXDATE) needed to expand recurring events into occurrences.
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the RRULE FREQ part
+type Frequency string
+
+// Supported frequencies. Sub-daily rules (SECONDLY, MINUTELY, HOURLY) are
+// rejected, so walking a series takes at most one step per day.
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// maxPeriods bounds the expansion loop so a rule that never matches cannot spin forever
+const maxPeriods = 100000
+
+// endOfTime bounds the walk over a whole series
+var endOfTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// ErrInvalidRule is returned when an RRULE cannot be parsed
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU or -1FR
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int // 0 means every matching weekday in the period
+}
+
+// Rule is a parsed RRULE
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	WeekStart  time.Weekday
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse parses an RRULE value such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10".
+// A leading "RRULE:" prefix is accepted.
+func Parse(value string) (*Rule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1, WeekStart: time.Monday}
+	for _, part := range strings.Split(value, ";") {
+		name, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(val))
+			switch rule.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ %q", val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err == nil && rule.Interval < 1 {
+				err = errors.New("INTERVAL must be positive")
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err == nil && rule.Count < 1 {
+				err = errors.New("COUNT must be positive")
+			}
+		case "UNTIL":
+			rule.Until, err = ParseDateTime(val)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(val)
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseInts(val, -31, 31)
+		case "BYMONTH":
+			var months []int
+			months, err = parseInts(val, 1, 12)
+			for _, m := range months {
+				rule.ByMonth = append(rule.ByMonth, time.Month(m))
+			}
+		case "WKST":
+			day, known := weekdayCodes[strings.ToUpper(val)]
+			if !known {
+				err = fmt.Errorf("unknown WKST %q", val)
+			}
+			rule.WeekStart = day
+		default:
+			err = fmt.Errorf("unsupported part %q", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalidRule)
+	}
+	return rule, nil
+}
+
+// String formats the rule back into RRULE syntax (without the "RRULE:" prefix)
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+FormatDateTime(r.Until))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, d := range r.ByDay {
+			days[i] = weekdayCode(d.Weekday)
+			if d.N != 0 {
+				days[i] = strconv.Itoa(d.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]int, len(r.ByMonth))
+		for i, m := range r.ByMonth {
+			months[i] = int(m)
+		}
+		parts = append(parts, "BYMONTH="+joinInts(months))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Between returns the occurrences of a series starting at dtstart that fall
+// within [from, to), skipping any listed in exdates. The first occurrence is
+// always dtstart itself, as required by RFC 5545.
+func (r *Rule) Between(dtstart, from, to time.Time, exdates []time.Time) []time.Time {
+	var occurrences []time.Time
+	r.iterate(dtstart, to, func(t time.Time) bool {
+		if !t.Before(from) && t.Before(to) && !isExcluded(t, exdates) {
+			occurrences = append(occurrences, t)
+		}
+		return true
+	})
+	return occurrences
+}
+
+// Index returns the zero-based position of occurrence in the series, or -1
+// if occurrence is not generated by the rule.
+func (r *Rule) Index(dtstart, occurrence time.Time) int {
+	index, found := 0, -1
+	r.iterate(dtstart, occurrence.Add(time.Second), func(t time.Time) bool {
+		if t.Equal(occurrence) {
+			found = index
+			return false
+		}
+		index++
+		return true
+	})
+	return found
+}
+
+// EndsBefore reports whether every occurrence of a series starting at dtstart,
+// other than those listed in exdates, starts before t. A series without COUNT
+// or UNTIL never ends and one with UNTIL before t has ended, both without
+// walking the series; otherwise the walk stops at the first occurrence from t.
+func (r *Rule) EndsBefore(dtstart, t time.Time, exdates []time.Time) bool {
+	switch {
+	case !dtstart.Before(t):
+		return false
+	case r.Count == 0 && r.Until.IsZero():
+		return false
+	case !r.Until.IsZero() && r.Until.Before(t):
+		return true
+	}
+	ended := true
+	r.iterate(dtstart, endOfTime, func(occurrence time.Time) bool {
+		if !occurrence.Before(t) && !isExcluded(occurrence, exdates) {
+			ended = false
+			return false
+		}
+		return true
+	})
+	return ended
+}
+
+// Last returns the final occurrence of a series bounded by COUNT or UNTIL,
+// skipping any listed in exdates. ok is false when the series never ends.
+func (r *Rule) Last(dtstart time.Time, exdates []time.Time) (last time.Time, ok bool) {
+	if r.Count == 0 && r.Until.IsZero() {
+		return time.Time{}, false
+	}
+	last = dtstart
+	r.iterate(dtstart, endOfTime, func(t time.Time) bool {
+		if !isExcluded(t, exdates) {
+			last = t
+		}
+		return true
+	})
+	return last, true
+}
+
+// iterate calls yield for every occurrence before limit, in order, honouring
+// COUNT and UNTIL. Iteration stops early when yield returns false.
+func (r *Rule) iterate(dtstart, limit time.Time, yield func(time.Time) bool) {
+	emitted := 0
+	if !dtstart.Before(limit) || !yield(dtstart) {
+		return
+	}
+	emitted++
+
+	period := dtstart
+	for i := 0; i < maxPeriods; i++ {
+		candidates := r.candidates(dtstart, period)
+		for _, t := range candidates {
+			if !t.After(dtstart) {
+				continue
+			}
+			if r.Count > 0 && emitted >= r.Count {
+				return
+			}
+			if !r.Until.IsZero() && t.After(r.Until) {
+				return
+			}
+			if !t.Before(limit) {
+				return
+			}
+			if !yield(t) {
+				return
+			}
+			emitted++
+		}
+		period = r.next(period)
+	}
+}
+
+// next advances period by INTERVAL units of the rule's frequency
+func (r *Rule) next(period time.Time) time.Time {
+	switch r.Freq {
+	case Daily:
+		return period.AddDate(0, 0, r.Interval)
+	case Weekly:
+		return period.AddDate(0, 0, 7*r.Interval)
+	case Monthly:
+		return firstOfMonth(period).AddDate(0, r.Interval, 0)
+	default:
+		return time.Date(period.Year()+r.Interval, time.January, 1, 0, 0, 0, 0, period.Location())
+	}
+}
+
+// candidates expands a single period into sorted occurrence start times
+func (r *Rule) candidates(dtstart, period time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case Daily:
+		if r.matchesByDay(period) && r.matchesByMonthDay(period) {
+			days = append(days, period)
+		}
+	case Weekly:
+		start := period.AddDate(0, 0, -int((7+period.Weekday()-r.WeekStart)%7))
+		for d := 0; d < 7; d++ {
+			day := start.AddDate(0, 0, d)
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchesByDay(day) {
+				continue
+			}
+			days = append(days, day)
+		}
+	case Monthly:
+		days = r.monthDays(dtstart, firstOfMonth(period))
+	case Yearly:
+		months := r.ByMonth
+		if len(months) == 0 {
+			months = []time.Month{dtstart.Month()}
+		}
+		for _, m := range months {
+			first := time.Date(period.Year(), m, 1, 0, 0, 0, 0, period.Location())
+			days = append(days, r.monthDays(dtstart, first)...)
+		}
+	}
+
+	result := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		if len(r.ByMonth) > 0 && !containsMonth(r.ByMonth, day.Month()) {
+			continue
+		}
+		result = append(result, time.Date(day.Year(), day.Month(), day.Day(),
+			dtstart.Hour(), dtstart.Minute(), dtstart.Second(), dtstart.Nanosecond(), dtstart.Location()))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// monthDays returns the days of the month starting at first selected by BYDAY/BYMONTHDAY
+func (r *Rule) monthDays(dtstart, first time.Time) []time.Time {
+	last := first.AddDate(0, 1, -1).Day()
+	var days []time.Time
+	for d := 1; d <= last; d++ {
+		day := first.AddDate(0, 0, d-1)
+		switch {
+		case len(r.ByDay) == 0 && len(r.ByMonthDay) == 0:
+			if d != dtstart.Day() {
+				continue
+			}
+		case len(r.ByMonthDay) > 0 && !r.matchesByMonthDay(day):
+			continue
+		case len(r.ByDay) > 0 && !r.matchesByDayInMonth(day, last):
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+func (r *Rule) matchesByDay(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, d := range r.ByDay {
+		if d.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesByDayInMonth(day time.Time, daysInMonth int) bool {
+	for _, d := range r.ByDay {
+		if d.Weekday != day.Weekday() {
+			continue
+		}
+		fromStart := (day.Day()-1)/7 + 1
+		fromEnd := -((daysInMonth-day.Day())/7 + 1)
+		if d.N == 0 || d.N == fromStart || d.N == fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesByMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := firstOfMonth(day).AddDate(0, 1, -1).Day()
+	for _, md := range r.ByMonthDay {
+		if md == day.Day() || (md < 0 && last+md+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseDateTime parses an iCalendar DATE or DATE-TIME value. Values without
+// a trailing "Z" are interpreted as UTC.
+func ParseDateTime(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+}
+
+// FormatDateTime formats t as a UTC iCalendar DATE-TIME value
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		code, prefix := item[len(item)-2:], item[:len(item)-2]
+		day, ok := weekdayCodes[code]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+		n := 0
+		if prefix != "" {
+			var err error
+			n, err = strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY ordinal %q", item)
+			}
+		}
+		days = append(days, WeekdayNum{Weekday: day, N: n})
+	}
+	return days, nil
+}
+
+func parseInts(value string, minValue, maxValue int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(item)
+		if err != nil || n == 0 || n < minValue || n > maxValue {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func weekdayCode(day time.Weekday) string {
+	for code, d := range weekdayCodes {
+		if d == day {
+			return code
+		}
+	}
+	return ""
+}
+
+func firstOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+}
+
+func containsMonth(months []time.Month, m time.Month) bool {
+	for _, month := range months {
+		if month == m {
+			return true
+		}
+	}
+	return false
+}
+
+func isExcluded(t time.Time, exdates []time.Time) bool {
+	for _, ex := range exdates {
+		if ex.Equal(t) {
+			return true
+		}
+	}
+	return false
+}
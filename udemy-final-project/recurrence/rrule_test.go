@@ -0,0 +1,160 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(year int, month time.Month, day, hour int) time.Time {
+	return time.Date(year, month, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestParse_RoundTrip(t *testing.T) {
+	rule, err := Parse("RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;UNTIL=20251231T235959Z")
+	require.NoError(t, err)
+	assert.Equal(t, Weekly, rule.Freq)
+	assert.Equal(t, 2, rule.Interval)
+	assert.Equal(t, "FREQ=WEEKLY;INTERVAL=2;UNTIL=20251231T235959Z;BYDAY=MO,WE", rule.String())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, value := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=MINUTELY",
+		"FREQ=SECONDLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20250101",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYSETPOS=1",
+	} {
+		_, err := Parse(value)
+		assert.ErrorIs(t, err, ErrInvalidRule, value)
+	}
+}
+
+func TestBetween_WeeklyCount(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4")
+	require.NoError(t, err)
+
+	// 2025-01-07 is a Tuesday
+	start := date(2025, time.January, 7, 18)
+	got := rule.Between(start, start, date(2026, time.January, 1, 0), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.January, 7, 18),
+		date(2025, time.January, 9, 18),
+		date(2025, time.January, 14, 18),
+		date(2025, time.January, 16, 18),
+	}, got)
+}
+
+func TestBetween_WindowAndExdates(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY")
+	require.NoError(t, err)
+
+	start := date(2025, time.March, 1, 9)
+	exdates := []time.Time{date(2025, time.March, 11, 9)}
+	got := rule.Between(start, date(2025, time.March, 10, 0), date(2025, time.March, 13, 0), exdates)
+	assert.Equal(t, []time.Time{
+		date(2025, time.March, 10, 9),
+		date(2025, time.March, 12, 9),
+	}, got)
+}
+
+func TestBetween_WindowBeforeStart(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;COUNT=3")
+	require.NoError(t, err)
+
+	start := date(2030, time.January, 7, 0)
+	assert.Empty(t, rule.Between(start, date(2030, time.January, 1, 0), date(2030, time.January, 6, 0), nil))
+	assert.Empty(t, rule.Between(start, date(2030, time.January, 1, 0), start, nil), "the window end is exclusive")
+	assert.Equal(t, []time.Time{start}, rule.Between(start, date(2030, time.January, 1, 0), start.Add(time.Hour), nil))
+}
+
+func TestBetween_MonthlyLastFriday(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;BYDAY=-1FR;COUNT=3")
+	require.NoError(t, err)
+
+	start := date(2025, time.January, 31, 17)
+	got := rule.Between(start, start, date(2026, time.January, 1, 0), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.January, 31, 17),
+		date(2025, time.February, 28, 17),
+		date(2025, time.March, 28, 17),
+	}, got)
+}
+
+func TestBetween_MonthlySkipsShortMonths(t *testing.T) {
+	rule, err := Parse("FREQ=MONTHLY;COUNT=3")
+	require.NoError(t, err)
+
+	start := date(2025, time.January, 31, 12)
+	got := rule.Between(start, start, date(2026, time.January, 1, 0), nil)
+	assert.Equal(t, []time.Time{
+		date(2025, time.January, 31, 12),
+		date(2025, time.March, 31, 12),
+		date(2025, time.May, 31, 12),
+	}, got)
+}
+
+func TestIndex(t *testing.T) {
+	rule, err := Parse("FREQ=WEEKLY;UNTIL=20250301T000000Z")
+	require.NoError(t, err)
+
+	start := date(2025, time.January, 6, 10)
+	assert.Equal(t, 0, rule.Index(start, start))
+	assert.Equal(t, 2, rule.Index(start, date(2025, time.January, 20, 10)))
+	assert.Equal(t, -1, rule.Index(start, date(2025, time.January, 21, 10)))
+	assert.Equal(t, -1, rule.Index(start, date(2025, time.March, 3, 10)))
+}
+
+func TestEndsBefore(t *testing.T) {
+	start := date(2025, time.January, 7, 18)
+
+	counted, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4")
+	require.NoError(t, err)
+	assert.False(t, counted.EndsBefore(start, start, nil))
+	assert.False(t, counted.EndsBefore(start, date(2025, time.January, 16, 18), nil))
+	assert.True(t, counted.EndsBefore(start, date(2025, time.January, 16, 19), nil))
+	assert.True(t, counted.EndsBefore(start, date(2025, time.January, 15, 0), []time.Time{date(2025, time.January, 16, 18)}))
+
+	until, err := Parse("FREQ=DAILY;UNTIL=99991230T000000Z")
+	require.NoError(t, err)
+	assert.False(t, until.EndsBefore(start, date(2026, time.January, 1, 0), nil))
+	ended, err := Parse("FREQ=DAILY;UNTIL=20250201T000000Z")
+	require.NoError(t, err)
+	assert.True(t, ended.EndsBefore(start, date(2025, time.February, 1, 1), nil))
+
+	endless, err := Parse("FREQ=DAILY")
+	require.NoError(t, err)
+	assert.False(t, endless.EndsBefore(start, date(9000, time.January, 1, 0), nil))
+}
+
+func TestLast(t *testing.T) {
+	start := date(2025, time.January, 7, 18)
+
+	counted, err := Parse("FREQ=WEEKLY;BYDAY=TU,TH;COUNT=4")
+	require.NoError(t, err)
+	last, ok := counted.Last(start, nil)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.January, 16, 18), last)
+
+	last, ok = counted.Last(start, []time.Time{date(2025, time.January, 16, 18)})
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.January, 14, 18), last)
+
+	until, err := Parse("FREQ=WEEKLY;UNTIL=20250201T000000Z")
+	require.NoError(t, err)
+	last, ok = until.Last(start, nil)
+	assert.True(t, ok)
+	assert.Equal(t, date(2025, time.January, 28, 18), last)
+
+	endless, err := Parse("FREQ=DAILY")
+	require.NoError(t, err)
+	_, ok = endless.Last(start, nil)
+	assert.False(t, ok)
+}
@@ -0,0 +1,709 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/tracing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// store is an implementation under test
+type store struct {
+	Repositories
+	// actions returns the actions of the outbox messages queued so far, oldest first
+	actions func() []string
+	// traces returns the trace IDs of the outbox messages queued so far, oldest first
+	traces func() []string
+}
+
+// start is the first occurrence of the events of the tests
+var start = time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC)
+
+// runConformance runs the tests every implementation must pass, each on an
+// empty store returned by open
+func runConformance(t *testing.T, open func(*testing.T) store) {
+	tests := []struct {
+		name string
+		run  func(*testing.T, store)
+	}{
+		{"Users", testUsers},
+		{"Events", testEvents},
+		{"Lifecycle", testLifecycle},
+		{"Queries", testQueries},
+		{"Registrations", testRegistrations},
+		{"UniqueRegistrations", testUniqueRegistrations},
+		{"Attendees", testAttendees},
+		{"Occurrences", testOccurrences},
+		{"SplitSeries", testSplitSeries},
+		{"Traces", testTraces},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, open(t))
+		})
+	}
+}
+
+func newUser(t *testing.T, s store, email string) models.User {
+	t.Helper()
+	user := models.User{Email: email, Password: "secret1"}
+	require.NoError(t, s.Users.Create(&user))
+	return user
+}
+
+// newEvent creates an event of owner, published unless edit changes it
+func newEvent(t *testing.T, s store, owner models.User, name string, edit func(*models.Event)) models.Event {
+	t.Helper()
+	event := models.Event{
+		Name:        name,
+		Description: name + " description",
+		Location:    "Istanbul",
+		DateTime:    start,
+		UserID:      owner.ID,
+		Status:      models.EventStatusPublished,
+	}
+	if edit != nil {
+		edit(&event)
+	}
+	require.NoError(t, s.Events.Create(t.Context(), &event))
+	return event
+}
+
+// names returns the names of events in order
+func names(events []models.Event) []string {
+	result := []string{}
+	for _, e := range events {
+		result = append(result, e.Name)
+	}
+	return result
+}
+
+// window lists the occurrences starting within the first five weeks of the tests
+func window(t *testing.T, s store, viewerID int64) []models.Event {
+	t.Helper()
+	page, err := s.Events.List(models.EventQuery{From: start, To: start.AddDate(0, 0, 35), ViewerID: viewerID})
+	require.NoError(t, err)
+	return page.Events
+}
+
+func testUsers(t *testing.T, s store) {
+	user := newUser(t, s, "alice@example.com")
+	assert.NotZero(t, user.ID)
+	assert.Equal(t, policy.RoleUser, user.Role)
+	assert.NotEqual(t, "secret1", user.Password, "the password is hashed")
+	err := s.Users.Create(&models.User{Email: "alice@example.com", Password: "secret2"})
+	assert.ErrorIs(t, err, models.ErrEmailTaken)
+
+	found, err := s.Users.GetByEmail("alice@example.com")
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.Equal(t, user.ID, found.ID)
+	found, err = s.Users.GetByEmail("bob@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	verified, err := s.Users.VerifyCredentials("alice@example.com", "secret1")
+	require.NoError(t, err)
+	require.NotNil(t, verified)
+	assert.Equal(t, user.ID, verified.ID)
+	verified, err = s.Users.VerifyCredentials("alice@example.com", "wrong")
+	require.NoError(t, err)
+	assert.Nil(t, verified)
+	verified, err = s.Users.VerifyCredentials("bob@example.com", "secret1")
+	require.NoError(t, err)
+	assert.Nil(t, verified)
+
+	updated, err := s.Users.SetRole(user.ID, policy.RoleOrganizer)
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleOrganizer, updated.Role)
+	assert.Empty(t, updated.Password)
+	_, err = s.Users.SetRole(user.ID, "superuser")
+	assert.ErrorIs(t, err, models.ErrInvalidRole)
+	_, err = s.Users.SetRole(user.ID+100, policy.RoleAdmin)
+	assert.ErrorIs(t, err, models.ErrUserNotFound)
+
+	promoted, err := s.Users.PromoteAdmins([]string{"ALICE@example.com", "nobody@example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), promoted)
+	promoted, err = s.Users.PromoteAdmins([]string{"alice@example.com"})
+	require.NoError(t, err)
+	assert.Zero(t, promoted, "admins are not promoted again")
+	found, err = s.Users.GetByEmail("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleAdmin, found.Role)
+
+	byToken, err := s.Users.GetByCalendarTokenHash(user.ID, "")
+	require.NoError(t, err)
+	assert.Nil(t, byToken, "users without a token never match")
+	require.NoError(t, s.Users.SetCalendarTokenHash(user.ID, "hash"))
+	byToken, err = s.Users.GetByCalendarTokenHash(user.ID, "hash")
+	require.NoError(t, err)
+	require.NotNil(t, byToken)
+	assert.Equal(t, user.ID, byToken.ID)
+	byToken, err = s.Users.GetByCalendarTokenHash(user.ID, "other")
+	require.NoError(t, err)
+	assert.Nil(t, byToken)
+	assert.ErrorIs(t, s.Users.SetCalendarTokenHash(user.ID+100, "hash"), models.ErrUserNotFound)
+}
+
+func testEvents(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	event := newEvent(t, s, owner, "Go Meetup", func(e *models.Event) {
+		e.Status = ""
+		e.RRule = "freq=weekly;count=2"
+		e.Address = models.Address{City: "Istanbul", Country: "TR"}
+	})
+	assert.NotZero(t, event.ID)
+	assert.Equal(t, models.EventStatusDraft, event.Status, "events start as drafts")
+
+	stored, err := s.Events.Get(event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go Meetup", stored.Name)
+	assert.True(t, start.Equal(stored.DateTime))
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", stored.RRule, "the rule is normalized")
+	assert.Equal(t, "Istanbul", stored.Address.City)
+	assert.Equal(t, models.EventStatusDraft, stored.Status)
+	_, err = s.Events.Get(event.ID + 100)
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+
+	event.Name = "Go Meetup (renamed)"
+	event.Capacity = 10
+	event.RRule = ""
+	event.ExDates = []time.Time{start}
+	require.NoError(t, s.Events.Update(t.Context(), &event))
+	stored, err = s.Events.Get(event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Go Meetup (renamed)", stored.Name)
+	assert.Equal(t, 10, stored.Capacity)
+	assert.Empty(t, stored.ExDates, "only recurring events have excluded dates")
+	missing := event
+	missing.ID += 100
+	assert.ErrorIs(t, s.Events.Update(t.Context(), &missing), models.ErrEventNotFound)
+
+	all, err := s.Events.All()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go Meetup (renamed)"}, names(all))
+
+	require.NoError(t, s.Events.Delete(t.Context(), event.ID))
+	_, err = s.Events.Get(event.ID)
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+	assert.ErrorIs(t, s.Events.Delete(t.Context(), event.ID), models.ErrEventNotFound)
+
+	assert.Equal(t, []string{models.EventActionCreated, models.EventActionUpdated, models.EventActionDeleted}, s.actions())
+}
+
+func testLifecycle(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	draft := newEvent(t, s, owner, "Draft", func(e *models.Event) { e.Status = models.EventStatusDraft })
+
+	published, err := s.Events.Publish(t.Context(), draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventStatusPublished, published.Status)
+	assert.NotNil(t, published.PublishedAt)
+	_, err = s.Events.Publish(t.Context(), draft.ID)
+	assert.ErrorIs(t, err, models.ErrInvalidTransition)
+	_, err = s.Events.Publish(t.Context(), draft.ID+100)
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+
+	_, err = s.Events.Cancel(t.Context(), draft.ID, "  ")
+	assert.ErrorIs(t, err, models.ErrCancelReasonRequired)
+	cancelled, err := s.Events.Cancel(t.Context(), draft.ID, " Venue unavailable ")
+	require.NoError(t, err)
+	assert.Equal(t, models.EventStatusCancelled, cancelled.Status)
+	assert.Equal(t, "Venue unavailable", cancelled.CancelReason)
+	assert.NotNil(t, cancelled.CancelledAt)
+	stored, err := s.Events.Get(draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventStatusCancelled, stored.Status)
+
+	ended := newEvent(t, s, owner, "Ended", nil)
+	newEvent(t, s, owner, "Endless", func(e *models.Event) { e.RRule = "FREQ=WEEKLY" })
+	newEvent(t, s, owner, "Later", func(e *models.Event) { e.DateTime = start.Add(2 * time.Hour) })
+
+	now := start.Add(time.Hour)
+	completed, err := s.Events.CompleteEnded(now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Ended"}, names(completed))
+	assert.Equal(t, models.EventStatusCompleted, completed[0].Status)
+	stored, err = s.Events.Get(ended.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EventStatusCompleted, stored.Status)
+	require.NotNil(t, stored.CompletedAt)
+	assert.True(t, now.Equal(*stored.CompletedAt))
+	completed, err = s.Events.CompleteEnded(now)
+	require.NoError(t, err)
+	assert.Empty(t, completed)
+
+	assert.Equal(t, []string{
+		models.EventActionCreated, models.EventActionPublished, models.EventActionCancelled,
+		models.EventActionCreated, models.EventActionCreated, models.EventActionCreated,
+		models.EventActionCompleted,
+	}, s.actions())
+}
+
+func testQueries(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	taksim, kizilay := []float64{41.0369, 28.9850}, []float64{39.9208, 32.8541}
+	newEvent(t, s, owner, "Go Meetup", func(e *models.Event) {
+		e.Latitude, e.Longitude = &taksim[0], &taksim[1]
+	})
+	newEvent(t, s, owner, "Yoga", func(e *models.Event) {
+		e.DateTime = start.Add(time.Hour)
+		e.Location = "Ankara"
+		e.Latitude, e.Longitude = &kizilay[0], &kizilay[1]
+	})
+	newEvent(t, s, owner, "Draft Meetup Plans", func(e *models.Event) {
+		e.DateTime = start.Add(2 * time.Hour)
+		e.Status = models.EventStatusDraft
+		e.Latitude, e.Longitude = &taksim[0], &taksim[1]
+	})
+
+	// Drafts are only found by their owner
+	page, err := s.Events.List(models.EventQuery{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go Meetup", "Yoga"}, names(page.Events))
+	page, err = s.Events.List(models.EventQuery{Location: "ISTAN", ViewerID: owner.ID})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Go Meetup", "Draft Meetup Plans"}, names(page.Events))
+
+	query := models.EventQuery{ViewerID: owner.ID, SortBy: models.SortByName, Descending: true, PageSize: 2}
+	var listed []models.Event
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 2)
+		page, err := s.Events.List(query)
+		require.NoError(t, err)
+		listed = append(listed, page.Events...)
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+	assert.Equal(t, []string{"Yoga", "Go Meetup", "Draft Meetup Plans"}, names(listed))
+	_, err = s.Events.List(models.EventQuery{PageToken: "garbage"})
+	assert.ErrorIs(t, err, models.ErrInvalidPageToken)
+
+	results, err := s.Events.Search(models.SearchQuery{Text: "meetup"})
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	assert.Equal(t, "Go Meetup", results.Results[0].Event.Name)
+	assert.Contains(t, results.Results[0].Highlights["name"], "<mark>Meetup</mark>")
+	other := newUser(t, s, "other@example.com")
+	results, err = s.Events.Search(models.SearchQuery{Text: "meetup", ViewerID: other.ID})
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1, "another user's draft is not found")
+	assert.Equal(t, "Go Meetup", results.Results[0].Event.Name)
+	results, err = s.Events.Search(models.SearchQuery{Text: "meetup", ViewerID: owner.ID, PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, results.Results, 1)
+	require.NotEmpty(t, results.NextPageToken)
+	next, err := s.Events.Search(models.SearchQuery{Text: "meetup", ViewerID: owner.ID, PageSize: 1, PageToken: results.NextPageToken})
+	require.NoError(t, err)
+	require.Len(t, next.Results, 1)
+	assert.ElementsMatch(t, []string{"Go Meetup", "Draft Meetup Plans"},
+		[]string{results.Results[0].Event.Name, next.Results[0].Event.Name})
+	_, err = s.Events.Search(models.SearchQuery{Text: " ! "})
+	assert.ErrorIs(t, err, models.ErrEmptySearch)
+
+	nearby, err := s.Events.Nearby(models.NearbyQuery{Latitude: 41.0, Longitude: 29.0, RadiusKm: 50})
+	require.NoError(t, err)
+	require.Len(t, nearby, 1)
+	assert.Equal(t, "Go Meetup", nearby[0].Name)
+	assert.InDelta(t, 4.3, nearby[0].DistanceKm, 0.5)
+	nearby, err = s.Events.Nearby(models.NearbyQuery{Latitude: 41.0, Longitude: 29.0, RadiusKm: 500, ViewerID: owner.ID})
+	require.NoError(t, err)
+	assert.Len(t, nearby, 3)
+	assert.Equal(t, "Yoga", nearby[2].Name, "nearest first")
+	_, err = s.Events.Nearby(models.NearbyQuery{Latitude: 91, RadiusKm: 5})
+	assert.ErrorIs(t, err, models.ErrInvalidCoordinates)
+}
+
+func testRegistrations(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	first := newUser(t, s, "first@example.com")
+	second := newUser(t, s, "second@example.com")
+	third := newUser(t, s, "third@example.com")
+	event := newEvent(t, s, owner, "Workshop", func(e *models.Event) {
+		e.Capacity = 1
+		e.Status = models.EventStatusDraft
+	})
+
+	_, err := s.Registrations.Register(t.Context(), event.ID, first.ID, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, models.ErrRegistrationClosed)
+	_, err = s.Registrations.Register(t.Context(), event.ID+100, first.ID, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+	_, err = s.Events.Publish(t.Context(), event.ID)
+	require.NoError(t, err)
+
+	confirmed, err := s.Registrations.Register(t.Context(), event.ID, first.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusConfirmed, confirmed.Status)
+	assert.Equal(t, models.ScopeSeries, confirmed.Scope)
+	var waitlisted []*models.Registration
+	for _, user := range []models.User{second, third} {
+		registration, err := s.Registrations.Register(t.Context(), event.ID, user.ID, models.OccurrenceTarget{})
+		require.NoError(t, err)
+		assert.Equal(t, models.RegistrationStatusWaitlisted, registration.Status)
+		waitlisted = append(waitlisted, registration)
+	}
+	for i, registration := range waitlisted {
+		position, err := s.Registrations.WaitlistPosition(registration)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), position)
+	}
+
+	// Cancelling the confirmed seat promotes the first waitlisted user
+	require.NoError(t, s.Registrations.Cancel(t.Context(), first.ID, event.ID, nil))
+	registrations, err := s.Registrations.ListByUser(second.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registrations[0].Status)
+	assert.Equal(t, "Workshop", registrations[0].Event.Name)
+	position, err := s.Registrations.WaitlistPosition(waitlisted[1])
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), position)
+	assert.ErrorIs(t, s.Registrations.Cancel(t.Context(), first.ID, event.ID+100, nil), models.ErrEventNotFound)
+
+	// Raising the capacity promotes the rest
+	event.Capacity = 2
+	require.NoError(t, s.Events.Update(t.Context(), &event))
+	registrations, err = s.Registrations.ListByUser(third.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registrations[0].Status)
+
+	events, err := s.Registrations.EventsOfUser(third.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Workshop"}, names(events))
+	events, err = s.Registrations.EventsOfUser(first.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	// Deleting the event removes its registrations
+	require.NoError(t, s.Events.Delete(t.Context(), event.ID))
+	registrations, err = s.Registrations.ListByUser(third.ID)
+	require.NoError(t, err)
+	assert.Empty(t, registrations)
+
+	assert.Equal(t, []string{
+		models.EventActionCreated, models.EventActionPublished,
+		models.RegistrationActionCreated, models.RegistrationActionCreated, models.RegistrationActionCreated,
+		models.RegistrationActionCancelled, models.RegistrationActionPromoted,
+		models.RegistrationActionPromoted, models.EventActionUpdated,
+		models.EventActionDeleted,
+	}, s.actions())
+}
+
+func testUniqueRegistrations(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	attendee := newUser(t, s, "attendee@example.com")
+	regular := newUser(t, s, "regular@example.com")
+	series := newEvent(t, s, owner, "Weekly", func(e *models.Event) {
+		e.RRule = "FREQ=WEEKLY;COUNT=4"
+		e.Capacity = 1
+	})
+	second, third := start.AddDate(0, 0, 7), start.AddDate(0, 0, 14)
+	following := models.OccurrenceTarget{Start: &third, Scope: models.ScopeFollowing}
+
+	// Occurrences can be registered for one by one, each once
+	_, err := s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &second})
+	require.NoError(t, err)
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &second})
+	assert.ErrorIs(t, err, models.ErrAlreadyRegistered)
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, models.ErrAlreadyRegistered, "the series covers the registered occurrence")
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, following)
+	require.NoError(t, err)
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &third})
+	assert.ErrorIs(t, err, models.ErrAlreadyRegistered, "the following occurrences are covered")
+	events, err := s.Registrations.EventsOfUser(attendee.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Weekly"}, names(events), "each event is listed once")
+
+	waitlisted, err := s.Registrations.Register(t.Context(), series.ID, regular.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, waitlisted.Status)
+	assert.False(t, waitlisted.CreatedAt.IsZero())
+	_, err = s.Registrations.Register(t.Context(), series.ID, regular.ID, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, models.ErrAlreadyRegistered, "waitlisted registrations count too")
+
+	// Cancelling needs a registration to cancel
+	assert.ErrorIs(t, s.Registrations.Cancel(t.Context(), attendee.ID, series.ID, nil), models.ErrRegistrationNotFound)
+	assert.ErrorIs(t, s.Registrations.Cancel(t.Context(), attendee.ID, series.ID, &start), models.ErrRegistrationNotFound)
+	require.NoError(t, s.Registrations.Cancel(t.Context(), attendee.ID, series.ID, &second))
+	assert.ErrorIs(t, s.Registrations.Cancel(t.Context(), attendee.ID, series.ID, &second), models.ErrRegistrationNotFound)
+	require.NoError(t, s.Registrations.Cancel(t.Context(), attendee.ID, series.ID, &third))
+
+	// The waitlisted registration got the freed seats, which its history shows
+	registrations, err := s.Registrations.ListForEvent(regular.ID, series.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	promoted := registrations[0]
+	assert.Equal(t, models.RegistrationStatusConfirmed, promoted.Status)
+	assert.True(t, waitlisted.CreatedAt.Equal(promoted.CreatedAt))
+	assert.False(t, promoted.UpdatedAt.Before(promoted.CreatedAt))
+	var statuses []string
+	for _, change := range promoted.History {
+		statuses = append(statuses, change.Status)
+		assert.False(t, change.ChangedAt.IsZero())
+	}
+	assert.Equal(t, []string{models.RegistrationStatusWaitlisted, models.RegistrationStatusConfirmed}, statuses)
+
+	// Cancelled registrations are kept with their history but listed nowhere else
+	history := func(registration models.Registration) []string {
+		var statuses []string
+		for _, change := range registration.History {
+			statuses = append(statuses, change.Status)
+		}
+		return statuses
+	}
+	registrations, err = s.Registrations.ListForEvent(attendee.ID, series.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 2)
+	cancelled := registrations[0]
+	assert.True(t, second.Equal(*cancelled.OccurrenceStart))
+	for _, registration := range registrations {
+		assert.Equal(t, models.RegistrationStatusCancelled, registration.Status)
+		assert.Equal(t, []string{models.RegistrationStatusConfirmed, models.RegistrationStatusCancelled}, history(registration))
+	}
+	events, err = s.Registrations.EventsOfUser(attendee.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	registered, err := s.Registrations.ListByUser(attendee.ID)
+	require.NoError(t, err)
+	assert.Empty(t, registered)
+	attendees, err := s.Registrations.Attendees(models.AttendeeQuery{EventID: series.ID})
+	require.NoError(t, err)
+	require.Len(t, attendees.Attendees, 1)
+	assert.Equal(t, regular.ID, attendees.Attendees[0].UserID)
+
+	// Registering again reactivates the cancelled registration, continuing its history
+	reactivated, err := s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &second})
+	require.NoError(t, err, "a cancelled registration can be made again")
+	assert.Equal(t, cancelled.ID, reactivated.ID)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, reactivated.Status, "the promoted registration holds the seat")
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &second})
+	assert.ErrorIs(t, err, models.ErrAlreadyRegistered)
+	registrations, err = s.Registrations.ListForEvent(attendee.ID, series.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 2)
+	assert.Equal(t, reactivated.ID, registrations[1].ID, "the reactivated registration joins the back of the waitlist")
+	assert.Equal(t, []string{models.RegistrationStatusConfirmed, models.RegistrationStatusCancelled, models.RegistrationStatusWaitlisted},
+		history(registrations[1]))
+}
+
+func testAttendees(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	event := newEvent(t, s, owner, "Workshop", func(e *models.Event) { e.Capacity = 2 })
+	other := newEvent(t, s, owner, "Other", nil)
+	var emails []string
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		user := newUser(t, s, email)
+		_, err := s.Registrations.Register(t.Context(), event.ID, user.ID, models.OccurrenceTarget{})
+		require.NoError(t, err)
+		emails = append(emails, email)
+	}
+	_, err := s.Registrations.Register(t.Context(), other.ID, owner.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+
+	// Attendees are paged in the order they registered
+	var listed []models.Attendee
+	query := models.AttendeeQuery{EventID: event.ID, PageSize: 2}
+	for {
+		page, err := s.Registrations.Attendees(query)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(page.Attendees), 2)
+		listed = append(listed, page.Attendees...)
+		if page.NextPageToken == "" {
+			break
+		}
+		query.PageToken = page.NextPageToken
+	}
+	var listedEmails []string
+	for _, attendee := range listed {
+		listedEmails = append(listedEmails, attendee.Email)
+		assert.NotZero(t, attendee.RegistrationID)
+		assert.False(t, attendee.RegisteredAt.IsZero())
+	}
+	assert.Equal(t, emails, listedEmails)
+
+	page, err := s.Registrations.Attendees(models.AttendeeQuery{EventID: event.ID, Status: models.RegistrationStatusWaitlisted})
+	require.NoError(t, err)
+	require.Len(t, page.Attendees, 1)
+	assert.Equal(t, "c@example.com", page.Attendees[0].Email)
+	assert.Empty(t, page.NextPageToken)
+	_, err = s.Registrations.Attendees(models.AttendeeQuery{EventID: event.ID, Status: "cancelled"})
+	assert.ErrorIs(t, err, models.ErrInvalidRegistrationStatus)
+	_, err = s.Registrations.Attendees(models.AttendeeQuery{EventID: event.ID, PageToken: "garbage"})
+	assert.ErrorIs(t, err, models.ErrInvalidPageToken)
+
+	// Removing a confirmed attendee promotes the waitlisted one
+	assert.ErrorIs(t, s.Registrations.Remove(t.Context(), other.ID, listed[0].RegistrationID), models.ErrRegistrationNotFound)
+	assert.ErrorIs(t, s.Registrations.Remove(t.Context(), event.ID+100, listed[0].RegistrationID), models.ErrEventNotFound)
+	require.NoError(t, s.Registrations.Remove(t.Context(), event.ID, listed[0].RegistrationID))
+	assert.ErrorIs(t, s.Registrations.Remove(t.Context(), event.ID, listed[0].RegistrationID), models.ErrRegistrationNotFound)
+	page, err = s.Registrations.Attendees(models.AttendeeQuery{EventID: event.ID, Status: models.RegistrationStatusConfirmed})
+	require.NoError(t, err)
+	listedEmails = nil
+	for _, attendee := range page.Attendees {
+		listedEmails = append(listedEmails, attendee.Email)
+	}
+	assert.Equal(t, emails[1:], listedEmails)
+
+	actions := s.actions()
+	assert.Equal(t, []string{models.RegistrationActionRemoved, models.RegistrationActionPromoted}, actions[len(actions)-2:])
+}
+
+func testOccurrences(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	attendee := newUser(t, s, "attendee@example.com")
+	regular := newUser(t, s, "regular@example.com")
+	series := newEvent(t, s, owner, "Weekly", func(e *models.Event) {
+		e.RRule = "FREQ=WEEKLY;COUNT=4"
+		e.Capacity = 1
+	})
+	week := func(n int) time.Time { return start.AddDate(0, 0, 7*n) }
+	second, third := week(1), week(2)
+
+	// A seat at one occurrence leaves none for the whole series
+	registration, err := s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &second})
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registration.Status)
+	assert.Equal(t, models.ScopeOccurrence, registration.Scope)
+	registration, err = s.Registrations.Register(t.Context(), series.ID, regular.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, registration.Status)
+	notOccurrence := start.Add(time.Hour)
+	_, err = s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &notOccurrence})
+	assert.ErrorIs(t, err, models.ErrOccurrenceNotFound)
+
+	moved, err := s.Events.UpdateOccurrence(t.Context(), series.ID, third, models.Event{
+		Name: "Weekly (moved)", Description: "Later", Location: "Ankara", DateTime: third.Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly (moved)", moved.Name)
+	require.NotNil(t, moved.OccurrenceStart)
+	assert.True(t, third.Equal(*moved.OccurrenceStart))
+	occurrences := window(t, s, 0)
+	assert.Equal(t, []string{"Weekly", "Weekly", "Weekly (moved)", "Weekly"}, names(occurrences))
+	assert.True(t, third.Add(time.Hour).Equal(occurrences[2].DateTime))
+	overrides, err := s.Events.Overrides([]int64{series.ID})
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.True(t, third.Equal(overrides[0].OccurrenceStart))
+
+	// Cancelling an occurrence drops its registrations
+	require.NoError(t, s.Events.CancelOccurrence(t.Context(), series.ID, second))
+	assert.ErrorIs(t, s.Events.CancelOccurrence(t.Context(), series.ID, second), models.ErrOccurrenceNotFound)
+	registrations, err := s.Registrations.ListByUser(attendee.ID)
+	require.NoError(t, err)
+	assert.Empty(t, registrations)
+	assert.Len(t, window(t, s, 0), 3)
+
+	deleted, err := s.Events.TruncateSeries(t.Context(), series.ID, week(3))
+	require.NoError(t, err)
+	assert.False(t, deleted)
+	occurrences = window(t, s, 0)
+	require.Len(t, occurrences, 2)
+	assert.True(t, start.Equal(occurrences[0].DateTime))
+	deleted, err = s.Events.TruncateSeries(t.Context(), series.ID, start)
+	require.NoError(t, err)
+	assert.True(t, deleted, "truncating at the first occurrence leaves nothing")
+	assert.Len(t, window(t, s, 0), 2)
+
+	// Series starting when the window ends or after it are not listed
+	newEvent(t, s, owner, "Next", func(e *models.Event) {
+		e.RRule = "FREQ=WEEKLY;COUNT=3"
+		e.DateTime = start.AddDate(0, 0, 35)
+	})
+	newEvent(t, s, owner, "February", func(e *models.Event) {
+		e.RRule = "FREQ=DAILY"
+		e.DateTime = time.Date(2030, time.February, 20, 18, 0, 0, 0, time.UTC)
+	})
+	assert.Len(t, window(t, s, 0), 2)
+
+	oneOff := newEvent(t, s, owner, "Once", nil)
+	_, err = s.Events.UpdateOccurrence(t.Context(), oneOff.ID, start, oneOff)
+	assert.ErrorIs(t, err, models.ErrNotRecurring)
+}
+
+func testSplitSeries(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	attendee := newUser(t, s, "attendee@example.com")
+	regular := newUser(t, s, "regular@example.com")
+	series := newEvent(t, s, owner, "Weekly", func(e *models.Event) { e.RRule = "FREQ=WEEKLY;COUNT=4" })
+	third := start.AddDate(0, 0, 14)
+	_, err := s.Registrations.Register(t.Context(), series.ID, attendee.ID, models.OccurrenceTarget{Start: &third})
+	require.NoError(t, err)
+	_, err = s.Registrations.Register(t.Context(), series.ID, regular.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+
+	evening := third.Add(2 * time.Hour)
+	created, err := s.Events.SplitSeries(t.Context(), series.ID, third, models.Event{
+		Name: "Weekly (evening)", Description: "Moved to the evening", Location: "Istanbul", DateTime: evening,
+	})
+	require.NoError(t, err)
+	assert.NotEqual(t, series.ID, created.ID)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", created.RRule)
+	assert.Equal(t, models.EventStatusPublished, created.Status)
+	assert.Equal(t, []string{"Weekly", "Weekly", "Weekly (evening)", "Weekly (evening)"}, names(window(t, s, 0)))
+
+	// The registration of a moved occurrence follows it, and whole-series
+	// registrations cover the new series too
+	registrations, err := s.Registrations.ListByUser(attendee.ID)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	assert.Equal(t, created.ID, registrations[0].EventID)
+	require.NotNil(t, registrations[0].OccurrenceStart)
+	assert.True(t, evening.Equal(*registrations[0].OccurrenceStart))
+	registrations, err = s.Registrations.ListByUser(regular.ID)
+	require.NoError(t, err)
+	var eventIDs []int64
+	for _, registration := range registrations {
+		eventIDs = append(eventIDs, registration.EventID)
+	}
+	assert.ElementsMatch(t, []int64{series.ID, created.ID}, eventIDs)
+
+	// Splitting at the first occurrence changes the whole series
+	renamed, err := s.Events.SplitSeries(t.Context(), created.ID, evening, models.Event{
+		Name: "Weekly (late)", Description: "Later still", Location: "Istanbul", DateTime: evening,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, renamed.ID)
+	stored, err := s.Events.Get(created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Weekly (late)", stored.Name)
+
+	assert.Equal(t, []string{
+		models.EventActionCreated, models.RegistrationActionCreated, models.RegistrationActionCreated,
+		models.EventActionUpdated, models.EventActionCreated, models.EventActionUpdated,
+	}, s.actions())
+}
+
+func testTraces(t *testing.T, s store) {
+	owner := newUser(t, s, "owner@example.com")
+	attendee := newUser(t, s, "attendee@example.com")
+	event := newEvent(t, s, owner, "Go Meetup", func(e *models.Event) { e.Capacity = 1 })
+	background := s.traces()[0]
+	assert.Len(t, background, 32, "a change outside a request starts its own trace")
+
+	// The messages of a request, promotions included, carry its trace
+	traced := tracing.WithID(t.Context(), "4bf92f3577b34da6a3ce929d0e0e4736")
+	_, err := s.Registrations.Register(traced, event.ID, owner.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	_, err = s.Registrations.Register(t.Context(), event.ID, attendee.ID, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	require.NoError(t, s.Registrations.Cancel(traced, owner.ID, event.ID, nil))
+
+	assert.Equal(t, []string{
+		models.EventActionCreated, models.RegistrationActionCreated, models.RegistrationActionCreated,
+		models.RegistrationActionCancelled, models.RegistrationActionPromoted,
+	}, s.actions())
+	traces := s.traces()
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traces[1])
+	assert.NotContains(t, []string{"", background, traces[1]}, traces[2])
+	assert.Equal(t, []string{traces[1], traces[1]}, traces[3:])
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"gorm.io/gorm"
+)
+
+// NewGorm returns the repositories stored in gormDB, a PostgreSQL database
+// migrated by the db package or a SQLite one opened by db.OpenSQLite
+func NewGorm(gormDB *gorm.DB) Repositories {
+	return Repositories{
+		Events:        gormEvents{db: gormDB},
+		Users:         gormUsers{db: gormDB},
+		Registrations: gormRegistrations{db: gormDB},
+	}
+}
+
+// gormEvents implements EventRepository with the models' queries
+type gormEvents struct {
+	db *gorm.DB
+}
+
+func (r gormEvents) Create(ctx context.Context, event *models.Event) error {
+	return event.Save(r.db.WithContext(ctx))
+}
+
+func (r gormEvents) Get(id int64) (*models.Event, error) {
+	return models.GetEventByID(r.db, id)
+}
+
+func (r gormEvents) All() ([]models.Event, error) {
+	return models.GetAllEvents(r.db)
+}
+
+func (r gormEvents) List(query models.EventQuery) (*models.EventPage, error) {
+	return models.ListEvents(r.db, query)
+}
+
+func (r gormEvents) Search(query models.SearchQuery) (*models.SearchPage, error) {
+	return models.SearchEvents(r.db, query)
+}
+
+func (r gormEvents) Nearby(query models.NearbyQuery) ([]models.NearbyEvent, error) {
+	return models.GetNearbyEvents(r.db, query)
+}
+
+func (r gormEvents) Update(ctx context.Context, event *models.Event) error {
+	return event.Update(r.db.WithContext(ctx))
+}
+
+func (r gormEvents) Delete(ctx context.Context, id int64) error {
+	return models.DeleteEvent(r.db.WithContext(ctx), id)
+}
+
+func (r gormEvents) Publish(ctx context.Context, id int64) (*models.Event, error) {
+	event := &models.Event{ID: id}
+	if err := event.Publish(r.db.WithContext(ctx)); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (r gormEvents) Cancel(ctx context.Context, id int64, reason string) (*models.Event, error) {
+	event := &models.Event{ID: id}
+	if err := event.Cancel(r.db.WithContext(ctx), reason); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+func (r gormEvents) CompleteEnded(now time.Time) ([]models.Event, error) {
+	return models.CompleteEndedEvents(r.db, now)
+}
+
+func (r gormEvents) UpdateOccurrence(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error) {
+	return models.Event{ID: id}.UpdateOccurrence(r.db.WithContext(ctx), start, changes)
+}
+
+func (r gormEvents) SplitSeries(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error) {
+	return models.Event{ID: id}.SplitSeries(r.db.WithContext(ctx), start, changes)
+}
+
+func (r gormEvents) CancelOccurrence(ctx context.Context, id int64, start time.Time) error {
+	return models.Event{ID: id}.CancelOccurrence(r.db.WithContext(ctx), start)
+}
+
+func (r gormEvents) TruncateSeries(ctx context.Context, id int64, start time.Time) (bool, error) {
+	return models.Event{ID: id}.TruncateSeries(r.db.WithContext(ctx), start)
+}
+
+func (r gormEvents) Overrides(eventIDs []int64) ([]models.OccurrenceOverride, error) {
+	return models.GetOccurrenceOverrides(r.db, eventIDs)
+}
+
+// gormRegistrations implements RegistrationRepository with the models' queries
+type gormRegistrations struct {
+	db *gorm.DB
+}
+
+func (r gormRegistrations) Register(ctx context.Context, eventID, userID int64, target models.OccurrenceTarget) (*models.Registration, error) {
+	return models.Event{ID: eventID}.Register(r.db.WithContext(ctx), userID, target)
+}
+
+func (r gormRegistrations) Cancel(ctx context.Context, userID, eventID int64, occurrence *time.Time) error {
+	return models.CancelEventRegistration(r.db.WithContext(ctx), userID, eventID, occurrence)
+}
+
+func (r gormRegistrations) WaitlistPosition(registration *models.Registration) (int64, error) {
+	return models.GetWaitlistPosition(r.db, registration)
+}
+
+func (r gormRegistrations) EventsOfUser(userID int64) ([]models.Event, error) {
+	return models.GetRegistrationsByUserID(r.db, userID)
+}
+
+func (r gormRegistrations) ListForEvent(userID, eventID int64) ([]models.Registration, error) {
+	return models.GetUserEventRegistrations(r.db, userID, eventID)
+}
+
+func (r gormRegistrations) ListByUser(userID int64) ([]models.Registration, error) {
+	return models.GetRegistrationDetailsByUserID(r.db, userID)
+}
+
+func (r gormRegistrations) Attendees(query models.AttendeeQuery) (*models.AttendeePage, error) {
+	return models.ListEventAttendees(r.db, query)
+}
+
+func (r gormRegistrations) Remove(ctx context.Context, eventID, registrationID int64) error {
+	return models.RemoveAttendee(r.db.WithContext(ctx), eventID, registrationID)
+}
+
+// gormUsers implements UserRepository with the models' queries
+type gormUsers struct {
+	db *gorm.DB
+}
+
+func (r gormUsers) Create(user *models.User) error {
+	return user.Save(r.db)
+}
+
+func (r gormUsers) GetByEmail(email string) (*models.User, error) {
+	return models.GetUserByEmail(r.db, email)
+}
+
+func (r gormUsers) VerifyCredentials(email, password string) (*models.User, error) {
+	return models.VerifyUserCredentials(r.db, email, password)
+}
+
+func (r gormUsers) SetRole(userID int64, role string) (*models.User, error) {
+	return models.SetUserRole(r.db, userID, role)
+}
+
+func (r gormUsers) PromoteAdmins(emails []string) (int64, error) {
+	return models.PromoteAdmins(r.db, emails)
+}
+
+func (r gormUsers) SetCalendarTokenHash(userID int64, hash string) error {
+	return models.SetCalendarTokenHash(r.db, userID, hash)
+}
+
+func (r gormUsers) GetByCalendarTokenHash(userID int64, hash string) (*models.User, error) {
+	return models.GetUserByCalendarTokenHash(r.db, userID, hash)
+}
@@ -0,0 +1,721 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+)
+
+// Memory is a store kept in memory, for tests and for running without a
+// database. It applies the rules of the models, records the outbox messages
+// the changes queue and is safe for concurrent use. Unlike the databases it
+// does not check that the users and events a row refers to exist.
+type Memory struct {
+	mu            sync.Mutex
+	lastID        int64
+	events        map[int64]models.Event
+	overrides     map[int64]models.OccurrenceOverride
+	registrations map[int64]models.Registration
+	// changes is the status history of the registrations
+	changes  map[int64]models.RegistrationChange
+	users    map[int64]models.User
+	messages []models.OutboxMessage
+}
+
+// NewMemory returns an empty in-memory store
+func NewMemory() *Memory {
+	return &Memory{
+		events:        map[int64]models.Event{},
+		overrides:     map[int64]models.OccurrenceOverride{},
+		registrations: map[int64]models.Registration{},
+		changes:       map[int64]models.RegistrationChange{},
+		users:         map[int64]models.User{},
+	}
+}
+
+// Repositories returns the repositories of the store
+func (m *Memory) Repositories() Repositories {
+	return Repositories{
+		Events:        memoryEvents{m},
+		Users:         memoryUsers{m},
+		Registrations: memoryRegistrations{m},
+	}
+}
+
+// Messages returns the outbox messages queued so far, oldest first
+func (m *Memory) Messages() []models.OutboxMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.messages)
+}
+
+// The helpers below are called with m.mu held
+
+// nextID returns a new row ID, shared by every kind of row
+func (m *Memory) nextID() int64 {
+	m.lastID++
+	return m.lastID
+}
+
+// event returns a copy of the stored event
+func (m *Memory) event(id int64) (models.Event, error) {
+	event, ok := m.events[id]
+	if !ok {
+		return models.Event{}, models.ErrEventNotFound
+	}
+	event.ExDates = slices.Clone(event.ExDates)
+	return event, nil
+}
+
+func (m *Memory) putEvent(event models.Event) {
+	event.ExDates = slices.Clone(event.ExDates)
+	event.OccurrenceStart = nil
+	m.events[event.ID] = event
+}
+
+// allEvents returns copies of the stored events in ID order
+func (m *Memory) allEvents() []models.Event {
+	events := rows(m.events, func(models.Event) bool { return true })
+	for i := range events {
+		events[i].ExDates = slices.Clone(events[i].ExDates)
+	}
+	return events
+}
+
+// rows returns the values of table that keep selects, in ID order
+func rows[T any](table map[int64]T, keep func(T) bool) []T {
+	var selected []T
+	for _, id := range slices.Sorted(maps.Keys(table)) {
+		if keep(table[id]) {
+			selected = append(selected, table[id])
+		}
+	}
+	return selected
+}
+
+// enqueue records an outbox message built by models.NewEventMessage or
+// models.NewRegistrationMessage
+func (m *Memory) enqueue(message models.OutboxMessage, err error) error {
+	if err != nil {
+		return err
+	}
+	message.ID = m.nextID()
+	message.CreatedAt = time.Now().UTC()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// registrationsOf returns the registrations of an event with the given status, in waitlist order
+func (m *Memory) registrationsOf(eventID int64, status string) []models.Registration {
+	registrations := rows(m.registrations, func(r models.Registration) bool {
+		return r.EventID == eventID && r.Status == status
+	})
+	slices.SortStableFunc(registrations, func(a, b models.Registration) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return registrations
+}
+
+// recordStatus appends the current status of registration to its history
+func (m *Memory) recordStatus(registration models.Registration) {
+	id := m.nextID()
+	m.changes[id] = models.RegistrationChange{
+		ID:             id,
+		RegistrationID: registration.ID,
+		Status:         registration.Status,
+		ChangedAt:      time.Now().UTC(),
+	}
+}
+
+// deleteRegistrations removes the registrations that match with their
+// history and returns them in ID order
+func (m *Memory) deleteRegistrations(match func(models.Registration) bool) []models.Registration {
+	deleted := rows(m.registrations, match)
+	for _, registration := range deleted {
+		delete(m.registrations, registration.ID)
+		maps.DeleteFunc(m.changes, func(_ int64, change models.RegistrationChange) bool {
+			return change.RegistrationID == registration.ID
+		})
+	}
+	return deleted
+}
+
+// cancelRegistrations moves the registrations that match and are not
+// cancelled yet to the cancelled status, records it in their history and
+// returns them in ID order
+func (m *Memory) cancelRegistrations(match func(models.Registration) bool) []models.Registration {
+	cancelled := rows(m.registrations, func(registration models.Registration) bool {
+		return registration.Status != models.RegistrationStatusCancelled && match(registration)
+	})
+	for i := range cancelled {
+		cancelled[i].Status = models.RegistrationStatusCancelled
+		cancelled[i].UpdatedAt = time.Now().UTC()
+		m.registrations[cancelled[i].ID] = cancelled[i]
+		m.recordStatus(cancelled[i])
+	}
+	return cancelled
+}
+
+// promote fills the free seats of event from its waitlist
+func (m *Memory) promote(ctx context.Context, event models.Event) error {
+	waitlisted := m.registrationsOf(event.ID, models.RegistrationStatusWaitlisted)
+	if len(waitlisted) == 0 {
+		return nil
+	}
+	confirmed := m.registrationsOf(event.ID, models.RegistrationStatusConfirmed)
+	for _, registration := range event.Promotable(confirmed, waitlisted) {
+		registration.UpdatedAt = time.Now().UTC()
+		m.registrations[registration.ID] = registration
+		m.recordStatus(registration)
+		if err := m.enqueue(models.NewRegistrationMessage(ctx, models.RegistrationActionPromoted, event, registration)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withEditable returns stored with the fields an update writes taken from changes
+func withEditable(stored, changes models.Event) models.Event {
+	stored.Name = changes.Name
+	stored.Description = changes.Description
+	stored.Location = changes.Location
+	stored.DateTime = changes.DateTime
+	stored.UserID = changes.UserID
+	stored.Capacity = changes.Capacity
+	stored.RRule = changes.RRule
+	stored.ExDates = changes.ExDates
+	stored.Latitude = changes.Latitude
+	stored.Longitude = changes.Longitude
+	stored.Address = changes.Address
+	return stored
+}
+
+// memoryEvents implements EventRepository in a Memory
+type memoryEvents struct {
+	*Memory
+}
+
+func (r memoryEvents) Create(ctx context.Context, event *models.Event) error {
+	if err := event.PrepareCreate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event.ID = r.nextID()
+	// Only the editable and initial status fields are written
+	stored := *event
+	stored.CancelReason, stored.CancelledAt, stored.CompletedAt = "", nil, nil
+	r.putEvent(stored)
+	return r.enqueue(models.NewEventMessage(ctx, models.EventActionCreated, *event))
+}
+
+func (r memoryEvents) Get(id int64) (*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r memoryEvents) All() ([]models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.allEvents(), nil
+}
+
+func (r memoryEvents) List(query models.EventQuery) (*models.EventPage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	overrides := rows(r.overrides, func(models.OccurrenceOverride) bool { return true })
+	return models.ListEventsIn(r.allEvents(), overrides, query)
+}
+
+func (r memoryEvents) Search(query models.SearchQuery) (*models.SearchPage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return models.SearchEventsIn(r.allEvents(), query)
+}
+
+func (r memoryEvents) Nearby(query models.NearbyQuery) ([]models.NearbyEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return models.NearbyEventsIn(r.allEvents(), query)
+}
+
+func (r memoryEvents) Update(ctx context.Context, event *models.Event) error {
+	if err := event.NormalizeRecurrence(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, err := r.event(event.ID)
+	if err != nil {
+		return err
+	}
+	stored = withEditable(stored, *event)
+	r.putEvent(stored)
+	if err := r.promote(ctx, stored); err != nil {
+		return err
+	}
+	return r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, stored))
+}
+
+func (r memoryEvents) Delete(ctx context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return err
+	}
+	delete(r.events, id)
+	r.deleteRegistrations(func(registration models.Registration) bool { return registration.EventID == id })
+	maps.DeleteFunc(r.overrides, func(_ int64, override models.OccurrenceOverride) bool { return override.EventID == id })
+	return r.enqueue(models.NewEventMessage(ctx, models.EventActionDeleted, event))
+}
+
+func (r memoryEvents) Publish(ctx context.Context, id int64) (*models.Event, error) {
+	return r.transition(ctx, id, models.EventStatusPublished, "")
+}
+
+func (r memoryEvents) Cancel(ctx context.Context, id int64, reason string) (*models.Event, error) {
+	return r.transition(ctx, id, models.EventStatusCancelled, reason)
+}
+
+// transition moves an event to status and queues the message whose action is the new status
+func (r memoryEvents) transition(ctx context.Context, id int64, status, reason string) (*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := event.Transition(status, reason, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	r.putEvent(changed)
+	if err := r.enqueue(models.NewEventMessage(ctx, status, changed)); err != nil {
+		return nil, err
+	}
+	return &changed, nil
+}
+
+func (r memoryEvents) CompleteEnded(now time.Time) ([]models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var completed []models.Event
+	for _, event := range r.allEvents() {
+		if event.Status != models.EventStatusPublished || !event.DateTime.Before(now) || !event.EndedBy(now) {
+			continue
+		}
+		event.Status = models.EventStatusCompleted
+		event.CompletedAt = &now
+		r.putEvent(event)
+		if err := r.enqueue(models.NewEventMessage(context.Background(), models.EventActionCompleted, event)); err != nil {
+			return completed, err
+		}
+		completed = append(completed, event)
+	}
+	return completed, nil
+}
+
+func (r memoryEvents) UpdateOccurrence(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := event.OccurrenceIndex(start); err != nil {
+		return nil, err
+	}
+	maps.DeleteFunc(r.overrides, func(_ int64, override models.OccurrenceOverride) bool {
+		return override.EventID == id && override.OccurrenceStart.Equal(start)
+	})
+	override := event.NewOverride(start, changes)
+	override.ID = r.nextID()
+	r.overrides[override.ID] = override
+
+	updated := event.Occurrence(start, []models.OccurrenceOverride{override})
+	if err := r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, updated)); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (r memoryEvents) SplitSeries(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	original, err := r.event(id)
+	if err != nil {
+		return nil, err
+	}
+	split, err := original.Split(start, changes)
+	if err != nil {
+		return nil, err
+	}
+	created := split.Following
+
+	if split.InPlace {
+		stored := withEditable(original, created)
+		r.putEvent(stored)
+		if err := r.promote(ctx, stored); err != nil {
+			return nil, err
+		}
+		if err := r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, stored)); err != nil {
+			return nil, err
+		}
+		return &created, nil
+	}
+
+	created.ID = r.nextID()
+	split.Following.ID = created.ID
+	r.putEvent(created)
+	r.putEvent(split.Original)
+	for _, override := range rows(r.overrides, func(o models.OccurrenceOverride) bool { return o.EventID == id }) {
+		if moved := split.MoveOverride(override); moved != nil {
+			r.overrides[override.ID] = *moved
+		}
+	}
+	for _, registration := range rows(r.registrations, func(registration models.Registration) bool { return registration.EventID == id }) {
+		moved, copied := split.MoveRegistration(registration)
+		if moved != nil {
+			moved.UpdatedAt = time.Now().UTC()
+			r.registrations[moved.ID] = *moved
+		}
+		if copied != nil {
+			copied.ID = r.nextID()
+			copied.UpdatedAt = time.Now().UTC()
+			r.registrations[copied.ID] = *copied
+			r.recordStatus(*copied)
+		}
+	}
+	if err := r.promote(ctx, created); err != nil {
+		return nil, err
+	}
+	if err := r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, split.Original)); err != nil {
+		return nil, err
+	}
+	if err := r.enqueue(models.NewEventMessage(ctx, models.EventActionCreated, created)); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (r memoryEvents) CancelOccurrence(ctx context.Context, id int64, start time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return err
+	}
+	if _, err := event.OccurrenceIndex(start); err != nil {
+		return err
+	}
+	event.ExDates = append(event.ExDates, start)
+	r.putEvent(event)
+	maps.DeleteFunc(r.overrides, func(_ int64, override models.OccurrenceOverride) bool {
+		return override.EventID == id && override.OccurrenceStart.Equal(start)
+	})
+	r.deleteRegistrations(func(registration models.Registration) bool {
+		return registration.EventID == id && registration.Scope == models.ScopeOccurrence &&
+			registration.OccurrenceStart != nil && registration.OccurrenceStart.Equal(start)
+	})
+	return r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, event))
+}
+
+func (r memoryEvents) TruncateSeries(ctx context.Context, id int64, start time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(id)
+	if err != nil {
+		return false, err
+	}
+	ended, first, err := event.EndBefore(start)
+	if err != nil || first {
+		return first, err
+	}
+	r.putEvent(ended)
+	maps.DeleteFunc(r.overrides, func(_ int64, override models.OccurrenceOverride) bool {
+		return override.EventID == id && !override.OccurrenceStart.Before(start)
+	})
+	r.deleteRegistrations(func(registration models.Registration) bool {
+		return registration.EventID == id && registration.OccurrenceStart != nil && !registration.OccurrenceStart.Before(start)
+	})
+	return false, r.enqueue(models.NewEventMessage(ctx, models.EventActionUpdated, ended))
+}
+
+func (r memoryEvents) Overrides(eventIDs []int64) ([]models.OccurrenceOverride, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	overrides := rows(r.overrides, func(override models.OccurrenceOverride) bool {
+		return slices.Contains(eventIDs, override.EventID)
+	})
+	slices.SortStableFunc(overrides, func(a, b models.OccurrenceOverride) int {
+		return a.OccurrenceStart.Compare(b.OccurrenceStart)
+	})
+	return overrides, nil
+}
+
+// memoryRegistrations implements RegistrationRepository in a Memory
+type memoryRegistrations struct {
+	*Memory
+}
+
+func (r memoryRegistrations) Register(ctx context.Context, eventID, userID int64, target models.OccurrenceTarget) (*models.Registration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(eventID)
+	if err != nil {
+		return nil, err
+	}
+	confirmed := r.registrationsOf(event.ID, models.RegistrationStatusConfirmed)
+	registration, err := event.NewRegistration(userID, target, confirmed)
+	if err != nil {
+		return nil, err
+	}
+	existing := rows(r.registrations, func(registration models.Registration) bool {
+		return registration.EventID == eventID && registration.UserID == userID
+	})
+	if registration.Duplicates(existing) {
+		return nil, models.ErrAlreadyRegistered
+	}
+	if previous := registration.Reactivates(existing); previous != nil {
+		registration.ID = previous.ID
+	} else {
+		registration.ID = r.nextID()
+	}
+	registration.CreatedAt = time.Now().UTC()
+	registration.UpdatedAt = registration.CreatedAt
+	r.registrations[registration.ID] = registration
+	r.recordStatus(registration)
+	if err := r.enqueue(models.NewRegistrationMessage(ctx, models.RegistrationActionCreated, event, registration)); err != nil {
+		return nil, err
+	}
+	return &registration, nil
+}
+
+func (r memoryRegistrations) Cancel(ctx context.Context, userID, eventID int64, occurrence *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(eventID)
+	if err != nil {
+		return err
+	}
+	cancelled := r.cancelRegistrations(func(registration models.Registration) bool {
+		if registration.UserID != userID || registration.EventID != eventID {
+			return false
+		}
+		if occurrence == nil {
+			return registration.OccurrenceStart == nil
+		}
+		return registration.OccurrenceStart != nil && registration.OccurrenceStart.Equal(*occurrence)
+	})
+	if len(cancelled) == 0 {
+		return models.ErrRegistrationNotFound
+	}
+	for _, registration := range cancelled {
+		if err := r.enqueue(models.NewRegistrationMessage(ctx, models.RegistrationActionCancelled, event, registration)); err != nil {
+			return err
+		}
+	}
+	return r.promote(ctx, event)
+}
+
+func (r memoryRegistrations) WaitlistPosition(registration *models.Registration) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	position := int64(1)
+	for _, other := range r.registrationsOf(registration.EventID, models.RegistrationStatusWaitlisted) {
+		if other.CreatedAt.Before(registration.CreatedAt) ||
+			(other.CreatedAt.Equal(registration.CreatedAt) && other.ID < registration.ID) {
+			position++
+		}
+	}
+	return position, nil
+}
+
+func (r memoryRegistrations) EventsOfUser(userID int64) ([]models.Event, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registered := map[int64]bool{}
+	for _, registration := range r.registrations {
+		if registration.UserID == userID && registration.Status != models.RegistrationStatusCancelled {
+			registered[registration.EventID] = true
+		}
+	}
+	events := []models.Event{}
+	for _, event := range r.allEvents() {
+		if registered[event.ID] {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (r memoryRegistrations) ListForEvent(userID, eventID int64) ([]models.Registration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registrations := rows(r.registrations, func(registration models.Registration) bool {
+		return registration.UserID == userID && registration.EventID == eventID
+	})
+	slices.SortStableFunc(registrations, func(a, b models.Registration) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	for i := range registrations {
+		registrations[i].History = rows(r.changes, func(change models.RegistrationChange) bool {
+			return change.RegistrationID == registrations[i].ID
+		})
+	}
+	return registrations, nil
+}
+
+func (r memoryRegistrations) ListByUser(userID int64) ([]models.Registration, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registrations := rows(r.registrations, func(registration models.Registration) bool {
+		return registration.UserID == userID && registration.Status != models.RegistrationStatusCancelled
+	})
+	slices.SortStableFunc(registrations, func(a, b models.Registration) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	for i := range registrations {
+		registrations[i].Event, _ = r.event(registrations[i].EventID)
+	}
+	return registrations, nil
+}
+
+func (r memoryRegistrations) Attendees(query models.AttendeeQuery) (*models.AttendeePage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	registrations := rows(r.registrations, func(registration models.Registration) bool {
+		return registration.EventID == query.EventID
+	})
+	for i := range registrations {
+		registrations[i].User = r.users[registrations[i].UserID]
+	}
+	return models.ListEventAttendeesIn(registrations, query)
+}
+
+func (r memoryRegistrations) Remove(ctx context.Context, eventID, registrationID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, err := r.event(eventID)
+	if err != nil {
+		return err
+	}
+	removed := r.cancelRegistrations(func(registration models.Registration) bool {
+		return registration.ID == registrationID && registration.EventID == eventID
+	})
+	if len(removed) == 0 {
+		return models.ErrRegistrationNotFound
+	}
+	if err := r.enqueue(models.NewRegistrationMessage(ctx, models.RegistrationActionRemoved, event, removed[0])); err != nil {
+		return err
+	}
+	return r.promote(ctx, event)
+}
+
+// memoryUsers implements UserRepository in a Memory
+type memoryUsers struct {
+	*Memory
+}
+
+// user returns the stored user selected by keep, or nil
+func (r memoryUsers) user(keep func(models.User) bool) *models.User {
+	users := rows(r.users, keep)
+	if len(users) == 0 {
+		return nil
+	}
+	return &users[0]
+}
+
+func (r memoryUsers) Create(user *models.User) error {
+	if err := user.PrepareCreate(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.user(func(u models.User) bool { return u.Email == user.Email }) != nil {
+		return models.ErrEmailTaken
+	}
+	user.ID = r.nextID()
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r memoryUsers) GetByEmail(email string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.user(func(u models.User) bool { return u.Email == email }), nil
+}
+
+func (r memoryUsers) VerifyCredentials(email, password string) (*models.User, error) {
+	user, err := r.GetByEmail(email)
+	if err != nil || user == nil {
+		return nil, err
+	}
+	if err := security.CheckPasswordHash(password, user.Password); err != nil {
+		return nil, nil // Invalid password
+	}
+	return user, nil
+}
+
+func (r memoryUsers) SetRole(userID int64, role string) (*models.User, error) {
+	if !policy.ValidRole(role) {
+		return nil, models.ErrInvalidRole
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, models.ErrUserNotFound
+	}
+	user.Role = role
+	r.users[userID] = user
+	user.Password = ""
+	return &user, nil
+}
+
+func (r memoryUsers) PromoteAdmins(emails []string) (int64, error) {
+	lowered := make([]string, len(emails))
+	for i, email := range emails {
+		lowered[i] = strings.ToLower(email)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var promoted int64
+	for id, user := range r.users {
+		if user.Role != policy.RoleAdmin && slices.Contains(lowered, strings.ToLower(user.Email)) {
+			user.Role = policy.RoleAdmin
+			r.users[id] = user
+			promoted++
+		}
+	}
+	return promoted, nil
+}
+
+func (r memoryUsers) SetCalendarTokenHash(userID int64, hash string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok {
+		return models.ErrUserNotFound
+	}
+	user.CalendarTokenHash = hash
+	r.users[userID] = user
+	return nil
+}
+
+func (r memoryUsers) GetByCalendarTokenHash(userID int64, hash string) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	user, ok := r.users[userID]
+	if !ok || user.CalendarTokenHash == "" || user.CalendarTokenHash != hash {
+		return nil, nil // User not found or token mismatch
+	}
+	return &user, nil
+}
@@ -0,0 +1,99 @@
+// Package repository provides the storage of events, users and registrations
+// behind interfaces, so the services can be used with PostgreSQL, SQLite or
+// an in-memory store. Every implementation passes the same conformance tests.
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+)
+
+// EventRepository stores events and the overrides of their occurrences.
+// Every change queues its message to the outbox in the same transaction, in
+// the trace of the request ctx carries (see package tracing).
+// Methods taking an event ID return models.ErrEventNotFound when no event has it.
+type EventRepository interface {
+	// Create stores a new event, setting its ID. Events without a status start as drafts.
+	Create(ctx context.Context, event *models.Event) error
+	Get(id int64) (*models.Event, error)
+	All() ([]models.Event, error)
+	List(query models.EventQuery) (*models.EventPage, error)
+	Search(query models.SearchQuery) (*models.SearchPage, error)
+	Nearby(query models.NearbyQuery) ([]models.NearbyEvent, error)
+	// Update writes the editable fields of event, promoting waitlisted users
+	// into any seats a higher capacity frees
+	Update(ctx context.Context, event *models.Event) error
+	// Delete removes an event with its registrations and overrides
+	Delete(ctx context.Context, id int64) error
+	Publish(ctx context.Context, id int64) (*models.Event, error)
+	Cancel(ctx context.Context, id int64, reason string) (*models.Event, error)
+	// CompleteEnded completes the published events whose last occurrence
+	// started before now and returns them
+	CompleteEnded(now time.Time) ([]models.Event, error)
+	UpdateOccurrence(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error)
+	SplitSeries(ctx context.Context, id int64, start time.Time, changes models.Event) (*models.Event, error)
+	CancelOccurrence(ctx context.Context, id int64, start time.Time) error
+	// TruncateSeries ends a series before the occurrence starting at start.
+	// It reports deleted=true, changing nothing, when that is the first occurrence.
+	TruncateSeries(ctx context.Context, id int64, start time.Time) (deleted bool, err error)
+	Overrides(eventIDs []int64) ([]models.OccurrenceOverride, error)
+}
+
+// RegistrationRepository stores the registrations of users for events,
+// waitlisting them when the event is full, with the history of their
+// statuses. Changes are queued to the outbox like those of events.
+type RegistrationRepository interface {
+	// Register returns models.ErrAlreadyRegistered when the user's
+	// registrations for the event already cover a selected occurrence
+	Register(ctx context.Context, eventID, userID int64, target models.OccurrenceTarget) (*models.Registration, error)
+	// Cancel removes the registration of a user for an event, or for the
+	// occurrence starting at occurrence, and promotes waitlisted users into
+	// the seats it frees. It returns models.ErrRegistrationNotFound when the
+	// user has no such registration.
+	Cancel(ctx context.Context, userID, eventID int64, occurrence *time.Time) error
+	// WaitlistPosition returns the 1-based position of a waitlisted registration
+	WaitlistPosition(registration *models.Registration) (int64, error)
+	// EventsOfUser returns the events a user is registered for, each once, in ID order
+	EventsOfUser(userID int64) ([]models.Event, error)
+	// ListForEvent returns the registrations of a user for an event with
+	// their history, oldest first
+	ListForEvent(userID, eventID int64) ([]models.Registration, error)
+	// ListByUser returns the registrations of a user with their events, oldest first
+	ListByUser(userID int64) ([]models.Registration, error)
+	// Attendees returns a page of the registrations of an event with the
+	// emails of their users, in the order they were made
+	Attendees(query models.AttendeeQuery) (*models.AttendeePage, error)
+	// Remove removes a registration for an event, promoting waitlisted users
+	// into the seats it frees. It returns models.ErrRegistrationNotFound when
+	// the event has no such registration.
+	Remove(ctx context.Context, eventID, registrationID int64) error
+}
+
+// UserRepository stores users
+type UserRepository interface {
+	// Create stores a new user with its password hashed, or returns
+	// models.ErrEmailTaken
+	Create(user *models.User) error
+	// GetByEmail returns nil without an error when no user has the email
+	GetByEmail(email string) (*models.User, error)
+	// VerifyCredentials returns nil without an error when the email or
+	// password does not match
+	VerifyCredentials(email, password string) (*models.User, error)
+	// SetRole returns the changed user without its password hash
+	SetRole(userID int64, role string) (*models.User, error)
+	// PromoteAdmins gives the admin role to the users with one of the emails,
+	// ignoring case, and returns how many were promoted
+	PromoteAdmins(emails []string) (int64, error)
+	SetCalendarTokenHash(userID int64, hash string) error
+	// GetByCalendarTokenHash returns nil without an error when the hash does not match
+	GetByCalendarTokenHash(userID int64, hash string) (*models.User, error)
+}
+
+// Repositories bundles the repositories of one store
+type Repositories struct {
+	Events        EventRepository
+	Users         UserRepository
+	Registrations RegistrationRepository
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	// Hashing at the production cost would make every test take seconds
+	auth := config.Default().Auth
+	auth.BcryptCost = bcrypt.MinCost
+	auth.EphemeralSigningKey = true
+	if err := security.Configure(auth); err != nil {
+		panic(err)
+	}
+	m.Run()
+}
+
+func TestMemory(t *testing.T) {
+	runConformance(t, func(t *testing.T) store {
+		memory := NewMemory()
+		return store{
+			Repositories: memory.Repositories(),
+			actions: func() []string {
+				var actions []string
+				for _, message := range memory.Messages() {
+					actions = append(actions, message.Action)
+				}
+				return actions
+			},
+			traces: func() []string {
+				var traces []string
+				for _, message := range memory.Messages() {
+					traces = append(traces, message.TraceID)
+				}
+				return traces
+			},
+		}
+	})
+}
+
+func TestSQLite(t *testing.T) {
+	runConformance(t, func(t *testing.T) store {
+		gormDB, err := db.OpenSQLite(filepath.Join(t.TempDir(), "events.db"))
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			sqlDB, _ := gormDB.DB()
+			_ = sqlDB.Close()
+		})
+		return gormStore(t, gormDB)
+	})
+}
+
+// TestPostgres runs the conformance tests against the configured database
+// (see config.Load), each in a schema of its own that is dropped afterwards.
+// It is skipped when the database cannot be reached.
+func TestPostgres(t *testing.T) {
+	cfg, err := config.Load(nil)
+	require.NoError(t, err)
+	admin, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
+	if err != nil {
+		t.Skipf("PostgreSQL is not available: %v", err)
+	}
+	adminDB, err := admin.DB()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = adminDB.Close() })
+
+	runConformance(t, func(t *testing.T) store {
+		schema := "conformance_" + strings.ToLower(rand.Text())
+		require.NoError(t, admin.Exec("CREATE SCHEMA "+schema).Error)
+		t.Cleanup(func() { _ = admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error })
+
+		gormDB, err := gorm.Open(postgres.Open(cfg.Database.DSN()+" search_path="+schema), &gorm.Config{TranslateError: true})
+		require.NoError(t, err)
+		sqlDB, err := gormDB.DB()
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = sqlDB.Close() })
+		migrator, err := db.NewMigrator(sqlDB)
+		require.NoError(t, err)
+		_, err = migrator.Up(context.Background())
+		require.NoError(t, err)
+		return gormStore(t, gormDB)
+	})
+}
+
+// gormStore returns the repositories of gormDB, reading the actions and traces from its outbox table
+func gormStore(t *testing.T, gormDB *gorm.DB) store {
+	return store{
+		Repositories: NewGorm(gormDB),
+		actions: func() []string {
+			var actions []string
+			require.NoError(t, gormDB.Model(&models.OutboxMessage{}).Order("id").Pluck("action", &actions).Error)
+			return actions
+		},
+		traces: func() []string {
+			var traces []string
+			require.NoError(t, gormDB.Model(&models.OutboxMessage{}).Order("id").Pluck("trace_id", &traces).Error)
+			return traces
+		},
+	}
+}
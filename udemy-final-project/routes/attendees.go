@@ -0,0 +1,142 @@
+package routes
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+)
+
+// csvContentType is the media type of the attendee export
+const csvContentType = "text/csv; charset=utf-8"
+
+// getEventAttendees godoc
+// @Summary List the attendees of an event
+// @Description List the registrations for an event in the order they were made, with the email of each attendee (requires authentication and ownership, or the admin role). With format=csv every attendee, or every attendee with the status, is exported as a CSV file instead of a page.
+// @Tags registrations
+// @Produce json
+// @Produce text/csv
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param status query string false "Only confirmed or only waitlisted registrations" Enums(confirmed, waitlisted)
+// @Param page_size query int false "Attendees per page (default 20, max 100)"
+// @Param page_token query string false "next_page_token from the previous page"
+// @Param format query string false "csv to export every attendee" Enums(json, csv)
+// @Success 200 {object} models.AttendeePage
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/registrations [get]
+// @Security BearerAuth
+func getEventAttendees(c *gin.Context) {
+	id := c.Param("id")
+	if !requireEventOwner(c, id, policy.ActionManageAttendees, "view the attendees of") {
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+	case "csv":
+		attendees, err := eventService.ExportEventAttendees(id, c.Query("status"))
+		if err != nil {
+			problem.Error(c, err)
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%s-attendees.csv"`, id))
+		c.Header("Content-Type", csvContentType)
+		c.Status(http.StatusOK)
+		if err := writeAttendeesCSV(c.Writer, attendees); err != nil {
+			_ = c.Error(err)
+		}
+		return
+	default:
+		problem.Write(c, http.StatusBadRequest, "format must be json or csv")
+		return
+	}
+
+	query := models.AttendeeQuery{Status: c.Query("status"), PageToken: c.Query("page_token")}
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "page_size must be an integer")
+			return
+		}
+		query.PageSize = pageSize
+	}
+	page, err := eventService.ListEventAttendees(id, query)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// removeAttendee godoc
+// @Summary Remove an attendee from an event
+// @Description Remove a registration for an event (requires authentication and ownership, or the admin role). The first waitlisted users are promoted into the freed seats and the removal is published with the registration_removed action.
+// @Tags registrations
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param registrationId path int true "Registration ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/registrations/{registrationId} [delete]
+// @Security BearerAuth
+func removeAttendee(c *gin.Context) {
+	id := c.Param("id")
+	if !requireEventOwner(c, id, policy.ActionManageAttendees, "remove the attendees of") {
+		return
+	}
+
+	if err := eventService.RemoveAttendee(c.Request.Context(), id, c.Param("registrationId")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Attendee removed from the event"})
+}
+
+// writeAttendeesCSV writes attendees as CSV with a header row
+func writeAttendeesCSV(w io.Writer, attendees []models.Attendee) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{
+		"registration_id", "user_id", "email", "status", "scope", "occurrence_start", "registered_at", "updated_at",
+	})
+	for _, attendee := range attendees {
+		occurrence := ""
+		if attendee.OccurrenceStart != nil {
+			occurrence = attendee.OccurrenceStart.UTC().Format(time.RFC3339)
+		}
+		_ = writer.Write([]string{
+			strconv.FormatInt(attendee.RegistrationID, 10),
+			strconv.FormatInt(attendee.UserID, 10),
+			csvCell(attendee.Email),
+			attendee.Status,
+			attendee.Scope,
+			occurrence,
+			attendee.RegisteredAt.UTC().Format(time.RFC3339),
+			attendee.UpdatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvCell keeps spreadsheets from evaluating a user-supplied value as a formula
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
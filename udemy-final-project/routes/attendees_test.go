@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetEventAttendees_CSV(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.user(t, "owner@example.com")
+	id := s.event(t, owner, 1)
+	confirmed := s.register(t, s.user(t, "=HYPERLINK(\"http://evil\")@example.com"), id)
+	second := s.user(t, "second@example.com")
+	waitlisted := s.register(t, second, id)
+
+	resp := s.do(http.MethodGet, "/events/"+id+"/registrations?format=csv", owner, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	assert.Equal(t, csvContentType, resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="event-`+id+`-attendees.csv"`, resp.Header().Get("Content-Disposition"))
+	rows, err := csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{
+		"registration_id", "user_id", "email", "status", "scope", "occurrence_start", "registered_at", "updated_at",
+	}, rows[0])
+	assert.Equal(t, strconv.FormatInt(confirmed.ID, 10), rows[1][0])
+	assert.Equal(t, strconv.FormatInt(confirmed.UserID, 10), rows[1][1])
+	assert.Equal(t, "'=HYPERLINK(\"http://evil\")@example.com", rows[1][2], "formulas are escaped")
+	assert.Equal(t, models.RegistrationStatusConfirmed, rows[1][3])
+	assert.Equal(t, "second@example.com", rows[2][2])
+	assert.Equal(t, models.RegistrationStatusWaitlisted, rows[2][3])
+
+	// The status filter applies to the export as well
+	resp = s.do(http.MethodGet, "/events/"+id+"/registrations?format=csv&status=waitlisted", owner, "")
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	rows, err = csv.NewReader(resp.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, strconv.FormatInt(waitlisted.ID, 10), rows[1][0])
+
+	resp = s.do(http.MethodGet, "/events/"+id+"/registrations?format=csv&status=cancelled", owner, "")
+	requireProblem(t, resp, http.StatusBadRequest)
+	resp = s.do(http.MethodGet, "/events/"+id+"/registrations?format=csv", second, "")
+	requireProblem(t, resp, http.StatusForbidden)
+}
+
+func TestCSVCell(t *testing.T) {
+	for value, want := range map[string]string{
+		"":                 "",
+		"ada@example.com":  "ada@example.com",
+		"=1+1@example.com": "'=1+1@example.com",
+		"+1@example.com":   "'+1@example.com",
+		"-1@example.com":   "'-1@example.com",
+		"@sum@example.com": "'@sum@example.com",
+		"\t=1@example.com": "'\t=1@example.com",
+		"\r=1@example.com": "'\r=1@example.com",
+		"a=1@example.com":  "a=1@example.com",
+	} {
+		assert.Equal(t, want, csvCell(value), value)
+	}
+}
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/ical"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+)
+
+// getEventICS godoc
+// @Summary Export event as iCalendar
+// @Description Download a single event as an iCalendar (.ics) file. Recurring events include their RRULE, EXDATEs and per-occurrence overrides. Cancelled events are exported with STATUS:CANCELLED; drafts are only available to their owner.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "Event ID"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/ics [get]
+func getEventICS(c *gin.Context) {
+	id := c.Param("id")
+	calendar, err := calendarService.EventCalendar(id, c.GetInt64("userId"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%s.ics"`, id))
+	c.Data(http.StatusOK, ical.ContentType, []byte(calendar))
+}
+
+// getUserCalendarFeed godoc
+// @Summary User calendar subscription feed
+// @Description iCalendar feed of every event the user is registered for, authenticated by the feed token instead of a JWT so calendar apps can subscribe to it. Waitlisted registrations are marked TENTATIVE.
+// @Tags calendar
+// @Produce text/calendar
+// @Param id path int true "User ID"
+// @Param token query string true "Calendar feed token"
+// @Success 200 {string} string "iCalendar document"
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /users/{id}/calendar.ics [get]
+func getUserCalendarFeed(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	calendar, err := calendarService.UserCalendar(userID, c.Query("token"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Data(http.StatusOK, ical.ContentType, []byte(calendar))
+}
+
+// rotateCalendarToken godoc
+// @Summary Rotate calendar feed token
+// @Description Issue a new calendar feed token for the authenticated user. Any previously issued feed URL stops working immediately.
+// @Tags calendar
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /users/{id}/calendar-token [post]
+// @Security BearerAuth
+func rotateCalendarToken(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorize(c, policy.ActionManageCalendar, userID, "You can only manage your own calendar feed") {
+		return
+	}
+
+	token, err := calendarService.RotateCalendarToken(userID)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	feedURL := url.URL{
+		Scheme:   "http",
+		Host:     c.Request.Host,
+		Path:     fmt.Sprintf("/users/%d/calendar.ics", userID),
+		RawQuery: url.Values{"token": {token}}.Encode(),
+	}
+	if c.Request.TLS != nil {
+		feedURL.Scheme = "https"
+	}
+	webcalURL := feedURL
+	webcalURL.Scheme = "webcal"
+
+	c.JSON(http.StatusOK, gin.H{
+		"token":      token,
+		"feed_url":   feedURL.String(),
+		"webcal_url": webcalURL.String(),
+	})
+}
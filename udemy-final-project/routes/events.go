@@ -2,25 +2,181 @@
 package routes
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
 )
 
+// occurrenceTarget reads the optional occurrence and scope query parameters
+// selecting part of a recurring event
+func occurrenceTarget(c *gin.Context) (models.OccurrenceTarget, error) {
+	var start *time.Time
+	if value := c.Query("occurrence"); value != "" {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return models.OccurrenceTarget{}, errors.New("occurrence must be an RFC 3339 timestamp")
+		}
+		start = &t
+	}
+	return models.NewOccurrenceTarget(start, c.Query("scope"))
+}
+
+// eventQuery reads the listing filters, sort order and page parameters
+func eventQuery(c *gin.Context) (models.EventQuery, error) {
+	query := models.EventQuery{
+		Location:  c.Query("location"),
+		Status:    c.Query("status"),
+		SortBy:    c.Query("sort"),
+		PageToken: c.Query("page_token"),
+		ViewerID:  c.GetInt64("userId"),
+	}
+
+	fromParam, toParam := c.Query("from"), c.Query("to")
+	if fromParam != "" || toParam != "" {
+		from, fromErr := time.Parse(time.RFC3339, fromParam)
+		to, toErr := time.Parse(time.RFC3339, toParam)
+		if fromErr != nil || toErr != nil {
+			return query, errors.New("from and to must both be RFC 3339 timestamps")
+		}
+		query.From, query.To = from, to
+	}
+	if value := c.Query("user_id"); value != "" {
+		userID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return query, errors.New("user_id must be an integer")
+		}
+		query.UserID = userID
+	}
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		query.Descending = true
+	default:
+		return query, errors.New("order must be asc or desc")
+	}
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			return query, errors.New("page_size must be an integer")
+		}
+		query.PageSize = pageSize
+	}
+	return query, nil
+}
+
 // getEvents godoc
-// @Summary Get all events
-// @Description Retrieve a list of all events
+// @Summary List events
+// @Description Retrieve a page of events, optionally filtered by location, owner and status. Drafts are only listed for their owner, who must send a bearer token. When both from and to are given, recurring events are expanded into their occurrences within that window (at most 366 days) and only occurrences in the window are returned. Pass next_page_token back as page_token, with the same filters and sort order, to fetch the following page.
 // @Tags events
 // @Produce json
-// @Success 200 {array} models.Event
-// @Failure 500 {object} map[string]string
+// @Param from query string false "Window start (RFC 3339)"
+// @Param to query string false "Window end, exclusive (RFC 3339)"
+// @Param location query string false "Case-insensitive location substring"
+// @Param user_id query int false "Owner user ID"
+// @Param status query string false "Lifecycle status" Enums(draft, published, cancelled, completed)
+// @Param sort query string false "Sort field" Enums(date_time, name) default(date_time)
+// @Param order query string false "Sort direction" Enums(asc, desc) default(asc)
+// @Param page_size query int false "Events per page (default 20, max 100)"
+// @Param page_token query string false "next_page_token from the previous page"
+// @Param Authorization header string false "Bearer token, to include your own drafts"
+// @Success 200 {object} models.EventPage
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events [get]
 func getEvents(c *gin.Context) {
-	events, err := eventService.GetAllEvents()
+	query, err := eventQuery(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	page, err := eventService.ListEvents(query)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// searchEvents godoc
+// @Summary Search events
+// @Description Full-text search over event name, location and description, most relevant first. Every word must match; words are stemmed, so "meetups" also finds "meetup". Matching fields are returned HTML-escaped with matches wrapped in <mark> tags. Drafts are only found by their owner.
+// @Tags events
+// @Produce json
+// @Param q query string true "Search text"
+// @Param page_size query int false "Results per page (default 20, max 100)"
+// @Param page_token query string false "next_page_token from the previous page"
+// @Param Authorization header string false "Bearer token, to include your own drafts"
+// @Success 200 {object} models.SearchPage
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/search [get]
+func searchEvents(c *gin.Context) {
+	query := models.SearchQuery{
+		Text:      c.Query("q"),
+		PageToken: c.Query("page_token"),
+		ViewerID:  c.GetInt64("userId"),
+	}
+	if value := c.Query("page_size"); value != "" {
+		pageSize, err := strconv.Atoi(value)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "page_size must be an integer")
+			return
+		}
+		query.PageSize = pageSize
+	}
+
+	page, err := eventService.SearchEvents(query)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// getNearbyEvents godoc
+// @Summary Find events near a location
+// @Description Retrieve events whose venue coordinates lie within radius_km of a point, nearest first. Events without coordinates are never returned, and drafts only to their owner.
+// @Tags events
+// @Produce json
+// @Param lat query number true "Latitude (-90 to 90)"
+// @Param lng query number true "Longitude (-180 to 180)"
+// @Param radius_km query number true "Search radius in kilometres (at most 500)"
+// @Param page_size query int false "Maximum number of events (default 20, max 100)"
+// @Param Authorization header string false "Bearer token, to include your own drafts"
+// @Success 200 {array} models.NearbyEvent
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/nearby [get]
+func getNearbyEvents(c *gin.Context) {
+	query := models.NearbyQuery{ViewerID: c.GetInt64("userId")}
+	var err error
+	for param, target := range map[string]*float64{
+		"lat":       &query.Latitude,
+		"lng":       &query.Longitude,
+		"radius_km": &query.RadiusKm,
+	} {
+		if *target, err = strconv.ParseFloat(c.Query(param), 64); err != nil {
+			problem.Write(c, http.StatusBadRequest, param+" must be a number")
+			return
+		}
+	}
+	if value := c.Query("page_size"); value != "" {
+		if query.PageSize, err = strconv.Atoi(value); err != nil {
+			problem.Write(c, http.StatusBadRequest, "page_size must be an integer")
+			return
+		}
+	}
+
+	events, err := eventService.GetNearbyEvents(query)
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, events)
@@ -28,18 +184,23 @@ func getEvents(c *gin.Context) {
 
 // getEventByID godoc
 // @Summary Get event by ID
-// @Description Retrieve a specific event by its ID
+// @Description Retrieve a specific event by its ID. Drafts are only returned to their owner.
 // @Tags events
 // @Produce json
 // @Param id path int true "Event ID"
+// @Param Authorization header string false "Bearer token, to view your own drafts"
 // @Success 200 {object} models.Event
-// @Failure 500 {object} map[string]string
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events/{id} [get]
 func getEventByID(c *gin.Context) {
 	id := c.Param("id")
 	event, err := eventService.GetEventByID(id)
+	if err == nil && !event.VisibleTo(c.GetInt64("userId")) {
+		err = models.ErrEventNotFound
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, event)
@@ -47,24 +208,36 @@ func getEventByID(c *gin.Context) {
 
 // createEvent godoc
 // @Summary Create a new event
-// @Description Create a new event (requires authentication)
+// @Description Create a new event (requires authentication). Events start as drafts, visible only to their owner, unless status is published.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param event body models.CreateEventRequest true "Event data"
 // @Success 201 {object} models.Event
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events [post]
 // @Security BearerAuth
 func createEvent(c *gin.Context) {
 
 	userID := c.GetInt64("userId")
+	if !authorize(c, policy.ActionCreateEvent, userID, "You do not have permission to create events") {
+		return
+	}
 
 	var request models.CreateEventRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := models.ValidateRecurrence(request.RRule); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	if err := models.ValidateCoordinates(request.Latitude, request.Longitude); err != nil {
+		problem.Error(c, err)
 		return
 	}
 
@@ -74,11 +247,18 @@ func createEvent(c *gin.Context) {
 		Location:    request.Location,
 		DateTime:    request.DateTime,
 		UserID:      userID,
+		Capacity:    request.CapacityOr(0),
+		RRule:       request.RRule,
+		ExDates:     request.ExDates,
+		Latitude:    request.Latitude,
+		Longitude:   request.Longitude,
+		Address:     request.Address,
+		Status:      request.Status,
 	}
 
-	createdEvent, err := eventService.CreateEvent(newEvent)
+	createdEvent, err := eventService.CreateEvent(c.Request.Context(), newEvent)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, createdEvent)
@@ -86,44 +266,58 @@ func createEvent(c *gin.Context) {
 
 // updateEvent godoc
 // @Summary Update an event
-// @Description Update an existing event (requires authentication and ownership)
+// @Description Update an existing event (requires authentication and ownership, or the admin role). For recurring events, pass occurrence (the original start of an occurrence) with scope=occurrence to edit only that occurrence, or scope=following to edit it and every later occurrence; omit both to edit the whole series.
 // @Tags events
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Event ID"
+// @Param occurrence query string false "Original start of the occurrence to edit (RFC 3339)"
+// @Param scope query string false "Which occurrences to edit" Enums(occurrence, following, series)
 // @Param event body models.CreateEventRequest true "Updated event data"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 403 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events/{id} [put]
 // @Security BearerAuth
 func updateEvent(c *gin.Context) {
 	id := c.Param("id")
-	userID := c.GetInt64("userId")
 
-	// Check if the event exists and belongs to the user
+	// Check if the event exists and the user may edit it
 	event, err := eventService.GetEventByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
-	if event.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to update this event"})
+	if !authorize(c, policy.ActionUpdateEvent, event.UserID, "You do not have permission to update this event") {
 		return
 	}
 
 	// Bind the updated event data
 	var request models.CreateEventRequest
 	if err := c.ShouldBindJSON(&request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := models.ValidateRecurrence(request.RRule); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	if err := models.ValidateCoordinates(request.Latitude, request.Longitude); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	target, err := occurrenceTarget(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	// Convert id from string to int64
 	eventID, err := strconv.ParseInt(id, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid event ID"})
+		problem.Write(c, http.StatusBadRequest, "Invalid event ID")
 		return
 	}
 	updatedEvent := models.Event{
@@ -132,45 +326,58 @@ func updateEvent(c *gin.Context) {
 		Description: request.Description,
 		Location:    request.Location,
 		DateTime:    request.DateTime,
-		UserID:      userID,
+		UserID:      event.UserID, // an admin's edit keeps the owner
+		Capacity:    request.CapacityOr(event.Capacity),
+		RRule:       request.RRule,
+		ExDates:     request.ExDates,
+		Latitude:    request.Latitude,
+		Longitude:   request.Longitude,
+		Address:     request.Address,
 	}
-	if err := eventService.UpdateEvent(updatedEvent); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	result, err := eventService.UpdateEventOccurrence(c.Request.Context(), updatedEvent, target)
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "event": result})
 }
 
 // deleteEvent godoc
 // @Summary Delete an event
-// @Description Delete an existing event (requires authentication and ownership)
+// @Description Delete an existing event (requires authentication and ownership, or the admin role). For recurring events, pass occurrence with scope=occurrence to cancel a single occurrence, or scope=following to end the series before it.
 // @Tags events
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Event ID"
+// @Param occurrence query string false "Original start of the occurrence to cancel (RFC 3339)"
+// @Param scope query string false "Which occurrences to cancel" Enums(occurrence, following, series)
 // @Success 204
-// @Failure 403 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events/{id} [delete]
 // @Security BearerAuth
 func deleteEvent(c *gin.Context) {
 	id := c.Param("id")
 
-	userID := c.GetInt64("userId")
-
-	// Check if the event exists and belongs to the user
+	// Check if the event exists and the user may delete it
 	event, err := eventService.GetEventByID(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
-	if event.UserID != userID {
-		c.JSON(http.StatusForbidden, gin.H{"error": "You do not have permission to delete this event"})
+	if !authorize(c, policy.ActionDeleteEvent, event.UserID, "You do not have permission to delete this event") {
 		return
 	}
 
-	if err := eventService.DeleteEvent(id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	target, err := occurrenceTarget(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := eventService.CancelEventOccurrence(c.Request.Context(), id, target); err != nil {
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusNoContent, nil)
@@ -0,0 +1,78 @@
+package routes
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const updateBody = `{"name":"Workshop","description":"Hands-on","location":"Istanbul","date_time":"2030-01-07T18:00:00Z"}`
+
+func TestUpdateEvent_KeepsCapacity(t *testing.T) {
+	s := newTestServer(t)
+	owner := s.user(t, "owner@example.com")
+	id := s.event(t, owner, 1)
+	s.register(t, s.user(t, "first@example.com"), id)
+	waitlisted := s.register(t, s.user(t, "second@example.com"), id)
+
+	resp := s.do(http.MethodPut, "/events/"+id, owner, updateBody)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	event, err := s.events.GetEventByID(id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, event.Capacity, "an update without capacity keeps it")
+	registrations, err := s.events.GetRegistrations(waitlisted.UserID, id)
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, registrations[0].Status)
+
+	resp = s.do(http.MethodPut, "/events/"+id, owner, updateBody[:len(updateBody)-1]+`,"capacity":0}`)
+	require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+	event, err = s.events.GetEventByID(id)
+	require.NoError(t, err)
+	assert.Equal(t, 0, event.Capacity, "an explicit 0 removes the limit")
+	registrations, err = s.events.GetRegistrations(waitlisted.UserID, id)
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registrations[0].Status)
+}
+
+// requireProblem checks that resp is a problem details response with status
+func requireProblem(t *testing.T, resp *httptest.ResponseRecorder, status int) problem.Details {
+	t.Helper()
+	require.Equal(t, status, resp.Code, resp.Body.String())
+	assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+	var details problem.Details
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &details))
+	assert.Equal(t, status, details.Status)
+	return details
+}
+
+func TestUpdateAndDeleteEvent_NotFound(t *testing.T) {
+	s := newTestServer(t)
+	user := s.user(t, "user@example.com")
+
+	for _, path := range []string{"/events/42", "/events/not-a-number"} {
+		details := requireProblem(t, s.do(http.MethodPut, path, user, updateBody), http.StatusNotFound)
+		assert.Equal(t, "EVENT_NOT_FOUND", details.Reason)
+		details = requireProblem(t, s.do(http.MethodDelete, path, user, ""), http.StatusNotFound)
+		assert.Equal(t, "EVENT_NOT_FOUND", details.Reason)
+	}
+}
+
+func TestUpdateAndDeleteEvent_NotOwner(t *testing.T) {
+	s := newTestServer(t)
+	id := s.event(t, s.user(t, "owner@example.com"), 1)
+	other := s.user(t, "other@example.com")
+
+	renamed := strings.Replace(updateBody, "Workshop", "Renamed", 1)
+	requireProblem(t, s.do(http.MethodPut, "/events/"+id, other, renamed), http.StatusForbidden)
+	requireProblem(t, s.do(http.MethodDelete, "/events/"+id, other, ""), http.StatusForbidden)
+	event, err := s.events.GetEventByID(id)
+	require.NoError(t, err, "the event is not deleted")
+	assert.Equal(t, "Workshop", event.Name, "the event is not updated")
+}
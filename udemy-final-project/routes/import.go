@@ -0,0 +1,99 @@
+package routes
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+)
+
+// importEvents godoc
+// @Summary Import events from an iCalendar file
+// @Description Create one event per VEVENT in an .ics file, uploaded either as the multipart form field "file" or as the raw request body. Each VEVENT is reported separately; one bad entry does not stop the others. With dry_run=true nothing is saved and the results show what would be created.
+// @Tags events
+// @Accept multipart/form-data
+// @Accept text/calendar
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param file formData file false "iCalendar file"
+// @Param dry_run query bool false "Validate without creating events"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 413 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/import [post]
+// @Security BearerAuth
+func importEvents(c *gin.Context) {
+	userID := c.GetInt64("userId")
+	if !authorize(c, policy.ActionImportEvents, userID, "You do not have permission to import events") {
+		return
+	}
+
+	dryRun := false
+	if value := c.Query("dry_run"); value != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			problem.Write(c, http.StatusBadRequest, "dry_run must be a boolean")
+			return
+		}
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, services.MaxImportSize)
+	document := io.Reader(c.Request.Body)
+	if c.ContentType() == "multipart/form-data" {
+		file, err := c.FormFile("file")
+		if err != nil {
+			importError(c, err)
+			return
+		}
+		opened, err := file.Open()
+		if err != nil {
+			problem.Error(c, err)
+			return
+		}
+		defer opened.Close()
+		document = opened
+	}
+
+	results, err := calendarService.ImportEvents(c.Request.Context(), userID, document, dryRun)
+	if err != nil {
+		importError(c, err)
+		return
+	}
+
+	created, failed := 0, 0
+	for _, result := range results {
+		switch result.Status {
+		case services.ImportStatusCreated:
+			created++
+		case services.ImportStatusFailed:
+			failed++
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"dry_run": dryRun,
+		"created": created,
+		"failed":  failed,
+		"results": results,
+	})
+}
+
+// importError writes the response of an import that could not read the
+// uploaded calendar. Uploads over MaxImportSize are rejected with 413.
+func importError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		problem.Write(c, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, http.ErrMissingFile), errors.Is(err, http.ErrNotMultipart):
+		problem.Write(c, http.StatusBadRequest, "An .ics file is required in the \"file\" field")
+	default:
+		problem.Error(c, err)
+	}
+}
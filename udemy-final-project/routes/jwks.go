@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+)
+
+// getJWKS godoc
+// @Summary JSON Web Key Set
+// @Description Public keys that verify the access tokens issued by this service, for other services. During a key rotation both the old and the new key are listed. HMAC secrets are never published.
+// @Tags auth
+// @Produce json
+// @Success 200 {object} security.JWKS
+// @Failure 500 {object} problem.Details
+// @Router /.well-known/jwks.json [get]
+func getJWKS(c *gin.Context) {
+	keys, err := security.Keys()
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, keys.JWKS())
+}
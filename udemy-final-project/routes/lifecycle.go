@@ -0,0 +1,96 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+)
+
+// publishEvent godoc
+// @Summary Publish an event
+// @Description Publish a draft event so that it is listed publicly and open for registration (requires authentication and ownership, or the admin role)
+// @Tags events
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {object} models.Event
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 409 {object} problem.Details "Event is not a draft"
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/publish [post]
+// @Security BearerAuth
+func publishEvent(c *gin.Context) {
+	id := c.Param("id")
+	if !requireEventOwner(c, id, policy.ActionPublishEvent, "publish") {
+		return
+	}
+
+	event, err := eventService.PublishEvent(c.Request.Context(), id)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// cancelEvent godoc
+// @Summary Cancel an event
+// @Description Cancel a draft or published event with a reason (requires authentication and ownership, or the admin role). The event stays visible with its reason, existing registrations are kept and new registrations are rejected.
+// @Tags events
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Param request body models.CancelEventRequest true "Cancellation reason"
+// @Success 200 {object} models.Event
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 409 {object} problem.Details "Event is already cancelled or completed"
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/cancel [post]
+// @Security BearerAuth
+func cancelEvent(c *gin.Context) {
+	id := c.Param("id")
+	var request models.CancelEventRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !requireEventOwner(c, id, policy.ActionCancelEvent, "cancel") {
+		return
+	}
+
+	event, err := eventService.CancelEvent(c.Request.Context(), id, request.Reason)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// requireEventOwner checks that the event exists and the authenticated user
+// may perform action on it, as its owner or an admin, writing the error
+// response when not. Drafts the user may not act on are reported as not found.
+func requireEventOwner(c *gin.Context, id string, action policy.Action, verb string) bool {
+	event, err := eventService.GetEventByID(id)
+	if err != nil {
+		problem.Error(c, err)
+		return false
+	}
+	allowed := authz.Allowed(subject(c), action, policy.Resource{OwnerID: event.UserID})
+	if !allowed && !event.VisibleTo(c.GetInt64("userId")) {
+		problem.Error(c, models.ErrEventNotFound)
+		return false
+	}
+	if !allowed {
+		problem.Error(c, services.Forbidden("PERMISSION_DENIED", "You do not have permission to "+verb+" this event"))
+		return false
+	}
+	return true
+}
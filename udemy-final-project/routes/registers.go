@@ -5,64 +5,112 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
 )
 
 // registerForEvent godoc
 // @Summary Register for an event
-// @Description Register the authenticated user for a specific event
+// @Description Register the authenticated user for a specific event. When the event is at capacity the user is placed on a FIFO waitlist. A user registers for each occurrence at most once.
 // @Tags registrations
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Event ID"
-// @Success 201 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Param occurrence query string false "Original start of an occurrence of a recurring event (RFC 3339)"
+// @Param scope query string false "Register for one occurrence, it and all following, or the whole series" Enums(occurrence, following, series)
+// @Success 201 {object} map[string]interface{}
+// @Success 202 {object} map[string]interface{} "Event is full, user was waitlisted"
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 409 {object} problem.Details "Already registered, or registration is closed"
+// @Failure 500 {object} problem.Details
 // @Router /events/{id}/register [post]
 // @Security BearerAuth
 func registerForEvent(c *gin.Context) {
 	eventID := c.Param("id")
 	userID := c.GetInt64("userId")
 
-	event, err := eventService.GetEventByID(eventID)
+	if _, err := eventService.GetEventByID(eventID); err != nil {
+		problem.Error(c, err)
+		return
+	}
+
+	target, err := occurrenceTarget(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
-	if event.ID == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+
+	registration, err := eventService.RegisterForEvent(c.Request.Context(), userID, eventID, target)
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
+	if registration.Status == models.RegistrationStatusWaitlisted {
+		position, err := eventService.GetWaitlistPosition(registration)
+		if err != nil {
+			problem.Error(c, err)
+			return
+		}
+		c.JSON(http.StatusAccepted, gin.H{
+			"message":           "Event is full, you have been added to the waitlist",
+			"status":            registration.Status,
+			"waitlist_position": position,
+			"registration":      registration,
+		})
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"message":      "Successfully registered for the event",
+		"status":       registration.Status,
+		"registration": registration,
+	})
+}
 
-	if err := eventService.RegisterForEvent(userID, eventID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+// getRegistration godoc
+// @Summary Get own registrations for an event
+// @Description Get the authenticated user's registrations for a specific event, for the series or single occurrences, cancelled ones included, with when they were made and the statuses they have had.
+// @Tags registrations
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Event ID"
+// @Success 200 {array} models.Registration
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /events/{id}/register [get]
+// @Security BearerAuth
+func getRegistration(c *gin.Context) {
+	registrations, err := eventService.GetRegistrations(c.GetInt64("userId"), c.Param("id"))
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, gin.H{"message": "Successfully registered for the event"})
+	c.JSON(http.StatusOK, registrations)
 }
 
 // getUserRegistrations godoc
 // @Summary Get user registrations
-// @Description Get all events that a user has registered for
+// @Description Get all events that a user has registered for, each once
 // @Tags registrations
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "User ID"
 // @Success 200 {array} models.Event
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /users/{id}/registrations [get]
 // @Security BearerAuth
 func getUserRegistrations(c *gin.Context) {
 	userIDParam := c.Param("id")
 	userID, err := strconv.ParseInt(userIDParam, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		problem.Write(c, http.StatusBadRequest, "Invalid user ID")
 		return
 	}
 
 	registrations, err := eventService.GetUserRegistrations(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, registrations)
@@ -70,32 +118,35 @@ func getUserRegistrations(c *gin.Context) {
 
 // cancelRegistration godoc
 // @Summary Cancel event registration
-// @Description Cancel the authenticated user's registration for a specific event
+// @Description Cancel the authenticated user's registration for a specific event. The registration is kept with the cancelled status and the first waitlisted user, if any, is promoted to the freed seat. Responds 404 when the user has no such registration or it is already cancelled.
 // @Tags registrations
 // @Produce json
 // @Param Authorization header string true "Bearer token"
 // @Param id path int true "Event ID"
+// @Param occurrence query string false "Original start of the registered occurrence (RFC 3339)"
 // @Success 200 {object} map[string]string
-// @Failure 404 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /events/{id}/register [delete]
 // @Security BearerAuth
 func cancelRegistration(c *gin.Context) {
 	eventID := c.Param("id")
 	userID := c.GetInt64("userId")
 
-	event, err := eventService.GetEventByID(eventID)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if _, err := eventService.GetEventByID(eventID); err != nil {
+		problem.Error(c, err)
 		return
 	}
-	if event.ID == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Event not found"})
+
+	target, err := occurrenceTarget(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := eventService.CancelRegistration(userID, eventID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := eventService.CancelRegistration(c.Request.Context(), userID, eventID, target); err != nil {
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "Successfully canceled registration for the event"})
@@ -0,0 +1,67 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+)
+
+// subject returns the authenticated user as a policy subject
+func subject(c *gin.Context) policy.Subject {
+	return policy.Subject{UserID: c.GetInt64("userId"), Role: c.GetString("role")}
+}
+
+// authorize checks that the authenticated user may perform action on a
+// resource owned by ownerID, writing a 403 problem with message when not
+func authorize(c *gin.Context, action policy.Action, ownerID int64, message string) bool {
+	if err := authz.Authorize(subject(c), action, policy.Resource{OwnerID: ownerID}); err != nil {
+		problem.Error(c, services.Forbidden("PERMISSION_DENIED", message))
+		return false
+	}
+	return true
+}
+
+// setUserRole godoc
+// @Summary Change a user's role
+// @Description Set the role of a user to user, organizer or admin (requires the admin role). The new role applies to access tokens issued after the change, so it takes effect when the user logs in or refreshes their token.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "User ID"
+// @Param request body models.SetRoleRequest true "New role"
+// @Success 200 {object} models.User
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /users/{id}/role [put]
+// @Security BearerAuth
+func setUserRole(c *gin.Context) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	if !authorize(c, policy.ActionManageRoles, userID, "Only admins can change roles") {
+		return
+	}
+
+	var request models.SetRoleRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := userService.SetUserRole(userID, request.Role)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, user)
+}
@@ -3,22 +3,32 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/middlewares"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 )
 
 var (
-	userService  services.UserService
-	eventService services.EventService
-	authService  services.AuthService
+	userService     services.UserService
+	eventService    services.EventService
+	authService     services.AuthService
+	calendarService services.CalendarService
+	webhookService  services.WebhookService
+	authz           *policy.Policy
+	broadcaster     *stream.Broadcaster
 )
 
 // InitServices initializes the service dependencies for the routes
-func InitServices(u services.UserService, e services.EventService, a services.AuthService) {
+func InitServices(u services.UserService, e services.EventService, a services.AuthService, cal services.CalendarService, w services.WebhookService, p *policy.Policy, b *stream.Broadcaster) {
 	userService = u
 	eventService = e
 	authService = a
+	calendarService = cal
+	webhookService = w
+	authz = p
+	broadcaster = b
 }
 
 // SetupRoutes configures all the API routes for the application
@@ -27,18 +37,45 @@ func SetupRoutes(server *gin.Engine) {
 	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Public routes (no authentication required)
-	server.GET("/events", getEvents)
-	server.GET("/events/:id", getEventByID)
+	server.GET("/.well-known/jwks.json", getJWKS)
+	server.GET("/users/:id/calendar.ics", getUserCalendarFeed)
 	server.POST("/auth/register", registerUser)
 	server.POST("/auth/login", loginUser)
+	server.POST("/auth/refresh", refreshTokens)
+
+	// Public routes that also show the caller's own drafts when a token is sent
+	viewer := server.Group("/")
+	viewer.Use(middlewares.OptionalAuthenticate)
+	viewer.GET("/events", getEvents)
+	viewer.GET("/events/search", searchEvents)
+	viewer.GET("/events/nearby", getNearbyEvents)
+	viewer.GET("/events/stream", streamEvents)
+	viewer.GET("/events/:id", getEventByID)
+	viewer.GET("/events/:id/ics", getEventICS)
 
 	// Protected routes (authentication required)
 	authenticated := server.Group("/")
 	authenticated.Use(middlewares.Authenticate)
+	authenticated.POST("/auth/logout", logoutUser)
 	authenticated.POST("/events", createEvent)
+	authenticated.POST("/events/import", importEvents)
 	authenticated.PUT("/events/:id", updateEvent)
 	authenticated.DELETE("/events/:id", deleteEvent)
+	authenticated.POST("/events/:id/publish", publishEvent)
+	authenticated.POST("/events/:id/cancel", cancelEvent)
 	authenticated.POST("/events/:id/register", registerForEvent)
+	authenticated.GET("/events/:id/register", getRegistration)
 	authenticated.GET("/users/:id/registrations", getUserRegistrations)
+	authenticated.POST("/users/:id/calendar-token", rotateCalendarToken)
 	authenticated.DELETE("/events/:id/register", cancelRegistration)
+	authenticated.GET("/events/:id/registrations", getEventAttendees)
+	authenticated.DELETE("/events/:id/registrations/:registrationId", removeAttendee)
+	authenticated.PUT("/users/:id/role", setUserRole)
+	authenticated.POST("/webhooks", createWebhook)
+	authenticated.GET("/webhooks", getWebhooks)
+	authenticated.GET("/webhooks/:id", getWebhook)
+	authenticated.PUT("/webhooks/:id", updateWebhook)
+	authenticated.DELETE("/webhooks/:id", deleteWebhook)
+	authenticated.GET("/webhooks/:id/deliveries", getWebhookDeliveries)
+	authenticated.POST("/webhooks/:id/deliveries/:deliveryId/redeliver", redeliverWebhook)
 }
@@ -0,0 +1,109 @@
+package routes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/services"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testServer serves the event routes over an in-memory store. Requests are
+// authenticated as the user whose ID is in the X-User-ID header, standing in
+// for the JWT middleware.
+type testServer struct {
+	engine *gin.Engine
+	users  services.UserService
+	events services.EventService
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	auth := config.Default().Auth
+	auth.BcryptCost = bcrypt.MinCost
+	auth.EphemeralSigningKey = true
+	require.NoError(t, security.Configure(auth))
+
+	repositories := repository.NewMemory().Repositories()
+	s := &testServer{
+		users:  services.NewUserService(repositories.Users, nil),
+		events: services.NewEventService(repositories.Events, repositories.Registrations),
+	}
+	InitServices(s.users, s.events, nil, nil, nil, policy.New(), nil)
+
+	gin.SetMode(gin.TestMode)
+	s.engine = gin.New()
+	authenticated := s.engine.Group("/")
+	authenticated.Use(func(c *gin.Context) {
+		userID, err := strconv.ParseInt(c.GetHeader("X-User-ID"), 10, 64)
+		if err != nil {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Set("userId", userID)
+		c.Set("role", policy.RoleUser)
+	})
+	authenticated.PUT("/events/:id", updateEvent)
+	authenticated.DELETE("/events/:id", deleteEvent)
+	authenticated.GET("/events/:id/registrations", getEventAttendees)
+	authenticated.DELETE("/events/:id/registrations/:registrationId", removeAttendee)
+	return s
+}
+
+// user registers a user with email
+func (s *testServer) user(t *testing.T, email string) *models.User {
+	t.Helper()
+	user, err := s.users.Register(email, "secret1")
+	require.NoError(t, err)
+	return user
+}
+
+// event creates a published event of owner with capacity
+func (s *testServer) event(t *testing.T, owner *models.User, capacity int) string {
+	t.Helper()
+	event, err := s.events.CreateEvent(t.Context(), models.Event{
+		Name:        "Workshop",
+		Description: "Hands-on",
+		Location:    "Istanbul",
+		DateTime:    time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC),
+		UserID:      owner.ID,
+		Capacity:    capacity,
+		Status:      models.EventStatusPublished,
+	})
+	require.NoError(t, err)
+	return strconv.FormatInt(event.ID, 10)
+}
+
+// register registers user for the event with id
+func (s *testServer) register(t *testing.T, user *models.User, id string) *models.Registration {
+	t.Helper()
+	registration, err := s.events.RegisterForEvent(t.Context(), user.ID, id, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	return registration
+}
+
+// do sends a request as user and returns the recorded response
+func (s *testServer) do(method, path string, user *models.User, body string) *httptest.ResponseRecorder {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", strconv.FormatInt(user.ID, 10))
+	recorder := httptest.NewRecorder()
+	s.engine.ServeHTTP(recorder, req)
+	return recorder
+}
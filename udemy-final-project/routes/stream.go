@@ -0,0 +1,94 @@
+package routes
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+)
+
+// Server-Sent Events settings
+const (
+	streamHeartbeat = 15 * time.Second
+	// streamRetry is how long clients wait before reconnecting, in milliseconds
+	streamRetry = 2000
+)
+
+// streamEvents godoc
+// @Summary Stream event changes
+// @Description Push event changes as Server-Sent Events. Each change is sent as an SSE event named after its action (created, updated, deleted, published, cancelled or completed) whose data is a stream.Message, with an id to resume from. Reconnect with the Last-Event-ID header to receive the changes missed since; when they are no longer available a "resync" event is sent first and the client should reload the events it shows. Clients that fall too far behind are disconnected and resume the same way. Changes of drafts are only sent to their owner, who must send a bearer token. A comment is sent every 15 seconds to keep the connection open.
+// @Tags events
+// @Produce text/event-stream
+// @Param Last-Event-ID header string false "ID of the last change received, to resume from"
+// @Param Authorization header string false "Bearer token, to include changes of your own drafts"
+// @Success 200 {object} stream.Message "Stream of changes"
+// @Failure 401 {object} problem.Details
+// @Router /events/stream [get]
+func streamEvents(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	sub, backlog, resumed := broadcaster.Subscribe(lastID)
+	defer sub.Close()
+	viewerID := c.GetInt64("userId")
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Stop proxies such as nginx from buffering the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry)
+	if !resumed {
+		fmt.Fprint(c.Writer, "event: resync\ndata: {}\n\n")
+	}
+	for _, change := range backlog {
+		if err := writeChange(c.Writer, change, viewerID); err != nil {
+			return
+		}
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(c.Writer, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case change, ok := <-sub.Changes():
+			if !ok {
+				// Lagging clients reconnect and resume from the history
+				log.Printf("Closing event stream: %v", sub.Err())
+				return
+			}
+			if err := writeChange(c.Writer, change, viewerID); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeChange writes a change as an SSE event. For changes viewerID may not
+// see only the ID is sent, which moves the client's resume point without
+// dispatching an event.
+func writeChange(w io.Writer, change stream.Change, viewerID int64) error {
+	if !change.VisibleTo(viewerID) {
+		_, err := fmt.Fprintf(w, "id: %s\n\n", change.ID)
+		return err
+	}
+	data, err := json.Marshal(change.Message())
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", change.ID, change.Envelope.GetType(), data)
+	return err
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
 )
 
 // registerUser godoc
@@ -16,18 +18,19 @@ import (
 // @Produce json
 // @Param user body models.User true "User registration data"
 // @Success 201 {object} models.User
-// @Failure 400 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Failure 400 {object} problem.Details
+// @Failure 409 {object} problem.Details "Email already registered"
+// @Failure 500 {object} problem.Details
 // @Router /auth/register [post]
 func registerUser(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
 	registeredUser, err := userService.Register(user.Email, user.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 	c.JSON(http.StatusCreated, registeredUser)
@@ -35,20 +38,20 @@ func registerUser(c *gin.Context) {
 
 // loginUser godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return a JWT access token with a refresh token
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param credentials body models.User true "User login credentials"
-// @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Failure 500 {object} map[string]string
+// @Success 200 {object} models.TokenPair
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 500 {object} problem.Details
 // @Router /auth/login [post]
 func loginUser(c *gin.Context) {
 	var user models.User
 	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
@@ -58,19 +61,72 @@ func loginUser(c *gin.Context) {
 
 	verifiedUser, err := userService.Login(user.Email, user.Password)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
+		return
+	}
+
+	pair, err := authService.StartSession(verifiedUser)
+	if err != nil {
+		problem.Error(c, err)
 		return
 	}
-	if verifiedUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid email or password"})
+
+	c.JSON(http.StatusOK, tokenResponse("login successful", pair))
+}
+
+// refreshTokens godoc
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes the whole session.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.RefreshRequest true "Refresh token"
+// @Success 200 {object} models.TokenPair
+// @Failure 400 {object} problem.Details
+// @Failure 401 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /auth/refresh [post]
+func refreshTokens(c *gin.Context) {
+	var request models.RefreshRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	token, err := authService.GenerateToken(verifiedUser.Email, verifiedUser.ID)
+	pair, err := authService.RefreshSession(request.RefreshToken)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Error(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "login successful", "token": token})
+	c.JSON(http.StatusOK, tokenResponse("tokens refreshed", pair))
+}
+
+// logoutUser godoc
+// @Summary Logout user
+// @Description Revoke the access token and the refresh tokens of its session
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /auth/logout [post]
+func logoutUser(c *gin.Context) {
+	claims := c.MustGet("claims").(*security.Claims)
+	if err := authService.EndSession(claims); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "logout successful"})
+}
+
+// tokenResponse is the body returned when tokens are issued
+func tokenResponse(message string, pair *models.TokenPair) gin.H {
+	return gin.H{
+		"message":       message,
+		"token":         pair.AccessToken,
+		"refresh_token": pair.RefreshToken,
+		"expires_at":    pair.ExpiresAt,
+	}
 }
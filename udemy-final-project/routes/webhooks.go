@@ -0,0 +1,222 @@
+package routes
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/problem"
+)
+
+// createdWebhook is a new webhook with the secret its deliveries are signed with
+type createdWebhook struct {
+	models.Webhook
+	Secret string `json:"secret" example:"whsec_4WZ2LFQH6XK3PNAJ7TGR5MYUEB"`
+}
+
+// createWebhook godoc
+// @Summary Create a webhook
+// @Description Subscribe a URL to the changes of the authenticated user's events. Each change with one of the given actions (every action when none are given) is POSTed as a models.WebhookPayload with an X-Webhook-Signature header "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">" computed with the secret. The secret is only returned here. Failed deliveries are retried with exponential backoff.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param request body models.WebhookRequest true "Webhook URL and actions"
+// @Success 201 {object} createdWebhook
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks [post]
+// @Security BearerAuth
+func createWebhook(c *gin.Context) {
+	var request models.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !authorizeWebhooks(c) {
+		return
+	}
+
+	webhook, err := webhookService.CreateWebhook(c.GetInt64("userId"), request)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, createdWebhook{Webhook: *webhook, Secret: webhook.Secret})
+}
+
+// getWebhooks godoc
+// @Summary List webhooks
+// @Description List the authenticated user's webhooks
+// @Tags webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} models.Webhook
+// @Failure 403 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks [get]
+// @Security BearerAuth
+func getWebhooks(c *gin.Context) {
+	if !authorizeWebhooks(c) {
+		return
+	}
+	webhooks, err := webhookService.ListWebhooks(c.GetInt64("userId"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// getWebhook godoc
+// @Summary Get a webhook
+// @Description Get one of the authenticated user's webhooks
+// @Tags webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} models.Webhook
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks/{id} [get]
+// @Security BearerAuth
+func getWebhook(c *gin.Context) {
+	if !authorizeWebhooks(c) {
+		return
+	}
+	webhook, err := webhookService.GetWebhook(c.GetInt64("userId"), c.Param("id"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// updateWebhook godoc
+// @Summary Update a webhook
+// @Description Change the URL, actions and active flag of one of the authenticated user's webhooks. An inactive webhook is not sent new changes and its pending deliveries fail.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Webhook ID"
+// @Param request body models.WebhookRequest true "Webhook URL and actions"
+// @Success 200 {object} models.Webhook
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks/{id} [put]
+// @Security BearerAuth
+func updateWebhook(c *gin.Context) {
+	var request models.WebhookRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !authorizeWebhooks(c) {
+		return
+	}
+
+	webhook, err := webhookService.UpdateWebhook(c.GetInt64("userId"), c.Param("id"), request)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, webhook)
+}
+
+// deleteWebhook godoc
+// @Summary Delete a webhook
+// @Description Delete one of the authenticated user's webhooks together with its delivery log
+// @Tags webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Webhook ID"
+// @Success 200 {object} map[string]string
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks/{id} [delete]
+// @Security BearerAuth
+func deleteWebhook(c *gin.Context) {
+	if !authorizeWebhooks(c) {
+		return
+	}
+	if err := webhookService.DeleteWebhook(c.GetInt64("userId"), c.Param("id")); err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted successfully"})
+}
+
+// getWebhookDeliveries godoc
+// @Summary List webhook deliveries
+// @Description Get the delivery log of one of the authenticated user's webhooks, newest first: the status, attempts and last response of each change sent to it. Deliveries are kept for 30 days.
+// @Tags webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Webhook ID"
+// @Param page_size query int false "Number of deliveries (default 20, max 100)"
+// @Success 200 {array} models.WebhookDelivery
+// @Failure 400 {object} problem.Details
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks/{id}/deliveries [get]
+// @Security BearerAuth
+func getWebhookDeliveries(c *gin.Context) {
+	pageSize := 0
+	if value := c.Query("page_size"); value != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(value); err != nil {
+			problem.Write(c, http.StatusBadRequest, "page_size must be an integer")
+			return
+		}
+	}
+	if !authorizeWebhooks(c) {
+		return
+	}
+
+	deliveries, err := webhookService.ListDeliveries(c.GetInt64("userId"), c.Param("id"), pageSize)
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// redeliverWebhook godoc
+// @Summary Redeliver a webhook delivery
+// @Description Send a delivery of one of the authenticated user's webhooks again, with the same payload and change ID. The redelivery is a new entry in the delivery log.
+// @Tags webhooks
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path int true "Webhook ID"
+// @Param deliveryId path int true "Delivery ID"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 403 {object} problem.Details
+// @Failure 404 {object} problem.Details
+// @Failure 500 {object} problem.Details
+// @Router /webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+// @Security BearerAuth
+func redeliverWebhook(c *gin.Context) {
+	if !authorizeWebhooks(c) {
+		return
+	}
+	delivery, err := webhookService.Redeliver(c.GetInt64("userId"), c.Param("id"), c.Param("deliveryId"))
+	if err != nil {
+		problem.Error(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// authorizeWebhooks checks that the authenticated user may manage their webhooks
+func authorizeWebhooks(c *gin.Context) bool {
+	return authorize(c, policy.ActionManageWebhooks, c.GetInt64("userId"), "You do not have permission to manage webhooks")
+}
@@ -2,34 +2,105 @@
 package security
 
 import (
+	"crypto/rand"
+	"errors"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// NOTE: In production this key must come from a secure source (env/secret manager).
-var jwtKey = []byte("supersecretkey")
+// DefaultAccessTokenTTL is how long an access token is valid until Configure
+// sets another duration. Clients use a refresh token to get a new one.
+const DefaultAccessTokenTTL = 15 * time.Minute
 
-// GenerateToken creates a JWT token for the given email and user ID.
+// accessTokenTTL is how long new access tokens are valid
+var accessTokenTTL = DefaultAccessTokenTTL
+
+// Claims are the verified claims of an access token
+type Claims struct {
+	UserID    int64
+	Email     string
+	Role      string // empty for tokens issued before users had roles
+	ID        string // jti, empty for tokens issued before tokens had IDs
+	ExpiresAt time.Time
+}
+
+// GenerateToken creates a JWT token for the given email and user ID. The
+// token carries no role, so it grants the user role.
 func GenerateToken(email string, userID int64) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	token, _, err := IssueToken(email, userID, "")
+	return token, err
+}
+
+// IssueToken creates a JWT token with a random ID for the given email, user
+// ID and role, signed with the configured signing key, and returns it along
+// with its claims.
+func IssueToken(email string, userID int64, role string) (string, *Claims, error) {
+	keys, err := Keys()
+	if err != nil {
+		return "", nil, err
+	}
+	claims := &Claims{
+		UserID:    userID,
+		Email:     email,
+		Role:      role,
+		ID:        rand.Text(),
+		ExpiresAt: time.Now().Add(accessTokenTTL).Truncate(time.Second),
+	}
+	mapClaims := jwt.MapClaims{
 		"email":  email,
-		"exp":    time.Now().Add(time.Hour * 2).Unix(),
+		"exp":    claims.ExpiresAt.Unix(),
+		"iat":    time.Now().Unix(),
+		"jti":    claims.ID,
 		"userId": userID,
-	})
-	return token.SignedString(jwtKey)
+	}
+	if role != "" {
+		mapClaims["role"] = role
+	}
+	signed, err := keys.sign(mapClaims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 // ValidateToken verifies a JWT token and returns the user ID if valid.
 func ValidateToken(tokenString string) (int64, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, jwt.ErrSignatureInvalid
-		}
-		return jwtKey, nil
-	})
+	claims, err := ParseToken(tokenString)
 	if err != nil {
 		return 0, err
 	}
-	return int64(token.Claims.(jwt.MapClaims)["userId"].(float64)), nil
+	return claims.UserID, nil
+}
+
+// ParseToken verifies a JWT token against the configured verification keys
+// and returns its claims. It does not check whether the token was revoked.
+func ParseToken(tokenString string) (*Claims, error) {
+	keys, err := Keys()
+	if err != nil {
+		return nil, err
+	}
+	return keys.Parse(tokenString)
+}
+
+// Parse verifies a JWT token against the key set and returns its claims
+func (s *KeySet) Parse(tokenString string) (*Claims, error) {
+	token, err := jwt.Parse(tokenString, s.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	mapClaims := token.Claims.(jwt.MapClaims)
+	userID, ok := mapClaims["userId"].(float64)
+	if !ok {
+		return nil, errors.New("token has no user ID")
+	}
+	claims := &Claims{UserID: int64(userID)}
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.Role, _ = mapClaims["role"].(string)
+	claims.ID, _ = mapClaims["jti"].(string)
+	if exp, err := mapClaims.GetExpirationTime(); err == nil && exp != nil {
+		claims.ExpiresAt = exp.Time
+	}
+	return claims, nil
 }
@@ -0,0 +1,353 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing or verification
+const minRSABits = 2048
+
+// hmacKeyID is the kid of the HS256 key configured with the JWT secret
+const hmacKeyID = "hs256"
+
+// Key is a JWT signing or verification key identified by its kid
+type Key struct {
+	ID        string
+	Algorithm string // RS256, ES256, EdDSA or HS256
+	method    jwt.SigningMethod
+	private   any // nil for verification-only keys
+	public    any // the key used to verify signatures
+}
+
+// KeySet holds the key tokens are signed with and every key tokens are
+// accepted from. During a rotation the set holds both the old and the new key.
+type KeySet struct {
+	signing      *Key
+	verification map[string]*Key
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// processKeys is the key set loaded by Configure
+var processKeys *KeySet
+
+// defaultKeys is the key set used until Configure is called, signing with a
+// temporary key
+var defaultKeys = sync.OnceValues(func() (*KeySet, error) {
+	return LoadKeys(config.Auth{EphemeralSigningKey: true})
+})
+
+// ErrNoSigningKey is returned by LoadKeys when no key is configured and a
+// temporary one is not allowed
+var ErrNoSigningKey = errors.New("no JWT signing key configured: set JWT_SIGNING_KEY_FILE, JWT_SIGNING_KEY or JWT_SECRET " +
+	"(JWT_EPHEMERAL_KEY=true generates a temporary key for development)")
+
+// Keys returns the key set loaded by Configure. See LoadKeys for the settings.
+func Keys() (*KeySet, error) {
+	if processKeys != nil {
+		return processKeys, nil
+	}
+	return defaultKeys()
+}
+
+// Configure sets the bcrypt cost of new password hashes, how long access
+// tokens are valid and the key set tokens are signed and verified with. It is
+// called once at startup, before any token is issued.
+func Configure(cfg config.Auth) error {
+	set, err := LoadKeys(cfg)
+	if err != nil {
+		return err
+	}
+	bcryptCost = cfg.BcryptCost
+	accessTokenTTL = cfg.AccessTokenTTL
+	processKeys = set
+	return nil
+}
+
+// LoadKeys builds a key set from the auth settings:
+//   - SigningKeyFile or SigningKey: PEM private key tokens are signed with.
+//     RSA keys sign with RS256, P-256 keys with ES256 and Ed25519 keys with EdDSA.
+//   - SigningKeyID: kid of the signing key, derived from the public key by default.
+//   - VerificationKeys: PEM files ("path" or "kid=path") of further keys
+//     whose tokens are accepted, e.g. the previous key during a rotation.
+//   - JWTSecret: HS256 secret. Its tokens are always accepted, but it only
+//     signs when no private key is configured, which allows moving from HS256
+//     to an asymmetric key without logging everyone out.
+//
+// Without any key LoadKeys returns ErrNoSigningKey, unless EphemeralSigningKey
+// allows generating an Ed25519 key, whose tokens do not survive a restart.
+func LoadKeys(cfg config.Auth) (*KeySet, error) {
+	set := &KeySet{verification: map[string]*Key{}}
+
+	pemData := []byte(cfg.SigningKey.Value())
+	if path := cfg.SigningKeyFile; path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT signing key file: %w", err)
+		}
+		pemData = data
+	}
+	if len(pemData) > 0 {
+		key, err := ParseKey(cfg.SigningKeyID, pemData)
+		if err != nil {
+			return nil, fmt.Errorf("JWT signing key: %w", err)
+		}
+		if key.private == nil {
+			return nil, errors.New("JWT signing key: a private key is required")
+		}
+		set.signing = key
+	}
+
+	if secret := cfg.JWTSecret.Value(); secret != "" {
+		key := HMACKey(hmacKeyID, []byte(secret))
+		set.verification[key.ID] = key
+		if set.signing == nil {
+			set.signing = key
+		}
+	}
+
+	if set.signing == nil {
+		if !cfg.EphemeralSigningKey {
+			return nil, ErrNoSigningKey
+		}
+		log.Println("WARNING: no JWT signing key configured, generating a temporary Ed25519 key; " +
+			"tokens will not survive a restart or work across replicas")
+		_, private, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			return nil, err
+		}
+		key, err := newKey("", private)
+		if err != nil {
+			return nil, err
+		}
+		set.signing = key
+	}
+	set.verification[set.signing.ID] = set.signing
+
+	for _, entry := range cfg.VerificationKeys {
+		kid, path, found := strings.Cut(entry, "=")
+		if !found {
+			kid, path = "", entry
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading JWT verification key: %w", err)
+		}
+		key, err := ParseKey(kid, data)
+		if err != nil {
+			return nil, fmt.Errorf("JWT verification key %s: %w", path, err)
+		}
+		if err := set.AddVerificationKey(key); err != nil {
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+// NewKeySet creates a key set signing with the given key
+func NewKeySet(signing *Key) *KeySet {
+	return &KeySet{
+		signing:      signing,
+		verification: map[string]*Key{signing.ID: signing},
+	}
+}
+
+// AddVerificationKey makes tokens signed with key valid as well
+func (s *KeySet) AddVerificationKey(key *Key) error {
+	if existing, ok := s.verification[key.ID]; ok && existing != key {
+		return fmt.Errorf("duplicate JWT key ID %q", key.ID)
+	}
+	s.verification[key.ID] = key
+	return nil
+}
+
+// SigningKey returns the key new tokens are signed with
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// ParseKey reads a PEM-encoded private or public key. An empty kid is derived
+// from the public key.
+func ParseKey(kid string, pemData []byte) (*Key, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, errors.New("no PEM data found")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		parsed, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return newKey(kid, parsed)
+}
+
+// HMACKey creates an HS256 key from a shared secret
+func HMACKey(kid string, secret []byte) *Key {
+	return &Key{ID: kid, Algorithm: "HS256", method: jwt.SigningMethodHS256, private: secret, public: secret}
+}
+
+// newKey wraps a parsed private or public key, choosing the algorithm from its type
+func newKey(kid string, parsed any) (*Key, error) {
+	key := &Key{ID: kid}
+	if signer, ok := parsed.(crypto.Signer); ok {
+		key.private = parsed
+		parsed = signer.Public()
+	}
+	key.public = parsed
+
+	switch public := parsed.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.Algorithm, key.method = "RS256", jwt.SigningMethodRS256
+	case *ecdsa.PublicKey:
+		if public.Curve != elliptic.P256() {
+			return nil, errors.New("ECDSA keys must use the P-256 curve")
+		}
+		key.Algorithm, key.method = "ES256", jwt.SigningMethodES256
+	case ed25519.PublicKey:
+		key.Algorithm, key.method = "EdDSA", jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type %T", parsed)
+	}
+
+	if key.ID == "" {
+		der, err := x509.MarshalPKIXPublicKey(key.public)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(der)
+		key.ID = base64.RawURLEncoding.EncodeToString(sum[:12])
+	}
+	return key, nil
+}
+
+// sign signs claims with the signing key, setting the kid header
+func (s *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.signing.method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// keyFunc finds the verification key named by a token's kid header. Tokens
+// without a kid are checked against the signing key. The token must use the
+// key's algorithm, so a public key can never be used as an HMAC secret.
+func (s *KeySet) keyFunc(token *jwt.Token) (any, error) {
+	key := s.signing
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = s.verification[kid]; !ok {
+			return nil, fmt.Errorf("unknown key ID %q", kid)
+		}
+	}
+	if token.Method.Alg() != key.Algorithm {
+		return nil, jwt.ErrSignatureInvalid
+	}
+	return key.public, nil
+}
+
+// JWKS returns the public verification keys. HMAC secrets are never published.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range s.verification {
+		if jwk, ok := key.JWK(); ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+	// Keep the output stable, with the signing key first
+	slices.SortFunc(jwks.Keys, func(a, b JWK) int {
+		switch {
+		case a.KeyID == s.signing.ID:
+			return -1
+		case b.KeyID == s.signing.ID:
+			return 1
+		}
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+	return jwks
+}
+
+// JWK returns the public key in JWK format. It reports false for HMAC keys.
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Algorithm}
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encodeBase64URL(public.N)
+		jwk.E = encodeBase64URL(big.NewInt(int64(public.E)))
+	case *ecdsa.PublicKey:
+		// Coordinates are padded to the curve size as RFC 7518 requires
+		ecdh, err := public.ECDH()
+		if err != nil {
+			return JWK{}, false
+		}
+		point := ecdh.Bytes() // 0x04 || X || Y
+		size := (len(point) - 1) / 2
+		jwk.KeyType = "EC"
+		jwk.Curve = "P-256"
+		jwk.X = base64.RawURLEncoding.EncodeToString(point[1 : 1+size])
+		jwk.Y = base64.RawURLEncoding.EncodeToString(point[1+size:])
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// encodeBase64URL encodes a big-endian unsigned integer
+func encodeBase64URL(n *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(n.Bytes())
+}
@@ -0,0 +1,175 @@
+package security
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pemKey(t *testing.T, key any) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func pemPublicKey(t *testing.T, key any) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func testClaims(userID int64) jwt.MapClaims {
+	return jwt.MapClaims{"userId": userID, "exp": time.Now().Add(time.Minute).Unix(), "jti": "test", "role": "organizer"}
+}
+
+func TestKeySet_SignAndParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for alg, private := range map[string]any{"RS256": rsaKey, "ES256": ecKey, "EdDSA": edKey} {
+		t.Run(alg, func(t *testing.T) {
+			key, err := ParseKey("", pemKey(t, private))
+			require.NoError(t, err)
+			assert.Equal(t, alg, key.Algorithm)
+			assert.NotEmpty(t, key.ID)
+
+			keys := NewKeySet(key)
+			token, err := keys.sign(testClaims(7))
+			require.NoError(t, err)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, jwt.MapClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+			assert.Equal(t, alg, parsed.Header["alg"])
+
+			claims, err := keys.Parse(token)
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), claims.UserID)
+			assert.Equal(t, "organizer", claims.Role)
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	_, oldPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	newPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	oldKey, err := ParseKey("2030-01", pemKey(t, oldPrivate))
+	require.NoError(t, err)
+	oldToken, err := NewKeySet(oldKey).sign(testClaims(1))
+	require.NoError(t, err)
+
+	newKey, err := ParseKey("2030-02", pemKey(t, newPrivate))
+	require.NoError(t, err)
+	rotated := NewKeySet(newKey)
+
+	// Tokens of the old key are rejected until its public key is added
+	_, err = rotated.Parse(oldToken)
+	assert.Error(t, err)
+
+	oldPublic, err := ParseKey("2030-01", pemPublicKey(t, oldPrivate.Public()))
+	require.NoError(t, err)
+	require.NoError(t, rotated.AddVerificationKey(oldPublic))
+	claims, err := rotated.Parse(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), claims.UserID)
+
+	newToken, err := rotated.sign(testClaims(2))
+	require.NoError(t, err)
+	claims, err = rotated.Parse(newToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), claims.UserID)
+
+	// The key IDs of a set are unique
+	assert.Error(t, rotated.AddVerificationKey(HMACKey("2030-01", []byte("secret"))))
+
+	jwks := rotated.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "2030-02", jwks.Keys[0].KeyID)
+	assert.Equal(t, "EC", jwks.Keys[0].KeyType)
+	assert.Equal(t, "P-256", jwks.Keys[0].Curve)
+	assert.Len(t, jwks.Keys[0].X, 43)
+	assert.Len(t, jwks.Keys[0].Y, 43)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+}
+
+func TestKeySet_RejectsForgedTokens(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	key, err := ParseKey("rsa", pemKey(t, rsaKey))
+	require.NoError(t, err)
+	keys := NewKeySet(key)
+
+	// An HS256 token "signed" with the public key must not verify
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(1))
+	forged.Header["kid"] = "rsa"
+	signed, err := forged.SignedString(pemPublicKey(t, &rsaKey.PublicKey))
+	require.NoError(t, err)
+	_, err = keys.Parse(signed)
+	assert.Error(t, err)
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, testClaims(1))
+	unknown.Header["kid"] = "other"
+	signed, err = unknown.SignedString(rsaKey)
+	require.NoError(t, err)
+	_, err = keys.Parse(signed)
+	assert.Error(t, err)
+
+	// HMAC secrets are never published
+	secret := HMACKey("hs256", []byte("secret"))
+	require.NoError(t, keys.AddVerificationKey(secret))
+	assert.Len(t, keys.JWKS().Keys, 1)
+	_, ok := secret.JWK()
+	assert.False(t, ok)
+}
+
+func TestParseKey_Rejects(t *testing.T) {
+	_, err := ParseKey("", []byte("not pem"))
+	assert.Error(t, err)
+
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	_, err = ParseKey("", pemKey(t, small))
+	assert.Error(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = ParseKey("", pemKey(t, p384))
+	assert.Error(t, err)
+}
+
+func TestLoadKeys_RequiresSigningKey(t *testing.T) {
+	_, err := LoadKeys(config.Auth{})
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+
+	// Only an explicit development setting allows a temporary key
+	set, err := LoadKeys(config.Auth{EphemeralSigningKey: true})
+	require.NoError(t, err)
+	token, err := set.sign(testClaims(1))
+	require.NoError(t, err)
+	_, err = set.Parse(token)
+	assert.NoError(t, err)
+
+	set, err = LoadKeys(config.Auth{JWTSecret: config.Secret("secret")})
+	require.NoError(t, err)
+	assert.Equal(t, hmacKeyID, set.SigningKey().ID)
+}
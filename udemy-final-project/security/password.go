@@ -4,10 +4,16 @@ package security
 
 import "golang.org/x/crypto/bcrypt"
 
-// HashPassword generates a bcrypt hash for the given password.
-// A cost of 14 is used which may be adjusted in the future for performance.
+// DefaultBcryptCost is the cost of password hashes until Configure sets another
+const DefaultBcryptCost = 14
+
+// bcryptCost is the cost of new password hashes. Existing hashes keep theirs.
+var bcryptCost = DefaultBcryptCost
+
+// HashPassword generates a bcrypt hash for the given password with the
+// configured cost.
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcryptCost)
 	return string(bytes), err
 }
 
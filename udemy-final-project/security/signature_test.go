@@ -0,0 +1,38 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignWebhook(t *testing.T) {
+	sentAt := time.Unix(1700000000, 0)
+	body := []byte(`{"action":"created"}`)
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte("1700000000." + string(body)))
+	expected := "t=1700000000,v1=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, expected, SignWebhook("secret", sentAt, body))
+}
+
+func TestVerifyWebhook(t *testing.T) {
+	sentAt := time.Unix(1700000000, 0)
+	body := []byte(`{"action":"created"}`)
+	header := SignWebhook("secret", sentAt, body)
+	tolerance := 5 * time.Minute
+
+	assert.NoError(t, VerifyWebhook("secret", header, body, tolerance, sentAt.Add(time.Minute)))
+	// A receiver rotating secrets may be sent several signatures
+	assert.NoError(t, VerifyWebhook("secret", "t=1700000000,v1=00,"+header[len("t=1700000000,"):], body, tolerance, sentAt))
+
+	assert.ErrorIs(t, VerifyWebhook("other", header, body, tolerance, sentAt), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyWebhook("secret", header, []byte(`{"action":"deleted"}`), tolerance, sentAt), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyWebhook("secret", "v1=abc", body, tolerance, sentAt), ErrInvalidSignature)
+	assert.ErrorIs(t, VerifyWebhook("secret", header, body, tolerance, sentAt.Add(10*time.Minute)), ErrSignatureExpired)
+}
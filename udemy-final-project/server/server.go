@@ -0,0 +1,112 @@
+// Package server runs the REST and gRPC servers and drains them on shutdown.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+
+	"google.golang.org/grpc"
+)
+
+// Streams ends the long-lived streams of live changes, which a graceful
+// shutdown would otherwise wait for
+type Streams interface {
+	Close()
+}
+
+// HTTP is an HTTP server listening on its address
+type HTTP struct {
+	server   *http.Server
+	listener net.Listener
+	streams  Streams
+}
+
+// ListenHTTP listens on addr for the requests of handler. Listening starts
+// right away so a port in use is reported before anything else is started.
+func ListenHTTP(addr string, handler http.Handler, streams Streams) (*HTTP, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTP{server: &http.Server{Handler: handler}, listener: listener, streams: streams}, nil
+}
+
+// Addr returns the address the server listens on
+func (s *HTTP) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve serves requests until the server is shut down, when it returns nil
+func (s *HTTP) Serve() error {
+	if err := s.server.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown ends the streams, stops accepting connections and waits for the
+// requests in progress to finish. When ctx is done first, the remaining
+// connections are closed.
+func (s *HTTP) Shutdown(ctx context.Context) error {
+	s.streams.Close()
+	if err := s.server.Shutdown(ctx); err != nil {
+		if closeErr := s.server.Close(); closeErr != nil {
+			log.Printf("Error closing HTTP server: %v", closeErr)
+		}
+		return fmt.Errorf("draining HTTP requests: %w", err)
+	}
+	return nil
+}
+
+// GRPC is a gRPC server listening on its address
+type GRPC struct {
+	server   *grpc.Server
+	listener net.Listener
+	streams  Streams
+}
+
+// ListenGRPC listens on addr for the calls of server
+func ListenGRPC(addr string, server *grpc.Server, streams Streams) (*GRPC, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &GRPC{server: server, listener: listener, streams: streams}, nil
+}
+
+// Addr returns the address the server listens on
+func (s *GRPC) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Serve serves calls until the server is stopped, when it returns nil
+func (s *GRPC) Serve() error {
+	if err := s.server.Serve(s.listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown ends the streams, stops accepting calls and waits for the calls in
+// progress to finish. When ctx is done first, the remaining calls are
+// cancelled.
+func (s *GRPC) Shutdown(ctx context.Context) error {
+	s.streams.Close()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return fmt.Errorf("draining gRPC calls: %w", ctx.Err())
+	}
+}
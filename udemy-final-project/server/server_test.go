@@ -0,0 +1,98 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeStreams records being closed
+type fakeStreams struct {
+	closed atomic.Bool
+}
+
+func (s *fakeStreams) Close() { s.closed.Store(true) }
+
+func TestHTTP_ShutdownDrainsRequests(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		_, _ = io.WriteString(w, "done")
+	})
+	streams := &fakeStreams{}
+	srv, err := ListenHTTP("127.0.0.1:0", handler, streams)
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+
+	type result struct {
+		body string
+		err  error
+	}
+	responses := make(chan result, 1)
+	go func() {
+		resp, err := http.Get("http://" + srv.Addr().String())
+		if err != nil {
+			responses <- result{err: err}
+			return
+		}
+		defer func() { _ = resp.Body.Close() }()
+		body, err := io.ReadAll(resp.Body)
+		responses <- result{body: string(body), err: err}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+	assert.True(t, streams.closed.Load())
+	assert.NoError(t, <-served)
+
+	// The request in progress completed
+	response := <-responses
+	require.NoError(t, response.err)
+	assert.Equal(t, "done", response.body)
+}
+
+func TestHTTP_ShutdownDeadline(t *testing.T) {
+	started := make(chan struct{})
+	handler := http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	})
+	srv, err := ListenHTTP("127.0.0.1:0", handler, &fakeStreams{})
+	require.NoError(t, err)
+	go func() { _ = srv.Serve() }()
+	go func() {
+		if resp, err := http.Get("http://" + srv.Addr().String()); err == nil {
+			_ = resp.Body.Close()
+		}
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, srv.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestGRPC_Shutdown(t *testing.T) {
+	streams := &fakeStreams{}
+	srv, err := ListenGRPC("127.0.0.1:0", grpc.NewServer(), streams)
+	require.NoError(t, err)
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, srv.Shutdown(ctx))
+	assert.True(t, streams.closed.Load())
+	assert.NoError(t, <-served)
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/ical"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+)
+
+// uidDomain is the right-hand side of generated VEVENT UIDs
+const uidDomain = "events.udemy-go-tryout"
+
+// ErrInvalidCalendarToken is returned when a calendar feed token does not match the user
+var ErrInvalidCalendarToken = errors.New("invalid calendar token")
+
+// MaxImportSize is the largest iCalendar document accepted for import, in bytes
+const MaxImportSize = 5 << 20
+
+// Outcomes of importing a single VEVENT
+const (
+	ImportStatusCreated = "created"
+	ImportStatusValid   = "valid" // dry run: the event would have been created
+	ImportStatusFailed  = "failed"
+)
+
+// ImportResult reports the outcome of importing a single VEVENT
+type ImportResult struct {
+	Index  int           `json:"index" example:"0"`
+	UID    string        `json:"uid,omitempty" example:"abc123@google.com"`
+	Name   string        `json:"name,omitempty" example:"Go Meetup"`
+	Status string        `json:"status" example:"created"`
+	Event  *models.Event `json:"event,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// calendarServiceImpl implements CalendarService
+type calendarServiceImpl struct {
+	eventService EventService
+	repositories repository.Repositories
+}
+
+// NewCalendarService creates a new instance of CalendarService reading from
+// repositories. Imported events are created through eventService so they are
+// published like any other event.
+func NewCalendarService(eventService EventService, repositories repository.Repositories) CalendarService {
+	return &calendarServiceImpl{
+		eventService: eventService,
+		repositories: repositories,
+	}
+}
+
+func (s *calendarServiceImpl) EventCalendar(eventID string, viewerID int64) (string, error) {
+	event, err := s.eventService.GetEventByID(eventID)
+	if err != nil {
+		return "", err
+	}
+	if !event.VisibleTo(viewerID) {
+		return "", classify(models.ErrEventNotFound)
+	}
+
+	overrides, err := s.repositories.Events.Overrides([]int64{event.ID})
+	if err != nil {
+		return "", err
+	}
+
+	calendar := ical.Calendar{Name: event.Name}
+	calendar.Events = seriesVEvents(*event, overrides, ical.StatusConfirmed)
+	return calendar.Encode(), nil
+}
+
+func (s *calendarServiceImpl) UserCalendar(userID int64, token string) (string, error) {
+	if token == "" {
+		return "", classify(ErrInvalidCalendarToken)
+	}
+	user, err := s.repositories.Users.GetByCalendarTokenHash(userID, security.HashOpaqueToken(token))
+	if err != nil {
+		return "", err
+	}
+	if user == nil {
+		return "", classify(ErrInvalidCalendarToken)
+	}
+
+	registrations, err := s.repositories.Registrations.ListByUser(userID)
+	if err != nil {
+		return "", err
+	}
+	var eventIDs []int64
+	for _, registration := range registrations {
+		if registration.Event.IsRecurring() {
+			eventIDs = append(eventIDs, registration.EventID)
+		}
+	}
+	overrides, err := s.repositories.Events.Overrides(eventIDs)
+	if err != nil {
+		return "", err
+	}
+
+	calendar := ical.Calendar{Name: "My Events"}
+	for _, registration := range registrations {
+		status := ical.StatusConfirmed
+		if registration.Status == models.RegistrationStatusWaitlisted {
+			status = ical.StatusTentative
+		}
+		calendar.Events = append(calendar.Events, registrationVEvents(registration, overrides, status)...)
+	}
+	return calendar.Encode(), nil
+}
+
+func (s *calendarServiceImpl) RotateCalendarToken(userID int64) (string, error) {
+	token, hash, err := security.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	if err := s.repositories.Users.SetCalendarTokenHash(userID, hash); err != nil {
+		return "", classify(err)
+	}
+	return token, nil
+}
+
+func (s *calendarServiceImpl) ImportEvents(ctx context.Context, userID int64, r io.Reader, dryRun bool) ([]ImportResult, error) {
+	decoded, err := ical.Decode(r)
+	if err != nil {
+		return nil, classify(err)
+	}
+
+	results := make([]ImportResult, 0, len(decoded))
+	for i, vevent := range decoded {
+		result := ImportResult{Index: i, UID: vevent.UID, Name: vevent.Summary}
+		event, err := eventFromVEvent(vevent, userID)
+		if err == nil && !dryRun {
+			event, err = s.eventService.CreateEvent(ctx, *event)
+		}
+
+		switch {
+		case err != nil:
+			result.Status = ImportStatusFailed
+			result.Error = err.Error()
+		case dryRun:
+			result.Status = ImportStatusValid
+			result.Event = event
+		default:
+			result.Status = ImportStatusCreated
+			result.Event = event
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// eventFromVEvent converts an imported VEVENT into an event owned by userID
+func eventFromVEvent(vevent ical.DecodedEvent, userID int64) (*models.Event, error) {
+	switch {
+	case vevent.Err != nil:
+		return nil, vevent.Err
+	case vevent.RecurrenceID != nil:
+		return nil, errors.New("changes to a single occurrence (RECURRENCE-ID) cannot be imported")
+	case vevent.Status == ical.StatusCancelled:
+		return nil, errors.New("cancelled events are not imported")
+	case vevent.Summary == "":
+		return nil, errors.New("SUMMARY is required")
+	}
+
+	event := &models.Event{
+		Name:        vevent.Summary,
+		Description: vevent.Description,
+		Location:    vevent.Location,
+		DateTime:    vevent.Start.UTC(),
+		UserID:      userID,
+		RRule:       vevent.RRule,
+		Latitude:    vevent.Latitude,
+		Longitude:   vevent.Longitude,
+		Status:      models.EventStatusPublished,
+	}
+	// Tentative events are imported as drafts for the owner to review
+	if vevent.Status == ical.StatusTentative {
+		event.Status = models.EventStatusDraft
+	}
+	for _, exdate := range vevent.ExDates {
+		event.ExDates = append(event.ExDates, exdate.UTC())
+	}
+	if err := models.ValidateRecurrence(event.RRule); err != nil {
+		return nil, err
+	}
+	if err := models.ValidateCoordinates(event.Latitude, event.Longitude); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// eventUID returns a stable UID for an event, or for the part of its series starting at start
+func eventUID(eventID int64, start *time.Time) string {
+	if start == nil {
+		return fmt.Sprintf("event-%d@%s", eventID, uidDomain)
+	}
+	return fmt.Sprintf("event-%d-%d@%s", eventID, start.Unix(), uidDomain)
+}
+
+// baseVEvent renders the fields shared by every VEVENT of an event. Cancelled
+// events and drafts override status so calendar clients can show them as such.
+func baseVEvent(e models.Event, status string) ical.Event {
+	switch e.Status {
+	case models.EventStatusCancelled:
+		status = ical.StatusCancelled
+	case models.EventStatusDraft:
+		status = ical.StatusTentative
+	}
+	return ical.Event{
+		UID:         eventUID(e.ID, nil),
+		Summary:     e.Name,
+		Description: e.Description,
+		Location:    e.Location,
+		Latitude:    e.Latitude,
+		Longitude:   e.Longitude,
+		Start:       e.DateTime,
+		Status:      status,
+	}
+}
+
+// seriesVEvents renders an event with its recurrence and any per-occurrence overrides
+func seriesVEvents(e models.Event, overrides []models.OccurrenceOverride, status string) []ical.Event {
+	master := baseVEvent(e, status)
+	master.RRule = e.RRule
+	master.ExDates = e.ExDates
+	vevents := []ical.Event{master}
+	for _, override := range overrides {
+		if override.EventID != e.ID {
+			continue
+		}
+		recurrenceID := override.OccurrenceStart
+		vevent := baseVEvent(e, status)
+		vevent.RecurrenceID = &recurrenceID
+		vevent.Summary = override.Name
+		vevent.Description = override.Description
+		vevent.Location = override.Location
+		vevent.Start = override.DateTime
+		vevents = append(vevents, vevent)
+	}
+	return vevents
+}
+
+// registrationVEvents renders the occurrences covered by a registration
+func registrationVEvents(registration models.Registration, overrides []models.OccurrenceOverride, status string) []ical.Event {
+	event := registration.Event
+	start := registration.OccurrenceStart
+	if start == nil || !event.IsRecurring() {
+		return seriesVEvents(event, overrides, status)
+	}
+
+	if registration.Scope != models.ScopeFollowing {
+		occurrences, err := event.Occurrences(*start, start.Add(time.Second), overrides)
+		if err != nil || len(occurrences) == 0 {
+			return nil
+		}
+		vevent := baseVEvent(occurrences[0], status)
+		vevent.UID = eventUID(event.ID, start)
+		return []ical.Event{vevent}
+	}
+
+	// "This and following" registrations become their own series starting at the occurrence
+	rule, err := recurrence.Parse(event.RRule)
+	if err != nil {
+		return nil
+	}
+	index := rule.Index(event.DateTime, *start)
+	if index < 0 {
+		// The series no longer has the occurrence the registration starts at
+		return nil
+	}
+	if rule.Count > 0 {
+		rule.Count -= index
+	}
+	following := event
+	following.DateTime = *start
+	following.RRule = rule.String()
+	following.ExDates = nil
+	for _, ex := range event.ExDates {
+		if !ex.Before(*start) {
+			following.ExDates = append(following.ExDates, ex)
+		}
+	}
+	var followingOverrides []models.OccurrenceOverride
+	for _, override := range overrides {
+		if override.EventID == event.ID && !override.OccurrenceStart.Before(*start) {
+			followingOverrides = append(followingOverrides, override)
+		}
+	}
+	vevents := seriesVEvents(following, followingOverrides, status)
+	for i := range vevents {
+		vevents[i].UID = eventUID(event.ID, start)
+	}
+	return vevents
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/ical"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// seriesStart is the first occurrence of the weekly series of the tests
+var seriesStart = time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC)
+
+// newTestCalendar returns the user, event and calendar services over an in-memory store
+func newTestCalendar(t *testing.T) (UserService, EventService, CalendarService) {
+	auth := config.Default().Auth
+	auth.BcryptCost = bcrypt.MinCost
+	auth.EphemeralSigningKey = true
+	require.NoError(t, security.Configure(auth))
+
+	repositories := repository.NewMemory().Repositories()
+	events := NewEventService(repositories.Events, repositories.Registrations)
+	return NewUserService(repositories.Users, nil), events, NewCalendarService(events, repositories)
+}
+
+// weeklySeries creates a published weekly series of four occurrences owned by ownerID
+func weeklySeries(t *testing.T, events EventService, ownerID int64) string {
+	t.Helper()
+	event, err := events.CreateEvent(t.Context(), models.Event{
+		Name:        "Weekly",
+		Description: "Meetup",
+		Location:    "Istanbul",
+		DateTime:    seriesStart,
+		UserID:      ownerID,
+		RRule:       "FREQ=WEEKLY;COUNT=4",
+		Status:      models.EventStatusPublished,
+	})
+	require.NoError(t, err)
+	return strconv.FormatInt(event.ID, 10)
+}
+
+func TestCalendarService_FeedToken(t *testing.T) {
+	users, events, calendars := newTestCalendar(t)
+	user, err := users.Register("user@example.com", "secret1")
+	require.NoError(t, err)
+	id := weeklySeries(t, events, user.ID)
+	_, err = events.RegisterForEvent(t.Context(), user.ID, id, models.OccurrenceTarget{})
+	require.NoError(t, err)
+
+	_, err = calendars.UserCalendar(user.ID, "")
+	assert.ErrorIs(t, err, ErrUnauthenticated, "no token has been issued yet")
+	first, err := calendars.RotateCalendarToken(user.ID)
+	require.NoError(t, err)
+	feed, err := calendars.UserCalendar(user.ID, first)
+	require.NoError(t, err)
+	assert.Contains(t, feed, "SUMMARY:Weekly")
+	_, err = calendars.UserCalendar(user.ID+1, first)
+	assert.ErrorIs(t, err, ErrUnauthenticated, "a token only opens its own user's feed")
+
+	// Rotating revokes the previous token
+	second, err := calendars.RotateCalendarToken(user.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	_, err = calendars.UserCalendar(user.ID, first)
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	_, err = calendars.UserCalendar(user.ID, second)
+	require.NoError(t, err)
+
+	_, err = calendars.RotateCalendarToken(user.ID + 100)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestCalendarService_SplitSeries(t *testing.T) {
+	users, events, calendars := newTestCalendar(t)
+	user, err := users.Register("user@example.com", "secret1")
+	require.NoError(t, err)
+	id := weeklySeries(t, events, user.ID)
+	third := seriesStart.AddDate(0, 0, 14)
+
+	// A "this and following" registration is exported as its own series
+	_, err = events.RegisterForEvent(t.Context(), user.ID, id, models.OccurrenceTarget{Start: &third, Scope: models.ScopeFollowing})
+	require.NoError(t, err)
+	token, err := calendars.RotateCalendarToken(user.ID)
+	require.NoError(t, err)
+	feed, err := calendars.UserCalendar(user.ID, token)
+	require.NoError(t, err)
+	assert.Contains(t, feed, "DTSTART:20300121T180000Z")
+	assert.Contains(t, feed, "RRULE:FREQ=WEEKLY;COUNT=2")
+
+	// Splitting the series exports each half with the occurrences it kept
+	event, err := events.GetEventByID(id)
+	require.NoError(t, err)
+	changed := *event
+	changed.Name = "Weekly, new room"
+	changed.DateTime = third
+	changed.RRule = "" // the new series keeps the remaining occurrences
+	target, err := models.NewOccurrenceTarget(&third, models.ScopeFollowing)
+	require.NoError(t, err)
+	following, err := events.UpdateEventOccurrence(t.Context(), changed, target)
+	require.NoError(t, err)
+	require.NotEqual(t, event.ID, following.ID)
+
+	before, err := calendars.EventCalendar(id, user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, before, "DTSTART:20300107T180000Z")
+	assert.Contains(t, before, "RRULE:FREQ=WEEKLY;UNTIL=20300121T175959Z")
+	after, err := calendars.EventCalendar(strconv.FormatInt(following.ID, 10), user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, after, "SUMMARY:Weekly\\, new room")
+	assert.Contains(t, after, "DTSTART:20300121T180000Z")
+	assert.Contains(t, after, "RRULE:FREQ=WEEKLY;COUNT=2")
+}
+
+func TestRegistrationVEvents_MissingOccurrence(t *testing.T) {
+	event := models.Event{ID: 1, Name: "Weekly", DateTime: seriesStart, RRule: "FREQ=WEEKLY;COUNT=4"}
+	third, offSeries := seriesStart.AddDate(0, 0, 14), seriesStart.AddDate(0, 0, 15)
+
+	vevents := registrationVEvents(models.Registration{
+		Event: event, OccurrenceStart: &third, Scope: models.ScopeFollowing,
+	}, nil, ical.StatusConfirmed)
+	require.Len(t, vevents, 1)
+	assert.Equal(t, "FREQ=WEEKLY;COUNT=2", vevents[0].RRule)
+
+	// A start the series does not generate has no occurrences to export
+	vevents = registrationVEvents(models.Registration{
+		Event: event, OccurrenceStart: &offSeries, Scope: models.ScopeFollowing,
+	}, nil, ical.StatusConfirmed)
+	assert.Empty(t, vevents)
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/ical"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+)
+
+// Kinds of domain errors. Errors returned by the services that a client can
+// act on wrap one of them, so errors.Is(err, ErrNotFound) works whatever the
+// underlying cause. Each transport translates the kinds in one place.
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrForbidden       = errors.New("forbidden")
+	ErrConflict        = errors.New("conflict")
+	ErrValidation      = errors.New("validation failed")
+	ErrUnauthenticated = errors.New("unauthenticated")
+	// ErrAlreadyExists is the conflict of creating something that exists
+	ErrAlreadyExists = fmt.Errorf("%w: already exists", ErrConflict)
+)
+
+// Error is a domain error: its kind, a machine-readable reason such as
+// EVENT_NOT_FOUND and the message shown to clients
+type Error struct {
+	Kind    error
+	Reason  string
+	Message string
+	Err     error // underlying error, if any
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// NotFound creates an error of the ErrNotFound kind
+func NotFound(reason, message string) *Error {
+	return &Error{Kind: ErrNotFound, Reason: reason, Message: message}
+}
+
+// Forbidden creates an error of the ErrForbidden kind
+func Forbidden(reason, message string) *Error {
+	return &Error{Kind: ErrForbidden, Reason: reason, Message: message}
+}
+
+// Validation creates an error of the ErrValidation kind
+func Validation(reason, message string) *Error {
+	return &Error{Kind: ErrValidation, Reason: reason, Message: message}
+}
+
+// domainErrors gives the kind and reason of the errors of the models and the
+// packages they use
+var domainErrors = []struct {
+	err    error
+	kind   error
+	reason string
+}{
+	{models.ErrEventNotFound, ErrNotFound, "EVENT_NOT_FOUND"},
+	{models.ErrOccurrenceNotFound, ErrNotFound, "OCCURRENCE_NOT_FOUND"},
+	{models.ErrUserNotFound, ErrNotFound, "USER_NOT_FOUND"},
+	{models.ErrRegistrationNotFound, ErrNotFound, "REGISTRATION_NOT_FOUND"},
+	{models.ErrWebhookNotFound, ErrNotFound, "WEBHOOK_NOT_FOUND"},
+	{models.ErrWebhookDeliveryNotFound, ErrNotFound, "WEBHOOK_DELIVERY_NOT_FOUND"},
+
+	{policy.ErrForbidden, ErrForbidden, "PERMISSION_DENIED"},
+
+	{models.ErrEmailTaken, ErrAlreadyExists, "EMAIL_TAKEN"},
+	{models.ErrAlreadyRegistered, ErrAlreadyExists, "ALREADY_REGISTERED"},
+	{models.ErrInvalidTransition, ErrConflict, "INVALID_TRANSITION"},
+	{models.ErrRegistrationClosed, ErrConflict, "REGISTRATION_CLOSED"},
+
+	{models.ErrInvalidToken, ErrUnauthenticated, "INVALID_TOKEN"},
+	{models.ErrTokenRevoked, ErrUnauthenticated, "TOKEN_REVOKED"},
+	{models.ErrInvalidRefreshToken, ErrUnauthenticated, "INVALID_REFRESH_TOKEN"},
+	{models.ErrRefreshTokenReused, ErrUnauthenticated, "REFRESH_TOKEN_REUSED"},
+	{ErrInvalidCalendarToken, ErrUnauthenticated, "INVALID_CALENDAR_TOKEN"},
+
+	{models.ErrNotRecurring, ErrValidation, "NOT_RECURRING"},
+	{models.ErrInvalidScope, ErrValidation, "INVALID_SCOPE"},
+	{models.ErrInvalidWindow, ErrValidation, "INVALID_WINDOW"},
+	{recurrence.ErrInvalidRule, ErrValidation, "INVALID_RRULE"},
+	{models.ErrInvalidSort, ErrValidation, "INVALID_SORT"},
+	{models.ErrInvalidPageSize, ErrValidation, "INVALID_PAGE_SIZE"},
+	{models.ErrInvalidPageToken, ErrValidation, "INVALID_PAGE_TOKEN"},
+	{models.ErrEmptySearch, ErrValidation, "EMPTY_SEARCH"},
+	{models.ErrInvalidCoordinates, ErrValidation, "INVALID_COORDINATES"},
+	{models.ErrInvalidRadius, ErrValidation, "INVALID_RADIUS"},
+	{models.ErrInvalidStatus, ErrValidation, "INVALID_STATUS"},
+	{models.ErrInvalidRegistrationStatus, ErrValidation, "INVALID_REGISTRATION_STATUS"},
+	{models.ErrCancelReasonRequired, ErrValidation, "CANCEL_REASON_REQUIRED"},
+	{models.ErrInvalidRole, ErrValidation, "INVALID_ROLE"},
+	{models.ErrInvalidWebhookURL, ErrValidation, "INVALID_WEBHOOK_URL"},
+	{models.ErrInvalidWebhookAction, ErrValidation, "INVALID_WEBHOOK_ACTION"},
+	{ical.ErrInvalidCalendar, ErrValidation, "INVALID_CALENDAR"},
+}
+
+// AsError returns err as a domain error, classifying the errors of the
+// models. It reports false for unexpected errors such as database failures,
+// which transports report as internal errors.
+func AsError(err error) (*Error, bool) {
+	if err == nil {
+		return nil, false
+	}
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr, true
+	}
+	for _, known := range domainErrors {
+		if errors.Is(err, known.err) {
+			return &Error{Kind: known.kind, Reason: known.reason, Message: err.Error(), Err: err}, true
+		}
+	}
+	return nil, false
+}
+
+// classify wraps the known errors of the models in a domain error and
+// returns other errors as they are
+func classify(err error) error {
+	if domainErr, ok := AsError(err); ok {
+		return domainErr
+	}
+	return err
+}
+
+// classified returns a service result with its error classified
+func classified[T any](value T, err error) (T, error) {
+	return value, classify(err)
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/recurrence"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAsError(t *testing.T) {
+	tests := []struct {
+		err    error
+		kind   error
+		reason string
+	}{
+		{models.ErrEventNotFound, ErrNotFound, "EVENT_NOT_FOUND"},
+		{fmt.Errorf("loading event: %w", models.ErrOccurrenceNotFound), ErrNotFound, "OCCURRENCE_NOT_FOUND"},
+		{models.ErrEmailTaken, ErrAlreadyExists, "EMAIL_TAKEN"},
+		{models.ErrAlreadyRegistered, ErrAlreadyExists, "ALREADY_REGISTERED"},
+		{models.ErrRegistrationNotFound, ErrNotFound, "REGISTRATION_NOT_FOUND"},
+		{models.ErrRegistrationClosed, ErrConflict, "REGISTRATION_CLOSED"},
+		{fmt.Errorf("%w: empty rule", recurrence.ErrInvalidRule), ErrValidation, "INVALID_RRULE"},
+		{ErrInvalidCalendarToken, ErrUnauthenticated, "INVALID_CALENDAR_TOKEN"},
+		{Forbidden("PERMISSION_DENIED", "not yours"), ErrForbidden, "PERMISSION_DENIED"},
+	}
+	for _, test := range tests {
+		t.Run(test.reason, func(t *testing.T) {
+			domainErr, ok := AsError(test.err)
+			require.True(t, ok)
+			assert.Equal(t, test.reason, domainErr.Reason)
+			assert.Equal(t, test.err.Error(), domainErr.Message)
+			assert.ErrorIs(t, domainErr, test.kind)
+		})
+	}
+
+	_, ok := AsError(errors.New("connection refused"))
+	assert.False(t, ok)
+	_, ok = AsError(nil)
+	assert.False(t, ok)
+}
+
+func TestClassify(t *testing.T) {
+	err := classify(models.ErrEventNotFound)
+	// Classified errors keep matching both their kind and their cause
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, models.ErrEventNotFound)
+	assert.NotErrorIs(t, err, ErrConflict)
+
+	// An existing email is a conflict too
+	assert.ErrorIs(t, classify(models.ErrEmailTaken), ErrConflict)
+
+	unexpected := errors.New("connection refused")
+	assert.Same(t, unexpected, classify(unexpected))
+	assert.NoError(t, classify(nil))
+}
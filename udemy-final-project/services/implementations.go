@@ -2,134 +2,272 @@
 package services
 
 import (
+	"context"
 	"errors"
-	"fmt"
+	"log"
 	"strconv"
+	"strings"
+	"time"
 
-	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/kafka"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
 )
 
-// userServiceImpl implements UserService
-type userServiceImpl struct{}
+// userServiceImpl implements UserService. New users get the user role; the
+// existing users whose email is one of the admin emails are made admins by
+// PromoteAdmins, since nothing verifies the email a user registers with.
+type userServiceImpl struct {
+	users       repository.UserRepository
+	adminEmails []string
+}
 
-// NewUserService creates a new instance of UserService
-func NewUserService() UserService {
-	return &userServiceImpl{}
+// NewUserService creates a new instance of UserService storing users in users
+// and promoting the existing users with the given emails to admin
+func NewUserService(users repository.UserRepository, adminEmails []string) UserService {
+	lowered := make([]string, len(adminEmails))
+	for i, email := range adminEmails {
+		lowered[i] = strings.ToLower(email)
+	}
+	return &userServiceImpl{users: users, adminEmails: lowered}
 }
 
 func (s *userServiceImpl) Register(email, password string) (*models.User, error) {
 	user := models.User{
 		Email:    email,
 		Password: password,
+		Role:     policy.RoleUser,
 	}
 
-	if err := user.Save(); err != nil {
-		return nil, err
+	if err := s.users.Create(&user); err != nil {
+		return nil, classify(err)
 	}
 
 	return &user, nil
 }
 
 func (s *userServiceImpl) Login(email, password string) (*models.User, error) {
-	return models.VerifyUserCredentials(email, password)
+	user, err := s.users.VerifyCredentials(email, password)
+	if err != nil {
+		return nil, classify(err)
+	}
+	if user == nil {
+		return nil, &Error{Kind: ErrUnauthenticated, Reason: "INVALID_CREDENTIALS", Message: "invalid email or password"}
+	}
+	return user, nil
 }
 
-// eventServiceImpl implements EventService
+func (s *userServiceImpl) SetUserRole(userID int64, role string) (*models.User, error) {
+	return classified(s.users.SetRole(userID, role))
+}
+
+// PromoteAdmins gives the admin role to the existing users with an admin email
+func (s *userServiceImpl) PromoteAdmins() error {
+	promoted, err := s.users.PromoteAdmins(s.adminEmails)
+	if promoted > 0 {
+		log.Printf("Promoted %d users with an admin email to admin", promoted)
+	}
+	return err
+}
+
+// eventServiceImpl implements EventService. Changes are published to Kafka
+// through the outbox, which the repositories write in the same transaction as
+// the change and the OutboxRelay delivers, in the trace of the request ctx
+// carries.
 type eventServiceImpl struct {
-	producer *kafka.Producer
+	events        repository.EventRepository
+	registrations repository.RegistrationRepository
+}
+
+// NewEventService creates a new instance of EventService over the given repositories
+func NewEventService(events repository.EventRepository, registrations repository.RegistrationRepository) EventService {
+	return &eventServiceImpl{events: events, registrations: registrations}
 }
 
-// NewEventService creates a new instance of EventService with Kafka producer
-func NewEventService() EventService {
-	producer, err := kafka.NewProducer()
+func (s *eventServiceImpl) GetAllEvents() ([]models.Event, error) {
+	return classified(s.events.All())
+}
+
+func (s *eventServiceImpl) GetEventByID(id string) (*models.Event, error) {
+	eventID, err := models.ParseEventID(id)
 	if err != nil {
-		// Log error but don't fail, allow service to work without Kafka
-		producer = nil
+		return nil, classify(err)
 	}
-	return &eventServiceImpl{
-		producer: producer,
+	return classified(s.events.Get(eventID))
+}
+
+func (s *eventServiceImpl) ListEvents(query models.EventQuery) (*models.EventPage, error) {
+	return classified(s.events.List(query))
+}
+
+func (s *eventServiceImpl) SearchEvents(query models.SearchQuery) (*models.SearchPage, error) {
+	return classified(s.events.Search(query))
+}
+
+func (s *eventServiceImpl) GetNearbyEvents(query models.NearbyQuery) ([]models.NearbyEvent, error) {
+	return classified(s.events.Nearby(query))
+}
+
+func (s *eventServiceImpl) CreateEvent(ctx context.Context, event models.Event) (*models.Event, error) {
+	if err := s.events.Create(ctx, &event); err != nil {
+		return nil, classify(err)
 	}
+	return &event, nil
 }
 
-func (s *eventServiceImpl) GetAllEvents() ([]models.Event, error) {
-	return models.GetAllEvents()
+func (s *eventServiceImpl) UpdateEvent(ctx context.Context, event models.Event) error {
+	return classify(s.events.Update(ctx, &event))
 }
 
-func (s *eventServiceImpl) GetEventByID(id string) (*models.Event, error) {
-	return models.GetEventByID(id)
+func (s *eventServiceImpl) UpdateEventOccurrence(ctx context.Context, event models.Event, target models.OccurrenceTarget) (*models.Event, error) {
+	if target.IsSeries() {
+		if err := s.UpdateEvent(ctx, event); err != nil {
+			return nil, err
+		}
+		return &event, nil
+	}
+
+	if target.Scope == models.ScopeFollowing {
+		return classified(s.events.SplitSeries(ctx, event.ID, *target.Start, event))
+	}
+	return classified(s.events.UpdateOccurrence(ctx, event.ID, *target.Start, event))
 }
 
-func (s *eventServiceImpl) CreateEvent(event models.Event) (*models.Event, error) {
-	if err := event.Save(); err != nil {
-		return nil, err
+func (s *eventServiceImpl) CancelEventOccurrence(ctx context.Context, id string, target models.OccurrenceTarget) error {
+	if target.IsSeries() {
+		return s.DeleteEvent(ctx, id)
 	}
-	// Publish to Kafka
-	if s.producer != nil {
-		go func() {
-			err := s.producer.PublishEvent("created", strconv.FormatInt(event.ID, 10), event)
-			if err != nil {
-				// Log error but don't fail the operation
-				// In production, you might want to use a proper logger
-				fmt.Printf("Failed to publish event to Kafka: %v\n", err)
-			}
-		}()
+
+	eventID, err := models.ParseEventID(id)
+	if err != nil {
+		return classify(err)
 	}
-	return &event, nil
+	if target.Scope == models.ScopeFollowing {
+		deleted, err := s.events.TruncateSeries(ctx, eventID, *target.Start)
+		if err != nil {
+			return classify(err)
+		}
+		if deleted {
+			return s.DeleteEvent(ctx, id)
+		}
+		return nil
+	}
+	return classify(s.events.CancelOccurrence(ctx, eventID, *target.Start))
 }
 
-func (s *eventServiceImpl) UpdateEvent(event models.Event) error {
-	err := event.Update()
-	if err == nil && s.producer != nil {
-		go func() {
-			err := s.producer.PublishEvent("updated", strconv.FormatInt(event.ID, 10), event)
-			if err != nil {
-				fmt.Printf("Failed to publish event to Kafka: %v\n", err)
-			}
-		}()
+func (s *eventServiceImpl) DeleteEvent(ctx context.Context, id string) error {
+	eventID, err := models.ParseEventID(id)
+	if err != nil {
+		return classify(err)
 	}
-	return err
+	return classify(s.events.Delete(ctx, eventID))
 }
 
-func (s *eventServiceImpl) DeleteEvent(id string) error {
-	event, err := s.GetEventByID(id)
+func (s *eventServiceImpl) PublishEvent(ctx context.Context, id string) (*models.Event, error) {
+	eventID, err := models.ParseEventID(id)
 	if err != nil {
-		return err
+		return nil, classify(err)
 	}
-	if event == nil {
-		return errors.New("event not found")
+	return classified(s.events.Publish(ctx, eventID))
+}
+
+func (s *eventServiceImpl) CancelEvent(ctx context.Context, id string, reason string) (*models.Event, error) {
+	eventID, err := models.ParseEventID(id)
+	if err != nil {
+		return nil, classify(err)
 	}
-	err = models.DeleteEvent(id)
-	if err == nil && s.producer != nil {
-		go func() {
-			err := s.producer.PublishEvent("deleted", strconv.FormatInt(event.ID, 10), *event)
-			if err != nil {
-				fmt.Printf("Failed to publish event to Kafka: %v\n", err)
-			}
-		}()
+	return classified(s.events.Cancel(ctx, eventID, reason))
+}
+
+func (s *eventServiceImpl) CompleteEndedEvents() ([]models.Event, error) {
+	return s.events.CompleteEnded(time.Now().UTC())
+}
+
+func (s *eventServiceImpl) RegisterForEvent(ctx context.Context, userID int64, eventID string, target models.OccurrenceTarget) (*models.Registration, error) {
+	id, err := models.ParseEventID(eventID)
+	if err != nil {
+		return nil, classify(err)
 	}
-	return err
+	// Register the user for the event, or waitlist them if it is full
+	return classified(s.registrations.Register(ctx, id, userID, target))
+}
+
+func (s *eventServiceImpl) CancelRegistration(ctx context.Context, userID int64, eventID string, target models.OccurrenceTarget) error {
+	id, err := models.ParseEventID(eventID)
+	if err != nil {
+		return classify(err)
+	}
+	// Cancelling frees a seat; the first waitlisted user is promoted in the same transaction
+	return classify(s.registrations.Cancel(ctx, userID, id, target.Start))
+}
+
+func (s *eventServiceImpl) GetWaitlistPosition(registration *models.Registration) (int64, error) {
+	return s.registrations.WaitlistPosition(registration)
+}
+
+// GetRegistrations returns the registrations of a user for an event with their
+// status history, or an error of the ErrNotFound kind when there are none
+func (s *eventServiceImpl) GetRegistrations(userID int64, eventID string) ([]models.Registration, error) {
+	id, err := models.ParseEventID(eventID)
+	if err != nil {
+		return nil, classify(err)
+	}
+	registrations, err := s.registrations.ListForEvent(userID, id)
+	if err != nil {
+		return nil, classify(err)
+	}
+	if len(registrations) == 0 {
+		return nil, classify(models.ErrRegistrationNotFound)
+	}
+	return registrations, nil
 }
 
-func (s *eventServiceImpl) RegisterForEvent(userID int64, eventID string) error {
-	// Get the event to ensure it exists
-	event, err := s.GetEventByID(eventID)
+// ListEventAttendees returns a page of the registrations for an event. The
+// caller checks that the user may manage its attendees.
+func (s *eventServiceImpl) ListEventAttendees(eventID string, query models.AttendeeQuery) (*models.AttendeePage, error) {
+	id, err := models.ParseEventID(eventID)
 	if err != nil {
-		return err
+		return nil, classify(err)
 	}
+	query.EventID = id
+	return classified(s.registrations.Attendees(query))
+}
 
-	// Register the user for the event
-	return event.Register(userID)
+// ExportEventAttendees returns every registration for an event, page by page.
+// A status limits them to the confirmed or the waitlisted registrations.
+func (s *eventServiceImpl) ExportEventAttendees(eventID, status string) ([]models.Attendee, error) {
+	attendees := []models.Attendee{}
+	query := models.AttendeeQuery{Status: status, PageSize: models.MaxPageSize}
+	for {
+		page, err := s.ListEventAttendees(eventID, query)
+		if err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, page.Attendees...)
+		if page.NextPageToken == "" {
+			return attendees, nil
+		}
+		query.PageToken = page.NextPageToken
+	}
 }
 
-func (s *eventServiceImpl) CancelRegistration(userID int64, eventID string) error {
-	event := models.Event{ID: 0} // We'll need to parse eventID
-	return event.CancelEventRegistration(userID, eventID)
+// RemoveAttendee removes a registration for an event on behalf of its owner;
+// the first waitlisted users are promoted into the seats it frees
+func (s *eventServiceImpl) RemoveAttendee(ctx context.Context, eventID, registrationID string) error {
+	id, err := models.ParseEventID(eventID)
+	if err != nil {
+		return classify(err)
+	}
+	registration, err := strconv.ParseInt(registrationID, 10, 64)
+	if err != nil {
+		return classify(models.ErrRegistrationNotFound)
+	}
+	return classify(s.registrations.Remove(ctx, id, registration))
 }
 
 func (s *eventServiceImpl) GetUserRegistrations(userID int64) ([]models.Event, error) {
-	return models.GetRegistrationsByUserID(userID)
+	return classified(s.registrations.EventsOfUser(userID))
 }
 
 // authServiceImpl implements AuthService
@@ -148,3 +286,19 @@ func (s *authServiceImpl) ValidateToken(_ string) (*models.User, error) {
 	// Future enhancement: add method to fetch user from token if required.
 	return nil, errors.New("not implemented")
 }
+
+func (s *authServiceImpl) StartSession(user *models.User) (*models.TokenPair, error) {
+	return classified(models.StartSession(user))
+}
+
+func (s *authServiceImpl) RefreshSession(refreshToken string) (*models.TokenPair, error) {
+	return classified(models.RefreshSession(refreshToken))
+}
+
+func (s *authServiceImpl) EndSession(claims *security.Claims) error {
+	return classify(models.EndSession(claims))
+}
+
+func (s *authServiceImpl) PurgeExpiredTokens() error {
+	return models.PurgeExpiredTokens(time.Now().UTC())
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/policy"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/repository"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestServices returns the user and event services over an in-memory store
+func newTestServices(t *testing.T, adminEmails ...string) (UserService, EventService) {
+	auth := config.Default().Auth
+	auth.BcryptCost = bcrypt.MinCost
+	auth.EphemeralSigningKey = true
+	require.NoError(t, security.Configure(auth))
+
+	repositories := repository.NewMemory().Repositories()
+	return NewUserService(repositories.Users, adminEmails),
+		NewEventService(repositories.Events, repositories.Registrations)
+}
+
+func TestUserService_Register(t *testing.T) {
+	users, _ := newTestServices(t, "Admin@example.com")
+
+	// An admin email is not verified at registration, so it grants nothing
+	admin, err := users.Register("admin@EXAMPLE.com", "secret1")
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleUser, admin.Role)
+	require.NoError(t, users.PromoteAdmins())
+	loggedIn, err := users.Login("admin@EXAMPLE.com", "secret1")
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleAdmin, loggedIn.Role, "existing users are promoted at startup")
+
+	user, err := users.Register("user@example.com", "secret1")
+	require.NoError(t, err)
+	assert.Equal(t, policy.RoleUser, user.Role)
+
+	_, err = users.Register("user@example.com", "secret2")
+	assert.ErrorIs(t, err, ErrConflict)
+	_, err = users.Login("user@example.com", "secret2")
+	assert.ErrorIs(t, err, ErrUnauthenticated)
+	loggedIn, err = users.Login("user@example.com", "secret1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, loggedIn.ID)
+	assert.Equal(t, policy.RoleUser, loggedIn.Role)
+}
+
+func TestEventService_RegisterForEvent(t *testing.T) {
+	users, events := newTestServices(t)
+	owner, err := users.Register("owner@example.com", "secret1")
+	require.NoError(t, err)
+	first, err := users.Register("first@example.com", "secret1")
+	require.NoError(t, err)
+	second, err := users.Register("second@example.com", "secret1")
+	require.NoError(t, err)
+
+	event, err := events.CreateEvent(t.Context(), models.Event{
+		Name:        "Workshop",
+		Description: "Hands-on",
+		Location:    "Istanbul",
+		DateTime:    time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC),
+		UserID:      owner.ID,
+		Capacity:    1,
+	})
+	require.NoError(t, err)
+	id := strconv.FormatInt(event.ID, 10)
+	_, err = events.RegisterForEvent(t.Context(), first.ID, id, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, ErrConflict, "drafts are closed for registration")
+	_, err = events.PublishEvent(t.Context(), id)
+	require.NoError(t, err)
+
+	registration, err := events.RegisterForEvent(t.Context(), first.ID, id, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, event.ID, registration.EventID)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registration.Status)
+	_, err = events.RegisterForEvent(t.Context(), first.ID, id, models.OccurrenceTarget{})
+	assert.ErrorIs(t, err, ErrAlreadyExists)
+	waitlisted, err := events.RegisterForEvent(t.Context(), second.ID, id, models.OccurrenceTarget{})
+	require.NoError(t, err)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, waitlisted.Status)
+	position, err := events.GetWaitlistPosition(waitlisted)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), position)
+
+	require.NoError(t, events.CancelRegistration(t.Context(), first.ID, id, models.OccurrenceTarget{}))
+	assert.ErrorIs(t, events.CancelRegistration(t.Context(), first.ID, id, models.OccurrenceTarget{}), ErrNotFound)
+	cancelled, err := events.GetRegistrations(first.ID, id)
+	require.NoError(t, err)
+	require.Len(t, cancelled, 1)
+	assert.Equal(t, models.RegistrationStatusCancelled, cancelled[0].Status)
+	registered, err := events.GetUserRegistrations(second.ID)
+	require.NoError(t, err)
+	require.Len(t, registered, 1)
+	assert.Equal(t, "Workshop", registered[0].Name)
+	registrations, err := events.GetRegistrations(second.ID, id)
+	require.NoError(t, err)
+	require.Len(t, registrations, 1)
+	assert.Equal(t, models.RegistrationStatusConfirmed, registrations[0].Status)
+	assert.Len(t, registrations[0].History, 2)
+}
+
+func TestEventService_UnknownEvent(t *testing.T) {
+	_, events := newTestServices(t)
+
+	for _, id := range []string{"abc", "-1", "42"} {
+		_, err := events.GetEventByID(id)
+		assert.ErrorIs(t, err, ErrNotFound, id)
+		assert.ErrorIs(t, events.DeleteEvent(t.Context(), id), ErrNotFound, id)
+		_, err = events.RegisterForEvent(t.Context(), 1, id, models.OccurrenceTarget{})
+		assert.ErrorIs(t, err, ErrNotFound, id)
+	}
+}
+
+func TestEventService_Attendees(t *testing.T) {
+	users, events := newTestServices(t)
+	owner, err := users.Register("owner@example.com", "secret1")
+	require.NoError(t, err)
+	event, err := events.CreateEvent(t.Context(), models.Event{
+		Name:        "Workshop",
+		Description: "Hands-on",
+		Location:    "Istanbul",
+		DateTime:    time.Date(2030, time.January, 7, 18, 0, 0, 0, time.UTC),
+		UserID:      owner.ID,
+		Capacity:    1,
+		Status:      models.EventStatusPublished,
+	})
+	require.NoError(t, err)
+	id := strconv.FormatInt(event.ID, 10)
+	for _, email := range []string{"first@example.com", "second@example.com"} {
+		user, err := users.Register(email, "secret1")
+		require.NoError(t, err)
+		_, err = events.RegisterForEvent(t.Context(), user.ID, id, models.OccurrenceTarget{})
+		require.NoError(t, err)
+	}
+
+	page, err := events.ListEventAttendees(id, models.AttendeeQuery{PageSize: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Attendees, 1)
+	assert.Equal(t, "first@example.com", page.Attendees[0].Email)
+	assert.NotEmpty(t, page.NextPageToken)
+	_, err = events.ListEventAttendees(id, models.AttendeeQuery{Status: "cancelled"})
+	assert.ErrorIs(t, err, ErrValidation)
+	attendees, err := events.ExportEventAttendees(id, "")
+	require.NoError(t, err)
+	require.Len(t, attendees, 2)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, attendees[1].Status)
+	waitlisted, err := events.ExportEventAttendees(id, models.RegistrationStatusWaitlisted)
+	require.NoError(t, err)
+	require.Len(t, waitlisted, 1)
+	assert.Equal(t, "second@example.com", waitlisted[0].Email)
+
+	assert.ErrorIs(t, events.RemoveAttendee(t.Context(), id, "abc"), ErrNotFound)
+	require.NoError(t, events.RemoveAttendee(t.Context(), id, strconv.FormatInt(attendees[0].RegistrationID, 10)))
+	attendees, err = events.ExportEventAttendees(id, "")
+	require.NoError(t, err)
+	require.Len(t, attendees, 1)
+	assert.Equal(t, "second@example.com", attendees[0].Email)
+	assert.Equal(t, models.RegistrationStatusConfirmed, attendees[0].Status)
+}
@@ -1,24 +1,46 @@
 package services
 
 import (
+	"context"
+	"io"
+
 	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"gorm.io/gorm"
 )
 
 // UserService interface for user operations
 type UserService interface {
 	Register(email, password string) (*models.User, error)
 	Login(email, password string) (*models.User, error)
+	SetUserRole(userID int64, role string) (*models.User, error)
+	PromoteAdmins() error
 }
 
-// EventService interface for event operations
+// EventService interface for event operations. Methods changing events take
+// the context of the request, whose trace ID is published with the change.
 type EventService interface {
 	GetAllEvents() ([]models.Event, error)
+	ListEvents(query models.EventQuery) (*models.EventPage, error)
+	SearchEvents(query models.SearchQuery) (*models.SearchPage, error)
+	GetNearbyEvents(query models.NearbyQuery) ([]models.NearbyEvent, error)
 	GetEventByID(id string) (*models.Event, error)
-	CreateEvent(event models.Event) (*models.Event, error)
-	UpdateEvent(event models.Event) error
-	DeleteEvent(id string) error
-	RegisterForEvent(userID int64, eventID string) error
-	CancelRegistration(userID int64, eventID string) error
+	CreateEvent(ctx context.Context, event models.Event) (*models.Event, error)
+	UpdateEvent(ctx context.Context, event models.Event) error
+	UpdateEventOccurrence(ctx context.Context, event models.Event, target models.OccurrenceTarget) (*models.Event, error)
+	DeleteEvent(ctx context.Context, id string) error
+	PublishEvent(ctx context.Context, id string) (*models.Event, error)
+	CancelEvent(ctx context.Context, id string, reason string) (*models.Event, error)
+	CompleteEndedEvents() ([]models.Event, error)
+	CancelEventOccurrence(ctx context.Context, id string, target models.OccurrenceTarget) error
+	RegisterForEvent(ctx context.Context, userID int64, eventID string, target models.OccurrenceTarget) (*models.Registration, error)
+	CancelRegistration(ctx context.Context, userID int64, eventID string, target models.OccurrenceTarget) error
+	GetWaitlistPosition(registration *models.Registration) (int64, error)
+	GetRegistrations(userID int64, eventID string) ([]models.Registration, error)
+	ListEventAttendees(eventID string, query models.AttendeeQuery) (*models.AttendeePage, error)
+	ExportEventAttendees(eventID, status string) ([]models.Attendee, error)
+	RemoveAttendee(ctx context.Context, eventID, registrationID string) error
 	GetUserRegistrations(userID int64) ([]models.Event, error)
 }
 
@@ -26,4 +48,39 @@ type EventService interface {
 type AuthService interface {
 	GenerateToken(email string, userID int64) (string, error)
 	ValidateToken(tokenString string) (*models.User, error)
+	StartSession(user *models.User) (*models.TokenPair, error)
+	RefreshSession(refreshToken string) (*models.TokenPair, error)
+	EndSession(claims *security.Claims) error
+	PurgeExpiredTokens() error
+}
+
+// CalendarService interface for iCalendar import and export operations
+type CalendarService interface {
+	EventCalendar(eventID string, viewerID int64) (string, error)
+	UserCalendar(userID int64, token string) (string, error)
+	RotateCalendarToken(userID int64) (string, error)
+	ImportEvents(ctx context.Context, userID int64, r io.Reader, dryRun bool) ([]ImportResult, error)
+}
+
+// OutboxRelay interface for delivering the event changes queued in the outbox
+// to Kafka, or to the local broadcaster and webhooks when Kafka is disabled
+type OutboxRelay interface {
+	RelayPending() (int, error)
+	Run(ctx context.Context)
+	Shutdown() error
+}
+
+// WebhookService interface for managing a user's webhooks and delivering the
+// changes of their events to them
+type WebhookService interface {
+	CreateWebhook(userID int64, request models.WebhookRequest) (*models.Webhook, error)
+	ListWebhooks(userID int64) ([]models.Webhook, error)
+	GetWebhook(userID int64, id string) (*models.Webhook, error)
+	UpdateWebhook(userID int64, id string, request models.WebhookRequest) (*models.Webhook, error)
+	DeleteWebhook(userID int64, id string) error
+	ListDeliveries(userID int64, webhookID string, limit int) ([]models.WebhookDelivery, error)
+	Redeliver(userID int64, webhookID, deliveryID string) (*models.WebhookDelivery, error)
+	EnqueueChange(tx *gorm.DB, envelope *eventpb.EventEnvelope) error
+	DeliverPending(ctx context.Context) (int, error)
+	Run(ctx context.Context)
 }
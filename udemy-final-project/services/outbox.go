@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/kafka"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+	"gorm.io/gorm"
+)
+
+// Outbox relay settings
+const (
+	outboxBatchSize     = 100
+	outboxRetention     = 7 * 24 * time.Hour
+	outboxPurgeInterval = time.Hour
+)
+
+// outboxRelayImpl implements OutboxRelay
+type outboxRelayImpl struct {
+	producer     *kafka.Producer
+	local        *stream.Broadcaster
+	webhooks     WebhookService
+	pollInterval time.Duration
+	format       string
+	lastPurge    time.Time
+}
+
+// NewOutboxRelay creates a new instance of OutboxRelay polling the outbox at
+// the configured interval. Messages are published in the configured format:
+// "protobuf" envelopes or the legacy "json", for as long as consumers that
+// only read JSON are still running. When Kafka is disabled, changes are
+// delivered to the local broadcaster and webhooks instead.
+func NewOutboxRelay(kafkaConfig config.Kafka, outbox config.Outbox, local *stream.Broadcaster, webhooks WebhookService) OutboxRelay {
+	var producer *kafka.Producer
+	if kafkaConfig.Enabled {
+		local = nil
+		var err error
+		producer, err = kafka.NewProducer(kafkaConfig)
+		if err != nil {
+			// Messages stay in the outbox until a producer is available
+			log.Printf("Failed to create Kafka producer: %v", err)
+			producer = nil
+		}
+	} else {
+		log.Println("Kafka is disabled, event changes are streamed within this process")
+	}
+
+	return &outboxRelayImpl{
+		producer:     producer,
+		local:        local,
+		webhooks:     webhooks,
+		pollInterval: outbox.PollInterval,
+		format:       kafkaConfig.MessageFormat,
+	}
+}
+
+func (r *outboxRelayImpl) RelayPending() (int, error) {
+	if r.local != nil {
+		return models.RelayOutbox(outboxBatchSize, models.OutboxHandler{
+			// Queued with the delivery, so the two commit or roll back together
+			Record: func(tx *gorm.DB, message models.OutboxMessage) error {
+				envelope, err := outboxEnvelope(message)
+				if err != nil {
+					return err
+				}
+				return r.webhooks.EnqueueChange(tx, envelope)
+			},
+			// Streamed once the delivery is committed, so subscribers never see
+			// a change twice. Registration changes are not streamed.
+			Delivered: func(message models.OutboxMessage) {
+				if !slices.Contains(models.EventActions, message.Action) {
+					return
+				}
+				envelope, err := outboxEnvelope(message)
+				if err != nil {
+					log.Printf("Failed to stream outbox message %d: %v", message.ID, err)
+					return
+				}
+				r.local.Publish(envelope)
+			},
+		})
+	}
+	if r.producer == nil {
+		return 0, nil
+	}
+	return models.RelayOutbox(outboxBatchSize, models.OutboxHandler{Send: func(message models.OutboxMessage) error {
+		if r.format == kafka.FormatJSON {
+			return r.producer.PublishEvent(message.Action, strconv.FormatInt(message.EventID, 10), json.RawMessage(message.Payload))
+		}
+		envelope, err := outboxEnvelope(message)
+		if err != nil {
+			return err
+		}
+		return r.producer.PublishEnvelope(envelope)
+	}})
+}
+
+// outboxEnvelope builds the versioned envelope for an outbox message. A
+// message that cannot be decoded is models.ErrUndeliverable.
+func outboxEnvelope(message models.OutboxMessage) (*eventpb.EventEnvelope, error) {
+	var event models.Event
+	if err := json.Unmarshal([]byte(message.Payload), &event); err != nil {
+		return nil, fmt.Errorf("%w: %w", models.ErrUndeliverable, err)
+	}
+	id := message.MessageID
+	if id == "" {
+		// Queued before messages had IDs
+		id = "outbox-" + strconv.FormatInt(message.ID, 10)
+	}
+	envelope := kafka.NewEnvelope(message.Action, id, message.CreatedAt, message.TraceID, event.ToProto())
+	if message.Registration != "" {
+		var registration models.Registration
+		if err := json.Unmarshal([]byte(message.Registration), &registration); err != nil {
+			return nil, fmt.Errorf("%w: %w", models.ErrUndeliverable, err)
+		}
+		envelope.Registration = registration.ToProto()
+	}
+	return envelope, nil
+}
+
+func (r *outboxRelayImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Drain the backlog in batches until it is empty or delivery fails
+		for ctx.Err() == nil {
+			delivered, err := r.RelayPending()
+			if err != nil {
+				log.Printf("Failed to relay outbox messages: %v", err)
+				break
+			}
+			if delivered < outboxBatchSize {
+				break
+			}
+		}
+
+		if time.Since(r.lastPurge) >= outboxPurgeInterval {
+			r.lastPurge = time.Now()
+			if _, err := models.PurgeDeliveredOutbox(time.Now().UTC().Add(-outboxRetention)); err != nil {
+				log.Printf("Failed to purge delivered outbox messages: %v", err)
+			}
+		}
+	}
+}
+
+// Shutdown flushes and closes the Kafka producer. Run must have returned, so
+// no batch is being relayed.
+func (r *outboxRelayImpl) Shutdown() error {
+	if r.producer == nil {
+		return nil
+	}
+	return r.producer.Close()
+}
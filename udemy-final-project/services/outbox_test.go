@@ -0,0 +1,95 @@
+package services
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/db"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/stream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+// fakeWebhooks queues changes for webhooks, failing while err is set
+type fakeWebhooks struct {
+	WebhookService
+	err    error
+	queued []string
+}
+
+func (w *fakeWebhooks) EnqueueChange(_ *gorm.DB, envelope *eventpb.EventEnvelope) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.queued = append(w.queued, envelope.GetType())
+	return nil
+}
+
+func TestOutboxRelay_StreamsDeliveredChanges(t *testing.T) {
+	testDB, err := db.OpenSQLite(filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := testDB.DB()
+		_ = sqlDB.Close()
+	})
+	db.DB = testDB
+	message, err := models.NewEventMessage(t.Context(), models.EventActionPublished, models.Event{ID: 1, Status: models.EventStatusPublished})
+	require.NoError(t, err)
+	require.NoError(t, testDB.Create(&message).Error)
+
+	broadcaster := stream.NewBroadcaster(10, 10)
+	sub, _, _ := broadcaster.Subscribe("")
+	webhooks := &fakeWebhooks{err: errors.New("database unavailable")}
+	relay := NewOutboxRelay(config.Kafka{}, config.Default().Outbox, broadcaster, webhooks)
+
+	// A change that could not be queued for webhooks stays pending and is not
+	// streamed, since it is sent again
+	_, err = relay.RelayPending()
+	require.ErrorIs(t, err, webhooks.err)
+	assert.Empty(t, sub.Changes())
+	var stored models.OutboxMessage
+	require.NoError(t, testDB.First(&stored, message.ID).Error)
+	assert.Nil(t, stored.DeliveredAt)
+
+	webhooks.err = nil
+	require.NoError(t, testDB.Model(&message).Update("next_attempt_at", message.CreatedAt).Error)
+	delivered, err := relay.RelayPending()
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []string{models.EventActionPublished}, webhooks.queued)
+	require.Len(t, sub.Changes(), 1)
+	assert.Equal(t, models.EventActionPublished, (<-sub.Changes()).Envelope.GetType())
+}
+
+func TestOutboxRelay_GivesUpUndecodableMessages(t *testing.T) {
+	testDB, err := db.OpenSQLite(filepath.Join(t.TempDir(), "outbox.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		sqlDB, _ := testDB.DB()
+		_ = sqlDB.Close()
+	})
+	db.DB = testDB
+	broken := models.OutboxMessage{Action: models.EventActionUpdated, EventID: 1, Payload: "{", NextAttemptAt: time.Now().UTC()}
+	require.NoError(t, testDB.Create(&broken).Error)
+	next, err := models.NewEventMessage(t.Context(), models.EventActionUpdated, models.Event{ID: 2})
+	require.NoError(t, err)
+	require.NoError(t, testDB.Create(&next).Error)
+
+	webhooks := &fakeWebhooks{}
+	relay := NewOutboxRelay(config.Kafka{}, config.Default().Outbox, stream.NewBroadcaster(10, 10), webhooks)
+	_, err = relay.RelayPending()
+	require.ErrorIs(t, err, models.ErrUndeliverable)
+	require.NoError(t, testDB.First(&broken, broken.ID).Error)
+	assert.NotNil(t, broken.FailedAt)
+
+	delivered, err := relay.RelayPending()
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Equal(t, []string{models.EventActionUpdated}, webhooks.queued)
+}
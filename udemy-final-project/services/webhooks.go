@@ -0,0 +1,323 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery settings
+const (
+	webhookBatchSize     = 50
+	webhookWorkers       = 4
+	webhookRetention     = 30 * 24 * time.Hour
+	webhookPurgeInterval = time.Hour
+)
+
+// Headers sent with each webhook delivery
+const (
+	HeaderWebhookSignature = "X-Webhook-Signature"
+	HeaderWebhookID        = "X-Webhook-ID" // ID of the change, the same on redelivery
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookAction    = "X-Webhook-Action"
+)
+
+var (
+	// errWebhookInactive fails the pending deliveries of a webhook that was deactivated
+	errWebhookInactive = errors.New("webhook is inactive")
+	// errWebhookAddress fails deliveries to addresses inside the network the service runs in
+	errWebhookAddress = errors.New("webhook address is not public")
+)
+
+// webhookServiceImpl implements WebhookService
+type webhookServiceImpl struct {
+	client       *http.Client
+	pollInterval time.Duration
+	timeout      time.Duration
+	maxAttempts  int
+	lastPurge    time.Time
+}
+
+// NewWebhookService creates a new instance of WebhookService. Deliveries are
+// polled at the configured interval, each request times out after the
+// configured timeout and a delivery is given up after the configured attempts.
+func NewWebhookService(cfg config.Webhooks) WebhookService {
+	return &webhookServiceImpl{
+		client:       newWebhookClient(cfg.Timeout, publicAddressOnly),
+		pollInterval: cfg.PollInterval,
+		timeout:      cfg.Timeout,
+		maxAttempts:  cfg.MaxAttempts,
+	}
+}
+
+// newWebhookClient returns the client sending deliveries. Each connection is
+// checked by control once the host name was resolved, and no proxy is used,
+// so the address actually dialed is the one checked.
+func newWebhookClient(timeout time.Duration, control func(network, address string, conn syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		// A redirect is reported as a failed delivery instead of being followed
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// nonPublicPrefixes lists the special-purpose ranges (RFC 6890) webhooks may
+// not connect to
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"; Linux routes it to localhost
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, including cloud metadata endpoints
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, including the broadcast address
+	netip.MustParsePrefix("::/128"),          // unspecified
+	netip.MustParsePrefix("::1/128"),         // loopback
+	netip.MustParsePrefix("64:ff9b::/96"),    // NAT64, which maps onto any IPv4 address
+	netip.MustParsePrefix("64:ff9b:1::/48"),  // local-use NAT64
+	netip.MustParsePrefix("100::/64"),        // discard-only
+	netip.MustParsePrefix("2001:db8::/32"),   // documentation
+	netip.MustParsePrefix("2002::/16"),       // 6to4, which embeds an IPv4 address
+	netip.MustParsePrefix("fc00::/7"),        // unique local
+	netip.MustParsePrefix("fe80::/10"),       // link-local
+	netip.MustParsePrefix("ff00::/8"),        // multicast
+}
+
+// publicAddressOnly refuses connections to any address in nonPublicPrefixes,
+// so webhooks cannot reach the services next to this one or the cloud
+// metadata endpoint. It runs on the resolved address, so a host name pointed
+// elsewhere after the webhook was saved cannot get around it.
+func publicAddressOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	ip = ip.Unmap().WithZone("")
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return fmt.Errorf("%w: %s", errWebhookAddress, ip)
+		}
+	}
+	return nil
+}
+
+func (s *webhookServiceImpl) CreateWebhook(userID int64, request models.WebhookRequest) (*models.Webhook, error) {
+	webhook := webhookFromRequest(request)
+	webhook.UserID = userID
+	if err := webhook.Save(); err != nil {
+		return nil, classify(err)
+	}
+	return &webhook, nil
+}
+
+func (s *webhookServiceImpl) ListWebhooks(userID int64) ([]models.Webhook, error) {
+	return classified(models.ListWebhooks(userID))
+}
+
+func (s *webhookServiceImpl) GetWebhook(userID int64, id string) (*models.Webhook, error) {
+	return classified(models.GetWebhook(userID, id))
+}
+
+func (s *webhookServiceImpl) UpdateWebhook(userID int64, id string, request models.WebhookRequest) (*models.Webhook, error) {
+	existing, err := s.GetWebhook(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	webhook := webhookFromRequest(request)
+	webhook.ID = existing.ID
+	webhook.UserID = userID
+	if err := webhook.Update(); err != nil {
+		return nil, classify(err)
+	}
+	return s.GetWebhook(userID, id)
+}
+
+func (s *webhookServiceImpl) DeleteWebhook(userID int64, id string) error {
+	return classify(models.DeleteWebhook(userID, id))
+}
+
+func (s *webhookServiceImpl) ListDeliveries(userID int64, webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	return classified(models.ListWebhookDeliveries(userID, webhookID, limit))
+}
+
+func (s *webhookServiceImpl) Redeliver(userID int64, webhookID, deliveryID string) (*models.WebhookDelivery, error) {
+	return classified(models.RedeliverWebhook(userID, webhookID, deliveryID))
+}
+
+// webhookFromRequest builds a webhook, active unless the request says otherwise
+func webhookFromRequest(request models.WebhookRequest) models.Webhook {
+	webhook := models.Webhook{URL: request.URL, Actions: request.Actions, Active: true}
+	if request.Active != nil {
+		webhook.Active = *request.Active
+	}
+	return webhook
+}
+
+// EnqueueChange queues a change for the webhooks of the owner of its event, as part of tx
+func (s *webhookServiceImpl) EnqueueChange(tx *gorm.DB, envelope *eventpb.EventEnvelope) error {
+	if envelope.GetEvent() == nil {
+		return nil
+	}
+	payload := webhookPayload(envelope)
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = models.EnqueueWebhookDeliveries(tx, payload.Event.UserID, payload.Action, payload.ID, payload.Event.ID, body)
+	return err
+}
+
+// webhookPayload builds the body delivered for a change. Legacy messages have
+// no ID, so they get a random one and are not deduplicated.
+func webhookPayload(envelope *eventpb.EventEnvelope) models.WebhookPayload {
+	payload := models.WebhookPayload{
+		ID:     envelope.GetEventId(),
+		Action: envelope.GetType(),
+		Event:  models.EventFromProto(envelope.GetEvent()),
+	}
+	if payload.ID == "" {
+		payload.ID = rand.Text()
+	}
+	if envelope.GetOccurredAt() != nil {
+		occurredAt := envelope.GetOccurredAt().AsTime()
+		payload.OccurredAt = &occurredAt
+	}
+	if envelope.GetRegistration() != nil {
+		registration := models.RegistrationFromProto(envelope.GetRegistration())
+		payload.Registration = &registration
+	}
+	return payload
+}
+
+// DeliverPending sends one batch of due deliveries and records the outcome of
+// each. It returns how many were attempted.
+func (s *webhookServiceImpl) DeliverPending(ctx context.Context) (int, error) {
+	// Deliveries not recorded within the lease, as when this process stops, are retried
+	deliveries, err := models.ClaimWebhookDeliveries(webhookBatchSize, s.timeout+time.Minute)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, webhookWorkers)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		wg.Add(1)
+		workers <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			maxAttempts := s.maxAttempts
+			statusCode, sendErr := 0, errWebhookInactive
+			if delivery.Webhook.Active {
+				statusCode, sendErr = s.send(ctx, delivery)
+			} else {
+				maxAttempts = 0
+			}
+			if err := delivery.RecordAttempt(statusCode, sendErr, maxAttempts); err != nil {
+				log.Printf("Failed to record webhook delivery %d: %v", delivery.ID, err)
+			}
+		}()
+	}
+	wg.Wait()
+	return len(deliveries), nil
+}
+
+// send POSTs a delivery's payload to its webhook, signed with the webhook's
+// secret. Any response other than 2xx is an error. The response body is never
+// read, so nothing the webhook returns ends up in the delivery log.
+func (s *webhookServiceImpl) send(ctx context.Context, delivery *models.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.Webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "event-api-webhooks/1.0")
+	req.Header.Set(HeaderWebhookID, delivery.MessageID)
+	req.Header.Set(HeaderWebhookDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderWebhookAction, delivery.Action)
+	req.Header.Set(HeaderWebhookSignature, security.SignWebhook(delivery.Webhook.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			log.Printf("Error closing webhook response body: %v", err)
+		}
+	}()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp.StatusCode, nil
+	}
+	return resp.StatusCode, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+func (s *webhookServiceImpl) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		// Send due deliveries in batches until none are left or claiming fails
+		for ctx.Err() == nil {
+			attempted, err := s.DeliverPending(ctx)
+			if err != nil {
+				log.Printf("Failed to deliver webhooks: %v", err)
+				break
+			}
+			if attempted < webhookBatchSize {
+				break
+			}
+		}
+
+		if time.Since(s.lastPurge) >= webhookPurgeInterval {
+			s.lastPurge = time.Now()
+			if _, err := models.PurgeWebhookDeliveries(time.Now().UTC().Add(-webhookRetention)); err != nil {
+				log.Printf("Failed to purge webhook deliveries: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/config"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/kafka"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/security"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testDelivery(url string) *models.WebhookDelivery {
+	return &models.WebhookDelivery{
+		ID:        7,
+		MessageID: "change-1",
+		Action:    models.EventActionCreated,
+		Payload:   `{"id":"change-1","action":"created"}`,
+		Webhook:   models.Webhook{URL: url, Secret: "whsec_test", Active: true},
+	}
+}
+
+// localWebhookService returns a webhook service that may send to the test
+// servers, which listen on loopback
+func localWebhookService() *webhookServiceImpl {
+	service := NewWebhookService(config.Default().Webhooks).(*webhookServiceImpl)
+	service.client = newWebhookClient(service.timeout, nil)
+	return service
+}
+
+func TestWebhookSend_SignsPayload(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	service := localWebhookService()
+	delivery := testDelivery(server.URL)
+	statusCode, err := service.send(context.Background(), delivery)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "change-1", received.Header.Get(HeaderWebhookID))
+	assert.Equal(t, "7", received.Header.Get(HeaderWebhookDelivery))
+	assert.Equal(t, models.EventActionCreated, received.Header.Get(HeaderWebhookAction))
+	assert.Equal(t, delivery.Payload, string(body))
+	signature := received.Header.Get(HeaderWebhookSignature)
+	assert.NoError(t, security.VerifyWebhook("whsec_test", signature, body, time.Minute, time.Now()))
+}
+
+func TestWebhookSend_Failures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/moved" {
+			http.Redirect(w, r, "/elsewhere", http.StatusFound)
+			return
+		}
+		http.Error(w, "database is down", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	service := localWebhookService()
+
+	// The response body is not kept in the delivery log
+	statusCode, err := service.send(context.Background(), testDelivery(server.URL))
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	require.Error(t, err)
+	assert.Equal(t, "unexpected status 503 Service Unavailable", err.Error())
+
+	// Redirects are not followed
+	statusCode, err = service.send(context.Background(), testDelivery(server.URL+"/moved"))
+	assert.Equal(t, http.StatusFound, statusCode)
+	assert.Error(t, err)
+
+	server.Close()
+	statusCode, err = service.send(context.Background(), testDelivery(server.URL))
+	assert.Zero(t, statusCode)
+	assert.Error(t, err)
+}
+
+func TestWebhookSend_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("a webhook on loopback was called")
+	}))
+	defer server.Close()
+	service := NewWebhookService(config.Default().Webhooks).(*webhookServiceImpl)
+
+	statusCode, err := service.send(context.Background(), testDelivery(server.URL))
+	assert.Zero(t, statusCode)
+	assert.ErrorIs(t, err, errWebhookAddress)
+
+	for _, address := range []string{
+		"127.0.0.1:80", "[::1]:443", "10.0.0.5:80", "172.16.3.4:80", "192.168.1.1:8080",
+		"169.254.169.254:80", "[fe80::1]:80", "0.0.0.0:80", "[::]:80", "[fd00::1]:80", "[::ffff:127.0.0.1]:80",
+		"0.1.2.3:80", "100.64.0.1:80", "198.18.0.1:80", "[64:ff9b::7f00:1]:80", "[64:ff9b::a9fe:a9fe]:80",
+		"224.0.0.1:80", "[ff02::1]:80", "240.0.0.1:80", "255.255.255.255:80", "[fe80::1%eth0]:80",
+		"[2002:7f00:1::]:80", "192.0.2.1:80",
+	} {
+		assert.ErrorIs(t, publicAddressOnly("tcp", address, nil), errWebhookAddress, address)
+	}
+	for _, address := range []string{"93.184.216.34:443", "[2606:2800:220:1::1]:443"} {
+		assert.NoError(t, publicAddressOnly("tcp", address, nil), address)
+	}
+}
+
+func TestWebhookPayload(t *testing.T) {
+	occurredAt := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	envelope := kafka.NewEnvelope(models.RegistrationActionCreated, "change-1", occurredAt, "", &eventpb.Event{Id: 3, Name: "Go Meetup", UserId: 1})
+	envelope.Registration = &eventpb.Registration{Id: 9, UserId: 2, EventId: 3, Status: models.RegistrationStatusWaitlisted, CreatedAt: timestamppb.New(occurredAt)}
+
+	payload := webhookPayload(envelope)
+	assert.Equal(t, "change-1", payload.ID)
+	assert.Equal(t, models.RegistrationActionCreated, payload.Action)
+	assert.Equal(t, occurredAt, *payload.OccurredAt)
+	assert.Equal(t, "Go Meetup", payload.Event.Name)
+	require.NotNil(t, payload.Registration)
+	assert.Equal(t, int64(2), payload.Registration.UserID)
+	assert.Equal(t, models.RegistrationStatusWaitlisted, payload.Registration.Status)
+
+	// Legacy messages without an ID get a random one
+	legacy := webhookPayload(&eventpb.EventEnvelope{Type: models.EventActionUpdated, Event: &eventpb.Event{Id: 3}})
+	assert.NotEmpty(t, legacy.ID)
+	assert.Nil(t, legacy.OccurredAt)
+	assert.Nil(t, legacy.Registration)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, models.WebhookBackoff(1))
+	assert.Equal(t, 20*time.Second, models.WebhookBackoff(2))
+	assert.Equal(t, 80*time.Second, models.WebhookBackoff(4))
+	assert.Equal(t, time.Hour, models.WebhookBackoff(20))
+}
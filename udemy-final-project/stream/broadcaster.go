@@ -0,0 +1,185 @@
+// Package stream fans event changes out to live subscribers, such as the
+// Server-Sent Events endpoint. Changes get sequential IDs and the most recent
+// ones are kept, so a subscriber that reconnects can resume where it left off.
+package stream
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+)
+
+// Errors reported by a subscription once its channel is closed
+var (
+	// ErrLagged is reported when a subscriber did not keep up and was dropped
+	ErrLagged = errors.New("subscriber fell behind")
+	// ErrClosed is reported when the broadcaster was closed
+	ErrClosed = errors.New("broadcaster closed")
+)
+
+// Change is an event change with the ID subscribers resume from
+type Change struct {
+	ID       string
+	Envelope *eventpb.EventEnvelope
+}
+
+// Broadcaster delivers each published change to every subscriber. A
+// subscriber whose buffer is full is dropped instead of blocking the others;
+// it reconnects and resumes from the history.
+type Broadcaster struct {
+	mu          sync.Mutex
+	epoch       string // distinguishes IDs of this process from those of earlier runs
+	seq         uint64
+	history     []Change // ring of the most recent changes, oldest at start
+	start       int
+	historySize int
+	bufferSize  int
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+// NewBroadcaster creates a broadcaster keeping historySize changes for
+// resuming and buffering bufferSize changes per subscriber
+func NewBroadcaster(historySize, bufferSize int) *Broadcaster {
+	return &Broadcaster{
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		historySize: max(historySize, 1),
+		bufferSize:  max(bufferSize, 1),
+		subscribers: map[*Subscription]struct{}{},
+	}
+}
+
+// Publish assigns the next ID to a change and delivers it to the subscribers.
+// Once the broadcaster is closed the change is dropped and returned without an ID.
+func (b *Broadcaster) Publish(envelope *eventpb.EventEnvelope) Change {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return Change{Envelope: envelope}
+	}
+
+	b.seq++
+	change := Change{ID: b.id(b.seq), Envelope: envelope}
+	if len(b.history) < b.historySize {
+		b.history = append(b.history, change)
+	} else {
+		b.history[b.start] = change
+		b.start = (b.start + 1) % b.historySize
+	}
+
+	for sub := range b.subscribers {
+		select {
+		case sub.changes <- change:
+		default:
+			b.drop(sub, ErrLagged)
+		}
+	}
+	return change
+}
+
+// Subscribe starts receiving changes. When lastID, the ID of the last change
+// the subscriber saw, is given, the changes after it are returned as the
+// backlog; resumed is false when they are no longer all in the history, or
+// lastID is from another process, and the subscriber must reload instead.
+func (b *Broadcaster) Subscribe(lastID string) (sub *Subscription, backlog []Change, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sub = &Subscription{broadcaster: b, changes: make(chan Change, b.bufferSize)}
+	if b.closed {
+		sub.err = ErrClosed
+		close(sub.changes)
+		return sub, nil, lastID == ""
+	}
+	b.subscribers[sub] = struct{}{}
+
+	if lastID == "" {
+		return sub, nil, true
+	}
+	seq, ok := b.parseID(lastID)
+	if !ok || seq > b.seq {
+		return sub, nil, false
+	}
+	oldest := b.seq - uint64(len(b.history)) + 1
+	if seq+1 < oldest {
+		// Changes after lastID were already dropped from the history
+		return sub, nil, false
+	}
+	for i := range b.history {
+		change := b.history[(b.start+i)%len(b.history)]
+		if oldest+uint64(i) > seq {
+			backlog = append(backlog, change)
+		}
+	}
+	return sub, backlog, true
+}
+
+// Close disconnects every subscriber. Later subscriptions are closed at once.
+// Close it before stopping the servers gracefully, which wait for open streams.
+func (b *Broadcaster) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for sub := range b.subscribers {
+		b.drop(sub, ErrClosed)
+	}
+}
+
+// drop removes a subscriber and closes its channel. b.mu must be held.
+func (b *Broadcaster) drop(sub *Subscription, err error) {
+	delete(b.subscribers, sub)
+	sub.err = err
+	close(sub.changes)
+}
+
+// id formats the ID of the change with the given sequence number
+func (b *Broadcaster) id(seq uint64) string {
+	return fmt.Sprintf("%s-%d", b.epoch, seq)
+}
+
+// parseID returns the sequence number of an ID issued by this broadcaster
+func (b *Broadcaster) parseID(id string) (uint64, bool) {
+	epoch, seq, ok := strings.Cut(id, "-")
+	if !ok || epoch != b.epoch {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(seq, 10, 64)
+	return parsed, err == nil
+}
+
+// Subscription receives the changes published after it was created
+type Subscription struct {
+	broadcaster *Broadcaster
+	changes     chan Change
+	err         error
+}
+
+// Changes returns the channel of changes. It is closed when the subscriber
+// falls behind, the broadcaster is closed or the subscription is closed.
+func (s *Subscription) Changes() <-chan Change {
+	return s.changes
+}
+
+// Err reports why the channel was closed: ErrLagged, ErrClosed, or nil when
+// the subscription was closed by its owner or is still open
+func (s *Subscription) Err() error {
+	s.broadcaster.mu.Lock()
+	defer s.broadcaster.mu.Unlock()
+	return s.err
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	b := s.broadcaster
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[s]; ok {
+		delete(b.subscribers, s)
+		close(s.changes)
+	}
+}
@@ -0,0 +1,141 @@
+package stream
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+	eventpb "github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/proto/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// envelope returns a change of the event with the given ID
+func envelope(action string, eventID int64) *eventpb.EventEnvelope {
+	return &eventpb.EventEnvelope{
+		Type:       action,
+		EventId:    action + "-" + strconv.FormatInt(eventID, 10),
+		OccurredAt: timestamppb.New(time.Date(2025, 1, 10, 10, 0, 0, 0, time.UTC)),
+		Event:      &eventpb.Event{Id: eventID, Name: "Go Meetup", UserId: 1, Status: models.EventStatusPublished},
+	}
+}
+
+// receive returns the next change of sub
+func receive(t *testing.T, sub *Subscription) Change {
+	t.Helper()
+	select {
+	case change, ok := <-sub.Changes():
+		require.True(t, ok, "subscription closed: %v", sub.Err())
+		return change
+	case <-time.After(time.Second):
+		t.Fatal("no change received")
+		return Change{}
+	}
+}
+
+func TestBroadcaster_FanOut(t *testing.T) {
+	b := NewBroadcaster(10, 10)
+	first, _, _ := b.Subscribe("")
+	second, _, _ := b.Subscribe("")
+	defer first.Close()
+	defer second.Close()
+
+	published := b.Publish(envelope(models.EventActionCreated, 1))
+	assert.Equal(t, published, receive(t, first))
+	assert.Equal(t, published, receive(t, second))
+
+	// A closed subscription stops receiving without affecting the others
+	first.Close()
+	next := b.Publish(envelope(models.EventActionUpdated, 1))
+	assert.Equal(t, next, receive(t, second))
+	assert.NotEqual(t, published.ID, next.ID)
+}
+
+func TestBroadcaster_Resume(t *testing.T) {
+	b := NewBroadcaster(3, 10)
+	var ids []string
+	for id := int64(1); id <= 5; id++ {
+		ids = append(ids, b.Publish(envelope(models.EventActionCreated, id)).ID)
+	}
+
+	// Changes 4 and 5 are still in the history
+	sub, backlog, resumed := b.Subscribe(ids[2])
+	defer sub.Close()
+	assert.True(t, resumed)
+	require.Len(t, backlog, 2)
+	assert.Equal(t, ids[3], backlog[0].ID)
+	assert.Equal(t, ids[4], backlog[1].ID)
+
+	// Up to date: nothing to replay
+	_, backlog, resumed = b.Subscribe(ids[4])
+	assert.True(t, resumed)
+	assert.Empty(t, backlog)
+
+	// Change 2 was dropped from the history, so the gap cannot be filled
+	_, backlog, resumed = b.Subscribe(ids[0])
+	assert.False(t, resumed)
+	assert.Empty(t, backlog)
+
+	// IDs of another process or malformed IDs cannot be resumed from
+	for _, id := range []string{"other-3", "garbage", NewBroadcaster(3, 10).id(1)} {
+		_, _, resumed = b.Subscribe(id)
+		assert.False(t, resumed, id)
+	}
+}
+
+func TestBroadcaster_DropsLaggingSubscriber(t *testing.T) {
+	b := NewBroadcaster(10, 2)
+	slow, _, _ := b.Subscribe("")
+	fast, _, _ := b.Subscribe("")
+	defer fast.Close()
+
+	for id := int64(1); id <= 4; id++ {
+		// Publishing never blocks on the slow subscriber's full buffer
+		b.Publish(envelope(models.EventActionUpdated, id))
+		assert.Equal(t, id, receive(t, fast).Envelope.GetEvent().GetId())
+	}
+
+	var received int
+	for range slow.Changes() {
+		received++
+	}
+	assert.Equal(t, 2, received)
+	assert.ErrorIs(t, slow.Err(), ErrLagged)
+}
+
+func TestBroadcaster_Close(t *testing.T) {
+	b := NewBroadcaster(10, 10)
+	sub, _, _ := b.Subscribe("")
+	b.Close()
+
+	_, ok := <-sub.Changes()
+	assert.False(t, ok)
+	assert.ErrorIs(t, sub.Err(), ErrClosed)
+
+	late, _, _ := b.Subscribe("")
+	_, ok = <-late.Changes()
+	assert.False(t, ok)
+	// Changes published after closing are dropped without using up an ID
+	assert.Empty(t, b.Publish(&eventpb.EventEnvelope{}).ID)
+	assert.Zero(t, b.seq)
+	// Closing an already dropped subscription is harmless
+	sub.Close()
+}
+
+func TestChange_Message(t *testing.T) {
+	change := Change{ID: "x-1", Envelope: envelope(models.EventActionCancelled, 7)}
+	message := change.Message()
+	assert.Equal(t, models.EventActionCancelled, message.Action)
+	assert.Equal(t, int64(7), message.Event.ID)
+	assert.Equal(t, "Go Meetup", message.Event.Name)
+	require.NotNil(t, message.OccurredAt)
+	assert.True(t, change.VisibleTo(0))
+
+	// Changes of drafts are only visible to their owner
+	change.Envelope.Event.Status = models.EventStatusDraft
+	assert.False(t, change.VisibleTo(0))
+	assert.False(t, change.VisibleTo(2))
+	assert.True(t, change.VisibleTo(1))
+}
@@ -0,0 +1,34 @@
+package stream
+
+import (
+	"time"
+
+	"github.com/gurkanindibay/udemy-go-tryout/udemy-final-project/models"
+)
+
+// Message is a change as sent to clients: the action and the event after it,
+// in the same JSON form as the REST API
+type Message struct {
+	Action     string       `json:"action" example:"updated"`
+	OccurredAt *time.Time   `json:"occurred_at,omitempty" example:"2025-01-10T10:00:00Z"`
+	Event      models.Event `json:"event"`
+}
+
+// Message returns the change as sent to clients
+func (c Change) Message() Message {
+	message := Message{
+		Action: c.Envelope.GetType(),
+		Event:  models.EventFromProto(c.Envelope.GetEvent()),
+	}
+	if c.Envelope.GetOccurredAt() != nil {
+		occurredAt := c.Envelope.GetOccurredAt().AsTime()
+		message.OccurredAt = &occurredAt
+	}
+	return message
+}
+
+// VisibleTo reports whether viewerID may see the change: changes of drafts
+// are only sent to their owner
+func (c Change) VisibleTo(viewerID int64) bool {
+	return models.EventFromProto(c.Envelope.GetEvent()).VisibleTo(viewerID)
+}
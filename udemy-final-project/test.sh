@@ -135,7 +135,7 @@ test_rest_api() {
     log_info "Test 3: Get Events (empty list expected)"
     local events_response=$(curl -s -X GET "$API_BASE_URL/events")
 
-    if echo "$events_response" | jq -e '. | length == 0' > /dev/null 2>&1; then
+    if echo "$events_response" | jq -e '.events | length == 0' > /dev/null 2>&1; then
         log_success "Get events successful (empty list as expected)"
     else
         log_error "Get events failed or returned unexpected data: $events_response"
@@ -151,7 +151,8 @@ test_rest_api() {
             "name": "Test Event",
             "description": "This is a test event",
             "location": "Test Location",
-            "date_time": "2025-12-25T10:00:00Z"
+            "date_time": "2030-12-25T10:00:00Z",
+            "status": "published"
         }')
 
     local event_id=$(echo "$create_event_response" | jq -r '.id')
@@ -166,7 +167,7 @@ test_rest_api() {
     log_info "Test 5: Get Events (should return 1 event)"
     events_response=$(curl -s -X GET "$API_BASE_URL/events")
 
-    if echo "$events_response" | jq -e '. | length == 1' > /dev/null 2>&1; then
+    if echo "$events_response" | jq -e '.events | length == 1' > /dev/null 2>&1; then
         log_success "Get events successful (1 event returned)"
     else
         log_error "Get events failed or returned wrong count: $events_response"
@@ -193,7 +194,7 @@ test_rest_api() {
             "name": "Updated Test Event",
             "description": "This is an updated test event",
             "location": "Updated Test Location",
-            "date_time": "2025-12-26T11:00:00Z"
+            "date_time": "2030-12-26T11:00:00Z"
         }')
 
     if [ "$update_response" = "{}" ] || echo "$update_response" | jq -e 'has("message")' > /dev/null 2>&1; then
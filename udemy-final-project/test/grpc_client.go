@@ -93,6 +93,7 @@ func main() {
 		Description: "This is a test event created via gRPC",
 		Location:    "gRPC Test Location",
 		DateTime:    timestamppb.New(time.Now().Add(24 * time.Hour)),
+		Status:      "published",
 	}
 
 	createEventResp, err := eventClient.CreateEvent(createAuthContext(), createEventReq)
@@ -0,0 +1,87 @@
+// Package tracing carries the W3C trace ID of a request in its context, so
+// the changes the request makes are published with the trace they belong to.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// Header is the W3C Trace Context header, and the gRPC metadata key, carrying
+// the trace of a request
+const Header = "traceparent"
+
+// traceIDKey is the context key of the trace ID
+type traceIDKey struct{}
+
+// NewID returns a random W3C trace ID (16 bytes, hex-encoded)
+func NewID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id) // crypto/rand.Read never fails
+	return hex.EncodeToString(id)
+}
+
+// WithID returns a copy of ctx carrying the trace ID id
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// ID returns the trace ID carried by ctx, or "" when it carries none
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
+
+// IDOrNew returns the trace ID carried by ctx, or a new one when it carries
+// none, as for changes made by background jobs
+func IDOrNew(ctx context.Context) string {
+	if id := ID(ctx); id != "" {
+		return id
+	}
+	return NewID()
+}
+
+// FromTraceparent returns ctx carrying the trace ID of a traceparent header
+// ("00-<trace ID>-<parent ID>-<flags>"), or a new trace ID when the header
+// is missing or invalid
+func FromTraceparent(ctx context.Context, traceparent string) context.Context {
+	id, ok := ParseTraceparent(traceparent)
+	if !ok {
+		id = NewID()
+	}
+	return WithID(ctx, id)
+}
+
+// ParseTraceparent returns the trace ID of a traceparent header. It reports
+// false for malformed headers and the all-zero trace ID, which is invalid.
+func ParseTraceparent(traceparent string) (string, bool) {
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", false
+	}
+	// Version 00 has exactly four fields, later versions may append more
+	if parts[0] == "00" && len(parts) != 4 {
+		return "", false
+	}
+	for _, part := range parts[:4] {
+		if !isLowerHex(part) {
+			return "", false
+		}
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// isLowerHex reports whether s only has lowercase hex digits
+func isLowerHex(s string) bool {
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,71 @@
+// Package worker runs background workers, such as the Kafka consumers and the
+// polling jobs, and stops them together on shutdown.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Group runs workers until it is shut down
+type Group struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	running map[string]int
+	done    sync.WaitGroup
+}
+
+// NewGroup creates an empty group of workers
+func NewGroup() *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel, running: map[string]int{}}
+}
+
+// Go starts a worker in a goroutine. Its context is cancelled on shutdown,
+// after which it should return promptly.
+func (g *Group) Go(name string, run func(ctx context.Context)) {
+	g.mu.Lock()
+	g.running[name]++
+	g.mu.Unlock()
+
+	g.done.Add(1)
+	go func() {
+		defer g.done.Done()
+		defer func() {
+			g.mu.Lock()
+			defer g.mu.Unlock()
+			if g.running[name]--; g.running[name] == 0 {
+				delete(g.running, name)
+			}
+		}()
+		log.Printf("Starting %s...", name)
+		run(g.ctx)
+	}()
+}
+
+// Shutdown cancels the workers and waits for them to return. When ctx is done
+// first, it reports the workers still running.
+func (g *Group) Shutdown(ctx context.Context) error {
+	g.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		g.done.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		names := slices.Sorted(maps.Keys(g.running))
+		return fmt.Errorf("workers still running: %s: %w", strings.Join(names, ", "), ctx.Err())
+	}
+}
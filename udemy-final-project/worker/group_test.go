@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup_ShutdownStopsWorkers(t *testing.T) {
+	group := NewGroup()
+	var stopped atomic.Int32
+	for range 3 {
+		group.Go("poller", func(ctx context.Context) {
+			<-ctx.Done()
+			stopped.Add(1)
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	assert.NoError(t, group.Shutdown(ctx))
+	assert.Equal(t, int32(3), stopped.Load())
+}
+
+func TestGroup_ShutdownDeadline(t *testing.T) {
+	group := NewGroup()
+	release := make(chan struct{})
+	defer close(release)
+	group.Go("stuck", func(context.Context) { <-release })
+	group.Go("poller", func(ctx context.Context) { <-ctx.Done() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := group.Shutdown(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "workers still running: stuck")
+	assert.NotContains(t, err.Error(), "poller")
+}